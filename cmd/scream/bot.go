@@ -0,0 +1,85 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/JamesPrial/go-scream/internal/app"
+	"github.com/JamesPrial/go-scream/internal/bot"
+	"github.com/JamesPrial/go-scream/internal/config"
+	"github.com/JamesPrial/go-scream/internal/encoding"
+	"github.com/JamesPrial/go-scream/internal/scream"
+)
+
+var botCmd = &cobra.Command{
+	Use:   "bot",
+	Short: "Run a long-lived Discord bot that answers /scream slash commands",
+	Long: `Run a long-lived Discord bot that keeps one gateway session open and
+answers /scream slash commands. Audio and channel flags set the defaults for
+options a user leaves out. When a guild ID is configured (--guild or
+SCREAM_GUILD_ID) the command is registered in that guild only, which takes
+effect immediately; otherwise it is registered globally.`,
+	Args: cobra.NoArgs,
+	RunE: runBot,
+}
+
+var botGuildFlag string
+
+func init() {
+	rootCmd.AddCommand(botCmd)
+	botCmd.Flags().StringVar(&tokenFlag, "token", "", "Discord bot token")
+	botCmd.Flags().StringVar(&botGuildFlag, "guild", "", "register the slash command in this guild only")
+	addAudioFlags(botCmd)
+	addChannelFlags(botCmd)
+}
+
+func runBot(cmd *cobra.Command, args []string) error {
+	cfg, err := buildConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Flags().Changed("guild") {
+		cfg.GuildID = botGuildFlag
+	}
+
+	if cfg.Token == "" {
+		return config.ErrMissingToken
+	}
+
+	if err := config.Validate(cfg); err != nil {
+		return err
+	}
+
+	logger := app.SetupLogger(cfg)
+
+	ctx, stop := app.SignalContext()
+	defer stop()
+
+	gen, err := app.NewGenerator(cfg.Backend, logger)
+	if err != nil {
+		return err
+	}
+
+	deps, err := app.NewBotDeps(cfg.Token, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := deps.Closer.Close(); cerr != nil {
+			slog.Warn("failed to close discord session", "error", cerr)
+		}
+	}()
+
+	frameEnc := encoding.NewGopusFrameEncoder(logger)
+	fileEnc := app.NewFileEncoder(cfg.Format, logger)
+	presets, err := config.LoadPresets(cfg)
+	if err != nil {
+		return err
+	}
+	svc := scream.NewServiceWithDeps(cfg, gen, fileEnc, frameEnc, deps.Player, deps.Resolver, logger).WithPresets(presets)
+
+	logger.Info("starting bot", "guild", cfg.GuildID)
+	return bot.New(deps.Session, svc, cfg, logger).Run(ctx)
+}
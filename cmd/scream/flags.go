@@ -6,17 +6,35 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/JamesPrial/go-scream/internal/config"
+	"github.com/JamesPrial/go-scream/internal/discord"
 )
 
 var (
 	tokenFlag    string
 	presetFlag   string
+	seedFlag     int64
+	morphToFlag  string
+	mixFlag      float64
+	textFlag     string
 	durationFlag time.Duration
 	volumeFlag   float64
 	backendFlag  string
 	formatFlag   string
 	outputFlag   string
 	dryRunFlag   bool
+
+	targetLUFSFlag float64
+	truePeakFlag   float64
+
+	voicesFlag       int
+	spreadFlag       float64
+	voicePresetsFlag []string
+
+	channelStrategyFlag   string
+	channelUserFlag       string
+	preferredChannelsFlag []string
+
+	setFlag []string
 )
 
 // buildConfig constructs a Config via: Default -> YAML -> env -> CLI flags.
@@ -40,12 +58,39 @@ func buildConfig(cmd *cobra.Command) (config.Config, error) {
 	if cmd.Flags().Changed("preset") {
 		cfg.Preset = presetFlag
 	}
+	if cmd.Flags().Changed("seed") {
+		cfg.Seed = seedFlag
+	}
+	if cmd.Flags().Changed("morph-to") {
+		cfg.MorphTo = morphToFlag
+	}
+	if cmd.Flags().Changed("mix") {
+		cfg.Mix = mixFlag
+	}
+	if cmd.Flags().Changed("text") {
+		cfg.Text = textFlag
+	}
 	if cmd.Flags().Changed("duration") {
 		cfg.Duration = durationFlag
 	}
 	if cmd.Flags().Changed("volume") {
 		cfg.Volume = volumeFlag
 	}
+	if cmd.Flags().Changed("target-lufs") {
+		cfg.TargetLUFS = targetLUFSFlag
+	}
+	if cmd.Flags().Changed("true-peak") {
+		cfg.TruePeak = truePeakFlag
+	}
+	if cmd.Flags().Changed("voices") {
+		cfg.Voices = voicesFlag
+	}
+	if cmd.Flags().Changed("spread") {
+		cfg.Spread = spreadFlag
+	}
+	if cmd.Flags().Changed("voice-preset") {
+		cfg.VoicePresets = voicePresetsFlag
+	}
 	if cmd.Flags().Changed("backend") {
 		cfg.Backend = config.BackendType(backendFlag)
 	}
@@ -64,14 +109,57 @@ func buildConfig(cmd *cobra.Command) (config.Config, error) {
 	if cmd.Flags().Changed("log-level") {
 		cfg.LogLevel = logLevelFlag
 	}
+	if cmd.Flags().Changed("presets-dir") {
+		cfg.PresetsDir = presetsDir
+	}
+	if cmd.Flags().Changed("set") {
+		// Flag overrides are applied after those from the config file.
+		cfg.Overrides = append(append([]string(nil), cfg.Overrides...), setFlag...)
+	}
+	if cmd.Flags().Changed("channel-strategy") {
+		cfg.ChannelStrategy = discord.ChannelStrategy(channelStrategyFlag)
+	}
+	if cmd.Flags().Changed("channel-user") {
+		cfg.ChannelUserID = channelUserFlag
+	}
+	if cmd.Flags().Changed("preferred-channel") {
+		cfg.PreferredChannels = preferredChannelsFlag
+	}
 
 	return cfg, nil
 }
 
 // addAudioFlags adds shared audio flags to a command.
 func addAudioFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVar(&presetFlag, "preset", "", "scream preset name")
+	cmd.Flags().StringVar(&presetFlag, "preset", "", "scream preset name (default classic; \"\" for a random scream)")
+	cmd.Flags().Int64Var(&seedFlag, "seed", 0, "random seed; replays a random scream or varies a preset (0 picks one)")
+	cmd.Flags().StringVar(&morphToFlag, "morph-to", "", "second preset to morph into over the scream, or to blend with --mix")
+	cmd.Flags().Float64Var(&mixFlag, "mix", 0, "blend this much of the --morph-to preset [0.0-1.0] instead of morphing over time")
 	cmd.Flags().DurationVar(&durationFlag, "duration", 0, "scream duration (e.g. 3s, 500ms)")
 	cmd.Flags().Float64Var(&volumeFlag, "volume", 0, "volume multiplier [0.0-1.0]")
+	cmd.Flags().Float64Var(&targetLUFSFlag, "target-lufs", 0, "normalize the scream to this integrated loudness in LUFS [-70 to -5] (0 disables)")
+	cmd.Flags().Float64Var(&truePeakFlag, "true-peak", 0, "true-peak ceiling in dBTP [-20 to 0] kept by --target-lufs (default -1)")
+	cmd.Flags().IntVar(&voicesFlag, "voices", 0, "scream as a crowd of this many voices [1-16] (native backend only)")
+	cmd.Flags().Float64Var(&spreadFlag, "spread", 0, "how far apart a crowd's voices are in pitch, time and stereo [0.0-1.0] (default 0.5)")
+	cmd.Flags().StringSliceVar(&voicePresetsFlag, "voice-preset", nil, "further presets that take turns giving a crowd's voices")
 	cmd.Flags().StringVar(&backendFlag, "backend", "", "audio backend (native|ffmpeg)")
 }
+
+// addTextFlag adds the --text flag, which makes a scream that sounds out the
+// given text, to a command.
+func addTextFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&textFlag, "text", "", "scream this text, e.g. AAAAHHHhhh, instead of a preset (the duration follows the text)")
+}
+
+// addChannelFlags adds voice channel auto-detection flags to a command.
+func addChannelFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&channelStrategyFlag, "channel-strategy", "", "channel auto-detection strategy (first|most|user|preferred)")
+	cmd.Flags().StringVar(&channelUserFlag, "channel-user", "", "user ID to follow with the 'user' channel strategy")
+	cmd.Flags().StringSliceVar(&preferredChannelsFlag, "preferred-channel", nil, "channel IDs to try in order with the 'preferred' channel strategy")
+}
+
+// addOverrideFlags adds the repeatable --set parameter override flag to a
+// command.
+func addOverrideFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&setFlag, "set", nil, "override a scream parameter, e.g. filter.lowpass_cutoff=4000 or layers[2].base_freq=400 (repeatable)")
+}
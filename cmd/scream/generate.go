@@ -23,6 +23,8 @@ func init() {
 	generateCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "output file path (required)")
 	_ = generateCmd.MarkFlagRequired("output")
 	addAudioFlags(generateCmd)
+	addTextFlag(generateCmd)
+	addOverrideFlags(generateCmd)
 	generateCmd.Flags().StringVar(&formatFlag, "format", "", "output format (ogg|wav)")
 }
 
@@ -49,6 +51,6 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 				logger.Warn("failed to close output file", "error", cerr)
 			}
 		}()
-		return svc.Generate(ctx, f)
+		return svc.WithParamsHook(reportRandomSeed(cmd.ErrOrStderr(), cfg)).Generate(ctx, f)
 	})
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// replayHint matches the seed line generate prints for a random scream and
+// captures the flags it says replay the scream.
+var replayHint = regexp.MustCompile(`random scream seed: -?\d+ \(replay with (.+)\)`)
+
+func Test_Generate_ReplaysPrintedSeed(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.wav")
+	_, stderr, err := runCLI(t, "generate", "--preset", "", "--duration", "500ms", "--format", "wav", "-o", first)
+	if err != nil {
+		t.Fatalf("generate error = %v", err)
+	}
+	m := replayHint.FindStringSubmatch(stderr)
+	if m == nil {
+		t.Fatalf("generate printed %q, want the random scream seed", stderr)
+	}
+
+	replay := filepath.Join(dir, "replay.wav")
+	args := []string{"generate", "--duration", "500ms", "--format", "wav", "-o", replay}
+	for _, arg := range strings.Fields(m[1]) {
+		args = append(args, strings.Trim(arg, `"`))
+	}
+	if _, _, err := runCLI(t, args...); err != nil {
+		t.Fatalf("generate %v error = %v", args[1:], err)
+	}
+
+	want, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) == 0 || !bytes.Equal(got, want) {
+		t.Errorf("replaying with %q gave different audio (%d bytes, want %d)", m[1], len(got), len(want))
+	}
+}
+
+func Test_Generate_PresetPrintsNoSeed(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "classic.wav")
+	_, stderr, err := runCLI(t, "generate", "--seed", "7", "--duration", "500ms", "--format", "wav", "-o", out)
+	if err != nil {
+		t.Fatalf("generate error = %v", err)
+	}
+	if strings.Contains(stderr, "seed") {
+		t.Errorf("generate of the default preset printed %q, want no seed", stderr)
+	}
+}
@@ -14,6 +14,7 @@ var (
 	configPath   string
 	verbose      bool
 	logLevelFlag string
+	presetsDir   string
 )
 
 var rootCmd = &cobra.Command{
@@ -26,6 +27,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "path to config file (YAML)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
 	rootCmd.PersistentFlags().StringVar(&logLevelFlag, "log-level", "", "log level (debug|info|warn|error)")
+	rootCmd.PersistentFlags().StringVar(&presetsDir, "presets-dir", "", "directory of user preset YAML files")
 }
 
 func main() {
@@ -21,6 +21,9 @@ func init() {
 	rootCmd.AddCommand(playCmd)
 	playCmd.Flags().StringVar(&tokenFlag, "token", "", "Discord bot token")
 	addAudioFlags(playCmd)
+	addTextFlag(playCmd)
+	addOverrideFlags(playCmd)
+	addChannelFlags(playCmd)
 	playCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "generate and encode but do not play")
 }
 
@@ -49,6 +52,6 @@ func runPlay(cmd *cobra.Command, args []string) error {
 	logger.Info("playing scream", "guild", cfg.GuildID, "channel", channelID)
 
 	return runWithService(cfg, logger, func(ctx context.Context, svc *scream.Service) error {
-		return svc.Play(ctx, cfg.GuildID, channelID)
+		return svc.WithParamsHook(reportRandomSeed(cmd.ErrOrStderr(), cfg)).Play(ctx, cfg.GuildID, channelID)
 	})
 }
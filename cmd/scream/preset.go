@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+	"github.com/JamesPrial/go-scream/internal/config"
+	"github.com/JamesPrial/go-scream/internal/scream"
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Inspect and export scream presets",
+}
+
+var presetShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print the fully-resolved parameters of a preset",
+	Long: `Print the fully-resolved parameters of a built-in or user preset, with
+any inheritance applied. The output can be used as the body of a user preset.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPresetShow,
+}
+
+var presetExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print the parameters generate and play would use, as a preset file",
+	Long: `Resolve the scream parameters exactly as generate and play would, from
+--preset (a random scream with --preset ""), --duration, --volume and --set,
+and print them as a user preset file that can be dropped into the presets
+directory. Random screams include the seed they were generated from, so
+--preset "" --seed N exports the random scream generate printed that seed for.`,
+	Args: cobra.NoArgs,
+	RunE: runPresetExport,
+}
+
+var (
+	presetJSONFlag bool
+	exportNameFlag string
+)
+
+func init() {
+	rootCmd.AddCommand(presetCmd)
+	presetCmd.AddCommand(presetShowCmd, presetExportCmd)
+
+	presetShowCmd.Flags().BoolVar(&presetJSONFlag, "json", false, "print JSON instead of YAML")
+
+	presetExportCmd.Flags().BoolVar(&presetJSONFlag, "json", false, "print JSON instead of YAML")
+	presetExportCmd.Flags().StringVar(&exportNameFlag, "name", "", "name of the exported preset (default <preset>-export, text-<seed> or random-<seed>)")
+	addAudioFlags(presetExportCmd)
+	addTextFlag(presetExportCmd)
+	addOverrideFlags(presetExportCmd)
+}
+
+func runPresetShow(cmd *cobra.Command, args []string) error {
+	cfg, err := buildConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	reg, err := config.LoadPresets(cfg)
+	if err != nil {
+		return err
+	}
+
+	params, ok := reg.Get(args[0])
+	if !ok {
+		return fmt.Errorf("%w: %q", scream.ErrUnknownPreset, args[0])
+	}
+	return writeParams(cmd.OutOrStdout(), params, presetJSONFlag)
+}
+
+func runPresetExport(cmd *cobra.Command, args []string) error {
+	cfg, err := buildConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := config.Validate(cfg); err != nil {
+		return err
+	}
+
+	reg, err := config.LoadPresets(cfg)
+	if err != nil {
+		return err
+	}
+
+	params, err := scream.ResolveParams(cfg, reg)
+	if err != nil {
+		return err
+	}
+
+	name := exportNameFlag
+	switch {
+	case name != "":
+	case cfg.Text != "":
+		name = "text-" + strconv.FormatInt(params.Seed, 10)
+	case cfg.Preset != "":
+		name = cfg.Preset + "-export"
+	default:
+		name = "random-" + strconv.FormatInt(params.Seed, 10)
+	}
+
+	return writeParams(cmd.OutOrStdout(), map[string]audio.ScreamParams{name: params}, presetJSONFlag)
+}
+
+// writeParams writes v to w as indented YAML, or as indented JSON when
+// asJSON is set. Both forms can be loaded as user presets.
+func writeParams(w io.Writer, v interface{}, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
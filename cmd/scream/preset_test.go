@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_PresetExport_Names(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"random scream", []string{"--preset", "", "--seed", "5"}, "random-5:"},
+		{"default preset", []string{"--seed", "5"}, "classic-export:"},
+		{"named preset", []string{"--preset", "banshee"}, "banshee-export:"},
+		{"text scream", []string{"--text", "AAAH", "--seed", "5"}, "text-5:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, err := runCLI(t, append([]string{"preset", "export"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("preset export error = %v", err)
+			}
+			if !strings.HasPrefix(stdout, tt.want) {
+				t.Errorf("preset export %v printed %q, want it to start with %q", tt.args, firstLine(stdout), tt.want)
+			}
+		})
+	}
+}
+
+func Test_PresetExport_RandomRecordsSeed(t *testing.T) {
+	stdout, _, err := runCLI(t, "preset", "export", "--preset", "", "--seed", "5")
+	if err != nil {
+		t.Fatalf("preset export error = %v", err)
+	}
+	if !strings.Contains(stdout, "\n  seed: 5\n") {
+		t.Errorf("preset export of a random scream = %q, want seed 5 recorded", stdout)
+	}
+}
+
+// firstLine returns s up to its first newline.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
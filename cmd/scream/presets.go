@@ -5,22 +5,41 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/JamesPrial/go-scream/internal/config"
 	"github.com/JamesPrial/go-scream/internal/scream"
 )
 
 var presetsCmd = &cobra.Command{
 	Use:   "presets",
 	Short: "List available scream presets",
-	Run:   runPresets,
+	Long: `List the built-in scream presets followed by any user presets loaded
+from --presets-dir or the presets section of the config file.`,
+	Args: cobra.NoArgs,
+	RunE: runPresets,
 }
 
 func init() {
 	rootCmd.AddCommand(presetsCmd)
 }
 
-func runPresets(cmd *cobra.Command, args []string) {
+func runPresets(cmd *cobra.Command, args []string) error {
+	cfg, err := buildConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	reg, err := config.LoadPresets(cfg)
+	if err != nil {
+		return err
+	}
+
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Available presets:")
-	for _, name := range scream.ListPresets() {
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", name)
+	for _, name := range scream.ListPresets(reg) {
+		if reg.IsBuiltin(name) {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", name)
+		} else {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  %s (user)\n", name)
+		}
 	}
+	return nil
 }
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 
 	"github.com/JamesPrial/go-scream/internal/app"
+	"github.com/JamesPrial/go-scream/internal/audio"
 	"github.com/JamesPrial/go-scream/internal/config"
 	"github.com/JamesPrial/go-scream/internal/discord"
 	"github.com/JamesPrial/go-scream/internal/encoding"
@@ -16,6 +18,11 @@ import (
 // (the Discord session) from the provided configuration. The caller must
 // close the returned closer when done.
 func newServiceFromConfig(cfg config.Config, logger *slog.Logger) (*scream.Service, io.Closer, error) {
+	presets, err := config.LoadPresets(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	gen, err := app.NewGenerator(cfg.Backend, logger)
 	if err != nil {
 		return nil, nil, err
@@ -25,15 +32,16 @@ func newServiceFromConfig(cfg config.Config, logger *slog.Logger) (*scream.Servi
 	fileEnc := app.NewFileEncoder(cfg.Format, logger)
 
 	var player discord.VoicePlayer
+	var resolver discord.ChannelResolver
 	var closer io.Closer
 	if cfg.Token != "" {
-		player, closer, err = app.NewDiscordDeps(cfg.Token, logger)
+		player, resolver, closer, err = app.NewDiscordDeps(cfg.Token, logger)
 		if err != nil {
 			return nil, nil, err
 		}
 	}
 
-	svc := scream.NewServiceWithDeps(cfg, gen, fileEnc, frameEnc, player, logger)
+	svc := scream.NewServiceWithDeps(cfg, gen, fileEnc, frameEnc, player, resolver, logger).WithPresets(presets)
 	return svc, closer, nil
 }
 
@@ -57,3 +65,19 @@ func runWithService(cfg config.Config, logger *slog.Logger, fn func(ctx context.
 	}
 	return fn(ctx, svc)
 }
+
+// reportRandomSeed returns a params hook that prints the seed of a random or
+// text scream to w, with the flags that replay it, so that a scream worth
+// keeping can be made again. A random scream needs --preset "" as well as
+// --seed, since a seed alone varies the default preset. It prints nothing
+// when cfg selects a preset.
+func reportRandomSeed(w io.Writer, cfg config.Config) func(audio.ScreamParams) {
+	return func(p audio.ScreamParams) {
+		switch {
+		case cfg.Text != "":
+			_, _ = fmt.Fprintf(w, "text scream seed: %d (replay with --seed %d)\n", p.Seed, p.Seed)
+		case cfg.Preset == "":
+			_, _ = fmt.Fprintf(w, "random scream seed: %d (replay with --preset \"\" --seed %d)\n", p.Seed, p.Seed)
+		}
+	}
+}
@@ -5,10 +5,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/JamesPrial/go-scream/internal/app"
 	"github.com/JamesPrial/go-scream/internal/config"
@@ -64,17 +66,91 @@ func resolveToken(openclawPath string) string {
 	return token
 }
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "usage: skill <guildID> [channelID]")
-		os.Exit(1)
+// buildConfig returns the skill configuration: defaults overlaid with the
+// SCREAM_* environment variables, then the resolved token and guild ID.
+//
+// ApplyEnv loads audio parameter overrides (SCREAM_PRESET, SCREAM_SEED,
+// SCREAM_MORPH_TO, SCREAM_MIX, SCREAM_DURATION, SCREAM_VOLUME, SCREAM_TARGET_LUFS,
+// SCREAM_TRUE_PEAK, SCREAM_VOICES, SCREAM_SPREAD, SCREAM_VOICE_PRESETS,
+// SCREAM_BACKEND) and channel auto-detection settings
+// (SCREAM_CHANNEL_STRATEGY, SCREAM_CHANNEL_USER_ID, SCREAM_PREFERRED_CHANNELS),
+// and the user presets directory (SCREAM_PRESETS_DIR).
+// Token and GuildID are set explicitly afterwards from skill-specific sources,
+// overriding any env values ApplyEnv may set.
+func buildConfig(token, guildID string) config.Config {
+	cfg := config.Default()
+	config.ApplyEnv(&cfg)
+	cfg.Token = token
+	cfg.GuildID = guildID
+	return cfg
+}
+
+// errUsage is returned by parseArgs when the arguments do not match the
+// usage it describes.
+var errUsage = errors.New("usage: skill <guildID> [channelID] [text]")
+
+// Discord snowflake IDs, such as channel IDs, are 17 to 20 digits long.
+const (
+	minSnowflakeLen = 17
+	maxSnowflakeLen = 20
+)
+
+// parseArgs splits the command-line arguments, without the program name, into
+// the guild ID, the channel ID and the text to scream, of which only the
+// guild ID is required. The channel is given by its ID or a <#id> mention,
+// so a second argument that is neither, such as a short number, is taken as
+// the text; an empty second argument is an empty channel ID.
+func parseArgs(args []string) (guildID, channelID, text string, err error) {
+	if len(args) == 0 || len(args) > 3 {
+		return "", "", "", errUsage
+	}
+	guildID, rest := args[0], args[1:]
+	if len(rest) > 0 {
+		if id, ok := channelArg(rest[0]); ok {
+			channelID, rest = id, rest[1:]
+		}
 	}
+	switch len(rest) {
+	case 0:
+	case 1:
+		text = rest[0]
+	default:
+		return "", "", "", errUsage
+	}
+	return guildID, channelID, text, nil
+}
 
-	guildID := os.Args[1]
+// channelArg returns the channel ID given by s, a snowflake ID or a <#id>
+// channel mention, and whether s gives one. An empty s gives an empty ID.
+func channelArg(s string) (string, bool) {
+	if s == "" {
+		return "", true
+	}
+	if id, ok := strings.CutPrefix(s, "<#"); ok {
+		id, ok = strings.CutSuffix(id, ">")
+		return id, ok && isSnowflake(id)
+	}
+	return s, isSnowflake(s)
+}
 
-	channelID := ""
-	if len(os.Args) > 2 {
-		channelID = os.Args[2]
+// isSnowflake reports whether s could be a Discord snowflake ID.
+func isSnowflake(s string) bool {
+	if len(s) < minSnowflakeLen || len(s) > maxSnowflakeLen {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func main() {
+	guildID, channelID, text, err := parseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	openclawPath := filepath.Join(os.Getenv("HOME"), ".openclaw", "openclaw.json")
@@ -84,13 +160,8 @@ func main() {
 		os.Exit(1)
 	}
 
-	cfg := config.Default()
-	// ApplyEnv loads audio parameter overrides (SCREAM_PRESET, SCREAM_DURATION,
-	// SCREAM_VOLUME, SCREAM_BACKEND). Token and GuildID are set explicitly below
-	// from skill-specific sources, overriding any env values ApplyEnv may set.
-	config.ApplyEnv(&cfg)
-	cfg.Token = token
-	cfg.GuildID = guildID
+	cfg := buildConfig(token, guildID)
+	cfg.Text = text
 
 	logger := app.SetupLogger(cfg)
 
@@ -111,7 +182,7 @@ func main() {
 	frameEnc := encoding.NewGopusFrameEncoder(logger)
 	fileEnc := app.NewFileEncoder(cfg.Format, logger)
 
-	player, sessionCloser, err := app.NewDiscordDeps(cfg.Token, logger)
+	player, resolver, sessionCloser, err := app.NewDiscordDeps(cfg.Token, logger)
 	if err != nil {
 		slog.Error("failed to create discord session", "error", err)
 		os.Exit(1)
@@ -122,7 +193,13 @@ func main() {
 		}
 	}()
 
-	svc := scream.NewServiceWithDeps(cfg, gen, fileEnc, frameEnc, player, logger)
+	presets, err := config.LoadPresets(cfg)
+	if err != nil {
+		slog.Error("failed to load presets", "error", err)
+		os.Exit(1)
+	}
+
+	svc := scream.NewServiceWithDeps(cfg, gen, fileEnc, frameEnc, player, resolver, logger).WithPresets(presets)
 	if err := svc.Play(ctx, cfg.GuildID, channelID); err != nil {
 		slog.Error("playback failed", "error", err)
 		os.Exit(1)
@@ -5,6 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/config"
+	"github.com/JamesPrial/go-scream/internal/discord"
 )
 
 // ---------------------------------------------------------------------------
@@ -409,3 +412,101 @@ func Test_resolveToken_FileWithEmptyToken(t *testing.T) {
 		t.Errorf("resolveToken() = %q, want empty string", got)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// buildConfig()
+// ---------------------------------------------------------------------------
+
+func Test_buildConfig_TokenAndGuildOverrideEnv(t *testing.T) {
+	t.Setenv("DISCORD_TOKEN", "env-token")
+	t.Setenv("SCREAM_GUILD_ID", "env-guild")
+
+	cfg := buildConfig("skill-token", "skill-guild")
+
+	if cfg.Token != "skill-token" {
+		t.Errorf("Token = %q, want %q", cfg.Token, "skill-token")
+	}
+	if cfg.GuildID != "skill-guild" {
+		t.Errorf("GuildID = %q, want %q", cfg.GuildID, "skill-guild")
+	}
+}
+
+func Test_buildConfig_ChannelStrategyFromEnv(t *testing.T) {
+	t.Setenv("SCREAM_CHANNEL_STRATEGY", "preferred")
+	t.Setenv("SCREAM_PREFERRED_CHANNELS", "c1,c2")
+
+	cfg := buildConfig("tok", "guild")
+
+	if cfg.ChannelStrategy != discord.StrategyPreferred {
+		t.Errorf("ChannelStrategy = %q, want %q", cfg.ChannelStrategy, discord.StrategyPreferred)
+	}
+	if len(cfg.PreferredChannels) != 2 {
+		t.Errorf("PreferredChannels = %v, want [c1 c2]", cfg.PreferredChannels)
+	}
+	if err := config.Validate(cfg); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func Test_buildConfig_DefaultsValid(t *testing.T) {
+	for _, key := range []string{"SCREAM_CHANNEL_STRATEGY", "SCREAM_PRESET", "SCREAM_BACKEND"} {
+		t.Setenv(key, "")
+	}
+
+	cfg := buildConfig("tok", "guild")
+
+	if cfg.ChannelStrategy != "" {
+		t.Errorf("ChannelStrategy = %q, want empty (first populated)", cfg.ChannelStrategy)
+	}
+	if err := config.Validate(cfg); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// parseArgs()
+// ---------------------------------------------------------------------------
+
+func Test_parseArgs_Cases(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantGuild   string
+		wantChannel string
+		wantText    string
+		wantErr     bool
+	}{
+		{name: "guild only", args: []string{"111"}, wantGuild: "111"},
+		{name: "guild and channel", args: []string{"111", "123456789012345678"}, wantGuild: "111", wantChannel: "123456789012345678"},
+		{name: "guild and channel mention", args: []string{"111", "<#123456789012345678>"}, wantGuild: "111", wantChannel: "123456789012345678"},
+		{name: "guild and text", args: []string{"111", "NOOOO"}, wantGuild: "111", wantText: "NOOOO"},
+		{name: "guild and numeric text", args: []string{"111", "1234"}, wantGuild: "111", wantText: "1234"},
+		{name: "guild, channel and text", args: []string{"111", "123456789012345678", "AAAHhh"}, wantGuild: "111", wantChannel: "123456789012345678", wantText: "AAAHhh"},
+		{name: "guild, channel and numeric text", args: []string{"111", "123456789012345678", "42"}, wantGuild: "111", wantChannel: "123456789012345678", wantText: "42"},
+		{name: "empty channel and text", args: []string{"111", "", "AAAH"}, wantGuild: "111", wantText: "AAAH"},
+		{name: "malformed mention", args: []string{"111", "<#42>"}, wantGuild: "111", wantText: "<#42>"},
+		{name: "no arguments", args: nil, wantErr: true},
+		{name: "two texts", args: []string{"111", "AAA", "OOO"}, wantErr: true},
+		{name: "numeric text then text", args: []string{"111", "42", "AAA"}, wantErr: true},
+		{name: "too many arguments", args: []string{"111", "123456789012345678", "AAA", "OOO"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guild, channel, text, err := parseArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseArgs(%q) expected error, got nil", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArgs(%q) unexpected error: %v", tt.args, err)
+			}
+			if guild != tt.wantGuild || channel != tt.wantChannel || text != tt.wantText {
+				t.Errorf("parseArgs(%q) = %q, %q, %q; want %q, %q, %q",
+					tt.args, guild, channel, text, tt.wantGuild, tt.wantChannel, tt.wantText)
+			}
+		})
+	}
+}
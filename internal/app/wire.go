@@ -18,6 +18,7 @@ import (
 	"github.com/JamesPrial/go-scream/internal/audio"
 	"github.com/JamesPrial/go-scream/internal/audio/ffmpeg"
 	"github.com/JamesPrial/go-scream/internal/audio/native"
+	"github.com/JamesPrial/go-scream/internal/bot"
 	"github.com/JamesPrial/go-scream/internal/config"
 	"github.com/JamesPrial/go-scream/internal/discord"
 	"github.com/JamesPrial/go-scream/internal/encoding"
@@ -50,16 +51,56 @@ func NewFileEncoder(format config.FormatType, logger *slog.Logger) encoding.File
 }
 
 // NewDiscordDeps creates a discordgo session for the given bot token, opens
-// the WebSocket connection, and returns a ready-to-use VoicePlayer together
-// with an io.Closer that must be called to close the session when done.
-// On any error both returned values are nil.
+// the WebSocket connection, and returns a ready-to-use VoicePlayer and
+// ChannelResolver together with an io.Closer that must be called to close the
+// session when done. On any error all returned values are nil.
 //
 // The discordgo session's log level is derived from logger so that internal
 // DAVE E2EE diagnostics are visible when go-scream runs with --log-level debug.
-func NewDiscordDeps(token string, logger *slog.Logger) (discord.VoicePlayer, io.Closer, error) {
+func NewDiscordDeps(token string, logger *slog.Logger) (discord.VoicePlayer, discord.ChannelResolver, io.Closer, error) {
+	session, err := openDiscordSession(token, logger)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sess := &discord.GoSession{S: session, Logger: logger}
+	player := discord.NewPlayer(sess, logger)
+	resolver := discord.NewChannelFinder(sess, logger)
+	return player, resolver, session, nil
+}
+
+// BotDeps bundles the Discord dependencies of the long-running bot mode. All
+// members share a single gateway session.
+type BotDeps struct {
+	Session  bot.Session
+	Player   discord.VoicePlayer
+	Resolver discord.ChannelResolver
+	Closer   io.Closer
+}
+
+// NewBotDeps opens a discordgo session for the given bot token and returns
+// the slash-command session, voice player, and channel resolver built on it.
+// BotDeps.Closer must be called to close the session when done. On error the
+// returned value is nil.
+func NewBotDeps(token string, logger *slog.Logger) (*BotDeps, error) {
+	session, err := openDiscordSession(token, logger)
+	if err != nil {
+		return nil, err
+	}
+	sess := &discord.GoSession{S: session, Logger: logger}
+	return &BotDeps{
+		Session:  &bot.GoSession{S: session},
+		Player:   discord.NewPlayer(sess, logger),
+		Resolver: discord.NewChannelFinder(sess, logger),
+		Closer:   session,
+	}, nil
+}
+
+// openDiscordSession creates a discordgo session for token with logging
+// bridged into logger, and opens the gateway connection.
+func openDiscordSession(token string, logger *slog.Logger) (*discordgo.Session, error) {
 	session, err := discordgo.New("Bot " + token)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create discord session: %w", err)
+		return nil, fmt.Errorf("failed to create discord session: %w", err)
 	}
 
 	// Bridge discordgo's logging into slog so DAVE diagnostics appear in
@@ -81,11 +122,9 @@ func NewDiscordDeps(token string, logger *slog.Logger) (discord.VoicePlayer, io.
 	}
 
 	if err := session.Open(); err != nil {
-		return nil, nil, fmt.Errorf("failed to open discord session: %w", err)
+		return nil, fmt.Errorf("failed to open discord session: %w", err)
 	}
-	sess := &discord.GoSession{S: session, Logger: logger}
-	player := discord.NewPlayer(sess, logger)
-	return player, session, nil
+	return session, nil
 }
 
 // discordLogLevel maps the slog logger's effective level to a discordgo
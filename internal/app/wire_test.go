@@ -160,6 +160,10 @@ func TestNewDiscordDeps_RequiresNetwork(t *testing.T) {
 	t.Skip("NewDiscordDeps requires a real Discord bot token and network access")
 }
 
+func TestNewBotDeps_RequiresNetwork(t *testing.T) {
+	t.Skip("NewBotDeps requires a real Discord bot token and network access")
+}
+
 // ---------------------------------------------------------------------------
 // NewGenerator table-driven (combined scenarios)
 // ---------------------------------------------------------------------------
@@ -0,0 +1,133 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// MaxVoices is the most voices a crowd can have.
+const MaxVoices = 16
+
+// DefaultSpread is the spread of a crowd whose Spread is 0.
+const DefaultSpread = 0.5
+
+// How far a crowd's voices stray from the first at a spread of 1: each is
+// detuned by up to maxVoiceDetune cents either way and starts up to
+// maxVoiceOffset late.
+const (
+	maxVoiceDetune = 50.0
+	maxVoiceOffset = 300 * time.Millisecond
+)
+
+// voiceSeedMix is the multiplier of a voice's index mixed into its seed, a
+// prime distinct from those the native backend mixes into layer seeds.
+const voiceSeedMix int64 = 1000117
+
+// Voice is one voice of a crowd, as returned by ScreamParams.CrowdVoices.
+type Voice struct {
+	// Params are the voice's own parameters, with the duration and output
+	// format of the crowd and no crowd of their own.
+	Params ScreamParams
+
+	// Offset is how long after the crowd starts the voice starts.
+	Offset time.Duration
+
+	// Pan places the voice in the stereo field [-1 (left), 1 (right)].
+	Pan float64
+
+	// Gain scales the voice in the mix so that the crowd is about as loud as
+	// any one of its voices.
+	Gain float64
+}
+
+// IsCrowd reports whether p renders a crowd of more than one voice.
+func (p ScreamParams) IsCrowd() bool {
+	return p.Voices > 1
+}
+
+// SpreadAmount returns the spread of the crowd of p: Spread, or
+// DefaultSpread if it is 0.
+func (p ScreamParams) SpreadAmount() float64 {
+	if p.Spread == 0 {
+		return DefaultSpread
+	}
+	return p.Spread
+}
+
+// CrowdVoices returns the Voices voices of the crowd p renders, or p alone as
+// a single voice if it is not a crowd. The voices take turns between p and
+// each of its VoiceMix. The first voice is p as it is; every other has its
+// own seed and, drawn from that seed, is detuned and starts late by up to
+// SpreadAmount of the most a voice can stray. The voices are spaced evenly
+// across SpreadAmount of the stereo field, from left to right, and each is
+// scaled by 1/√Voices, since the voices are uncorrelated and their powers
+// add up.
+func (p ScreamParams) CrowdVoices() []Voice {
+	if !p.IsCrowd() {
+		return []Voice{{Params: p.crowdVoiceBase(0), Gain: 1}}
+	}
+	spread := p.SpreadAmount()
+	gain := 1 / math.Sqrt(float64(p.Voices))
+	voices := make([]Voice, p.Voices)
+	for i := range voices {
+		v := Voice{
+			Params: p.crowdVoiceBase(i),
+			Pan:    spread * (2*float64(i)/float64(p.Voices-1) - 1),
+			Gain:   gain,
+		}
+		if i > 0 {
+			mix := int64(i) * voiceSeedMix
+			v.Params.Seed ^= mix
+			if v.Params.MorphTo != nil {
+				v.Params.MorphTo.Seed ^= mix
+			}
+			r := rand.New(rand.NewSource(v.Params.Seed))
+			ratio := math.Pow(2, (2*r.Float64()-1)*spread*maxVoiceDetune/1200)
+			v.Params.detune(ratio)
+			v.Offset = time.Duration(math.Round(r.Float64() * spread * float64(maxVoiceOffset)))
+		}
+		voices[i] = v
+	}
+	return voices
+}
+
+// crowdVoiceBase returns the parameters voice i of the crowd of p starts
+// from: a copy of p, or of the VoiceMix whose turn it is with the duration
+// and output format of p, without a crowd.
+func (p ScreamParams) crowdVoiceBase(i int) ScreamParams {
+	base := p
+	if k := i % (len(p.VoiceMix) + 1); k > 0 {
+		base = p.VoiceMix[k-1]
+		base.Duration = p.Duration
+		base.SampleRate = p.SampleRate
+		base.Channels = p.Channels
+	}
+	base = base.Clone()
+	base.Voices = 0
+	base.Spread = 0
+	base.VoiceMix = nil
+	return base
+}
+
+// detune multiplies the pitch of every tonal layer of p, and of its MorphTo,
+// by ratio.
+func (p *ScreamParams) detune(ratio float64) {
+	for i := range p.Layers {
+		l := &p.Layers[i]
+		if l.Type == LayerNoiseBurst || l.Type == LayerBackgroundNoise {
+			continue
+		}
+		l.BaseFreq *= ratio
+		l.FreqRange *= ratio
+		l.SweepRate *= ratio
+	}
+	if p.MorphTo != nil {
+		p.MorphTo.detune(ratio)
+	}
+}
+
+// validCrowd reports whether the crowd settings of p are in range.
+func (p ScreamParams) validCrowd() bool {
+	return p.Voices >= 0 && p.Voices <= MaxVoices && p.Spread >= 0 && p.Spread <= 1
+}
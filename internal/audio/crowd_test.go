@@ -0,0 +1,213 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCrowdVoices_SingleVoice(t *testing.T) {
+	for _, voices := range []int{0, 1} {
+		p := validBaseParams()
+		p.Voices = voices
+		p.Spread = 1
+		got := p.CrowdVoices()
+		if len(got) != 1 {
+			t.Fatalf("Voices %d: %d voices, want 1", voices, len(got))
+		}
+		want := validBaseParams()
+		if !reflect.DeepEqual(got[0].Params, want) || got[0].Offset != 0 || got[0].Pan != 0 || got[0].Gain != 1 {
+			t.Errorf("Voices %d: voice = %+v, want the params alone at unity gain", voices, got[0])
+		}
+	}
+}
+
+func TestCrowdVoices(t *testing.T) {
+	p := validBaseParams()
+	p.Seed = 42
+	for i := range p.Layers {
+		p.Layers[i].BaseFreq = 400
+		p.Layers[i].FreqRange = 100
+	}
+	p.Voices = 5
+	p.Spread = 1
+
+	voices := p.CrowdVoices()
+	if len(voices) != 5 {
+		t.Fatalf("%d voices, want 5", len(voices))
+	}
+	if voices[0].Params.Seed != 42 || voices[0].Offset != 0 || voices[0].Params.Layers[0].BaseFreq != 400 {
+		t.Errorf("first voice = %+v, want the params as they are", voices[0])
+	}
+
+	seeds := map[int64]bool{}
+	for i, v := range voices {
+		if v.Params.IsCrowd() || v.Params.VoiceMix != nil {
+			t.Errorf("voice %d has a crowd of its own", i)
+		}
+		if seeds[v.Params.Seed] {
+			t.Errorf("voice %d shares its seed %d", i, v.Params.Seed)
+		}
+		seeds[v.Params.Seed] = true
+
+		if want := -1 + 0.5*float64(i); math.Abs(v.Pan-want) > 1e-12 {
+			t.Errorf("voice %d pan = %v, want %v", i, v.Pan, want)
+		}
+		if math.Abs(v.Gain-1/math.Sqrt(5)) > 1e-12 {
+			t.Errorf("voice %d gain = %v, want 1/√5", i, v.Gain)
+		}
+		if v.Offset < 0 || v.Offset > maxVoiceOffset {
+			t.Errorf("voice %d offset = %v, want within %v", i, v.Offset, maxVoiceOffset)
+		}
+
+		// Tonal layers are detuned together, by at most 50 cents; noise
+		// layers are left alone.
+		ratio := v.Params.Layers[0].BaseFreq / 400
+		if cents := 1200 * math.Log2(ratio); math.Abs(cents) > maxVoiceDetune {
+			t.Errorf("voice %d detuned by %.1f cents, want at most %v", i, cents, maxVoiceDetune)
+		}
+		for j, l := range v.Params.Layers {
+			want := 400 * ratio
+			if l.Type == LayerNoiseBurst || l.Type == LayerBackgroundNoise {
+				want = 400
+			}
+			if math.Abs(l.BaseFreq-want) > 1e-9 || math.Abs(l.FreqRange/l.BaseFreq-0.25) > 1e-12 {
+				t.Errorf("voice %d layer %d: base %v, range %v; want base %v", i, j, l.BaseFreq, l.FreqRange, want)
+			}
+		}
+	}
+	if voices[1].Offset == 0 && voices[2].Offset == 0 {
+		t.Error("no voice after the first starts late")
+	}
+
+	if !reflect.DeepEqual(voices, p.CrowdVoices()) {
+		t.Error("CrowdVoices() is not deterministic")
+	}
+	if p.Layers[0].BaseFreq != 400 {
+		t.Error("CrowdVoices() modified the params")
+	}
+}
+
+func TestCrowdVoices_Spread(t *testing.T) {
+	p := validBaseParams()
+	p.Voices = 3
+	p.Layers[0].BaseFreq = 400
+
+	// A spread of 0 is the default.
+	def := p.CrowdVoices()
+	p.Spread = DefaultSpread
+	if !reflect.DeepEqual(def, p.CrowdVoices()) {
+		t.Error("a spread of 0 should be DefaultSpread")
+	}
+
+	p.Spread = 0.1
+	for i, v := range p.CrowdVoices() {
+		if math.Abs(v.Pan) > 0.1 || v.Offset > maxVoiceOffset/10 {
+			t.Errorf("voice %d at spread 0.1: pan %v, offset %v", i, v.Pan, v.Offset)
+		}
+		if cents := 1200 * math.Log2(v.Params.Layers[0].BaseFreq/400); math.Abs(cents) > maxVoiceDetune/10 {
+			t.Errorf("voice %d at spread 0.1 detuned by %.1f cents", i, cents)
+		}
+	}
+}
+
+func TestCrowdVoices_VoiceMix(t *testing.T) {
+	p := validBaseParams()
+	other := validBaseParams()
+	other.Duration = time.Second
+	other.Channels = 1
+	other.Filter.LowpassCutoff = 3000
+	other.Voices = 9 // ignored
+	p.VoiceMix = []ScreamParams{other}
+	p.Voices = 4
+
+	for i, v := range p.CrowdVoices() {
+		wantCutoff := 8000.0
+		if i%2 == 1 {
+			wantCutoff = 3000
+		}
+		if v.Params.Filter.LowpassCutoff != wantCutoff {
+			t.Errorf("voice %d low-pass cutoff = %v, want %v", i, v.Params.Filter.LowpassCutoff, wantCutoff)
+		}
+		if v.Params.Duration != p.Duration || v.Params.Channels != p.Channels || v.Params.Voices != 0 {
+			t.Errorf("voice %d should take its duration and format from the crowd, without a crowd of its own", i)
+		}
+	}
+}
+
+func TestCrowdVoices_DetunesMorph(t *testing.T) {
+	p := validBaseParams()
+	p.Layers[0].BaseFreq = 400
+	end := validBaseParams()
+	end.Layers[0].BaseFreq = 800
+	p.MorphTo = &end
+	p.Voices = 2
+	p.Spread = 1
+
+	v := p.CrowdVoices()[1].Params
+	if math.Abs(v.MorphTo.Layers[0].BaseFreq/v.Layers[0].BaseFreq-2) > 1e-12 {
+		t.Errorf("morph start %v and end %v not detuned together", v.Layers[0].BaseFreq, v.MorphTo.Layers[0].BaseFreq)
+	}
+	if end.Layers[0].BaseFreq != 800 {
+		t.Error("CrowdVoices() modified the morph target")
+	}
+}
+
+func TestScreamParams_TailCrowd(t *testing.T) {
+	p := validBaseParams()
+	p.Voices = 8
+	p.Spread = 1
+	p.Filter.ReverbMix = 0.3
+
+	var want time.Duration
+	for _, v := range p.CrowdVoices() {
+		want = max(want, v.Offset+p.Filter.Tail())
+	}
+	if got := p.Tail(); got != want || got <= p.Filter.Tail() {
+		t.Errorf("Tail() = %v, want %v, past the reverb's tail %v", got, want, p.Filter.Tail())
+	}
+}
+
+func TestValidate_Voices(t *testing.T) {
+	badMix := validBaseParams()
+	badMix.Layers[0].Amplitude = 2
+
+	tests := []struct {
+		name   string
+		modify func(p *ScreamParams)
+		ok     bool
+	}{
+		{"none", func(p *ScreamParams) {}, true},
+		{"crowd", func(p *ScreamParams) { p.Voices, p.Spread = MaxVoices, 1 }, true},
+		{"voice mix", func(p *ScreamParams) { p.Voices, p.VoiceMix = 3, []ScreamParams{Randomize(1)} }, true},
+		{"negative voices", func(p *ScreamParams) { p.Voices = -1 }, false},
+		{"too many voices", func(p *ScreamParams) { p.Voices = MaxVoices + 1 }, false},
+		{"negative spread", func(p *ScreamParams) { p.Spread = -0.1 }, false},
+		{"spread above 1", func(p *ScreamParams) { p.Spread = 1.1 }, false},
+		{"invalid voice mix", func(p *ScreamParams) { p.VoiceMix = []ScreamParams{badMix} }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			tt.modify(&p)
+			err := p.Validate()
+			if tt.ok && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidVoices) {
+				t.Errorf("Validate() = %v, want ErrInvalidVoices", err)
+			}
+		})
+	}
+}
+
+func TestInterpolate_CrowdFromFirst(t *testing.T) {
+	a, b := Randomize(1), Randomize(2)
+	a.Voices, a.Spread = 4, 0.3
+	b.Voices, b.Spread = 8, 0.9
+	if got := Interpolate(a, b, 1); got.Voices != 4 || got.Spread != 0.3 {
+		t.Errorf("Interpolate() voices %d, spread %v; want a's 4, 0.3", got.Voices, got.Spread)
+	}
+}
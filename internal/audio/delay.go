@@ -0,0 +1,51 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// Bounds of the delay effect: the time between echoes in milliseconds, and
+// the feedback, kept below 1 so that the echoes always die away.
+const (
+	maxDelayTime     = 2000
+	maxDelayFeedback = 0.9
+)
+
+// DelayRepeats returns how many echoes the delay of f makes before they have
+// died away by 60 dB: one, plus one for each time the feedback lets them
+// round the loop again. A filtered feedback path only makes them die sooner.
+func (f FilterParams) DelayRepeats() int {
+	if f.DelayFeedback <= 0 {
+		return 1
+	}
+	return 1 + int(math.Ceil(-3/math.Log10(f.DelayFeedback)))
+}
+
+// DelayTail returns how long the echoes of f ring on after their input
+// stops, or 0 if f has no delay.
+func (f FilterParams) DelayTail() time.Duration {
+	if f.DelayMix == 0 {
+		return 0
+	}
+	tail := f.DelayTime * float64(f.DelayRepeats())
+	return time.Duration(math.Ceil(tail)) * time.Millisecond
+}
+
+// Tail returns how long the effects of f ring on after their input stops.
+// The reverb follows the delay, so it rings on after the last echo.
+func (f FilterParams) Tail() time.Duration {
+	return f.DelayTail() + f.ReverbTail()
+}
+
+// validDelay reports whether the delay settings of f are in range. A delay
+// that is heard must have a delay time.
+func (f FilterParams) validDelay() bool {
+	if f.DelayMix > 0 && f.DelayTime <= 0 {
+		return false
+	}
+	return f.DelayMix >= 0 && f.DelayMix <= 1 &&
+		f.DelayTime >= 0 && f.DelayTime <= maxDelayTime &&
+		f.DelayFeedback >= 0 && f.DelayFeedback <= maxDelayFeedback &&
+		f.DelayLowpass >= 0
+}
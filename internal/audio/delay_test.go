@@ -0,0 +1,79 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate_Delay(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *FilterParams)
+		ok     bool
+	}{
+		{"no delay", func(f *FilterParams) {}, true},
+		{"time without mix", func(f *FilterParams) { f.DelayTime = 300 }, true},
+		{"full delay", func(f *FilterParams) {
+			f.DelayMix, f.DelayTime, f.DelayFeedback, f.DelayPingPong, f.DelayLowpass = 1, 2000, 0.9, true, 3000
+		}, true},
+		{"mix without time", func(f *FilterParams) { f.DelayMix = 0.5 }, false},
+		{"negative mix", func(f *FilterParams) { f.DelayMix = -0.1 }, false},
+		{"mix above 1", func(f *FilterParams) { f.DelayMix, f.DelayTime = 1.5, 300 }, false},
+		{"time too long", func(f *FilterParams) { f.DelayMix, f.DelayTime = 0.5, 2001 }, false},
+		{"feedback too high", func(f *FilterParams) { f.DelayMix, f.DelayTime, f.DelayFeedback = 0.5, 300, 0.95 }, false},
+		{"negative low-pass", func(f *FilterParams) { f.DelayLowpass = -1 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			tt.modify(&p.Filter)
+			err := p.Validate()
+			if tt.ok && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidDelay) {
+				t.Errorf("Validate() = %v, want ErrInvalidDelay", err)
+			}
+		})
+	}
+}
+
+func TestFilterParams_DelayTail(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  FilterParams
+		repeats int
+		want    time.Duration
+	}{
+		{"no delay", FilterParams{DelayTime: 300, DelayFeedback: 0.5}, 11, 0},
+		{"single echo", FilterParams{DelayMix: 0.5, DelayTime: 300}, 1, 300 * time.Millisecond},
+		// 0.5^10 is the first repeat below -60 dB.
+		{"feedback", FilterParams{DelayMix: 0.5, DelayTime: 250, DelayFeedback: 0.5}, 11, 2750 * time.Millisecond},
+		{"most feedback", FilterParams{DelayMix: 0.5, DelayTime: 100, DelayFeedback: 0.9}, 67, 6700 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.DelayRepeats(); got != tt.repeats {
+				t.Errorf("DelayRepeats() = %d, want %d", got, tt.repeats)
+			}
+			if got := tt.filter.DelayTail(); got != tt.want {
+				t.Errorf("DelayTail() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterParams_TailAddsDelayAndReverb(t *testing.T) {
+	f := FilterParams{DelayMix: 0.5, DelayTime: 300, ReverbMix: 0.3, ReverbRoom: 0.5}
+	if got, want := f.Tail(), f.DelayTail()+f.ReverbTail(); got != want {
+		t.Errorf("Tail() = %v, want %v", got, want)
+	}
+
+	p := validBaseParams()
+	p.Filter.DelayMix = 0.5
+	p.Filter.DelayTime = 400
+	if got := p.Tail(); got != 400*time.Millisecond {
+		t.Errorf("ScreamParams.Tail() = %v, want 400ms", got)
+	}
+}
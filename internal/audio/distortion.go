@@ -0,0 +1,30 @@
+package audio
+
+// maxDistortionDrive bounds the gain driving the distortion, in dB.
+const maxDistortionDrive = 48
+
+// Distortion names the curve a waveshaping distortion bends the signal
+// through once it has been driven.
+type Distortion string
+
+const (
+	DistortionSoft Distortion = "soft" // smooth saturation; tanh
+	DistortionHard Distortion = "hard" // harsh, flat-topped clipping
+	DistortionTube Distortion = "tube" // asymmetric saturation, adding even harmonics
+	DistortionFold Distortion = "fold" // peaks folded back on themselves; metallic at high drive
+)
+
+// DistortionTubeBias offsets the signal on the tanh curve of a tube
+// distortion, so that it saturates sooner on one side than the other.
+const DistortionTubeBias = 0.3
+
+// validDistortion reports whether the distortion of f is off or known,
+// with a drive of 0 to maxDistortionDrive dB and a non-negative tone.
+func (f FilterParams) validDistortion() bool {
+	switch f.Distortion {
+	case "", DistortionSoft, DistortionHard, DistortionTube, DistortionFold:
+	default:
+		return false
+	}
+	return f.DistortionDrive >= 0 && f.DistortionDrive <= maxDistortionDrive && f.DistortionTone >= 0
+}
@@ -0,0 +1,37 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate_Distortion(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *FilterParams)
+		ok     bool
+	}{
+		{"no distortion", func(f *FilterParams) {}, true},
+		{"soft", func(f *FilterParams) { f.Distortion = DistortionSoft }, true},
+		{"hard", func(f *FilterParams) { f.Distortion = DistortionHard }, true},
+		{"tube", func(f *FilterParams) { f.Distortion, f.DistortionDrive, f.DistortionTone = DistortionTube, 48, 5000 }, true},
+		{"fold", func(f *FilterParams) { f.Distortion = DistortionFold }, true},
+		{"unknown", func(f *FilterParams) { f.Distortion = "fuzz" }, false},
+		{"negative drive", func(f *FilterParams) { f.Distortion, f.DistortionDrive = DistortionSoft, -1 }, false},
+		{"drive too high", func(f *FilterParams) { f.Distortion, f.DistortionDrive = DistortionSoft, 49 }, false},
+		{"negative tone", func(f *FilterParams) { f.Distortion, f.DistortionTone = DistortionSoft, -100 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			tt.modify(&p.Filter)
+			err := p.Validate()
+			if tt.ok && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidDistortion) {
+				t.Errorf("Validate() = %v, want ErrInvalidDistortion", err)
+			}
+		})
+	}
+}
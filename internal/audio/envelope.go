@@ -0,0 +1,116 @@
+package audio
+
+// Envelope shapes the loudness of a layer, or of a whole scream, over time.
+// It rises from silence to full level over Attack, falls to Sustain over
+// Decay and holds it, then falls to silence over the last Release seconds of
+// the scream. Points, if set, replace the attack, decay and sustain with a
+// line through the given breakpoints. The zero value keeps the full level
+// throughout.
+type Envelope struct {
+	Attack  float64 `yaml:"attack" json:"attack"`   // Seconds to rise from silence to full level
+	Decay   float64 `yaml:"decay" json:"decay"`     // Seconds to fall from full level to Sustain; 0 holds full level
+	Sustain float64 `yaml:"sustain" json:"sustain"` // Level held after the decay [0, 1]
+	Release float64 `yaml:"release" json:"release"` // Seconds to fall to silence at the end of the scream
+
+	// Points, if set, is the level at given times, in time order; the level
+	// moves linearly between them and holds the nearest one outside them.
+	Points []EnvelopePoint `yaml:"points,omitempty" json:"points,omitempty"`
+}
+
+// EnvelopePoint is a breakpoint of an Envelope.
+type EnvelopePoint struct {
+	Time  float64 `yaml:"time" json:"time"`   // Seconds from the start of the scream
+	Level float64 `yaml:"level" json:"level"` // Level [0, 1]
+}
+
+// DefaultEnvelope returns the envelope of randomized screams and of user
+// presets that do not set one: a fade in and out short enough not to be
+// heard as one, which keeps the start and end of the scream free of clicks.
+func DefaultEnvelope() Envelope {
+	return Envelope{Attack: 0.01, Release: 0.05}
+}
+
+// Shape returns the breakpoints e traces over a scream of length seconds, in
+// the form accepted by ShapeAt, or nil if e keeps the full level throughout.
+// If the attack and release together are longer than the scream, both are
+// shortened in proportion so that the scream still reaches full level.
+func (e Envelope) Shape(length float64) []EnvelopePoint {
+	if len(e.Points) > 0 {
+		e.Attack = 0
+	}
+	if fit := e.Attack + e.Release; fit > length && fit > 0 {
+		e.Attack *= length / fit
+		e.Release *= length / fit
+	}
+
+	var shape []EnvelopePoint
+	switch {
+	case len(e.Points) > 0:
+		shape = append(shape, e.Points...)
+	case e.Attack > 0 || e.Decay > 0:
+		shape = []EnvelopePoint{{0, 1}}
+		if e.Attack > 0 {
+			shape = []EnvelopePoint{{0, 0}, {e.Attack, 1}}
+		}
+		if e.Decay > 0 {
+			shape = append(shape, EnvelopePoint{e.Attack + e.Decay, e.Sustain})
+		}
+	}
+	if e.Release <= 0 {
+		return shape
+	}
+
+	// The release falls linearly from wherever the envelope is when it
+	// starts, cutting short any stage still in progress.
+	start := max(0, length-e.Release)
+	level := ShapeAt(shape, start)
+	i := 0
+	for i < len(shape) && shape[i].Time < start {
+		i++
+	}
+	return append(shape[:i], EnvelopePoint{start, level}, EnvelopePoint{length, 0})
+}
+
+// ShapeAt returns the level at time t of the breakpoints shape, as returned by
+// Envelope.Shape. An empty shape is 1 throughout.
+func ShapeAt(shape []EnvelopePoint, t float64) float64 {
+	if len(shape) == 0 {
+		return 1
+	}
+	if t <= shape[0].Time {
+		return shape[0].Level
+	}
+	for i := 1; i < len(shape); i++ {
+		b := shape[i]
+		if t < b.Time {
+			a := shape[i-1]
+			return a.Level + (b.Level-a.Level)*(t-a.Time)/(b.Time-a.Time)
+		}
+	}
+	return shape[len(shape)-1].Level
+}
+
+// validate reports whether every time of e is non-negative, every level is in
+// [0, 1] and the breakpoints are in time order.
+func (e Envelope) validate() bool {
+	if e.Attack < 0 || e.Decay < 0 || e.Release < 0 || e.Sustain < 0 || e.Sustain > 1 {
+		return false
+	}
+	for i, p := range e.Points {
+		if p.Time < 0 || p.Level < 0 || p.Level > 1 {
+			return false
+		}
+		if i > 0 && p.Time < e.Points[i-1].Time {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns a copy of e that shares no breakpoints with e.
+func (e Envelope) clone() Envelope {
+	if e.Points != nil {
+		e.Points = append([]EnvelopePoint(nil), e.Points...)
+	}
+	return e
+}
@@ -0,0 +1,135 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// Envelope.Shape() and ShapeAt()
+// ---------------------------------------------------------------------------
+
+func TestEnvelope_Shape(t *testing.T) {
+	tests := []struct {
+		name string
+		env  Envelope
+		want []EnvelopePoint
+	}{
+		{"zero is flat", Envelope{}, nil},
+		{"sustain alone is flat", Envelope{Sustain: 0.5}, nil},
+		{"attack", Envelope{Attack: 0.5}, []EnvelopePoint{{0, 0}, {0.5, 1}}},
+		{"decay", Envelope{Decay: 1, Sustain: 0.5}, []EnvelopePoint{{0, 1}, {1, 0.5}}},
+		{"release", Envelope{Release: 1}, []EnvelopePoint{{3, 1}, {4, 0}}},
+		{
+			"adsr",
+			Envelope{Attack: 0.5, Decay: 0.5, Sustain: 0.5, Release: 1},
+			[]EnvelopePoint{{0, 0}, {0.5, 1}, {1, 0.5}, {3, 0.5}, {4, 0}},
+		},
+		{
+			"release cuts the decay short",
+			Envelope{Decay: 4, Release: 2},
+			[]EnvelopePoint{{0, 1}, {2, 0.5}, {4, 0}},
+		},
+		{
+			"attack and release shortened to fit",
+			Envelope{Attack: 4, Release: 4},
+			[]EnvelopePoint{{0, 0}, {2, 1}, {4, 0}},
+		},
+		{
+			"points replace the stages",
+			Envelope{Attack: 3, Points: []EnvelopePoint{{1, 0.2}, {2, 0.8}}, Release: 1},
+			[]EnvelopePoint{{1, 0.2}, {2, 0.8}, {3, 0.8}, {4, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.env.Shape(4); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Shape(4) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvelope_ShapeDoesNotModifyPoints(t *testing.T) {
+	env := Envelope{Points: []EnvelopePoint{{0, 1}, {3.5, 1}}, Release: 1}
+	env.Shape(4)
+	if want := []EnvelopePoint{{0, 1}, {3.5, 1}}; !reflect.DeepEqual(env.Points, want) {
+		t.Errorf("Points = %v after Shape, want %v", env.Points, want)
+	}
+}
+
+func TestShapeAt(t *testing.T) {
+	shape := []EnvelopePoint{{1, 0}, {2, 1}, {2, 0.5}, {4, 0}}
+	tests := []struct {
+		t    float64
+		want float64
+	}{
+		{0, 0},
+		{1, 0},
+		{1.5, 0.5},
+		{2, 0.5},
+		{3, 0.25},
+		{4, 0},
+		{5, 0},
+	}
+	for _, tt := range tests {
+		if got := ShapeAt(shape, tt.t); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("ShapeAt(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+	if got := ShapeAt(nil, 1); got != 1 {
+		t.Errorf("ShapeAt(nil) = %v, want 1", got)
+	}
+}
+
+func TestDefaultEnvelope_FadesInAndOut(t *testing.T) {
+	shape := DefaultEnvelope().Shape(3)
+	if got := ShapeAt(shape, 0); got != 0 {
+		t.Errorf("level at the start = %v, want 0", got)
+	}
+	if got := ShapeAt(shape, 1.5); got != 1 {
+		t.Errorf("level in the middle = %v, want 1", got)
+	}
+	if got := ShapeAt(shape, 3); got != 0 {
+		t.Errorf("level at the end = %v, want 0", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Validate() with envelopes
+// ---------------------------------------------------------------------------
+
+func TestValidate_InvalidEnvelope(t *testing.T) {
+	for _, env := range []Envelope{
+		{Attack: -1},
+		{Decay: -1},
+		{Release: -1},
+		{Sustain: 1.5},
+		{Points: []EnvelopePoint{{-1, 0}}},
+		{Points: []EnvelopePoint{{0, 2}}},
+		{Points: []EnvelopePoint{{1, 0}, {0.5, 1}}},
+	} {
+		p := validBaseParams()
+		p.Envelope = env
+		if err := p.Validate(); !errors.Is(err, ErrInvalidEnvelope) {
+			t.Errorf("Validate() with envelope %+v = %v, want ErrInvalidEnvelope", env, err)
+		}
+
+		p = validBaseParams()
+		p.Layers[2].Envelope = env
+		err := p.Validate()
+		var lve *LayerValidationError
+		if !errors.As(err, &lve) || lve.Layer != 2 || !errors.Is(err, ErrInvalidEnvelope) {
+			t.Errorf("Validate() with layer envelope %+v = %v, want layer 2 ErrInvalidEnvelope", env, err)
+		}
+	}
+
+	p := validBaseParams()
+	p.Envelope = Envelope{Attack: 0.1, Decay: 0.2, Sustain: 0.5, Release: 10}
+	p.Layers[0].Envelope = Envelope{Points: []EnvelopePoint{{0, 0}, {0.5, 1}, {0.5, 0.2}}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() with valid envelopes = %v, want nil", err)
+	}
+}
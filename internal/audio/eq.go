@@ -0,0 +1,73 @@
+package audio
+
+import "math"
+
+// Bounds of the biquad filters: the number of bands of an EQ, the Q of any
+// band or of the resonant high- and low-pass filters, and the boost or cut
+// of a peak or shelf in dB.
+const (
+	maxEQBands = 8
+	maxFilterQ = 20
+	maxEQGain  = 24
+)
+
+// ButterworthQ is the Q of a biquad filter whose Q is left at 0: the
+// flattest passband there is, with no resonant peak at the cutoff.
+const ButterworthQ = math.Sqrt2 / 2
+
+// EQType names the response of a band of a parametric EQ.
+type EQType string
+
+const (
+	EQPeak      EQType = "peak"       // boosts or cuts around Freq by Gain
+	EQLowShelf  EQType = "low_shelf"  // boosts or cuts everything below Freq by Gain
+	EQHighShelf EQType = "high_shelf" // boosts or cuts everything above Freq by Gain
+	EQLowpass   EQType = "lowpass"    // cuts above Freq at 12 dB/octave
+	EQHighpass  EQType = "highpass"   // cuts below Freq at 12 dB/octave
+	EQBandpass  EQType = "bandpass"   // keeps only a band around Freq
+	EQNotch     EQType = "notch"      // removes a band around Freq
+)
+
+// EQBand is one band of a parametric EQ: a biquad filter with the response
+// of Type. The higher its Q, the narrower a peak, band or notch and the more
+// a low- or high-pass filter resonates at its cutoff.
+type EQBand struct {
+	Type EQType  `yaml:"type" json:"type"`
+	Freq float64 `yaml:"freq" json:"freq"` // Centre or corner frequency in Hz, below the Nyquist frequency
+	Q    float64 `yaml:"q" json:"q"`       // Sharpness [0, 20]; 0 is ButterworthQ
+	Gain float64 `yaml:"gain" json:"gain"` // Boost or cut in dB [-24, 24] of a peak or shelf; ignored by the other types
+}
+
+// FilterQ returns q, or ButterworthQ if q is 0, as the Q of a biquad filter.
+func FilterQ(q float64) float64 {
+	if q == 0 {
+		return ButterworthQ
+	}
+	return q
+}
+
+// validEQ reports whether the Qs of the resonant high- and low-pass filters
+// of f are in range, and its EQ has at most maxEQBands bands, each of a
+// known type with a frequency below the Nyquist frequency of sampleRate, a
+// Q in range and a gain within maxEQGain dB.
+func (f FilterParams) validEQ(sampleRate int) bool {
+	if !validQ(f.HighpassQ) || !validQ(f.LowpassQ) || len(f.EQ) > maxEQBands {
+		return false
+	}
+	for _, b := range f.EQ {
+		switch b.Type {
+		case EQPeak, EQLowShelf, EQHighShelf, EQLowpass, EQHighpass, EQBandpass, EQNotch:
+		default:
+			return false
+		}
+		if b.Freq <= 0 || b.Freq >= float64(sampleRate)/2 || !validQ(b.Q) || math.Abs(b.Gain) > maxEQGain {
+			return false
+		}
+	}
+	return true
+}
+
+// validQ reports whether q is a Q that validEQ accepts.
+func validQ(q float64) bool {
+	return q >= 0 && q <= maxFilterQ
+}
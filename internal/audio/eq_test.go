@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate_EQ(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *FilterParams)
+		ok     bool
+	}{
+		{"no EQ", func(f *FilterParams) {}, true},
+		{"biquad", func(f *FilterParams) { f.Biquad, f.HighpassQ, f.LowpassQ = true, 0.5, 20 }, true},
+		{"every band", func(f *FilterParams) {
+			f.EQ = []EQBand{
+				{Type: EQPeak, Freq: 3000, Q: 2, Gain: -24},
+				{Type: EQLowShelf, Freq: 200, Gain: 24},
+				{Type: EQHighShelf, Freq: 8000, Gain: 3},
+				{Type: EQLowpass, Freq: 12000, Q: 5},
+				{Type: EQHighpass, Freq: 80},
+				{Type: EQBandpass, Freq: 1000, Q: 10},
+				{Type: EQNotch, Freq: 50, Q: 20},
+			}
+		}, true},
+		{"negative highpass Q", func(f *FilterParams) { f.HighpassQ = -1 }, false},
+		{"lowpass Q too high", func(f *FilterParams) { f.LowpassQ = 21 }, false},
+		{"too many bands", func(f *FilterParams) { f.EQ = make([]EQBand, 9) }, false},
+		{"unknown type", func(f *FilterParams) { f.EQ = []EQBand{{Type: "tilt", Freq: 1000}} }, false},
+		{"no frequency", func(f *FilterParams) { f.EQ = []EQBand{{Type: EQPeak}} }, false},
+		{"frequency at Nyquist", func(f *FilterParams) { f.EQ = []EQBand{{Type: EQPeak, Freq: 24000}} }, false},
+		{"negative Q", func(f *FilterParams) { f.EQ = []EQBand{{Type: EQNotch, Freq: 1000, Q: -1}} }, false},
+		{"gain too high", func(f *FilterParams) { f.EQ = []EQBand{{Type: EQPeak, Freq: 1000, Gain: 25}} }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			tt.modify(&p.Filter)
+			err := p.Validate()
+			if tt.ok && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidEQ) {
+				t.Errorf("Validate() = %v, want ErrInvalidEQ", err)
+			}
+		})
+	}
+}
+
+func TestFilterQ(t *testing.T) {
+	if got := FilterQ(0); got != ButterworthQ {
+		t.Errorf("FilterQ(0) = %v, want %v", got, ButterworthQ)
+	}
+	if got := FilterQ(3); got != 3 {
+		t.Errorf("FilterQ(3) = %v, want 3", got)
+	}
+}
@@ -13,8 +13,33 @@ var (
 	ErrInvalidFilterCutoff = errors.New("filter cutoff must be non-negative")
 	ErrInvalidLimiterLevel = errors.New("limiter level must be between 0 and 1 (exclusive of 0)")
 	ErrInvalidCrusherBits  = errors.New("crusher bits must be between 1 and 16")
+	ErrInvalidLimiter      = errors.New("limiter must be hard or lookahead, attack between 0 and 20 ms and release between 0 and 2000 ms")
+	ErrInvalidPan          = errors.New("pan must be between -1 and 1")
+	ErrInvalidAutoPan      = errors.New("auto-pan rate must be non-negative and depth between 0 and 1")
+	ErrInvalidWidth        = errors.New("stereo width must be between 0 and 1")
+	ErrNoLayers            = errors.New("at least one layer is required")
+	ErrInvalidLayerType    = errors.New("unknown layer type")
+	ErrInvalidVowel        = errors.New("vowel must contain only the letters a, e, i, o and u")
+	ErrInvalidPerturbation = errors.New("jitter and shimmer must be between 0 and 1")
+	ErrInvalidLevels       = errors.New("levels must be between 0 and 1")
+	ErrInvalidText         = errors.New("text must contain between 1 and 100 letters")
+	ErrInvalidEnvelope     = errors.New("envelope times must be non-negative and in order, and levels between 0 and 1")
+	ErrInvalidPitch        = errors.New("pitch curve must be rising, falling or arch, times non-negative and in order, and bends within 48 semitones")
+	ErrInvalidModulation   = errors.New("vibrato and tremolo rates must be non-negative, vibrato depth between 0 and 12 semitones and tremolo depth between 0 and 1")
+	ErrInvalidWaveform     = errors.New("waveform must be sine, saw, square, triangle, pulse or supersaw, pulse width between 0 and 1 and detune between 0 and 100 cents")
+	ErrInvalidEQ           = errors.New("filter Qs must be between 0 and 20, and an EQ have at most 8 bands, each peak, low_shelf, high_shelf, lowpass, highpass, bandpass or notch, with a frequency below the Nyquist frequency, Q between 0 and 20 and gain within 24 dB")
+	ErrInvalidNoise        = errors.New("noise colour must be white, pink, brown, blue, band or breath, and band noise needs a frequency below the Nyquist frequency and Q between 0 and 20")
+	ErrInvalidReverb       = errors.New("reverb mix, room size and damping must be between 0 and 1, and pre-delay between 0 and 500 ms")
+	ErrInvalidDelay        = errors.New("delay mix must be between 0 and 1, time between 0 and 2000 ms and set if the delay is heard, feedback between 0 and 0.9 and low-pass cutoff non-negative")
+	ErrInvalidDistortion   = errors.New("distortion must be soft, hard, tube or fold, drive between 0 and 48 dB and tone non-negative")
+	ErrInvalidMorph        = errors.New("invalid morph target")
+	ErrInvalidVoices       = errors.New("voices must be between 0 and 16, spread between 0 and 1 and every voice mix valid")
 )
 
+// ErrCancelled is returned when generation is stopped because its context was
+// cancelled or its deadline expired. Errors wrapping it also wrap ctx.Err().
+var ErrCancelled = errors.New("generation cancelled")
+
 // LayerValidationError wraps an error with the layer index.
 type LayerValidationError struct {
 	Layer int
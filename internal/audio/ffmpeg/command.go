@@ -3,6 +3,7 @@ package ffmpeg
 import (
 	"fmt"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -13,8 +14,59 @@ import (
 // the background noise layer, decorrelating it from other random streams.
 const backgroundNoiseSeedOffset = 7777
 
+// rightNoiseSeedOffset is added to the aevalsrc time expression seed for the
+// independent right-channel noise source of a stereo noise layer. The layer
+// index is added on top so that each noise layer gets its own source.
+const rightNoiseSeedOffset = 9901
+
+// Vocal layer approximation. aevalsrc cannot run the native backend's
+// formant filters, so a vocal layer is drawn as a sum of harmonics weighted by
+// the formant responses, with jitter and shimmer as slow pitch and loudness
+// wobbles at fixed rates.
+const (
+	vocalHarmonics   = 12
+	vocalMaxFreq     = 20000 // harmonics at or above this are dropped (Hz)
+	vocalJitterRate  = 7.3   // pitch wobble rate standing in for jitter (Hz)
+	vocalShimmerRate = 11.1  // loudness wobble rate standing in for shimmer (Hz)
+	vocalExprGain    = 0.7   // brings the harmonic sum near the native level
+)
+
+// Waveform approximation. aevalsrc cannot band-limit a wave as the native
+// backend does, so every wave but the sine is drawn as a sum of its first
+// harmonics, each dropped once it reaches waveMaxFreq.
+const (
+	waveHarmonics     = 16    // harmonics drawn of each wave
+	supersawHarmonics = 8     // harmonics drawn of each supersaw voice
+	waveMaxFreq       = 20000 // harmonics at or above this are dropped (Hz)
+)
+
+// Reverb approximation. FFmpeg has no Freeverb, so the reverb is drawn with
+// aecho as reverbEchoTaps echoes spaced ever wider from the shortest comb
+// delay to the reverb time, each decaying as the comb filters would. The
+// echoes are mixed to the loudness of the dry signal. Damping is ignored.
+const reverbEchoTaps = 16
+
+// The shortest attack and release alimiter accepts, in ms.
+const (
+	alimiterMinAttack  = 0.1
+	alimiterMinRelease = 1
+)
+
+// Coloured noise. aevalsrc can only draw white noise, so noise layers of
+// any other colour are drawn by anoisesrc, filtered for band and breath
+// noise, and scaled by anoisesrcGains to the loudness of white noise. Each
+// is multiplied by an aevalsrc of the layer's gain over time and mixed with
+// the other layers. FFmpeg's blue noise is pink noise mirrored in frequency,
+// which rises by 3 dB per octave only in the upper octaves.
+var anoisesrcGains = map[audio.NoiseColor]float64{
+	audio.NoisePink:  3.008,
+	audio.NoiseBrown: 2.877,
+	audio.NoiseBlue:  3.008,
+}
+
 // buildArgs builds the complete FFmpeg CLI argument list from ScreamParams.
-// The output is raw s16le PCM written to stdout (pipe:1).
+// The output is raw s16le PCM written to stdout (pipe:1). A scream with
+// coloured noise is built as a filter graph by noiseGraph.
 func buildArgs(params audio.ScreamParams) []string {
 	sampleRate := strconv.Itoa(params.SampleRate)
 	channels := strconv.Itoa(params.Channels)
@@ -23,39 +75,234 @@ func buildArgs(params audio.ScreamParams) []string {
 
 	aevalsrcExpr := buildAevalsrcExpr(params)
 	aevalsrcArg := fmt.Sprintf("aevalsrc='%s':s=%s:d=%s", aevalsrcExpr, sampleRate, durationStr)
+	if params.IsStereo() {
+		aevalsrcArg = fmt.Sprintf("aevalsrc='%s':c=stereo:s=%s:d=%s", aevalsrcExpr, sampleRate, durationStr)
+	}
 
 	filterChain := buildFilterChain(params.Filter)
 
-	return []string{
-		"-nostdin",
-		"-v", "quiet",
-		"-f", "lavfi",
-		"-i", aevalsrcArg,
-		"-af", filterChain,
+	args := []string{"-nostdin", "-v", "quiet"}
+	if hasColoredNoise(params) {
+		args = append(args, "-filter_complex", noiseGraph(params, aevalsrcArg, filterChain))
+	} else {
+		args = append(args, "-f", "lavfi", "-i", aevalsrcArg, "-af", filterChain)
+	}
+	if tail := params.Tail(); tail > 0 {
+		// aecho plays its echoes out after the padding too; cut them off
+		// where the native backend stops.
+		total := (params.Duration + tail).Seconds()
+		args = append(args, "-t", strconv.FormatFloat(total, 'f', -1, 64))
+	}
+	return append(args,
 		"-f", "s16le",
 		"-acodec", "pcm_s16le",
 		"-ac", channels,
 		"-ar", sampleRate,
 		"pipe:1",
-	}
+	)
 }
 
-// buildAevalsrcExpr builds the aevalsrc expression by summing all active layers.
+// buildAevalsrcExpr builds the aevalsrc expression by summing all active layers
+// and applying the scream's envelope to the sum. Stereo params produce
+// separate left and right expressions joined by "|". Layers of coloured
+// noise are left to noiseGraph.
 func buildAevalsrcExpr(params audio.ScreamParams) string {
+	shape := params.Envelope.Shape(params.Duration.Seconds())
+	if params.IsStereo() {
+		left := make([]string, 0, len(params.Layers))
+		right := make([]string, 0, len(params.Layers))
+		for i, layer := range params.Layers {
+			if coloredNoise(layer) {
+				continue
+			}
+			l, r := stereoLayerExprs(layer, params, i)
+			left = append(left, l)
+			right = append(right, r)
+		}
+		return envelopeExpr(shape, sumExpr(left)) + "|" + envelopeExpr(shape, sumExpr(right))
+	}
+
 	parts := make([]string, 0, len(params.Layers))
 	for i, layer := range params.Layers {
-		expr := layerExpr(layer, params.Noise, params.Seed, i)
+		if coloredNoise(layer) {
+			continue
+		}
+		expr := layerExpr(layer, params.Seed, i, params.Duration.Seconds())
 		parts = append(parts, expr)
 	}
+	return envelopeExpr(shape, sumExpr(parts))
+}
+
+// sumExpr joins the expressions parts into their sum, or "0" if there are
+// none.
+func sumExpr(parts []string) string {
+	if len(parts) == 0 {
+		return "0"
+	}
 	return strings.Join(parts, "+")
 }
 
+// coloredNoise reports whether layer is a noise layer of a colour that
+// aevalsrc cannot draw, and so is drawn by noiseGraph.
+func coloredNoise(layer audio.LayerParams) bool {
+	switch layer.Type {
+	case audio.LayerNoiseBurst, audio.LayerBackgroundNoise:
+		return layer.Amplitude != 0 && layer.Noise.Color != "" && layer.Noise.Color != audio.NoiseWhite
+	default:
+		return false
+	}
+}
+
+// hasColoredNoise reports whether any layer of params is coloured noise.
+func hasColoredNoise(params audio.ScreamParams) bool {
+	return slices.ContainsFunc(params.Layers, coloredNoise)
+}
+
+// noiseGraph builds the filter graph of a scream with coloured noise: the
+// aevalsrc source of its other layers, src, mixed with the noise of each
+// coloured noise layer and put through filterChain. The noise of layer i is
+// labelled n<i> and its gain over time, which is the layer's expression with
+// its noise taken as 1, g<i>; their product is l<i>.
+func noiseGraph(params audio.ScreamParams, src, filterChain string) string {
+	sampleRate := strconv.Itoa(params.SampleRate)
+	duration := params.Duration.Seconds()
+	durationStr := strconv.FormatFloat(duration, 'f', -1, 64)
+	shape := params.Envelope.Shape(duration)
+
+	graph := []string{src + "[main]"}
+	mix := "[main]"
+	for i, layer := range params.Layers {
+		if !coloredNoise(layer) {
+			continue
+		}
+		seed := deriveSeed(params.Seed, layer.Seed, i)
+		noise := anoisesrcExpr(layer.Noise, seed, sampleRate, durationStr)
+		shaping := noiseShapingExpr(layer.Noise, params.SampleRate)
+		var gain string
+		if params.IsStereo() {
+			same := 1 - params.Width
+			diff := math.Sqrt(1 - same*same)
+			right := anoisesrcExpr(layer.Noise, seed+rightNoiseSeedOffset, sampleRate, durationStr)
+			graph = append(graph,
+				fmt.Sprintf("%s[n%da]", noise, i),
+				fmt.Sprintf("%s[n%db]", right, i),
+				fmt.Sprintf("[n%da][n%db]amerge=inputs=2,pan=stereo|c0=c0|c1=%s*c0+%s*c1,%s[n%d]",
+					i, i, fmtFloat(same), fmtFloat(diff), shaping, i),
+			)
+			l, r := stereoLayerExprs(layer, params, i)
+			gain = fmt.Sprintf("aevalsrc='%s|%s':c=stereo:s=%s:d=%s",
+				envelopeExpr(shape, l), envelopeExpr(shape, r), sampleRate, durationStr)
+		} else {
+			graph = append(graph, fmt.Sprintf("%s,%s[n%d]", noise, shaping, i))
+			gain = fmt.Sprintf("aevalsrc='%s':s=%s:d=%s",
+				envelopeExpr(shape, layerExpr(layer, params.Seed, i, duration)), sampleRate, durationStr)
+		}
+		graph = append(graph,
+			fmt.Sprintf("%s[g%d]", gain, i),
+			fmt.Sprintf("[n%d][g%d]amultiply[l%d]", i, i, i),
+		)
+		mix += fmt.Sprintf("[l%d]", i)
+	}
+	inputs := strings.Count(mix, "[")
+	graph = append(graph, fmt.Sprintf("%samix=inputs=%d:normalize=0,%s", mix, inputs, filterChain))
+	return strings.Join(graph, ";")
+}
+
+// anoisesrcExpr builds the anoisesrc source of noise n: FFmpeg's noise of
+// its colour, or white noise to be filtered for band and breath noise.
+func anoisesrcExpr(n audio.NoiseParams, seed int64, sampleRate, duration string) string {
+	color := "white"
+	if _, ok := anoisesrcGains[n.Color]; ok {
+		color = string(n.Color)
+	}
+	return fmt.Sprintf("anoisesrc=c=%s:r=%s:a=1:d=%s:s=%d", color, sampleRate, duration, seed)
+}
+
+// noiseShapingExpr builds the filters that follow the anoisesrc of noise n:
+// the filters of band and breath noise, then a volume filter bringing it to
+// the loudness of white noise.
+func noiseShapingExpr(n audio.NoiseParams, sampleRate int) string {
+	var filters []string
+	for _, b := range n.Bands() {
+		filters = append(filters, eqBandExpr(b))
+	}
+	gain := n.FilterGain(sampleRate)
+	if g, ok := anoisesrcGains[n.Color]; ok {
+		gain = g
+	}
+	return strings.Join(append(filters, fmt.Sprintf("volume=%s", fmtFloat(gain))), ",")
+}
+
+// stereoLayerExprs builds the left and right channel expressions for a
+// single layer. The layer is positioned with the same balance pan law as the
+// native backend: the nearer channel keeps unity gain and the farther one is
+// attenuated linearly. Noise layers get a decorrelated right channel.
+func stereoLayerExprs(layer audio.LayerParams, params audio.ScreamParams, index int) (string, string) {
+	duration := params.Duration.Seconds()
+	left := layerExpr(layer, params.Seed, index, duration)
+	if left == "0" {
+		return "0", "0"
+	}
+	right := channelLayerExpr(layer, params.Seed, index, duration, params.Width)
+
+	pos := panExpr(layer, params.Width, deriveSeed(params.Seed, layer.Seed, index))
+	if pos == "" {
+		return left, right
+	}
+	return fmt.Sprintf("min(1,1-(%s))*(%s)", pos, left), fmt.Sprintf("min(1,1+(%s))*(%s)", pos, right)
+}
+
+// panExpr builds the pan position expression for a layer, or "" when the
+// layer is centered and not auto-panned. width scales the static position and
+// the auto-pan depth; seed picks the starting phase of the auto-pan LFO.
+func panExpr(layer audio.LayerParams, width float64, seed int64) string {
+	pan := layer.Pan * width
+	depth := layer.PanDepth * width
+	if layer.PanRate > 0 && depth > 0 {
+		phase := float64(seed%1000) / 1000
+		return fmt.Sprintf("clip(%s+%s*sin(2*PI*(%s*t+%s)),-1,1)",
+			fmtFloat(pan), fmtFloat(depth), fmtFloat(layer.PanRate), fmtFloat(phase))
+	}
+	if pan == 0 {
+		return ""
+	}
+	return fmtFloat(max(-1, min(1, pan)))
+}
+
 // layerExpr builds the FFmpeg aevalsrc expression for a single synthesis layer.
-// Zero-amplitude layers return "0".
-func layerExpr(layer audio.LayerParams, noise audio.NoiseParams, globalSeed int64, index int) string {
+// duration is the length of the scream in seconds. Zero-amplitude layers
+// return "0".
+func layerExpr(layer audio.LayerParams, globalSeed int64, index int, duration float64) string {
+	return channelLayerExpr(layer, globalSeed, index, duration, 0)
+}
+
+// channelLayerExpr builds a layer expression whose noise, if any, is blended
+// with an independent source by decorrelation in [0, 1]. A decorrelation of 0
+// yields the same expression as layerExpr. A layer with Levels is scaled by
+// them over duration, and then by its envelope and tremolo.
+func channelLayerExpr(layer audio.LayerParams, globalSeed int64, index int, duration, decorrelation float64) string {
+	expr := sourceExpr(layer, globalSeed, index, duration, decorrelation)
+	if expr == "0" {
+		return expr
+	}
+	if len(layer.Levels) > 0 {
+		expr = fmt.Sprintf("%s*(%s)", piecewiseExpr(layer.Levels, duration), expr)
+	}
+	expr = envelopeExpr(layer.Envelope.Shape(duration), expr)
+	if layer.Tremolo.On() {
+		expr = fmt.Sprintf("(1-%s*(0.5+0.5*sin(2*PI*%s*t)))*(%s)",
+			fmtFloat(layer.Tremolo.Depth), fmtFloat(layer.Tremolo.Rate), expr)
+	}
+	return expr
+}
+
+// sourceExpr builds the expression for the sound of a layer before its
+// levels are applied, as described by channelLayerExpr.
+func sourceExpr(layer audio.LayerParams, globalSeed int64, index int, duration, decorrelation float64) string {
 	seed := deriveSeed(globalSeed, layer.Seed, index)
 	seedStr := strconv.FormatInt(seed, 10)
 	sampleRate := "48000" // aevalsrc uses its own sample rate; use a constant for the random seeding
+	warp, ratio := warpExpr(layer, duration), ratioExpr(layer, duration)
 
 	switch layer.Type {
 	case audio.LayerPrimaryScream:
@@ -67,10 +314,8 @@ func layerExpr(layer audio.LayerParams, noise audio.NoiseParams, globalSeed int6
 		baseFreq := fmtFloat(layer.BaseFreq)
 		freqRange := fmtFloat(layer.FreqRange)
 		jumpRate := fmtFloat(layer.JumpRate)
-		return fmt.Sprintf(
-			"%s*(1+%s*t)*sin(2*PI*t*(%s+%s*random(floor(t*%s)*%d+%s)))",
-			amp, rise, baseFreq, freqRange, jumpRate, audio.CoprimePrimaryScream, seedStr,
-		)
+		freq := fmt.Sprintf("%s+%s*random(floor(t*%s)*%d+%s)", baseFreq, freqRange, jumpRate, audio.CoprimePrimaryScream, seedStr)
+		return fmt.Sprintf("%s*(1+%s*t)*%s", amp, rise, waveExpr(layer, freq, warp, ratio))
 
 	case audio.LayerHarmonicSweep:
 		if layer.Amplitude == 0 {
@@ -81,10 +326,9 @@ func layerExpr(layer audio.LayerParams, noise audio.NoiseParams, globalSeed int6
 		sweepRate := fmtFloat(layer.SweepRate)
 		freqRange := fmtFloat(layer.FreqRange)
 		jumpRate := fmtFloat(layer.JumpRate)
-		return fmt.Sprintf(
-			"%s*sin(2*PI*t*(%s+%s*t+%s*random(floor(t*%s)*%d+%s)))",
-			amp, baseFreq, sweepRate, freqRange, jumpRate, audio.CoprimeHarmonicSweep, seedStr,
-		)
+		freq := fmt.Sprintf("%s+%s*t+%s*random(floor(t*%s)*%d+%s)",
+			baseFreq, sweepRate, freqRange, jumpRate, audio.CoprimeHarmonicSweep, seedStr)
+		return fmt.Sprintf("%s*%s", amp, waveExpr(layer, freq, warp, ratio))
 
 	case audio.LayerHighShriek:
 		if layer.Amplitude == 0 {
@@ -95,47 +339,362 @@ func layerExpr(layer audio.LayerParams, noise audio.NoiseParams, globalSeed int6
 		baseFreq := fmtFloat(layer.BaseFreq)
 		freqRange := fmtFloat(layer.FreqRange)
 		jumpRate := fmtFloat(layer.JumpRate)
-		return fmt.Sprintf(
-			"%s*(1+%s*t)*sin(2*PI*t*(%s+%s*random(floor(t*%s)*%d+%s)))",
-			amp, rise, baseFreq, freqRange, jumpRate, audio.CoprimeHighShriek, seedStr,
-		)
+		freq := fmt.Sprintf("%s+%s*random(floor(t*%s)*%d+%s)", baseFreq, freqRange, jumpRate, audio.CoprimeHighShriek, seedStr)
+		return fmt.Sprintf("%s*(1+%s*t)*%s", amp, rise, waveExpr(layer, freq, warp, ratio))
 
 	case audio.LayerNoiseBurst:
-		burstAmp := noise.BurstAmp
-		if burstAmp == 0 {
+		if layer.Amplitude == 0 {
 			return "0"
 		}
-		burstSeed := deriveSeed(globalSeed, noise.BurstSeed, index)
-		burstSeedStr := strconv.FormatInt(burstSeed, 10)
-		burstAmpStr := fmtFloat(burstAmp)
-		burstRateStr := fmtFloat(noise.BurstRate)
-		thresholdStr := fmtFloat(noise.Threshold)
+		burstAmpStr := fmtFloat(layer.Amplitude)
+		burstRateStr := fmtFloat(layer.BurstRate)
+		thresholdStr := fmtFloat(layer.Threshold)
+		white := noiseExpr(layer, fmt.Sprintf("(2*random(t*%s)-1)", sampleRate), decorrelation, index)
 		return fmt.Sprintf(
-			"%s*gt(random(floor(t*%s)*%d+%s),%s)*(2*random(t*%s)-1)",
-			burstAmpStr, burstRateStr, audio.CoprimeNoiseBurst, burstSeedStr, thresholdStr, sampleRate,
+			"%s*gt(random(floor(t*%s)*%d+%s),%s)*%s",
+			burstAmpStr, burstRateStr, audio.CoprimeNoiseBurst, seedStr, thresholdStr, white,
 		)
 
 	case audio.LayerBackgroundNoise:
-		floorAmp := noise.FloorAmp
-		if floorAmp == 0 {
+		if layer.Amplitude == 0 {
 			return "0"
 		}
-		floorAmpStr := fmtFloat(floorAmp)
-		return fmt.Sprintf(
-			"%s*(2*random(t*%s+%d)-1)",
-			floorAmpStr, sampleRate, backgroundNoiseSeedOffset,
-		)
+		floorAmpStr := fmtFloat(layer.Amplitude)
+		white := noiseExpr(layer, fmt.Sprintf("(2*random(t*%s+%d)-1)", sampleRate, backgroundNoiseSeedOffset), decorrelation, index)
+		return fmt.Sprintf("%s*%s", floorAmpStr, white)
+
+	case audio.LayerVocal:
+		if layer.Amplitude == 0 {
+			return "0"
+		}
+		return vocalExpr(layer, seed, duration, warp)
 
 	default:
 		return "0"
 	}
 }
 
+// vocalExpr builds the expression for a vocal layer: vocalHarmonics harmonics
+// of the jumping pitch, falling off as 1/k and weighted by the band-pass
+// response of each formant of the vowel sung at time t. The pitch is stored in
+// variable 0 and the formant frequencies and gains in variables 1 to 5 so that
+// they are computed once per sample. warp is the layer's warpExpr; the pitch
+// bend it adds moves the harmonics but not the formant weights.
+func vocalExpr(layer audio.LayerParams, seed int64, duration float64, warp string) string {
+	phase := fmtFloat(float64(seed%1000) / 1000)
+	pitch := fmt.Sprintf("(%s+%s*random(floor(t*%s)*%d+%d))",
+		fmtFloat(layer.BaseFreq), fmtFloat(layer.FreqRange), fmtFloat(layer.JumpRate), audio.CoprimeVocal, seed)
+
+	freqs, gains := vowelTrackExprs(layer.Vowel, duration)
+	stores := []string{
+		fmt.Sprintf("st(0,%s)", pitch),
+		fmt.Sprintf("st(1,%s)", freqs[0]),
+		fmt.Sprintf("st(2,%s)", freqs[1]),
+		fmt.Sprintf("st(3,%s)", freqs[2]),
+		fmt.Sprintf("st(4,%s)", gains[1]),
+		fmt.Sprintf("st(5,%s)", gains[2]),
+	}
+
+	// Phase of the pitch with a sinusoidal wobble of relative depth jitter.
+	jitterRate := fmtFloat(vocalJitterRate)
+	cycles := fmt.Sprintf("ld(0)*(%s+%s/(2*PI*%s)*sin(2*PI*(%s*t+%s)))",
+		warp, fmtFloat(layer.Jitter), jitterRate, jitterRate, phase)
+
+	harmonics := make([]string, 0, vocalHarmonics)
+	for k := 1; k <= vocalHarmonics; k++ {
+		f := fmt.Sprintf("%d*ld(0)", k)
+		// Band-pass response 1/sqrt(1+Q^2*(f/F-F/f)^2) with Q = F/B and a
+		// bandwidth B of at least half the pitch, as in the native layer.
+		resp := func(freqVar int) string {
+			return fmt.Sprintf("1/sqrt(1+pow(ld(%d)/max(60,ld(0)/2)*(%s/ld(%d)-ld(%d)/(%s)),2))", freqVar, f, freqVar, freqVar, f)
+		}
+		weight := fmt.Sprintf("(%s+ld(4)*%s+ld(5)*%s)", resp(1), resp(2), resp(3))
+		harmonics = append(harmonics, fmt.Sprintf("lt(%s,%d)*%s/%d*sin(2*PI*%d*%s)", f, vocalMaxFreq, weight, k, k, cycles))
+	}
+
+	shimmer := fmt.Sprintf("(1-%s*(0.5+0.5*sin(2*PI*(%s*t+%s))))",
+		fmtFloat(layer.Shimmer), fmtFloat(vocalShimmerRate), phase)
+	envelope := fmt.Sprintf("%s*(1+%s*t)*%s", fmtFloat(layer.Amplitude*vocalExprGain), fmtFloat(layer.Rise), shimmer)
+
+	return fmt.Sprintf("(%s;%s*gt(ld(0),0)*(%s))",
+		strings.Join(stores, ";"), envelope, strings.Join(harmonics, "+"))
+}
+
+// warpExpr returns an expression for the time warped by the pitch bend of a
+// tonal layer: the phase, in cycles, of a tone of 1 Hz bent by the layer's
+// pitch contour and vibrato. A tone of frequency f has phase f times it. The
+// contour is integrated exactly; the vibrato is added to first order, as a
+// sinusoidal wobble of the phase. It returns "t" for a layer without a bend.
+func warpExpr(layer audio.LayerParams, duration float64) string {
+	warp, ratio := "t", ratioExpr(layer, duration)
+	if shape := layer.Pitch.Shape(duration); shape != nil {
+		warp = contourWarpExpr(shape)
+	}
+	if layer.Vibrato.On() {
+		// A wobble of d semitones changes the frequency by about d*ln(2)/12.
+		depth := layer.Vibrato.Depth * math.Ln2 / 12
+		rate := fmtFloat(layer.Vibrato.Rate)
+		warp = fmt.Sprintf("(%s+%s*%s/(2*PI*%s)*(1-cos(2*PI*%s*t)))", warp, ratio, fmtFloat(depth), rate, rate)
+	}
+	return warp
+}
+
+// ratioExpr returns an expression for the frequency ratio by which the pitch
+// contour of a tonal layer bends it, or "1" if the contour is flat.
+func ratioExpr(layer audio.LayerParams, duration float64) string {
+	if shape := layer.Pitch.Shape(duration); shape != nil {
+		return fmt.Sprintf("pow(2,(%s)/12)", shapeExpr(shape))
+	}
+	return "1"
+}
+
+// waveExpr builds the expression for the wave of a tonal layer, in [-1, 1],
+// at frequency freq bent by the layer's warpExpr warp and ratioExpr ratio. A
+// sine is drawn exactly and the other waves as sums of harmonics, with the
+// frequency stored in variable 6, the phase in variable 7 and the bent
+// frequency in variable 8 so that they are computed once per sample.
+func waveExpr(layer audio.LayerParams, freq, warp, ratio string) string {
+	var wave string
+	switch layer.Waveform {
+	case audio.WaveSaw, audio.WaveSquare, audio.WaveTriangle, audio.WavePulse:
+		wave = harmonicWaveExpr(layer.Waveform, layer.PulseWidth, "ld(7)", "ld(8)", waveHarmonics)
+	case audio.WaveSupersaw:
+		var power float64
+		voices := make([]string, len(audio.SupersawVoices))
+		for i, v := range audio.SupersawVoices {
+			r := fmtFloat(math.Exp2(v.Detune * layer.Detune / 1200))
+			cycles := fmt.Sprintf("(ld(7)*%s+%s)", r, fmtFloat(v.Phase))
+			voices[i] = fmt.Sprintf("%s*%s", fmtFloat(v.Gain),
+				harmonicWaveExpr(audio.WaveSaw, 0, cycles, "ld(8)*"+r, supersawHarmonics))
+			power += v.Gain * v.Gain
+		}
+		wave = fmt.Sprintf("%s*(%s)", fmtFloat(1/math.Sqrt(power)), strings.Join(voices, "+"))
+	default:
+		return fmt.Sprintf("sin(2*PI*%s*(%s))", warp, freq)
+	}
+	return fmt.Sprintf("(st(6,%s);st(7,%s*ld(6));st(8,ld(6)*%s);%s)", freq, warp, ratio, wave)
+}
+
+// harmonicWaveExpr builds the expression for the first n harmonics of wave,
+// at phase cycles and frequency freq, matching the shape the native
+// oscillator draws. A pulse wave is 1 for width of each cycle and -1 for the
+// rest; width is ignored by the other waves.
+func harmonicWaveExpr(wave audio.Waveform, width float64, cycles, freq string, n int) string {
+	terms := make([]string, 0, n)
+	term := func(k int, coef float64, fn string, shift float64) {
+		gate := fmt.Sprintf("lt(%d*%s,%d)", k, freq, waveMaxFreq)
+		arg := fmt.Sprintf("%d*%s", k, cycles)
+		if shift != 0 {
+			arg = fmt.Sprintf("%d*(%s-%s)", k, cycles, fmtFloat(shift))
+		}
+		terms = append(terms, fmt.Sprintf("%s*%s*%s(2*PI*%s)", gate, fmtFloat(coef), fn, arg))
+	}
+
+	var scale, offset float64
+	switch wave {
+	case audio.WaveSaw:
+		// A rising ramp has every harmonic, falling off as 1/k.
+		scale = -2 / math.Pi
+		for k := 1; k <= n; k++ {
+			term(k, 1/float64(k), "sin", 0)
+		}
+	case audio.WaveSquare:
+		scale = 4 / math.Pi
+		for j := range n {
+			k := 2*j + 1
+			term(k, 1/float64(k), "sin", 0)
+		}
+	case audio.WaveTriangle:
+		// Odd harmonics falling off as 1/k^2, alternating in sign.
+		scale = 8 / (math.Pi * math.Pi)
+		for j := range n {
+			k := 2*j + 1
+			coef := 1 / float64(k*k)
+			if j%2 == 1 {
+				coef = -coef
+			}
+			term(k, coef, "sin", 0)
+		}
+	case audio.WavePulse:
+		// A pulse centred on width/2, offset by its mean 2*width-1.
+		scale, offset = 4/math.Pi, 2*width-1
+		for k := 1; k <= n; k++ {
+			coef := math.Sin(math.Pi*float64(k)*width) / float64(k)
+			if math.Abs(coef) > 1e-9 {
+				term(k, coef, "cos", width/2)
+			}
+		}
+	}
+	expr := fmt.Sprintf("%s*(%s)", fmtFloat(scale), strings.Join(terms, "+"))
+	if offset != 0 {
+		expr = fmt.Sprintf("(%s+%s)", fmtFloat(offset), expr)
+	}
+	return expr
+}
+
+// contourWarpExpr returns the integral over time of the frequency ratio
+// 2^(s/12) of the bend s, in semitones, traced by the breakpoints shape. The
+// bend is linear between breakpoints, so within each segment the integral is
+// an exponential, and it is linear before the first breakpoint and after the
+// last.
+func contourWarpExpr(shape []audio.EnvelopePoint) string {
+	ratio := func(s float64) float64 { return math.Exp2(s / 12) }
+
+	// at[i] is the integral up to shape[i].Time.
+	at := make([]float64, len(shape))
+	at[0] = ratio(shape[0].Level) * shape[0].Time
+	for i := 1; i < len(shape); i++ {
+		a, b := shape[i-1], shape[i]
+		at[i] = at[i-1]
+		if b.Time <= a.Time {
+			continue
+		}
+		if b.Level == a.Level {
+			at[i] += ratio(a.Level) * (b.Time - a.Time)
+		} else {
+			slope := (b.Level - a.Level) / (b.Time - a.Time)
+			at[i] += 12 / (slope * math.Ln2) * (ratio(b.Level) - ratio(a.Level))
+		}
+	}
+
+	last := len(shape) - 1
+	expr := fmt.Sprintf("%s+%s*(t-%s)",
+		fmtFloat(at[last]), fmtFloat(ratio(shape[last].Level)), fmtFloat(shape[last].Time))
+	for i := last - 1; i >= 0; i-- {
+		a, b := shape[i], shape[i+1]
+		if b.Time <= a.Time {
+			continue
+		}
+		var seg string
+		if b.Level == a.Level {
+			seg = fmt.Sprintf("%s+%s*(t-%s)", fmtFloat(at[i]), fmtFloat(ratio(a.Level)), fmtFloat(a.Time))
+		} else {
+			slope := (b.Level - a.Level) / (b.Time - a.Time)
+			seg = fmt.Sprintf("%s+%s*(pow(2,(%s+%s*(t-%s))/12)-%s)",
+				fmtFloat(at[i]), fmtFloat(12/(slope*math.Ln2)),
+				fmtFloat(a.Level), fmtFloat(slope), fmtFloat(a.Time), fmtFloat(ratio(a.Level)))
+		}
+		expr = fmt.Sprintf("if(lt(t,%s),%s,%s)", fmtFloat(b.Time), seg, expr)
+	}
+	if shape[0].Time > 0 {
+		expr = fmt.Sprintf("if(lt(t,%s),%s*t,%s)", fmtFloat(shape[0].Time), fmtFloat(ratio(shape[0].Level)), expr)
+	}
+	return "(" + expr + ")"
+}
+
+// vowelTrackExprs returns expressions for the frequency and gain of each
+// formant as the vowel sequence s is sung over duration seconds. Each is
+// piecewise linear in t through the formants of the letters of s, like
+// audio.VowelFormants.
+func vowelTrackExprs(s string, duration float64) (freqs, gains [audio.FormantCount]string) {
+	var points [][audio.FormantCount]audio.Formant
+	for _, c := range strings.ToLower(s) {
+		if strings.ContainsRune(audio.Vowels, c) {
+			points = append(points, audio.VowelFormants(string(c), 0))
+		}
+	}
+	if len(points) == 0 {
+		points = append(points, audio.VowelFormants("", 0))
+	}
+
+	for j := range freqs {
+		fv := make([]float64, len(points))
+		gv := make([]float64, len(points))
+		for i, p := range points {
+			fv[i], gv[i] = p[j].Freq, p[j].Gain
+		}
+		freqs[j] = piecewiseExpr(fv, duration)
+		gains[j] = piecewiseExpr(gv, duration)
+	}
+	return freqs, gains
+}
+
+// piecewiseExpr returns an expression in t that moves linearly through
+// values, spaced evenly over duration seconds, and holds the last value
+// afterwards.
+func piecewiseExpr(values []float64, duration float64) string {
+	last := len(values) - 1
+	if last == 0 || duration <= 0 {
+		return fmtFloat(values[0])
+	}
+	seg := duration / float64(last)
+	expr := fmtFloat(values[last])
+	for i := last - 1; i >= 0; i-- {
+		start := float64(i) * seg
+		slope := (values[i+1] - values[i]) / seg
+		lin := fmt.Sprintf("%s+%s*(t-%s)", fmtFloat(values[i]), fmtFloat(slope), fmtFloat(start))
+		expr = fmt.Sprintf("if(lt(t,%s),%s,%s)", fmtFloat(start+seg), lin, expr)
+	}
+	return expr
+}
+
+// envelopeExpr returns expr scaled by the envelope breakpoints shape, as
+// returned by audio.Envelope.Shape, or expr itself if shape is empty.
+func envelopeExpr(shape []audio.EnvelopePoint, expr string) string {
+	if len(shape) == 0 {
+		return expr
+	}
+	return fmt.Sprintf("%s*(%s)", shapeExpr(shape), expr)
+}
+
+// shapeExpr returns an expression in t that moves linearly through the
+// envelope breakpoints shape and holds the nearest one outside them, like
+// audio.ShapeAt. shape must not be empty.
+func shapeExpr(shape []audio.EnvelopePoint) string {
+	last := len(shape) - 1
+	expr := fmtFloat(shape[last].Level)
+	for i := last - 1; i >= 0; i-- {
+		a, b := shape[i], shape[i+1]
+		if b.Time <= a.Time {
+			// A step: the earlier branches already cover every t before it.
+			continue
+		}
+		slope := (b.Level - a.Level) / (b.Time - a.Time)
+		lin := fmt.Sprintf("%s+%s*(t-%s)", fmtFloat(a.Level), fmtFloat(slope), fmtFloat(a.Time))
+		expr = fmt.Sprintf("if(lt(t,%s),%s,%s)", fmtFloat(b.Time), lin, expr)
+	}
+	if shape[0].Time > 0 {
+		expr = fmt.Sprintf("if(lt(t,%s),%s,%s)", fmtFloat(shape[0].Time), fmtFloat(shape[0].Level), expr)
+	}
+	return expr
+}
+
+// noiseExpr returns the noise term of the expression of a noise layer, given
+// its white noise expression white: white decorrelated by decorrelateNoise,
+// or 1 for coloured noise, whose expression is only its gain.
+func noiseExpr(layer audio.LayerParams, white string, decorrelation float64, index int) string {
+	if coloredNoise(layer) {
+		return "1"
+	}
+	return decorrelateNoise(white, decorrelation, index)
+}
+
+// decorrelateNoise blends the white noise expression white with an
+// independent source so that the correlation between them is
+// 1-decorrelation and the noise power is unchanged. A decorrelation of 0
+// returns white as is.
+func decorrelateNoise(white string, decorrelation float64, index int) string {
+	if decorrelation <= 0 {
+		return white
+	}
+	same := 1 - decorrelation
+	diff := math.Sqrt(1 - same*same)
+	return fmt.Sprintf("(%s*%s+%s*(2*random(t*48000+%d)-1))",
+		fmtFloat(same), white, fmtFloat(diff), rightNoiseSeedOffset+index)
+}
+
 // buildFilterChain builds the FFmpeg -af filter chain string from FilterParams.
 // Filters are applied in order: highpass, lowpass, acrusher, acompressor, volume, alimiter.
+// The EQ bands and then a distortion go between lowpass and acrusher. With a
+// delay or reverb the input is first padded with silence for their tail, and
+// their aecho filters follow acompressor, the delay's first.
 func buildFilterChain(filter audio.FilterParams) string {
 	highpass := fmt.Sprintf("highpass=f=%s", fmtFloat(filter.HighpassCutoff))
 	lowpass := fmt.Sprintf("lowpass=f=%s", fmtFloat(filter.LowpassCutoff))
+	if filter.Biquad {
+		highpass += fmt.Sprintf(":t=q:w=%s", fmtFloat(audio.FilterQ(filter.HighpassQ)))
+		lowpass += fmt.Sprintf(":t=q:w=%s", fmtFloat(audio.FilterQ(filter.LowpassQ)))
+	}
 	acrusher := fmt.Sprintf("acrusher=bits=%d:mix=%s:mode=log:aa=1",
 		filter.CrusherBits,
 		fmtFloat(filter.CrusherMix),
@@ -147,9 +706,137 @@ func buildFilterChain(filter audio.FilterParams) string {
 		fmtFloat(filter.CompThreshold),
 	)
 	volume := fmt.Sprintf("volume=%sdB", fmtFloat(filter.VolumeBoostDB))
-	alimiter := fmt.Sprintf("alimiter=limit=%s:attack=1:release=10", fmtFloat(filter.LimiterLevel))
+	alimiter := limiterExpr(filter)
+
+	var filters []string
+	if tail := filter.Tail(); tail > 0 {
+		filters = append(filters, fmt.Sprintf("apad=pad_dur=%s", fmtFloat(tail.Seconds())))
+	}
+	filters = append(filters, highpass, lowpass)
+	for _, b := range filter.EQ {
+		filters = append(filters, eqBandExpr(b))
+	}
+	if filter.Distortion != "" {
+		filters = append(filters, distortionExpr(filter))
+	}
+	filters = append(filters, acrusher, acompressor)
+	if filter.DelayMix > 0 {
+		filters = append(filters, delayExpr(filter))
+	}
+	if filter.ReverbMix > 0 {
+		filters = append(filters, reverbExpr(filter))
+	}
+	return strings.Join(append(filters, volume, alimiter), ",")
+}
 
-	return strings.Join([]string{highpass, lowpass, acrusher, acompressor, volume, alimiter}, ",")
+// limiterExpr returns the alimiter filter for the limiter of filter.
+// alimiter always looks ahead, so it stands in for the hard limiter as well;
+// its attack and release are kept within the ranges it accepts.
+func limiterExpr(filter audio.FilterParams) string {
+	return fmt.Sprintf("alimiter=limit=%s:attack=%s:release=%s",
+		fmtFloat(filter.LimiterLevel),
+		fmtFloat(math.Max(alimiterMinAttack, filter.LimiterAttackMillis())),
+		fmtFloat(math.Max(alimiterMinRelease, filter.LimiterReleaseMillis())),
+	)
+}
+
+// eqFilters maps each audio.EQType to the FFmpeg biquad filter with its
+// response.
+var eqFilters = map[audio.EQType]string{
+	audio.EQPeak:      "equalizer",
+	audio.EQLowShelf:  "lowshelf",
+	audio.EQHighShelf: "highshelf",
+	audio.EQLowpass:   "lowpass",
+	audio.EQHighpass:  "highpass",
+	audio.EQBandpass:  "bandpass",
+	audio.EQNotch:     "bandreject",
+}
+
+// eqBandExpr builds the filter for the EQ band b. FFmpeg's biquads follow the
+// same cookbook as the native backend's, so they match it closely.
+func eqBandExpr(b audio.EQBand) string {
+	expr := fmt.Sprintf("%s=f=%s:t=q:w=%s", eqFilters[b.Type], fmtFloat(b.Freq), fmtFloat(audio.FilterQ(b.Q)))
+	switch b.Type {
+	case audio.EQPeak, audio.EQLowShelf, audio.EQHighShelf:
+		expr += fmt.Sprintf(":g=%s", fmtFloat(b.Gain))
+	}
+	return expr
+}
+
+// distortionExpr builds the filters standing in for the distortion of filter,
+// which must have a curve. Soft and hard clipping map onto asoftclip after a
+// volume filter for the drive; asoftclip has no tube or fold curve, so
+// those are written out for aeval. None of them is oversampled, so they
+// alias more than the native backend's.
+func distortionExpr(filter audio.FilterParams) string {
+	drive := math.Pow(10, filter.DistortionDrive/20)
+	var shaper string
+	switch filter.Distortion {
+	case audio.DistortionHard:
+		shaper = fmt.Sprintf("volume=%sdB,asoftclip=type=hard", fmtFloat(filter.DistortionDrive))
+	case audio.DistortionTube:
+		bias := math.Tanh(audio.DistortionTubeBias)
+		shaper = fmt.Sprintf("aeval=exprs='(tanh(%s*val(ch)+%s)-%s)/%s':c=same",
+			fmtFloat(drive), fmtFloat(audio.DistortionTubeBias), fmtFloat(bias), fmtFloat(1+bias))
+	case audio.DistortionFold:
+		u := fmt.Sprintf("(%s*val(ch)+1)/4", fmtFloat(drive))
+		shaper = fmt.Sprintf("aeval=exprs='1-4*abs(%s-floor(%s)-0.5)':c=same", u, u)
+	default:
+		shaper = fmt.Sprintf("volume=%sdB,asoftclip=type=tanh", fmtFloat(filter.DistortionDrive))
+	}
+	if filter.DistortionTone > 0 {
+		shaper += fmt.Sprintf(",lowpass=f=%s:p=1", fmtFloat(filter.DistortionTone))
+	}
+	return shaper
+}
+
+// delayExpr builds the aecho filter standing in for the delay of filter,
+// which must have a non-zero DelayMix: one tap for each repeat of the
+// feedback loop. aecho has no feedback path to filter and cannot bounce its
+// echoes between channels, so the delay's low-pass and ping-pong are
+// ignored.
+func delayExpr(filter audio.FilterParams) string {
+	repeats := filter.DelayRepeats()
+	delays := make([]string, repeats)
+	decays := make([]string, repeats)
+	for i := range repeats {
+		delays[i] = fmtFloat(filter.DelayTime * float64(i+1))
+		decays[i] = fmtFloat(max(1e-6, filter.DelayMix*math.Pow(filter.DelayFeedback, float64(i))))
+	}
+	return fmt.Sprintf("aecho=in_gain=1:out_gain=1:delays=%s:decays=%s",
+		strings.Join(delays, "|"),
+		strings.Join(decays, "|"),
+	)
+}
+
+// reverbExpr builds the aecho filter standing in for the reverb of filter,
+// which must have a non-zero ReverbMix.
+func reverbExpr(filter audio.FilterParams) string {
+	first := audio.ReverbCombDelays[0]
+	longest := audio.ReverbCombDelays[len(audio.ReverbCombDelays)-1]
+	rt := filter.ReverbTime()
+	feedback := audio.ReverbFeedback(filter.ReverbRoom)
+
+	var delays [reverbEchoTaps]float64 // seconds after the pre-delay
+	var gains [reverbEchoTaps]float64
+	var power float64
+	for i := range delays {
+		delays[i] = first * math.Pow(rt/first, float64(i)/(reverbEchoTaps-1))
+		gains[i] = math.Pow(feedback, delays[i]/longest)
+		power += gains[i] * gains[i]
+	}
+
+	delayStrs := make([]string, reverbEchoTaps)
+	decayStrs := make([]string, reverbEchoTaps)
+	for i := range delays {
+		delayStrs[i] = fmtFloat(filter.ReverbPreDelay + delays[i]*1000)
+		decayStrs[i] = fmtFloat(max(1e-6, filter.ReverbMix*gains[i]/math.Sqrt(power)))
+	}
+	return fmt.Sprintf("aecho=in_gain=%s:out_gain=1:delays=%s:decays=%s",
+		fmtFloat(max(1e-6, 1-filter.ReverbMix)),
+		strings.Join(delayStrs, "|"),
+		strings.Join(decayStrs, "|"),
+	)
 }
 
 // fmtFloat formats a float64 with 6 decimal places for use in FFmpeg expressions.
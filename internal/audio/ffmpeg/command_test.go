@@ -2,6 +2,9 @@ package ffmpeg
 
 import (
 	"fmt"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -16,14 +19,13 @@ func classicParams() audio.ScreamParams {
 		SampleRate: 48000,
 		Channels:   2,
 		Seed:       42,
-		Layers: [5]audio.LayerParams{
+		Layers: []audio.LayerParams{
 			{Type: audio.LayerPrimaryScream, BaseFreq: 500, FreqRange: 1500, JumpRate: 10, Amplitude: 0.4, Rise: 1.2, Seed: 4242},
 			{Type: audio.LayerHarmonicSweep, BaseFreq: 350, SweepRate: 500, FreqRange: 800, JumpRate: 6, Amplitude: 0.25, Seed: 3000},
 			{Type: audio.LayerHighShriek, BaseFreq: 1200, FreqRange: 1600, JumpRate: 20, Amplitude: 0.25, Rise: 2.5, Seed: 7000},
-			{Type: audio.LayerNoiseBurst, Amplitude: 0.18, Seed: 4000},
-			{Type: audio.LayerBackgroundNoise, Amplitude: 0.1},
+			{Type: audio.LayerNoiseBurst, BurstRate: 8, Threshold: 0.7, Amplitude: 0.18, Seed: 4000},
+			{Type: audio.LayerBackgroundNoise, Amplitude: 0.1, Seed: 4000},
 		},
-		Noise: audio.NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000},
 		Filter: audio.FilterParams{
 			HighpassCutoff: 120, LowpassCutoff: 8000,
 			CrusherBits: 8, CrusherMix: 0.5,
@@ -238,14 +240,30 @@ func Test_buildAevalsrcExpr_NonEmptyForAllPresets(t *testing.T) {
 	}
 }
 
+func Test_buildAevalsrcExpr_LayerStack(t *testing.T) {
+	params := classicParams()
+	burst := params.Layers[3]
+	params.Layers = []audio.LayerParams{burst, burst}
+	params.Layers[1].Threshold = 0.35
+
+	expr := buildAevalsrcExpr(params)
+	if n := strings.Count(expr, "gt(random("); n != 2 {
+		t.Errorf("buildAevalsrcExpr() has %d burst gates, want 2: %s", n, expr)
+	}
+	if strings.Contains(expr, "sin(") {
+		t.Errorf("buildAevalsrcExpr() without tonal layers should not contain 'sin(', got: %s", expr)
+	}
+	if !strings.Contains(expr, "0.350000") {
+		t.Errorf("buildAevalsrcExpr() should use each layer's own threshold, got: %s", expr)
+	}
+}
+
 func Test_buildAevalsrcExpr_ZeroAmplitudeLayer(t *testing.T) {
 	params := classicParams()
 	// Set all layer amplitudes to zero
 	for i := range params.Layers {
 		params.Layers[i].Amplitude = 0
 	}
-	params.Noise.BurstAmp = 0
-	params.Noise.FloorAmp = 0
 
 	expr := buildAevalsrcExpr(params)
 	// Should still produce a valid (non-empty) expression even with zero amplitudes
@@ -435,6 +453,242 @@ func Test_buildFilterChain_FilterOrder(t *testing.T) {
 	}
 }
 
+func Test_buildFilterChain_Reverb(t *testing.T) {
+	filter := classicParams().Filter
+	if chain := buildFilterChain(filter); strings.Contains(chain, "aecho") || strings.Contains(chain, "apad") {
+		t.Errorf("buildFilterChain() without reverb should have no aecho or apad, got: %s", chain)
+	}
+
+	filter.ReverbMix = 0.25
+	filter.ReverbRoom = 0.5
+	filter.ReverbPreDelay = 30
+	chain := buildFilterChain(filter)
+
+	pad := fmt.Sprintf("apad=pad_dur=%s,", fmtFloat(filter.ReverbTail().Seconds()))
+	if !strings.HasPrefix(chain, pad) {
+		t.Errorf("buildFilterChain() should start with %q, got: %s", pad, chain)
+	}
+	echo, comp, vol := strings.Index(chain, "aecho="), strings.Index(chain, "acompressor="), strings.Index(chain, "volume=")
+	if echo == -1 || echo < comp || echo > vol {
+		t.Errorf("buildFilterChain() should have aecho between acompressor and volume, got: %s", chain)
+	}
+	if !strings.Contains(chain, "aecho=in_gain=0.750000:out_gain=1:delays=55.306122|") {
+		t.Errorf("aecho should keep 0.75 of the dry signal and echo first after the pre-delay and shortest comb, got: %s", chain)
+	}
+}
+
+func Test_reverbExpr_Taps(t *testing.T) {
+	filter := audio.FilterParams{ReverbMix: 1, ReverbRoom: 0.5}
+	expr := reverbExpr(filter)
+
+	_, rest, _ := strings.Cut(expr, ":delays=")
+	delayList, decayList, _ := strings.Cut(rest, ":decays=")
+	delays, decays := strings.Split(delayList, "|"), strings.Split(decayList, "|")
+	if len(delays) != reverbEchoTaps || len(decays) != reverbEchoTaps {
+		t.Fatalf("reverbExpr() has %d delays and %d decays, want %d of each: %s", len(delays), len(decays), reverbEchoTaps, expr)
+	}
+	// The last echo comes at the reverb time, 60 dB down on the first.
+	if want := fmtFloat(filter.ReverbTime() * 1000); delays[reverbEchoTaps-1] != want {
+		t.Errorf("last delay = %s, want %s", delays[reverbEchoTaps-1], want)
+	}
+	num := func(s string) float64 { v, _ := strconv.ParseFloat(s, 64); return v }
+	for i := 1; i < reverbEchoTaps; i++ {
+		if num(delays[i]) <= num(delays[i-1]) || num(decays[i]) >= num(decays[i-1]) {
+			t.Errorf("tap %d (%s ms, %s) should be later and quieter than tap %d (%s ms, %s)",
+				i, delays[i], decays[i], i-1, delays[i-1], decays[i-1])
+		}
+	}
+	if !strings.HasPrefix(expr, "aecho=in_gain=0.000001:") {
+		t.Errorf("a fully wet reverb should keep almost none of the dry signal, got: %s", expr)
+	}
+}
+
+func Test_buildFilterChain_Delay(t *testing.T) {
+	filter := classicParams().Filter
+	filter.DelayMix = 0.5
+	filter.DelayTime = 300
+	filter.ReverbMix = 0.25
+	chain := buildFilterChain(filter)
+
+	pad := fmt.Sprintf("apad=pad_dur=%s,", fmtFloat(filter.Tail().Seconds()))
+	if !strings.HasPrefix(chain, pad) {
+		t.Errorf("buildFilterChain() should pad for the delay and reverb with %q, got: %s", pad, chain)
+	}
+	delay := "aecho=in_gain=1:out_gain=1:delays=300.000000:decays=0.500000,"
+	comp, echo, reverb := strings.Index(chain, "acompressor="), strings.Index(chain, delay), strings.Index(chain, "aecho=in_gain=0.750000")
+	if echo == -1 || echo < comp || reverb < echo {
+		t.Errorf("buildFilterChain() should have the delay's %q between acompressor and the reverb, got: %s", delay, chain)
+	}
+}
+
+func Test_buildFilterChain_Distortion(t *testing.T) {
+	filter := classicParams().Filter
+	if chain := buildFilterChain(filter); strings.Contains(chain, "asoftclip") || strings.Contains(chain, "aeval") {
+		t.Errorf("buildFilterChain() without distortion should have no asoftclip or aeval, got: %s", chain)
+	}
+
+	filter.Distortion = audio.DistortionSoft
+	filter.DistortionDrive = 12
+	chain := buildFilterChain(filter)
+	want := "lowpass=f=8000.000000,volume=12.000000dB,asoftclip=type=tanh,acrusher="
+	if !strings.Contains(chain, want) {
+		t.Errorf("buildFilterChain() should drive asoftclip between lowpass and acrusher with %q, got: %s", want, chain)
+	}
+}
+
+func Test_buildFilterChain_Limiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *audio.FilterParams)
+		want   string
+	}{
+		{"default", func(f *audio.FilterParams) {}, "alimiter=limit=0.950000:attack=1.000000:release=10.000000"},
+		{"hard", func(f *audio.FilterParams) { f.Limiter = audio.LimiterHard }, "alimiter=limit=0.950000:attack=1.000000:release=10.000000"},
+		{"lookahead", func(f *audio.FilterParams) {
+			f.Limiter, f.LimiterAttack, f.LimiterRelease = audio.LimiterLookahead, 5, 200
+		}, "alimiter=limit=0.950000:attack=5.000000:release=200.000000"},
+		{"below alimiter's range", func(f *audio.FilterParams) {
+			f.Limiter, f.LimiterAttack, f.LimiterRelease = audio.LimiterLookahead, 0.01, 0.5
+		}, "alimiter=limit=0.950000:attack=0.100000:release=1.000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := classicParams().Filter
+			tt.modify(&filter)
+			if chain := buildFilterChain(filter); !strings.HasSuffix(chain, tt.want) {
+				t.Errorf("buildFilterChain() should end with %q, got: %s", tt.want, chain)
+			}
+		})
+	}
+}
+
+func Test_buildFilterChain_Biquad(t *testing.T) {
+	filter := classicParams().Filter
+	if chain := buildFilterChain(filter); strings.Contains(chain, ":t=q") {
+		t.Errorf("buildFilterChain() without biquads should not set Q, got: %s", chain)
+	}
+
+	filter.Biquad = true
+	filter.LowpassQ = 4
+	chain := buildFilterChain(filter)
+	want := "highpass=f=120.000000:t=q:w=0.707107,lowpass=f=8000.000000:t=q:w=4.000000,"
+	if !strings.Contains(chain, want) {
+		t.Errorf("buildFilterChain() should set the Q of highpass and lowpass with %q, got: %s", want, chain)
+	}
+}
+
+func Test_buildFilterChain_EQ(t *testing.T) {
+	filter := classicParams().Filter
+	filter.Distortion = audio.DistortionSoft
+	filter.EQ = []audio.EQBand{
+		{Type: audio.EQPeak, Freq: 3000, Q: 2, Gain: -6},
+		{Type: audio.EQNotch, Freq: 1000},
+	}
+	chain := buildFilterChain(filter)
+	want := "lowpass=f=8000.000000,equalizer=f=3000.000000:t=q:w=2.000000:g=-6.000000,bandreject=f=1000.000000:t=q:w=0.707107,volume="
+	if !strings.Contains(chain, want) {
+		t.Errorf("buildFilterChain() should put the EQ bands between lowpass and the distortion with %q, got: %s", want, chain)
+	}
+}
+
+func Test_eqBandExpr(t *testing.T) {
+	tests := []struct {
+		band audio.EQBand
+		want string
+	}{
+		{audio.EQBand{Type: audio.EQPeak, Freq: 1000, Q: 1, Gain: 6}, "equalizer=f=1000.000000:t=q:w=1.000000:g=6.000000"},
+		{audio.EQBand{Type: audio.EQLowShelf, Freq: 200, Gain: 3}, "lowshelf=f=200.000000:t=q:w=0.707107:g=3.000000"},
+		{audio.EQBand{Type: audio.EQHighShelf, Freq: 8000, Gain: -3}, "highshelf=f=8000.000000:t=q:w=0.707107:g=-3.000000"},
+		{audio.EQBand{Type: audio.EQLowpass, Freq: 5000, Q: 4, Gain: 6}, "lowpass=f=5000.000000:t=q:w=4.000000"},
+		{audio.EQBand{Type: audio.EQHighpass, Freq: 80}, "highpass=f=80.000000:t=q:w=0.707107"},
+		{audio.EQBand{Type: audio.EQBandpass, Freq: 1000, Q: 10}, "bandpass=f=1000.000000:t=q:w=10.000000"},
+		{audio.EQBand{Type: audio.EQNotch, Freq: 50, Q: 20}, "bandreject=f=50.000000:t=q:w=20.000000"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.band.Type), func(t *testing.T) {
+			if got := eqBandExpr(tt.band); got != tt.want {
+				t.Errorf("eqBandExpr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_distortionExpr(t *testing.T) {
+	bias := math.Tanh(audio.DistortionTubeBias)
+	tests := []struct {
+		name   string
+		filter audio.FilterParams
+		want   string
+	}{
+		{
+			"soft",
+			audio.FilterParams{Distortion: audio.DistortionSoft, DistortionDrive: 6},
+			"volume=6.000000dB,asoftclip=type=tanh",
+		},
+		{
+			"hard with tone",
+			audio.FilterParams{Distortion: audio.DistortionHard, DistortionTone: 3000},
+			"volume=0.000000dB,asoftclip=type=hard,lowpass=f=3000.000000:p=1",
+		},
+		{
+			"tube",
+			audio.FilterParams{Distortion: audio.DistortionTube, DistortionDrive: 20},
+			fmt.Sprintf("aeval=exprs='(tanh(10.000000*val(ch)+0.300000)-%s)/%s':c=same", fmtFloat(bias), fmtFloat(1+bias)),
+		},
+		{
+			"fold",
+			audio.FilterParams{Distortion: audio.DistortionFold},
+			"aeval=exprs='1-4*abs((1.000000*val(ch)+1)/4-floor((1.000000*val(ch)+1)/4)-0.5)':c=same",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := distortionExpr(tt.filter); got != tt.want {
+				t.Errorf("distortionExpr() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_delayExpr(t *testing.T) {
+	filter := audio.FilterParams{DelayMix: 0.8, DelayTime: 250, DelayFeedback: 0.5, DelayPingPong: true, DelayLowpass: 2000}
+	expr := delayExpr(filter)
+
+	_, rest, _ := strings.Cut(expr, ":delays=")
+	delayList, decayList, _ := strings.Cut(rest, ":decays=")
+	delays, decays := strings.Split(delayList, "|"), strings.Split(decayList, "|")
+	if len(delays) != filter.DelayRepeats() || len(decays) != filter.DelayRepeats() {
+		t.Fatalf("delayExpr() has %d delays and %d decays, want %d of each: %s", len(delays), len(decays), filter.DelayRepeats(), expr)
+	}
+	for i, want := range []string{"250.000000", "500.000000", "750.000000"} {
+		if delays[i] != want {
+			t.Errorf("delay %d = %s, want %s", i, delays[i], want)
+		}
+	}
+	for i, want := range []string{"0.800000", "0.400000", "0.200000"} {
+		if decays[i] != want {
+			t.Errorf("decay %d = %s, want %s", i, decays[i], want)
+		}
+	}
+}
+
+func Test_BuildArgs_ReverbTail(t *testing.T) {
+	params := classicParams()
+	if slices.Contains(buildArgs(params), "-t") {
+		t.Errorf("buildArgs() without reverb should not limit the output duration")
+	}
+
+	params.Filter.ReverbMix = 0.3
+	args := buildArgs(params)
+	i := slices.Index(args, "-t")
+	if i == -1 {
+		t.Fatalf("buildArgs() with reverb should limit the output to the scream and its tail, got: %v", args)
+	}
+	if want := strconv.FormatFloat((params.Duration + params.Tail()).Seconds(), 'f', -1, 64); args[i+1] != want {
+		t.Errorf("-t %s, want %s", args[i+1], want)
+	}
+}
+
 // --- layerExpr tests ---
 
 func Test_layerExpr_PrimaryScream(t *testing.T) {
@@ -447,9 +701,8 @@ func Test_layerExpr_PrimaryScream(t *testing.T) {
 		Rise:      1.2,
 		Seed:      4242,
 	}
-	noise := audio.NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000}
 
-	expr := layerExpr(layer, noise, 42, 0)
+	expr := layerExpr(layer, 42, 0, 1)
 
 	if !strings.Contains(expr, "sin") {
 		t.Errorf("layerExpr(PrimaryScream) should contain 'sin', got: %s", expr)
@@ -469,9 +722,8 @@ func Test_layerExpr_HarmonicSweep(t *testing.T) {
 		Amplitude: 0.25,
 		Seed:      3000,
 	}
-	noise := audio.NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000}
 
-	expr := layerExpr(layer, noise, 42, 1)
+	expr := layerExpr(layer, 42, 1, 1)
 
 	if !strings.Contains(expr, "sin") {
 		t.Errorf("layerExpr(HarmonicSweep) should contain 'sin', got: %s", expr)
@@ -491,9 +743,8 @@ func Test_layerExpr_HighShriek(t *testing.T) {
 		Rise:      2.5,
 		Seed:      7000,
 	}
-	noise := audio.NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000}
 
-	expr := layerExpr(layer, noise, 42, 2)
+	expr := layerExpr(layer, 42, 2, 1)
 
 	if !strings.Contains(expr, "sin") {
 		t.Errorf("layerExpr(HighShriek) should contain 'sin', got: %s", expr)
@@ -506,12 +757,13 @@ func Test_layerExpr_HighShriek(t *testing.T) {
 func Test_layerExpr_NoiseBurst(t *testing.T) {
 	layer := audio.LayerParams{
 		Type:      audio.LayerNoiseBurst,
+		BurstRate: 8,
+		Threshold: 0.7,
 		Amplitude: 0.18,
 		Seed:      4000,
 	}
-	noise := audio.NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000}
 
-	expr := layerExpr(layer, noise, 42, 3)
+	expr := layerExpr(layer, 42, 3, 1)
 
 	if !strings.Contains(expr, "random") {
 		t.Errorf("layerExpr(NoiseBurst) should contain 'random', got: %s", expr)
@@ -527,9 +779,8 @@ func Test_layerExpr_BackgroundNoise(t *testing.T) {
 		Type:      audio.LayerBackgroundNoise,
 		Amplitude: 0.1,
 	}
-	noise := audio.NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000}
 
-	expr := layerExpr(layer, noise, 42, 4)
+	expr := layerExpr(layer, 42, 4, 1)
 
 	if !strings.Contains(expr, "random") {
 		t.Errorf("layerExpr(BackgroundNoise) should contain 'random', got: %s", expr)
@@ -550,9 +801,8 @@ func Test_layerExpr_ZeroAmplitude(t *testing.T) {
 		Rise:      1.2,
 		Seed:      4242,
 	}
-	noise := audio.NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000}
 
-	expr := layerExpr(layer, noise, 42, 0)
+	expr := layerExpr(layer, 42, 0, 1)
 
 	// Zero amplitude should return "0" or empty string to indicate silence
 	if expr != "0" && expr != "" {
@@ -563,6 +813,463 @@ func Test_layerExpr_ZeroAmplitude(t *testing.T) {
 	}
 }
 
+func Test_layerExpr_Vocal(t *testing.T) {
+	layer := audio.LayerParams{
+		Type:      audio.LayerVocal,
+		BaseFreq:  180,
+		FreqRange: 300,
+		JumpRate:  4,
+		Amplitude: 0.5,
+		Seed:      5150,
+		Vowel:     "ao",
+		Jitter:    0.02,
+		Shimmer:   0.1,
+	}
+
+	expr := layerExpr(layer, 42, 0, 2)
+
+	for _, want := range []string{"sin", "180", "st(0,", "650", "400", "if(lt(t,2.000000)"} {
+		if !strings.Contains(expr, want) {
+			t.Errorf("layerExpr(Vocal) should contain %q, got: %s", want, expr)
+		}
+	}
+	if strings.Count(expr, "(") != strings.Count(expr, ")") {
+		t.Errorf("layerExpr(Vocal) has unbalanced parentheses: %s", expr)
+	}
+
+	layer.Amplitude = 0
+	if got := layerExpr(layer, 42, 0, 2); got != "0" {
+		t.Errorf("layerExpr(Vocal) with zero amplitude = %q, want \"0\"", got)
+	}
+}
+
+func Test_layerExpr_Levels(t *testing.T) {
+	layer := audio.LayerParams{
+		Type:      audio.LayerBackgroundNoise,
+		Amplitude: 0.1,
+	}
+	plain := layerExpr(layer, 42, 4, 2)
+
+	layer.Levels = []float64{0, 1}
+	want := piecewiseExpr(layer.Levels, 2) + "*(" + plain + ")"
+	if got := layerExpr(layer, 42, 4, 2); got != want {
+		t.Errorf("layerExpr() with levels = %q, want %q", got, want)
+	}
+
+	layer.Amplitude = 0
+	if got := layerExpr(layer, 42, 4, 2); got != "0" {
+		t.Errorf("layerExpr() with levels and zero amplitude = %q, want \"0\"", got)
+	}
+}
+
+func Test_piecewiseExpr(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		duration float64
+		want     string
+	}{
+		{"single value", []float64{650}, 3, "650.000000"},
+		{"zero duration", []float64{650, 400}, 0, "650.000000"},
+		{"two values", []float64{600, 400}, 2, "if(lt(t,2.000000),600.000000+-100.000000*(t-0.000000),400.000000)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := piecewiseExpr(tt.values, tt.duration); got != tt.want {
+				t.Errorf("piecewiseExpr(%v, %v) = %q, want %q", tt.values, tt.duration, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_shapeExpr(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape []audio.EnvelopePoint
+		want  string
+	}{
+		{"single point", []audio.EnvelopePoint{{Time: 0, Level: 0.5}}, "0.500000"},
+		{
+			"attack",
+			[]audio.EnvelopePoint{{Time: 0, Level: 0}, {Time: 0.5, Level: 1}},
+			"if(lt(t,0.500000),0.000000+2.000000*(t-0.000000),1.000000)",
+		},
+		{
+			"late start and step",
+			[]audio.EnvelopePoint{{Time: 1, Level: 1}, {Time: 1, Level: 0.5}},
+			"if(lt(t,1.000000),1.000000,0.500000)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shapeExpr(tt.shape); got != tt.want {
+				t.Errorf("shapeExpr(%v) = %q, want %q", tt.shape, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buildAevalsrcExpr_Envelopes(t *testing.T) {
+	params := classicParams()
+	params.Channels = 1
+	plain := buildAevalsrcExpr(params)
+
+	// The scream's envelope scales the whole sum.
+	params.Envelope = audio.Envelope{Attack: 0.5}
+	shape := shapeExpr(params.Envelope.Shape(3))
+	if got, want := buildAevalsrcExpr(params), shape+"*("+plain+")"; got != want {
+		t.Errorf("buildAevalsrcExpr() with envelope = %q, want %q", got, want)
+	}
+
+	// A layer's envelope scales that layer after its levels.
+	layer := params.Layers[4]
+	layer.Levels = []float64{0, 1}
+	leveled := layerExpr(layer, 42, 4, 3)
+	layer.Envelope = audio.Envelope{Release: 1}
+	want := shapeExpr(layer.Envelope.Shape(3)) + "*(" + leveled + ")"
+	if got := layerExpr(layer, 42, 4, 3); got != want {
+		t.Errorf("layerExpr() with envelope = %q, want %q", got, want)
+	}
+
+	params = stereoParams()
+	params.Envelope = audio.Envelope{Release: 1}
+	for i, ch := range strings.Split(buildAevalsrcExpr(params), "|") {
+		if !strings.HasPrefix(ch, shapeExpr(params.Envelope.Shape(3))+"*(") {
+			t.Errorf("channel %d is not scaled by the envelope: %q", i, ch)
+		}
+	}
+}
+
+func Test_warpExpr(t *testing.T) {
+	tests := []struct {
+		name  string
+		layer audio.LayerParams
+		want  string
+	}{
+		{"unbent", audio.LayerParams{}, "t"},
+		{
+			// The integral of 2^(12t/12) is (2^t-1)/ln(2) up to the end of
+			// the rise, and grows at twice the rate after it.
+			"rising octave",
+			audio.LayerParams{Pitch: audio.PitchContour{Curve: audio.PitchRising, Semitones: 12}},
+			"(if(lt(t,1.000000),0.000000+1.442695*(pow(2,(0.000000+12.000000*(t-0.000000))/12)-1.000000),1.442695+2.000000*(t-1.000000)))",
+		},
+		{
+			"late fall",
+			audio.LayerParams{Pitch: audio.PitchContour{Points: []audio.PitchPoint{{Time: 0.5, Semitones: 0}, {Time: 1, Semitones: -12}}}},
+			"(if(lt(t,0.500000),1.000000*t,if(lt(t,1.000000),0.500000+-0.721348*(pow(2,(0.000000+-24.000000*(t-0.500000))/12)-1.000000),0.860674+0.500000*(t-1.000000))))",
+		},
+		{
+			"vibrato",
+			audio.LayerParams{Vibrato: audio.LFO{Rate: 5, Depth: 1}},
+			"(t+1*0.057762/(2*PI*5.000000)*(1-cos(2*PI*5.000000*t)))",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := warpExpr(tt.layer, 1); got != tt.want {
+				t.Errorf("warpExpr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_layerExpr_PitchAndModulation(t *testing.T) {
+	for _, lt := range []audio.LayerType{audio.LayerPrimaryScream, audio.LayerHarmonicSweep, audio.LayerHighShriek, audio.LayerVocal} {
+		layer := audio.LayerParams{Type: lt, BaseFreq: 400, FreqRange: 100, JumpRate: 4, Amplitude: 0.3}
+		layer.Pitch = audio.PitchContour{Curve: audio.PitchArch, Semitones: 5}
+		layer.Vibrato = audio.LFO{Rate: 6, Depth: 0.5}
+		expr := layerExpr(layer, 42, 0, 2)
+		if warp := warpExpr(layer, 2); !strings.Contains(expr, warp) {
+			t.Errorf("layerExpr(%v) does not use the warped time %q: %s", lt, warp, expr)
+		}
+		if strings.Count(expr, "(") != strings.Count(expr, ")") {
+			t.Errorf("layerExpr(%v) has unbalanced parentheses: %s", lt, expr)
+		}
+	}
+
+	layer := audio.LayerParams{Type: audio.LayerBackgroundNoise, Amplitude: 0.1}
+	plain := layerExpr(layer, 42, 4, 2)
+	layer.Tremolo = audio.LFO{Rate: 8, Depth: 0.4}
+	want := "(1-0.400000*(0.5+0.5*sin(2*PI*8.000000*t)))*(" + plain + ")"
+	if got := layerExpr(layer, 42, 4, 2); got != want {
+		t.Errorf("layerExpr() with tremolo = %q, want %q", got, want)
+	}
+}
+
+func Test_waveExpr(t *testing.T) {
+	sine := audio.LayerParams{Type: audio.LayerPrimaryScream, BaseFreq: 400, FreqRange: 100, JumpRate: 4, Amplitude: 0.3}
+	if got, want := waveExpr(sine, "400", "t", "1"), "sin(2*PI*t*(400))"; got != want {
+		t.Errorf("waveExpr(sine) = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		layer audio.LayerParams
+		terms int
+	}{
+		{audio.LayerParams{Waveform: audio.WaveSaw}, waveHarmonics},
+		{audio.LayerParams{Waveform: audio.WaveSquare}, waveHarmonics},
+		{audio.LayerParams{Waveform: audio.WaveTriangle}, waveHarmonics},
+		// Every fourth harmonic of a pulse a quarter wide is missing.
+		{audio.LayerParams{Waveform: audio.WavePulse, PulseWidth: 0.25}, waveHarmonics * 3 / 4},
+		{audio.LayerParams{Waveform: audio.WaveSupersaw, Detune: 20}, supersawHarmonics * len(audio.SupersawVoices)},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.layer.Waveform), func(t *testing.T) {
+			expr := waveExpr(tt.layer, "400", "t", "2")
+			if !strings.HasPrefix(expr, "(st(6,400);st(7,t*ld(6));st(8,ld(6)*2);") {
+				t.Errorf("waveExpr() does not store the frequency and phase: %s", expr)
+			}
+			if got := strings.Count(expr, "lt("); got != tt.terms {
+				t.Errorf("waveExpr() has %d harmonics, want %d", got, tt.terms)
+			}
+			if strings.Count(expr, "(") != strings.Count(expr, ")") {
+				t.Errorf("waveExpr() has unbalanced parentheses: %s", expr)
+			}
+		})
+	}
+}
+
+func Test_harmonicWaveExpr(t *testing.T) {
+	tests := []struct {
+		wave  audio.Waveform
+		width float64
+		want  string
+	}{
+		{
+			audio.WaveSaw, 0,
+			"-0.636620*(lt(1*f,20000)*1.000000*sin(2*PI*1*p)+lt(2*f,20000)*0.500000*sin(2*PI*2*p))",
+		},
+		{
+			audio.WaveTriangle, 0,
+			"0.810569*(lt(1*f,20000)*1.000000*sin(2*PI*1*p)+lt(3*f,20000)*-0.111111*sin(2*PI*3*p))",
+		},
+		{
+			audio.WavePulse, 0.5,
+			"1.273240*(lt(1*f,20000)*1.000000*cos(2*PI*1*(p-0.250000)))",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.wave), func(t *testing.T) {
+			if got := harmonicWaveExpr(tt.wave, tt.width, "p", "f", 2); got != tt.want {
+				t.Errorf("harmonicWaveExpr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_layerExpr_Waveform(t *testing.T) {
+	layer := audio.LayerParams{Type: audio.LayerHighShriek, BaseFreq: 1000, FreqRange: 500, JumpRate: 10, Amplitude: 0.2, Rise: 1}
+	layer.Waveform = audio.WaveSquare
+	expr := layerExpr(layer, 42, 2, 3)
+	if !strings.Contains(expr, "st(6,1000.000000+500.000000*random(") {
+		t.Errorf("layerExpr(square) does not build the wave from the jumping pitch: %s", expr)
+	}
+	if strings.Contains(expr, "sin(2*PI*t*") {
+		t.Errorf("layerExpr(square) still draws a sine: %s", expr)
+	}
+}
+
+// --- Stereo tests ---
+
+// stereoParams returns classicParams with stereo placement enabled.
+func stereoParams() audio.ScreamParams {
+	p := classicParams()
+	p.Width = 0.8
+	p.Layers[1].Pan = -0.5
+	p.Layers[2].PanRate = 0.25
+	p.Layers[2].PanDepth = 0.6
+	return p
+}
+
+func Test_BuildArgs_StereoChannelLayout(t *testing.T) {
+	args := buildArgs(stereoParams())
+	joined := strings.Join(args, " ")
+	if !strings.Contains(joined, ":c=stereo:") {
+		t.Errorf("buildArgs() for stereo params should set c=stereo, got: %v", args)
+	}
+
+	mono := strings.Join(buildArgs(classicParams()), " ")
+	if strings.Contains(mono, "c=stereo") {
+		t.Errorf("buildArgs() without width should not set a channel layout, got: %s", mono)
+	}
+}
+
+func Test_buildAevalsrcExpr_StereoHasTwoChannels(t *testing.T) {
+	expr := buildAevalsrcExpr(stereoParams())
+	channels := strings.Split(expr, "|")
+	if len(channels) != 2 {
+		t.Fatalf("stereo expr has %d channel expressions, want 2: %s", len(channels), expr)
+	}
+	if channels[0] == channels[1] {
+		t.Error("left and right expressions are identical; want panned, decorrelated channels")
+	}
+
+	if strings.Contains(buildAevalsrcExpr(classicParams()), "|") {
+		t.Error("zero-width expr should be a single channel expression")
+	}
+}
+
+func Test_buildAevalsrcExpr_MonoOutputIgnoresWidth(t *testing.T) {
+	p := stereoParams()
+	p.Channels = 1
+	want := classicParams()
+	want.Channels = 1
+	if got := buildAevalsrcExpr(p); got != buildAevalsrcExpr(want) {
+		t.Errorf("mono expr should ignore stereo settings\ngot:  %s\nwant: %s", got, buildAevalsrcExpr(want))
+	}
+}
+
+func Test_stereoLayerExprs_Panning(t *testing.T) {
+	p := stereoParams()
+
+	// Centered, static layer: both channels are the plain layer expression.
+	l, r := stereoLayerExprs(p.Layers[0], p, 0)
+	if want := layerExpr(p.Layers[0], p.Seed, 0, p.Duration.Seconds()); l != want || r != want {
+		t.Errorf("centered layer = (%s, %s), want plain expression %s", l, r, want)
+	}
+
+	// Statically panned layer: pan scaled by width (-0.5 * 0.8 = -0.4).
+	l, r = stereoLayerExprs(p.Layers[1], p, 1)
+	if !strings.HasPrefix(l, "min(1,1-(-0.400000))*") || !strings.HasPrefix(r, "min(1,1+(-0.400000))*") {
+		t.Errorf("panned layer = (%s, %s), want balance gains for pan -0.4", l, r)
+	}
+
+	// Auto-panned layer uses a clipped LFO.
+	l, _ = stereoLayerExprs(p.Layers[2], p, 2)
+	if !strings.Contains(l, "clip(") || !strings.Contains(l, "0.250000*t") {
+		t.Errorf("auto-panned layer should contain a clipped 0.25 Hz LFO, got: %s", l)
+	}
+
+	// Silent layers stay silent in both channels.
+	p.Layers[0].Amplitude = 0
+	if l, r := stereoLayerExprs(p.Layers[0], p, 0); l != "0" || r != "0" {
+		t.Errorf("silent layer = (%s, %s), want (0, 0)", l, r)
+	}
+}
+
+func Test_stereoLayerExprs_NoiseDecorrelated(t *testing.T) {
+	p := stereoParams()
+	for _, i := range []int{3, 4} {
+		l, r := stereoLayerExprs(p.Layers[i], p, i)
+		if l == r {
+			t.Errorf("layer %d: right noise should differ from left, got %s", i, r)
+		}
+		if want := fmt.Sprintf("random(t*48000+%d)", rightNoiseSeedOffset+i); !strings.Contains(r, want) {
+			t.Errorf("layer %d: right noise should use independent source %q, got %s", i, want, r)
+		}
+	}
+}
+
+func Test_decorrelateNoise(t *testing.T) {
+	white := "(2*random(t*48000)-1)"
+	if got := decorrelateNoise(white, 0, 3); got != white {
+		t.Errorf("decorrelateNoise(0) = %s, want unchanged", got)
+	}
+	got := decorrelateNoise(white, 1, 3)
+	if !strings.HasPrefix(got, "(0.000000*"+white+"+1.000000*") {
+		t.Errorf("decorrelateNoise(1) = %s, want only the independent source", got)
+	}
+}
+
+// --- Noise colour tests ---
+
+func Test_BuildArgs_WhiteNoiseUsesLavfi(t *testing.T) {
+	params := classicParams()
+	params.Layers[3].Noise.Color = audio.NoiseWhite
+	args := buildArgs(params)
+	if slices.Contains(args, "-filter_complex") || !slices.Contains(args, "lavfi") {
+		t.Errorf("buildArgs() with white noise should use a lavfi input, got: %v", args)
+	}
+}
+
+func Test_BuildArgs_ColoredNoise(t *testing.T) {
+	params := classicParams()
+	params.Layers[3].Noise.Color = audio.NoisePink
+	args := buildArgs(params)
+	if slices.Contains(args, "-af") || slices.Contains(args, "lavfi") {
+		t.Fatalf("buildArgs() with coloured noise should not use -af or a lavfi input, got: %v", args)
+	}
+	i := slices.Index(args, "-filter_complex")
+	if i == -1 {
+		t.Fatalf("buildArgs() with coloured noise should contain -filter_complex, got: %v", args)
+	}
+	graph := args[i+1]
+	for _, want := range []string{
+		"[main];anoisesrc=c=pink:r=48000:a=1:d=3:s=",
+		",volume=3.008000[n3]",
+		"[g3];[n3][g3]amultiply[l3]",
+		"[main][l3]amix=inputs=2:normalize=0," + buildFilterChain(params.Filter),
+	} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("graph should contain %q, got: %s", want, graph)
+		}
+	}
+	if strings.Count(graph, "2*random(t*") != 1 {
+		t.Errorf("only the white background noise should be drawn by aevalsrc, got: %s", graph)
+	}
+}
+
+func Test_noiseGraph_Stereo(t *testing.T) {
+	params := stereoParams()
+	params.Layers[4].Noise.Color = audio.NoiseBrown
+	graph := noiseGraph(params, "aevalsrc='0'", "anull")
+	seed := deriveSeed(params.Seed, params.Layers[4].Seed, 4)
+	for _, want := range []string{
+		fmt.Sprintf("anoisesrc=c=brown:r=48000:a=1:d=3:s=%d[n4a]", seed),
+		fmt.Sprintf("anoisesrc=c=brown:r=48000:a=1:d=3:s=%d[n4b]", seed+rightNoiseSeedOffset),
+		"[n4a][n4b]amerge=inputs=2,pan=stereo|c0=c0|c1=",
+		":c=stereo:",
+		"[main][l4]amix=inputs=2:normalize=0,anull",
+	} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("graph should contain %q, got: %s", want, graph)
+		}
+	}
+}
+
+func Test_noiseShapingExpr(t *testing.T) {
+	tests := []struct {
+		noise audio.NoiseParams
+		want  string
+	}{
+		{audio.NoiseParams{Color: audio.NoisePink}, "volume=3.008000"},
+		{audio.NoiseParams{Color: audio.NoiseBrown}, "volume=2.877000"},
+		{audio.NoiseParams{Color: audio.NoiseBlue}, "volume=3.008000"},
+		{
+			audio.NoiseParams{Color: audio.NoiseBand, Freq: 1000, Q: 2},
+			"bandpass=f=1000.000000:t=q:w=2.000000,volume=" + fmtFloat(math.Sqrt(2*48000/(math.Pi*1000))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.noise.Color), func(t *testing.T) {
+			if got := noiseShapingExpr(tt.noise, 48000); got != tt.want {
+				t.Errorf("noiseShapingExpr() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	breath := noiseShapingExpr(audio.NoiseParams{Color: audio.NoiseBreath}, 48000)
+	if n := strings.Count(breath, ","); n != len(audio.BreathSpectrum) {
+		t.Errorf("breath noise should have %d filters and a volume, got: %s", len(audio.BreathSpectrum), breath)
+	}
+}
+
+func Test_anoisesrcExpr_BandIsWhite(t *testing.T) {
+	for _, c := range []audio.NoiseColor{audio.NoiseBand, audio.NoiseBreath} {
+		if got := anoisesrcExpr(audio.NoiseParams{Color: c}, 7, "48000", "3"); !strings.Contains(got, "c=white") {
+			t.Errorf("anoisesrcExpr(%s) = %s, want white noise", c, got)
+		}
+	}
+}
+
+func Test_layerExpr_ColoredNoiseIsGain(t *testing.T) {
+	layer := audio.LayerParams{Type: audio.LayerBackgroundNoise, Amplitude: 0.1, Noise: audio.NoiseParams{Color: audio.NoisePink}}
+	if got := layerExpr(layer, 42, 0, 1); strings.Contains(got, "random") {
+		t.Errorf("layerExpr() of coloured noise should be only its gain, got: %s", got)
+	}
+}
+
 // --- fmtFloat tests ---
 
 func Test_fmtFloat_Cases(t *testing.T) {
@@ -699,13 +1406,16 @@ func Test_BuildArgs_AllPresets(t *testing.T) {
 
 			joined := strings.Join(args, " ")
 			requiredFragments := []string{
-				"-f lavfi",
 				"aevalsrc=",
-				"-af",
 				"-f s16le",
 				"-acodec pcm_s16le",
 				"pipe:1",
 			}
+			if hasColoredNoise(params) {
+				requiredFragments = append(requiredFragments, "-filter_complex")
+			} else {
+				requiredFragments = append(requiredFragments, "-f lavfi", "-af")
+			}
 			for _, frag := range requiredFragments {
 				if !strings.Contains(joined, frag) {
 					t.Errorf("buildArgs() for preset %q missing '%s' in: %s", name, frag, joined)
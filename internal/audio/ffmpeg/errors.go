@@ -8,3 +8,11 @@ var ErrFFmpegNotFound = errors.New("ffmpeg: executable not found on PATH")
 
 // ErrFFmpegFailed is returned when the ffmpeg process exits with a non-zero status.
 var ErrFFmpegFailed = errors.New("ffmpeg: process failed")
+
+// ErrMorphUnsupported is returned when params morph over time, which an
+// ffmpeg filter graph cannot express.
+var ErrMorphUnsupported = errors.New("ffmpeg: morphing between parameters is only supported by the native backend")
+
+// ErrCrowdUnsupported is returned when params make a crowd of voices, which
+// an ffmpeg filter graph does not render.
+var ErrCrowdUnsupported = errors.New("ffmpeg: crowds of voices are only supported by the native backend")
@@ -2,14 +2,20 @@ package ffmpeg
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"os/exec"
+	"time"
 
 	"github.com/JamesPrial/go-scream/internal/audio"
 )
 
+// killWaitDelay is how long Generate waits for ffmpeg's output to close
+// after the process has been killed on cancellation.
+const killWaitDelay = time.Second
+
 // Compile-time check that Generator implements audio.Generator.
 var _ audio.Generator = (*Generator)(nil)
 
@@ -36,14 +42,31 @@ func NewGeneratorWithPath(path string, logger *slog.Logger) *Generator {
 }
 
 // Generate validates params, invokes ffmpeg, and returns the raw PCM audio as an io.Reader.
-// Returns an error wrapping ErrFFmpegFailed if the process exits with a non-zero status.
-func (g *Generator) Generate(params audio.ScreamParams) (io.Reader, error) {
+// Returns an error wrapping ErrFFmpegFailed if the process exits with a non-zero status,
+// ErrMorphUnsupported if params has a MorphTo, or ErrCrowdUnsupported if
+// params make a crowd of voices.
+// If ctx is done before ffmpeg exits, the process is killed and the returned
+// error wraps audio.ErrCancelled.
+func (g *Generator) Generate(ctx context.Context, params audio.ScreamParams) (io.Reader, error) {
 	if err := params.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid params: %w", err)
 	}
+	if params.MorphTo != nil {
+		return nil, ErrMorphUnsupported
+	}
+	if params.IsCrowd() {
+		return nil, ErrCrowdUnsupported
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", audio.ErrCancelled, err)
+	}
 
 	args := buildArgs(params)
-	cmd := exec.Command(g.ffmpegPath, args...)
+	cmd := exec.CommandContext(ctx, g.ffmpegPath, args...)
+	// Bound the wait for output pipes after the process is killed, in case a
+	// child process inherited them.
+	cmd.WaitDelay = killWaitDelay
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -52,6 +75,10 @@ func (g *Generator) Generate(params audio.ScreamParams) (io.Reader, error) {
 	g.logger.Debug("running ffmpeg", "path", g.ffmpegPath, "args", args)
 
 	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			g.logger.Debug("ffmpeg cancelled", "error", err)
+			return nil, fmt.Errorf("%w: %w", audio.ErrCancelled, ctxErr)
+		}
 		return nil, fmt.Errorf("%w: %s", ErrFFmpegFailed, stderr.String())
 	}
 
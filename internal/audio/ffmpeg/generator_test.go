@@ -1,11 +1,17 @@
 package ffmpeg
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,11 +20,16 @@ import (
 
 var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
 
-// skipIfNoFFmpeg skips the test if ffmpeg is not available on PATH.
+// skipIfNoFFmpeg skips the test if ffmpeg is not available on PATH. On CI,
+// which installs ffmpeg, it fails the test instead, so that the ffmpeg tests
+// cannot pass there by being skipped.
 func skipIfNoFFmpeg(t *testing.T) {
 	t.Helper()
 	_, err := exec.LookPath("ffmpeg")
 	if err != nil {
+		if os.Getenv("CI") != "" {
+			t.Fatal("ffmpeg not available on CI")
+		}
 		t.Skip("ffmpeg not available")
 	}
 }
@@ -31,14 +42,13 @@ func testParams() audio.ScreamParams {
 		SampleRate: 48000,
 		Channels:   2,
 		Seed:       42,
-		Layers: [5]audio.LayerParams{
+		Layers: []audio.LayerParams{
 			{Type: audio.LayerPrimaryScream, BaseFreq: 500, FreqRange: 1500, JumpRate: 10, Amplitude: 0.4, Rise: 1.2, Seed: 4242},
 			{Type: audio.LayerHarmonicSweep, BaseFreq: 350, SweepRate: 500, FreqRange: 800, JumpRate: 6, Amplitude: 0.25, Seed: 3000},
 			{Type: audio.LayerHighShriek, BaseFreq: 1200, FreqRange: 1600, JumpRate: 20, Amplitude: 0.25, Rise: 2.5, Seed: 7000},
-			{Type: audio.LayerNoiseBurst, Amplitude: 0.18, Seed: 4000},
-			{Type: audio.LayerBackgroundNoise, Amplitude: 0.1},
+			{Type: audio.LayerNoiseBurst, BurstRate: 8, Threshold: 0.7, Amplitude: 0.18, Seed: 4000},
+			{Type: audio.LayerBackgroundNoise, Amplitude: 0.1, Seed: 4000},
 		},
-		Noise: audio.NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000},
 		Filter: audio.FilterParams{
 			HighpassCutoff: 120, LowpassCutoff: 8000,
 			CrusherBits: 8, CrusherMix: 0.5,
@@ -96,7 +106,7 @@ func TestGenerator_CorrectByteCount(t *testing.T) {
 	}
 
 	params := testParams()
-	reader, err := gen.Generate(params)
+	reader, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -122,7 +132,7 @@ func TestGenerator_NonSilent(t *testing.T) {
 	}
 
 	params := testParams()
-	reader, err := gen.Generate(params)
+	reader, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -160,7 +170,7 @@ func TestGenerator_AllPresets(t *testing.T) {
 			// Override duration to 1s for speed
 			params.Duration = 1 * time.Second
 
-			reader, err := gen.Generate(params)
+			reader, err := gen.Generate(context.Background(), params)
 			if err != nil {
 				t.Fatalf("Generate() with seed %d error = %v", seed, err)
 			}
@@ -194,7 +204,7 @@ func TestGenerator_AllNamedPresets(t *testing.T) {
 			// Override duration to 1s for speed
 			params.Duration = 1 * time.Second
 
-			reader, err := gen.Generate(params)
+			reader, err := gen.Generate(context.Background(), params)
 			if err != nil {
 				t.Fatalf("Generate() for preset %q error = %v", name, err)
 			}
@@ -224,7 +234,7 @@ func TestGenerator_InvalidDuration(t *testing.T) {
 	params := testParams()
 	params.Duration = 0
 
-	_, err = gen.Generate(params)
+	_, err = gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with Duration=0 should return error")
 	}
@@ -244,7 +254,7 @@ func TestGenerator_NegativeDuration(t *testing.T) {
 	params := testParams()
 	params.Duration = -1 * time.Second
 
-	_, err = gen.Generate(params)
+	_, err = gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with negative Duration should return error")
 	}
@@ -264,7 +274,7 @@ func TestGenerator_InvalidSampleRate(t *testing.T) {
 	params := testParams()
 	params.SampleRate = 0
 
-	_, err = gen.Generate(params)
+	_, err = gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with SampleRate=0 should return error")
 	}
@@ -284,7 +294,7 @@ func TestGenerator_NegativeSampleRate(t *testing.T) {
 	params := testParams()
 	params.SampleRate = -44100
 
-	_, err = gen.Generate(params)
+	_, err = gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with negative SampleRate should return error")
 	}
@@ -304,7 +314,7 @@ func TestGenerator_InvalidChannels(t *testing.T) {
 	params := testParams()
 	params.Channels = 3
 
-	_, err = gen.Generate(params)
+	_, err = gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with Channels=3 should return error")
 	}
@@ -324,7 +334,7 @@ func TestGenerator_ZeroChannels(t *testing.T) {
 	params := testParams()
 	params.Channels = 0
 
-	_, err = gen.Generate(params)
+	_, err = gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with Channels=0 should return error")
 	}
@@ -337,7 +347,7 @@ func TestGenerator_BadBinaryPath(t *testing.T) {
 	gen := NewGeneratorWithPath("/nonexistent/ffmpeg", discardLogger)
 
 	params := testParams()
-	_, err := gen.Generate(params)
+	_, err := gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with bad binary path should return error")
 	}
@@ -357,7 +367,7 @@ func TestGenerator_InvalidAmplitude(t *testing.T) {
 	params := testParams()
 	params.Layers[0].Amplitude = 1.5
 
-	_, err = gen.Generate(params)
+	_, err = gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with invalid amplitude should return error")
 	}
@@ -377,7 +387,7 @@ func TestGenerator_InvalidCrusherBits(t *testing.T) {
 	params := testParams()
 	params.Filter.CrusherBits = 0
 
-	_, err = gen.Generate(params)
+	_, err = gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with CrusherBits=0 should return error")
 	}
@@ -397,7 +407,7 @@ func TestGenerator_InvalidLimiterLevel(t *testing.T) {
 	params := testParams()
 	params.Filter.LimiterLevel = 0
 
-	_, err = gen.Generate(params)
+	_, err = gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Fatal("Generate() with LimiterLevel=0 should return error")
 	}
@@ -417,7 +427,7 @@ func TestGenerator_EvenByteCount(t *testing.T) {
 	}
 
 	params := testParams()
-	reader, err := gen.Generate(params)
+	reader, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -442,7 +452,7 @@ func TestGenerator_StereoAligned(t *testing.T) {
 
 	params := testParams()
 	params.Channels = 2
-	reader, err := gen.Generate(params)
+	reader, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -468,7 +478,7 @@ func TestGenerator_MonoOutput(t *testing.T) {
 
 	params := testParams()
 	params.Channels = 1
-	reader, err := gen.Generate(params)
+	reader, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -497,7 +507,7 @@ func TestGenerator_DeterministicOutput(t *testing.T) {
 
 	params := testParams()
 
-	reader1, err := gen.Generate(params)
+	reader1, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() first call error = %v", err)
 	}
@@ -506,7 +516,7 @@ func TestGenerator_DeterministicOutput(t *testing.T) {
 		t.Fatalf("ReadAll() first call error = %v", err)
 	}
 
-	reader2, err := gen.Generate(params)
+	reader2, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() second call error = %v", err)
 	}
@@ -535,6 +545,197 @@ func TestGenerator_DeterministicOutput(t *testing.T) {
 	}
 }
 
+// --- Filter graph tests ---
+
+// graphCases returns params covering the graphs buildArgs builds: every
+// built-in preset in stereo and mono, random and text screams, every kind of
+// layer, and every filter of the chain with each distortion, in stereo and
+// mono. Each is cut to at most a second.
+func graphCases() map[string]audio.ScreamParams {
+	cases := make(map[string]audio.ScreamParams)
+	for _, name := range audio.AllPresets() {
+		params, _ := audio.GetPreset(name)
+		cases[string(name)+"/stereo"] = params
+		params.Channels = 1
+		cases[string(name)+"/mono"] = params
+	}
+	for _, seed := range []int64{1, 42, 777} {
+		cases[fmt.Sprintf("random/%d", seed)] = audio.Randomize(seed)
+	}
+	text, _ := audio.FromText("AAAHhh sss mmm", 7)
+	cases["text"] = text
+
+	layers := testParams()
+	layers.Width = 0.8
+	for i, wave := range []audio.Waveform{audio.WaveSine, audio.WaveSaw, audio.WaveSquare, audio.WaveTriangle, audio.WavePulse, audio.WaveSupersaw} {
+		layers.Layers = append(layers.Layers, audio.LayerParams{
+			Type: audio.LayerHighShriek, BaseFreq: 300 + 100*float64(i), FreqRange: 200, JumpRate: 5, Amplitude: 0.1, Seed: int64(100 + i),
+			Waveform: wave, PulseWidth: 0.3, Detune: 20,
+			Pan: 0.5, PanRate: 2, PanDepth: 0.3,
+			Levels:   []float64{0.2, 1, 0.5},
+			Envelope: audio.Envelope{Attack: 0.1, Decay: 0.2, Sustain: 0.6, Release: 0.2},
+			Tremolo:  audio.LFO{Rate: 6, Depth: 0.3},
+			Pitch:    audio.PitchContour{Curve: audio.PitchArch, Semitones: 5},
+			Vibrato:  audio.LFO{Rate: 5, Depth: 0.5},
+		})
+	}
+	layers.Layers[0].Pitch = audio.PitchContour{Points: []audio.PitchPoint{{Time: 0, Semitones: -3}, {Time: 0.5, Semitones: 4}}}
+	for i, color := range []audio.NoiseColor{audio.NoisePink, audio.NoiseBrown, audio.NoiseBlue, audio.NoiseBand, audio.NoiseBreath} {
+		layers.Layers = append(layers.Layers, audio.LayerParams{
+			Type: audio.LayerBackgroundNoise, Amplitude: 0.05, Seed: int64(200 + i), Pan: -0.4,
+			Noise: audio.NoiseParams{Color: color, Freq: 1500, Q: 2},
+		})
+	}
+	layers.Layers = append(layers.Layers, audio.LayerParams{
+		Type: audio.LayerVocal, BaseFreq: 200, FreqRange: 100, JumpRate: 3, Amplitude: 0.3, Seed: 300,
+		Vowel: "aeiou", Jitter: 0.05, Shimmer: 0.2,
+	})
+	cases["layers/stereo"] = layers
+	layers.Channels = 1
+	cases["layers/mono"] = layers
+
+	for _, d := range []audio.Distortion{audio.DistortionSoft, audio.DistortionHard, audio.DistortionTube, audio.DistortionFold} {
+		filters := testParams()
+		filters.Width = 0.6
+		f := &filters.Filter
+		f.Biquad, f.HighpassQ, f.LowpassQ = true, 0.9, 2
+		f.EQ = []audio.EQBand{
+			{Type: audio.EQPeak, Freq: 1000, Q: 1.5, Gain: 6},
+			{Type: audio.EQLowShelf, Freq: 200, Gain: -4},
+			{Type: audio.EQHighShelf, Freq: 6000, Gain: 3},
+			{Type: audio.EQLowpass, Freq: 10000},
+			{Type: audio.EQHighpass, Freq: 80},
+			{Type: audio.EQBandpass, Freq: 2000, Q: 0.7},
+			{Type: audio.EQNotch, Freq: 3000, Q: 4},
+		}
+		f.Distortion, f.DistortionDrive, f.DistortionTone = d, 12, 5000
+		f.DelayMix, f.DelayTime, f.DelayFeedback, f.DelayPingPong, f.DelayLowpass = 0.3, 150, 0.4, true, 3000
+		f.ReverbMix, f.ReverbRoom, f.ReverbDamping, f.ReverbPreDelay = 0.3, 0.7, 0.5, 20
+		f.Limiter, f.LimiterAttack, f.LimiterRelease = audio.LimiterLookahead, 2, 100
+		cases["filters/"+string(d)+"/stereo"] = filters
+		filters.Channels = 1
+		cases["filters/"+string(d)+"/mono"] = filters
+	}
+
+	for name, params := range cases {
+		params.Duration = min(params.Duration, time.Second)
+		cases[name] = params
+	}
+	return cases
+}
+
+// nullOutputArgs returns args, the arguments of an ffmpeg command writing
+// PCM to stdout, with the PCM output replaced by ffmpeg's null muxer and
+// errors logged rather than silenced.
+func nullOutputArgs(args []string) []string {
+	out := slices.Clone(args[:slices.Index(args, "s16le")-1])
+	if i := slices.Index(out, "quiet"); i > 0 && out[i-1] == "-v" {
+		out[i] = "error"
+	}
+	return append(out, "-f", "null", "-")
+}
+
+func TestBuildArgs_RendersInFFmpeg(t *testing.T) {
+	skipIfNoFFmpeg(t)
+
+	for name, params := range graphCases() {
+		t.Run(name, func(t *testing.T) {
+			if err := params.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			args := nullOutputArgs(buildArgs(params))
+			out, err := exec.Command("ffmpeg", args...).CombinedOutput()
+			if err != nil {
+				t.Errorf("ffmpeg failed: %v\n%s\nargs: %s", err, out, strings.Join(args, " "))
+			}
+		})
+	}
+}
+
+// --- Cancellation tests (fake ffmpeg) ---
+
+// writeFakeFFmpeg writes an executable shell script standing in for ffmpeg
+// and returns its path.
+func writeFakeFFmpeg(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg script requires a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatalf("writing fake ffmpeg: %v", err)
+	}
+	return path
+}
+
+func TestGenerator_CancelKillsProcess(t *testing.T) {
+	gen := NewGeneratorWithPath(writeFakeFFmpeg(t, "exec sleep 30"), discardLogger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := gen.Generate(ctx, testParams())
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, audio.ErrCancelled) {
+		t.Fatalf("Generate() error = %v, want audio.ErrCancelled", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Generate() error = %v, want wrapping context.DeadlineExceeded", err)
+	}
+	if errors.Is(err, ErrFFmpegFailed) {
+		t.Errorf("Generate() error = %v, cancellation should not be reported as ErrFFmpegFailed", err)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("Generate() took %v after cancellation, want the process killed promptly", elapsed)
+	}
+}
+
+func TestGenerator_CancelledBeforeStart(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	gen := NewGeneratorWithPath(writeFakeFFmpeg(t, "touch "+marker), discardLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := gen.Generate(ctx, testParams())
+	if !errors.Is(err, audio.ErrCancelled) {
+		t.Fatalf("Generate() error = %v, want audio.ErrCancelled", err)
+	}
+	if _, statErr := os.Stat(marker); statErr == nil {
+		t.Error("ffmpeg was started despite a cancelled context")
+	}
+}
+
+func TestGenerator_FakeFailureNotCancelled(t *testing.T) {
+	gen := NewGeneratorWithPath(writeFakeFFmpeg(t, "echo 'boom' >&2; exit 1"), discardLogger)
+
+	_, err := gen.Generate(context.Background(), testParams())
+	if !errors.Is(err, ErrFFmpegFailed) {
+		t.Fatalf("Generate() error = %v, want ErrFFmpegFailed", err)
+	}
+	if errors.Is(err, audio.ErrCancelled) {
+		t.Errorf("Generate() error = %v, failure should not be reported as cancellation", err)
+	}
+}
+
+func TestGenerator_FakeOutput(t *testing.T) {
+	gen := NewGeneratorWithPath(writeFakeFFmpeg(t, "printf 'abcd'"), discardLogger)
+
+	reader, err := gen.Generate(context.Background(), testParams())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(data) != "abcd" {
+		t.Errorf("Generate() output = %q, want the fake ffmpeg stdout", data)
+	}
+}
+
 // --- Benchmarks ---
 
 func BenchmarkGenerator_1s(b *testing.B) {
@@ -552,7 +753,7 @@ func BenchmarkGenerator_1s(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		reader, err := gen.Generate(params)
+		reader, err := gen.Generate(context.Background(), params)
 		if err != nil {
 			b.Fatalf("Generate() error = %v", err)
 		}
@@ -562,3 +763,28 @@ func BenchmarkGenerator_1s(b *testing.B) {
 		}
 	}
 }
+
+func TestGenerator_MorphUnsupported(t *testing.T) {
+	// The morph is rejected before ffmpeg is run, so no binary is needed.
+	gen := NewGeneratorWithPath("/nonexistent/ffmpeg", discardLogger)
+
+	params := testParams()
+	target := testParams()
+	params.MorphTo = &target
+
+	if _, err := gen.Generate(context.Background(), params); !errors.Is(err, ErrMorphUnsupported) {
+		t.Errorf("Generate() error = %v, want ErrMorphUnsupported", err)
+	}
+}
+
+func TestGenerator_CrowdUnsupported(t *testing.T) {
+	// The crowd is rejected before ffmpeg is run, so no binary is needed.
+	gen := NewGeneratorWithPath("/nonexistent/ffmpeg", discardLogger)
+
+	params := testParams()
+	params.Voices = 4
+
+	if _, err := gen.Generate(context.Background(), params); !errors.Is(err, ErrCrowdUnsupported) {
+		t.Errorf("Generate() error = %v, want ErrCrowdUnsupported", err)
+	}
+}
@@ -1,10 +1,16 @@
 package audio
 
-import "io"
+import (
+	"context"
+	"io"
+)
 
-// Generator produces raw PCM audio data (s16le, 48kHz, stereo).
+// Generator produces raw PCM audio data (s16le, 48kHz, stereo). The audio
+// lasts for the params' Duration plus their Tail.
+//
+// Generation stops when ctx is done. Implementations then return an error
+// wrapping both ErrCancelled and ctx.Err(), either from Generate itself or
+// from a Read on the returned reader if audio is produced lazily.
 type Generator interface {
-	Generate(params ScreamParams) (io.Reader, error)
+	Generate(ctx context.Context, params ScreamParams) (io.Reader, error)
 }
-
-
@@ -0,0 +1,77 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// Limiter names how the limiter at the end of the filter chain keeps the
+// signal within LimiterLevel.
+type Limiter string
+
+const (
+	LimiterHard      Limiter = "hard"      // clips every sample to the level; the default
+	LimiterLookahead Limiter = "lookahead" // turns the gain down smoothly ahead of each peak, including peaks between samples
+)
+
+// Attack and release of a lookahead limiter, in milliseconds: the defaults,
+// which match the ffmpeg backend's limiter, and the bounds.
+const (
+	DefaultLimiterAttack  = 1.0
+	DefaultLimiterRelease = 10.0
+	maxLimiterAttack      = 20
+	maxLimiterRelease     = 2000
+)
+
+// LimiterAttackMillis returns the attack of the limiter of f in
+// milliseconds: LimiterAttack, or DefaultLimiterAttack if it is 0.
+func (f FilterParams) LimiterAttackMillis() float64 {
+	if f.LimiterAttack == 0 {
+		return DefaultLimiterAttack
+	}
+	return f.LimiterAttack
+}
+
+// LimiterReleaseMillis returns the release of the limiter of f in
+// milliseconds: LimiterRelease, or DefaultLimiterRelease if it is 0.
+func (f FilterParams) LimiterReleaseMillis() float64 {
+	if f.LimiterRelease == 0 {
+		return DefaultLimiterRelease
+	}
+	return f.LimiterRelease
+}
+
+// LimiterDelay returns how many samples the limiter of f delays the signal
+// by at sampleRate: a lookahead limiter looks ahead by its attack, at least a
+// sample, and by TruePeakDelay more to find the peaks between samples, and
+// waits a sample more for every channel to share its gain; the hard limiter
+// does not delay it.
+func (f FilterParams) LimiterDelay(sampleRate int) int {
+	if f.Limiter != LimiterLookahead {
+		return 0
+	}
+	return max(1, int(math.Round(f.LimiterAttackMillis()/1000*float64(sampleRate)))) + TruePeakDelay + 1
+}
+
+// LimiterTail returns how long the limiter of f delays the signal by at
+// sampleRate, rounded up to the millisecond, so that the end of the scream is
+// not cut off.
+func (f FilterParams) LimiterTail(sampleRate int) time.Duration {
+	delay := f.LimiterDelay(sampleRate)
+	if delay == 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(float64(delay)*1000/float64(sampleRate))) * time.Millisecond
+}
+
+// validLimiter reports whether the limiter of f is known, with an attack of
+// 0 to maxLimiterAttack ms and a release of 0 to maxLimiterRelease ms.
+func (f FilterParams) validLimiter() bool {
+	switch f.Limiter {
+	case "", LimiterHard, LimiterLookahead:
+	default:
+		return false
+	}
+	return f.LimiterAttack >= 0 && f.LimiterAttack <= maxLimiterAttack &&
+		f.LimiterRelease >= 0 && f.LimiterRelease <= maxLimiterRelease
+}
@@ -0,0 +1,91 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate_Limiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *FilterParams)
+		ok     bool
+	}{
+		{"default", func(f *FilterParams) {}, true},
+		{"hard", func(f *FilterParams) { f.Limiter = LimiterHard }, true},
+		{"lookahead", func(f *FilterParams) {
+			f.Limiter, f.LimiterAttack, f.LimiterRelease = LimiterLookahead, 20, 2000
+		}, true},
+		{"unknown", func(f *FilterParams) { f.Limiter = "soft" }, false},
+		{"negative attack", func(f *FilterParams) { f.Limiter, f.LimiterAttack = LimiterLookahead, -1 }, false},
+		{"attack too long", func(f *FilterParams) { f.Limiter, f.LimiterAttack = LimiterLookahead, 21 }, false},
+		{"negative release", func(f *FilterParams) { f.Limiter, f.LimiterRelease = LimiterLookahead, -1 }, false},
+		{"release too long", func(f *FilterParams) { f.Limiter, f.LimiterRelease = LimiterLookahead, 2001 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			tt.modify(&p.Filter)
+			err := p.Validate()
+			if tt.ok && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidLimiter) {
+				t.Errorf("Validate() = %v, want ErrInvalidLimiter", err)
+			}
+		})
+	}
+}
+
+func TestFilterParams_LimiterTimes(t *testing.T) {
+	var f FilterParams
+	if f.LimiterAttackMillis() != DefaultLimiterAttack || f.LimiterReleaseMillis() != DefaultLimiterRelease {
+		t.Errorf("zero attack, release = %v, %v; want the defaults %v, %v",
+			f.LimiterAttackMillis(), f.LimiterReleaseMillis(), DefaultLimiterAttack, DefaultLimiterRelease)
+	}
+	f.LimiterAttack, f.LimiterRelease = 5, 100
+	if f.LimiterAttackMillis() != 5 || f.LimiterReleaseMillis() != 100 {
+		t.Errorf("attack, release = %v, %v; want 5, 100", f.LimiterAttackMillis(), f.LimiterReleaseMillis())
+	}
+}
+
+func TestFilterParams_LimiterDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FilterParams
+		delay  int
+		tail   time.Duration
+	}{
+		{"hard", FilterParams{}, 0, 0},
+		{"lookahead", FilterParams{Limiter: LimiterLookahead}, 48 + TruePeakDelay + 1, 2 * time.Millisecond},
+		{"long attack", FilterParams{Limiter: LimiterLookahead, LimiterAttack: 10}, 480 + TruePeakDelay + 1, 11 * time.Millisecond},
+		{"tiny attack", FilterParams{Limiter: LimiterLookahead, LimiterAttack: 0.001}, 1 + TruePeakDelay + 1, 1 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.LimiterDelay(48000); got != tt.delay {
+				t.Errorf("LimiterDelay() = %d, want %d", got, tt.delay)
+			}
+			if got := tt.filter.LimiterTail(48000); got != tt.tail {
+				t.Errorf("LimiterTail() = %v, want %v", got, tt.tail)
+			}
+		})
+	}
+}
+
+func TestScreamParams_TailLimiter(t *testing.T) {
+	p := ScreamParams{SampleRate: 48000, Filter: FilterParams{ReverbMix: 0.3, Limiter: LimiterLookahead}}
+	if got, want := p.Tail(), p.Filter.ReverbTail()+p.Filter.LimiterTail(48000); got != want {
+		t.Errorf("Tail() = %v, want the reverb's and limiter's tails %v", got, want)
+	}
+	p.Voices = 3
+	var want time.Duration
+	for _, v := range p.CrowdVoices() {
+		want = max(want, v.Offset+v.Params.Tail())
+	}
+	want += p.Filter.LimiterTail(48000)
+	if got := p.Tail(); got != want {
+		t.Errorf("crowd Tail() = %v, want %v, through the crowd's limiter", got, want)
+	}
+}
@@ -0,0 +1,262 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Loudness measurement after ITU-R BS.1770-4, as used by EBU R128: the
+// signal is K-weighted, its mean square taken over 400 ms blocks overlapping
+// by 75%, and blocks below the absolute gate, or more than relativeGate LU
+// below the loudness of the blocks left, are dropped.
+const (
+	loudnessStepMillis = 100 // step between the starts of blocks
+	loudnessBlockSteps = 4   // steps per 400 ms block
+	loudnessOffset     = -0.691
+	absoluteGate       = -70.0 // LUFS
+	relativeGate       = -10.0 // LU
+)
+
+// Bounds of a loudness normalization target and true-peak ceiling.
+const (
+	MinTargetLUFS = -70.0
+	MaxTargetLUFS = -5.0
+	MinTruePeak   = -20.0
+	MaxTruePeak   = 0.0
+)
+
+// DefaultTruePeak is the true-peak ceiling loudness normalization keeps
+// below by default, in dBTP, leaving headroom for Opus encoding.
+const DefaultTruePeak = -1.0
+
+// Loudness is the measured loudness of a signal.
+type Loudness struct {
+	// Integrated is the gated loudness of the whole signal in LUFS, or -Inf
+	// for silence or a signal shorter than one 400 ms block.
+	Integrated float64
+
+	// TruePeak is the highest level the signal reaches between as well as at
+	// its samples, in dBTP, or -Inf for silence.
+	TruePeak float64
+}
+
+// MeasureLoudness measures the loudness of s16le PCM with channels
+// interleaved channels. Every channel is weighted equally, as the left and
+// right channels of BS.1770 are.
+func MeasureLoudness(pcm []byte, sampleRate, channels int) Loudness {
+	frames := len(pcm) / (2 * channels)
+	weighting := make([]*kWeighting, channels)
+	peaks := make([]TruePeakMeter, channels)
+	for c := range weighting {
+		weighting[c] = newKWeighting(sampleRate)
+	}
+
+	step := max(1, sampleRate*loudnessStepMillis/1000)
+	var steps []float64 // sum of squared K-weighted samples in each step
+	var sum, peak float64
+	for i := range frames {
+		for c := range channels {
+			v := s16At(pcm, i*channels+c)
+			k := weighting[c].process(v)
+			sum += k * k
+			peak = math.Max(peak, peaks[c].Process(v))
+		}
+		if (i+1)%step == 0 {
+			steps = append(steps, sum)
+			sum = 0
+		}
+	}
+	// The peak meters lag their input; flush out the last samples.
+	for c := range peaks {
+		for range TruePeakDelay {
+			peak = math.Max(peak, peaks[c].Process(0))
+		}
+	}
+
+	return Loudness{
+		Integrated: gatedLoudness(steps, step*loudnessBlockSteps),
+		TruePeak:   20 * math.Log10(peak),
+	}
+}
+
+// Normalize scales the s16le PCM in place so that its integrated loudness is
+// targetLUFS, unless that would take its true peak above ceiling dBTP, in
+// which case it is scaled only as far as the ceiling allows. It returns the
+// loudness measured before scaling and the gain applied in dB. PCM with no
+// measurable loudness is left untouched.
+func Normalize(pcm []byte, sampleRate, channels int, targetLUFS, ceiling float64) (Loudness, float64) {
+	measured := MeasureLoudness(pcm, sampleRate, channels)
+	if math.IsInf(measured.Integrated, -1) {
+		return measured, 0
+	}
+
+	gainDB := math.Min(targetLUFS-measured.Integrated, ceiling-measured.TruePeak)
+	gain := math.Pow(10, gainDB/20)
+	for i := 0; i+1 < len(pcm); i += 2 {
+		v := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) * gain
+		v = math.Max(-32768, math.Min(32767, math.Round(v)))
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(v)))
+	}
+	return measured, gainDB
+}
+
+// gatedLoudness returns the integrated loudness of steps, the sums of
+// squares of consecutive steps of a signal, taking blocks of
+// loudnessBlockSteps steps, blockLen samples in all.
+func gatedLoudness(steps []float64, blockLen int) float64 {
+	var blocks []float64 // mean squares of the blocks above the absolute gate
+	var total float64
+	for i := loudnessBlockSteps; i <= len(steps); i++ {
+		var sum float64
+		for _, s := range steps[i-loudnessBlockSteps : i] {
+			sum += s
+		}
+		z := sum / float64(blockLen)
+		if blockLoudness(z) > absoluteGate {
+			blocks = append(blocks, z)
+			total += z
+		}
+	}
+	if len(blocks) == 0 {
+		return math.Inf(-1)
+	}
+
+	gate := blockLoudness(total/float64(len(blocks))) + relativeGate
+	var sum float64
+	var n int
+	for _, z := range blocks {
+		if blockLoudness(z) > gate {
+			sum += z
+			n++
+		}
+	}
+	return blockLoudness(sum / float64(n))
+}
+
+// blockLoudness returns the loudness in LUFS of a block whose K-weighted
+// mean squares, summed over its channels, are z.
+func blockLoudness(z float64) float64 {
+	return loudnessOffset + 10*math.Log10(z)
+}
+
+// s16At returns the i-th s16le sample of pcm, scaled to [-1, 1).
+func s16At(pcm []byte, i int) float64 {
+	return float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) / 32768
+}
+
+// kWeighting is the K-weighting filter of BS.1770: a high shelf modelling
+// the acoustic effect of the head followed by a high-pass filter, with their
+// coefficients derived for any sample rate.
+type kWeighting struct {
+	shelf, highpass kStage
+}
+
+// kStage is one biquad stage of a kWeighting filter.
+type kStage struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// newKWeighting creates a K-weighting filter for sampleRate.
+func newKWeighting(sampleRate int) *kWeighting {
+	fs := float64(sampleRate)
+	f := &kWeighting{}
+
+	k := math.Tan(math.Pi * 1681.974450955533 / fs)
+	q := 0.7071752369554196
+	vh := math.Pow(10, 3.999843853973347/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	f.shelf = kStage{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	k = math.Tan(math.Pi * 38.13547087602444 / fs)
+	q = 0.5003270373238773
+	a0 = 1 + k/q + k*k
+	f.highpass = kStage{
+		b0: 1, b1: -2, b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return f
+}
+
+// process filters a single sample.
+func (f *kWeighting) process(sample float64) float64 {
+	return f.highpass.process(f.shelf.process(sample))
+}
+
+// process filters a single sample.
+func (s *kStage) process(sample float64) float64 {
+	out := s.b0*sample + s.b1*s.x1 + s.b2*s.x2 - s.a1*s.y1 - s.a2*s.y2
+	s.x2, s.x1 = s.x1, sample
+	s.y2, s.y1 = s.y1, out
+	return out
+}
+
+// True-peak detection after BS.1770 Annex 2: the signal is upsampled
+// truePeakOversample times with a windowed-sinc interpolator reaching
+// TruePeakDelay samples either side of the point it interpolates.
+const (
+	truePeakOversample = 4
+	TruePeakDelay      = 6
+	truePeakTaps       = 2 * TruePeakDelay
+)
+
+// truePeakFIR holds the interpolator's taps for each fraction of a sample,
+// p/truePeakOversample, past the sample TruePeakDelay samples back.
+var truePeakFIR = newTruePeakFIR()
+
+// newTruePeakFIR computes truePeakFIR: a sinc windowed by a Blackman window,
+// each phase normalized to unity gain at DC.
+func newTruePeakFIR() [truePeakOversample][truePeakTaps]float64 {
+	var h [truePeakOversample][truePeakTaps]float64
+	for p := range h {
+		frac := float64(p) / truePeakOversample
+		var sum float64
+		for k := range truePeakTaps {
+			// Distance from the interpolated point to the sample k back.
+			d := float64(k-TruePeakDelay) + frac
+			sinc := 1.0
+			if d != 0 {
+				sinc = math.Sin(math.Pi*d) / (math.Pi * d)
+			}
+			window := 0.42 + 0.5*math.Cos(math.Pi*d/TruePeakDelay) + 0.08*math.Cos(2*math.Pi*d/TruePeakDelay)
+			h[p][k] = sinc * window
+			sum += h[p][k]
+		}
+		for k := range h[p] {
+			h[p][k] /= sum
+		}
+	}
+	return h
+}
+
+// TruePeakMeter tracks the true peak of a signal, one sample at a time. The
+// zero value is ready to use.
+type TruePeakMeter struct {
+	in  [truePeakTaps]float64 // recent input samples
+	pos int                   // index of the newest in in
+}
+
+// Process feeds the meter sample and returns the highest magnitude the
+// signal reaches from the sample TruePeakDelay samples back up to the one
+// after it.
+func (m *TruePeakMeter) Process(sample float64) float64 {
+	m.pos = (m.pos + 1) % len(m.in)
+	m.in[m.pos] = sample
+	var peak float64
+	for _, h := range truePeakFIR {
+		var v float64
+		for k, c := range h {
+			v += c * m.in[(m.pos-k+len(m.in))%len(m.in)]
+		}
+		peak = math.Max(peak, math.Abs(v))
+	}
+	return peak
+}
@@ -0,0 +1,147 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// sinePCM returns seconds of a sine wave of amplitude amp at freq Hz as s16le
+// PCM, the same sample in each of channels channels, at 48 kHz. Channels
+// after the first are silent if mono is set.
+func sinePCM(freq, amp, seconds float64, channels int, mono bool) []byte {
+	frames := int(seconds * 48000)
+	pcm := make([]byte, 0, frames*channels*2)
+	for i := range frames {
+		v := int16(math.Round(amp * 32767 * math.Sin(2*math.Pi*freq*float64(i)/48000)))
+		for c := range channels {
+			s := v
+			if mono && c > 0 {
+				s = 0
+			}
+			pcm = binary.LittleEndian.AppendUint16(pcm, uint16(s))
+		}
+	}
+	return pcm
+}
+
+func TestKWeighting_Coefficients48k(t *testing.T) {
+	// The coefficients tabled in BS.1770-4 for 48 kHz.
+	f := newKWeighting(48000)
+	got := []float64{f.shelf.b0, f.shelf.b1, f.shelf.b2, f.shelf.a1, f.shelf.a2, f.highpass.a1, f.highpass.a2}
+	want := []float64{1.53512485958697, -2.69169618940638, 1.19839281085285, -1.69065929318241, 0.73248077421585, -1.99004745483398, 0.99007225036621}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-6 {
+			t.Errorf("coefficient %d = %.14f, want %.14f", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMeasureLoudness(t *testing.T) {
+	tests := []struct {
+		name     string
+		pcm      []byte
+		channels int
+		want     float64
+	}{
+		// A full-scale 997 Hz sine in one channel reads -3.01 LUFS.
+		{"full scale mono", sinePCM(997, 1, 2, 1, false), 1, -3.01},
+		{"-20 dB mono", sinePCM(997, 0.1, 2, 1, false), 1, -23.01},
+		{"one channel of stereo", sinePCM(997, 0.1, 2, 2, true), 2, -23.01},
+		{"both channels of stereo", sinePCM(997, 0.1, 2, 2, false), 2, -20.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MeasureLoudness(tt.pcm, 48000, tt.channels)
+			if math.Abs(got.Integrated-tt.want) > 0.05 {
+				t.Errorf("Integrated = %.3f LUFS, want %.3f", got.Integrated, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeasureLoudness_GatesQuietPassages(t *testing.T) {
+	loud := sinePCM(997, 0.1, 2, 1, false)
+	quiet := sinePCM(997, 0.001, 4, 1, false) // 40 dB down, below the relative gate
+	got := MeasureLoudness(append(append([]byte(nil), loud...), quiet...), 48000, 1)
+	// Ungated, the quiet passage would bring the loudness down to -27.8
+	// LUFS; only the blocks straddling the two count against it.
+	if math.Abs(got.Integrated+23.01) > 0.5 {
+		t.Errorf("Integrated = %.3f LUFS, want about -23.01 with the quiet passage gated out", got.Integrated)
+	}
+}
+
+func TestMeasureLoudness_Silence(t *testing.T) {
+	tests := []struct {
+		name string
+		pcm  []byte
+	}{
+		{"silence", make([]byte, 48000*2)},
+		{"shorter than a block", sinePCM(997, 0.5, 0.3, 1, false)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MeasureLoudness(tt.pcm, 48000, 1)
+			if !math.IsInf(got.Integrated, -1) {
+				t.Errorf("Integrated = %v, want -Inf", got.Integrated)
+			}
+		})
+	}
+}
+
+func TestMeasureLoudness_TruePeak(t *testing.T) {
+	// A sine at a quarter of the sample rate, sampled 45 degrees off its
+	// peaks, has samples at 0.707 of its amplitude but a true peak of 1.
+	var pcm []byte
+	for i := range 4800 {
+		v := 0.5 * math.Sin(math.Pi/2*float64(i)+math.Pi/4)
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(int16(math.Round(v*32767))))
+	}
+	got := MeasureLoudness(pcm, 48000, 1)
+	want := 20 * math.Log10(0.5)
+	if math.Abs(got.TruePeak-want) > 0.5 {
+		t.Errorf("TruePeak = %.2f dBTP, want about %.2f (sample peak %.2f)", got.TruePeak, want, want-3.01)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		amp      float64
+		target   float64
+		ceiling  float64
+		wantLUFS float64
+	}{
+		{"raises quiet audio", 0.01, -23, -1, -23},
+		{"lowers loud audio", 0.9, -16, -1, -16},
+		{"stops at the ceiling", 0.1, -5, -6, -9.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pcm := sinePCM(997, tt.amp, 2, 1, false)
+			before, gain := Normalize(pcm, 48000, 1, tt.target, tt.ceiling)
+			after := MeasureLoudness(pcm, 48000, 1)
+			if math.Abs(after.Integrated-tt.wantLUFS) > 0.1 {
+				t.Errorf("Integrated after = %.3f LUFS, want %.3f", after.Integrated, tt.wantLUFS)
+			}
+			if math.Abs(before.Integrated+gain-after.Integrated) > 0.1 {
+				t.Errorf("before %.3f + gain %.3f dB != after %.3f", before.Integrated, gain, after.Integrated)
+			}
+			if after.TruePeak > tt.ceiling+0.1 {
+				t.Errorf("TruePeak after = %.3f dBTP, want at most %.3f", after.TruePeak, tt.ceiling)
+			}
+		})
+	}
+}
+
+func TestNormalize_SilenceUntouched(t *testing.T) {
+	pcm := make([]byte, 48000*2)
+	pcm[100] = 1
+	_, gain := Normalize(pcm, 48000, 1, -16, -1)
+	if gain != 0 {
+		t.Errorf("gain = %v, want 0", gain)
+	}
+	if pcm[100] != 1 {
+		t.Error("Normalize modified silent PCM")
+	}
+}
@@ -0,0 +1,269 @@
+package audio
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Interpolate returns the parameters mix of the way from a to b, so that 0
+// gives a and 1 gives b; mix is clamped to [0, 1]. Numeric fields are blended
+// linearly and integer fields such as CrusherBits are rounded to the nearest
+// value; filter Qs of 0 are blended as ButterworthQ. Fields that cannot be
+// blended (seeds, layer types, vowels, waveforms, noise colours, biquad
+// switches, ping-pong and distortion curves) are taken from whichever of a
+// and b mix is nearer, a at exactly one half, so the result is
+// deterministic. Layer levels, envelope breakpoints, pitch breakpoints and
+// EQ bands are blended one by one when a and b have as many, and otherwise
+// picked the same way, as are pitch curves and EQ band types.
+// Layers are blended by index; if one side has more layers, its extra layers
+// are kept with their amplitude faded towards zero at the other side.
+// SampleRate, Channels and the crowd settings always come from a, and the
+// result has no MorphTo.
+func Interpolate(a, b ScreamParams, mix float64) ScreamParams {
+	mix = math.Max(0, math.Min(1, mix))
+	nearB := mix > 0.5
+
+	out := ScreamParams{
+		Duration:   time.Duration(math.Round(lerp(float64(a.Duration), float64(b.Duration), mix))),
+		SampleRate: a.SampleRate,
+		Channels:   a.Channels,
+		Seed:       pick(a.Seed, b.Seed, nearB),
+		Width:      lerp(a.Width, b.Width, mix),
+		Envelope:   lerpEnvelope(a.Envelope, b.Envelope, mix, nearB),
+		Voices:     a.Voices,
+		Spread:     a.Spread,
+		VoiceMix:   a.VoiceMix,
+	}
+	out.Layers = make([]LayerParams, max(len(a.Layers), len(b.Layers)))
+	for i := range out.Layers {
+		switch {
+		case i >= len(b.Layers):
+			out.Layers[i] = a.Layers[i]
+			out.Layers[i].Amplitude = lerp(a.Layers[i].Amplitude, 0, mix)
+			continue
+		case i >= len(a.Layers):
+			out.Layers[i] = b.Layers[i]
+			out.Layers[i].Amplitude = lerp(0, b.Layers[i].Amplitude, mix)
+			continue
+		}
+		la, lb := a.Layers[i], b.Layers[i]
+		out.Layers[i] = LayerParams{
+			Type:       pick(la.Type, lb.Type, nearB),
+			BaseFreq:   lerp(la.BaseFreq, lb.BaseFreq, mix),
+			FreqRange:  lerp(la.FreqRange, lb.FreqRange, mix),
+			SweepRate:  lerp(la.SweepRate, lb.SweepRate, mix),
+			JumpRate:   lerp(la.JumpRate, lb.JumpRate, mix),
+			Amplitude:  lerp(la.Amplitude, lb.Amplitude, mix),
+			Rise:       lerp(la.Rise, lb.Rise, mix),
+			Seed:       pick(la.Seed, lb.Seed, nearB),
+			Pan:        lerp(la.Pan, lb.Pan, mix),
+			PanRate:    lerp(la.PanRate, lb.PanRate, mix),
+			PanDepth:   lerp(la.PanDepth, lb.PanDepth, mix),
+			Levels:     lerpLevels(la.Levels, lb.Levels, mix, nearB),
+			Envelope:   lerpEnvelope(la.Envelope, lb.Envelope, mix, nearB),
+			Tremolo:    lerpLFO(la.Tremolo, lb.Tremolo, mix),
+			Pitch:      lerpPitch(la.Pitch, lb.Pitch, mix, nearB),
+			Vibrato:    lerpLFO(la.Vibrato, lb.Vibrato, mix),
+			Waveform:   pick(la.Waveform, lb.Waveform, nearB),
+			PulseWidth: lerp(la.PulseWidth, lb.PulseWidth, mix),
+			Detune:     lerp(la.Detune, lb.Detune, mix),
+			Noise:      lerpNoise(la.Noise, lb.Noise, mix, nearB),
+			BurstRate:  lerp(la.BurstRate, lb.BurstRate, mix),
+			Threshold:  lerp(la.Threshold, lb.Threshold, mix),
+			Vowel:      pick(la.Vowel, lb.Vowel, nearB),
+			Jitter:     lerp(la.Jitter, lb.Jitter, mix),
+			Shimmer:    lerp(la.Shimmer, lb.Shimmer, mix),
+		}
+	}
+	fa, fb := a.Filter, b.Filter
+	out.Filter = FilterParams{
+		HighpassCutoff:  lerp(fa.HighpassCutoff, fb.HighpassCutoff, mix),
+		LowpassCutoff:   lerp(fa.LowpassCutoff, fb.LowpassCutoff, mix),
+		CrusherBits:     int(math.Round(lerp(float64(fa.CrusherBits), float64(fb.CrusherBits), mix))),
+		CrusherMix:      lerp(fa.CrusherMix, fb.CrusherMix, mix),
+		CompRatio:       lerp(fa.CompRatio, fb.CompRatio, mix),
+		CompThreshold:   lerp(fa.CompThreshold, fb.CompThreshold, mix),
+		CompAttack:      lerp(fa.CompAttack, fb.CompAttack, mix),
+		CompRelease:     lerp(fa.CompRelease, fb.CompRelease, mix),
+		VolumeBoostDB:   lerp(fa.VolumeBoostDB, fb.VolumeBoostDB, mix),
+		LimiterLevel:    lerp(fa.LimiterLevel, fb.LimiterLevel, mix),
+		Limiter:         pick(fa.Limiter, fb.Limiter, nearB),
+		LimiterAttack:   lerpLimiterTime(fa.LimiterAttack, fb.LimiterAttack, mix, DefaultLimiterAttack),
+		LimiterRelease:  lerpLimiterTime(fa.LimiterRelease, fb.LimiterRelease, mix, DefaultLimiterRelease),
+		Biquad:          pick(fa.Biquad, fb.Biquad, nearB),
+		HighpassQ:       lerpQ(fa.HighpassQ, fb.HighpassQ, mix),
+		LowpassQ:        lerpQ(fa.LowpassQ, fb.LowpassQ, mix),
+		EQ:              lerpEQ(fa.EQ, fb.EQ, mix, nearB),
+		ReverbMix:       lerp(fa.ReverbMix, fb.ReverbMix, mix),
+		ReverbRoom:      lerp(fa.ReverbRoom, fb.ReverbRoom, mix),
+		ReverbDamping:   lerp(fa.ReverbDamping, fb.ReverbDamping, mix),
+		ReverbPreDelay:  lerp(fa.ReverbPreDelay, fb.ReverbPreDelay, mix),
+		DelayMix:        lerp(fa.DelayMix, fb.DelayMix, mix),
+		DelayTime:       lerp(fa.DelayTime, fb.DelayTime, mix),
+		DelayFeedback:   lerp(fa.DelayFeedback, fb.DelayFeedback, mix),
+		DelayPingPong:   pick(fa.DelayPingPong, fb.DelayPingPong, nearB),
+		DelayLowpass:    lerp(fa.DelayLowpass, fb.DelayLowpass, mix),
+		Distortion:      pick(fa.Distortion, fb.Distortion, nearB),
+		DistortionDrive: lerp(fa.DistortionDrive, fb.DistortionDrive, mix),
+		DistortionTone:  lerp(fa.DistortionTone, fb.DistortionTone, mix),
+	}
+	return out
+}
+
+// MorphStart returns the parameters a morphing scream starts from: p without
+// its MorphTo.
+func (p ScreamParams) MorphStart() ScreamParams {
+	p.MorphTo = nil
+	return p
+}
+
+// MorphEnd returns the parameters a morphing scream ends with: p.MorphTo with
+// the duration and output format of p. It returns p.MorphStart() if p does not
+// morph.
+func (p ScreamParams) MorphEnd() ScreamParams {
+	if p.MorphTo == nil {
+		return p.MorphStart()
+	}
+	end := p.MorphTo.Clone()
+	end.Duration = p.Duration
+	end.SampleRate = p.SampleRate
+	end.Channels = p.Channels
+	end.MorphTo = nil
+	return end
+}
+
+// lerp returns the value mix of the way from a to b. It returns exactly b
+// when mix is 1, and exactly a when mix is 0 or a equals b.
+func lerp(a, b, mix float64) float64 {
+	if mix == 1 {
+		return b
+	}
+	return a + (b-a)*mix
+}
+
+// lerpLevels blends the layer levels a and b level by level if they have as
+// many levels, and otherwise returns a copy of b if nearB is true and of a
+// if not.
+func lerpLevels(a, b []float64, mix float64, nearB bool) []float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return slices.Clone(pick(a, b, nearB))
+	}
+	out := make([]float64, len(a))
+	for i := range out {
+		out[i] = lerp(a[i], b[i], mix)
+	}
+	return out
+}
+
+// lerpEnvelope blends the envelopes a and b stage by stage. Their breakpoints
+// are blended point by point if they have as many, and otherwise copied from
+// b if nearB is true and from a if not.
+func lerpEnvelope(a, b Envelope, mix float64, nearB bool) Envelope {
+	out := Envelope{
+		Attack:  lerp(a.Attack, b.Attack, mix),
+		Decay:   lerp(a.Decay, b.Decay, mix),
+		Sustain: lerp(a.Sustain, b.Sustain, mix),
+		Release: lerp(a.Release, b.Release, mix),
+	}
+	if len(a.Points) != len(b.Points) || len(a.Points) == 0 {
+		out.Points = slices.Clone(pick(a.Points, b.Points, nearB))
+		return out
+	}
+	out.Points = make([]EnvelopePoint, len(a.Points))
+	for i := range out.Points {
+		out.Points[i] = EnvelopePoint{
+			Time:  lerp(a.Points[i].Time, b.Points[i].Time, mix),
+			Level: lerp(a.Points[i].Level, b.Points[i].Level, mix),
+		}
+	}
+	return out
+}
+
+// lerpPitch blends the pitch contours a and b. The curve comes from b if
+// nearB is true and from a if not, and the breakpoints are blended as by
+// lerpEnvelope.
+func lerpPitch(a, b PitchContour, mix float64, nearB bool) PitchContour {
+	out := PitchContour{
+		Curve:     pick(a.Curve, b.Curve, nearB),
+		Semitones: lerp(a.Semitones, b.Semitones, mix),
+	}
+	if len(a.Points) != len(b.Points) || len(a.Points) == 0 {
+		out.Points = slices.Clone(pick(a.Points, b.Points, nearB))
+		return out
+	}
+	out.Points = make([]PitchPoint, len(a.Points))
+	for i := range out.Points {
+		out.Points[i] = PitchPoint{
+			Time:      lerp(a.Points[i].Time, b.Points[i].Time, mix),
+			Semitones: lerp(a.Points[i].Semitones, b.Points[i].Semitones, mix),
+		}
+	}
+	return out
+}
+
+// lerpQ blends the filter Qs a and b as lerp does, treating a Q of 0 as the
+// ButterworthQ it stands for.
+func lerpQ(a, b, mix float64) float64 {
+	if mix == 0 || mix == 1 || a == b {
+		return lerp(a, b, mix)
+	}
+	return lerp(FilterQ(a), FilterQ(b), mix)
+}
+
+// lerpLimiterTime blends the limiter attacks or releases a and b as lerp
+// does, treating 0 as the default def it stands for.
+func lerpLimiterTime(a, b, mix, def float64) float64 {
+	if mix == 0 || mix == 1 || a == b {
+		return lerp(a, b, mix)
+	}
+	if a == 0 {
+		a = def
+	}
+	if b == 0 {
+		b = def
+	}
+	return lerp(a, b, mix)
+}
+
+// lerpEQ blends the EQs a and b band by band if they have as many bands, and
+// otherwise returns a copy of b if nearB is true and of a if not. The type
+// of each band is picked the same way.
+func lerpEQ(a, b []EQBand, mix float64, nearB bool) []EQBand {
+	if len(a) != len(b) || len(a) == 0 {
+		return slices.Clone(pick(a, b, nearB))
+	}
+	out := make([]EQBand, len(a))
+	for i := range out {
+		out[i] = EQBand{
+			Type: pick(a[i].Type, b[i].Type, nearB),
+			Freq: lerp(a[i].Freq, b[i].Freq, mix),
+			Q:    lerpQ(a[i].Q, b[i].Q, mix),
+			Gain: lerp(a[i].Gain, b[i].Gain, mix),
+		}
+	}
+	return out
+}
+
+// lerpNoise blends the noise settings a and b. The colour comes from b if
+// nearB is true and from a if not.
+func lerpNoise(a, b NoiseParams, mix float64, nearB bool) NoiseParams {
+	return NoiseParams{
+		Color: pick(a.Color, b.Color, nearB),
+		Freq:  lerp(a.Freq, b.Freq, mix),
+		Q:     lerpQ(a.Q, b.Q, mix),
+	}
+}
+
+// lerpLFO blends the LFOs a and b.
+func lerpLFO(a, b LFO, mix float64) LFO {
+	return LFO{Rate: lerp(a.Rate, b.Rate, mix), Depth: lerp(a.Depth, b.Depth, mix)}
+}
+
+// pick returns b if nearB is true and a otherwise.
+func pick[T any](a, b T, nearB bool) T {
+	if nearB {
+		return b
+	}
+	return a
+}
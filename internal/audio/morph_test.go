@@ -0,0 +1,441 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestInterpolate_Endpoints(t *testing.T) {
+	a, _ := GetPreset(PresetDeathMetal)
+	b, _ := GetPreset(PresetWhisper)
+
+	if got := Interpolate(a, b, 0); !reflect.DeepEqual(got, a) {
+		t.Error("Interpolate(a, b, 0) != a")
+	}
+	if got := Interpolate(a, b, 1); !reflect.DeepEqual(got, b) {
+		t.Error("Interpolate(a, b, 1) != b")
+	}
+	if got := Interpolate(a, b, -2); !reflect.DeepEqual(got, a) {
+		t.Error("Interpolate(a, b, -2) should clamp to a")
+	}
+	if got := Interpolate(a, b, 3); !reflect.DeepEqual(got, b) {
+		t.Error("Interpolate(a, b, 3) should clamp to b")
+	}
+}
+
+func TestInterpolate_BlendsNumericFields(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	a.Duration = 2 * time.Second
+	b.Duration = 4 * time.Second
+	a.Layers[0].BaseFreq = 200
+	b.Layers[0].BaseFreq = 600
+	a.Layers[3].Threshold = 0.5
+	b.Layers[3].Threshold = 0.9
+	a.Filter.LowpassCutoff = 4000
+	b.Filter.LowpassCutoff = 8000
+	a.Width = 0
+	b.Width = 1
+
+	got := Interpolate(a, b, 0.25)
+	if got.Duration != 2500*time.Millisecond {
+		t.Errorf("Duration = %v, want 2.5s", got.Duration)
+	}
+	if got.Layers[0].BaseFreq != 300 {
+		t.Errorf("Layers[0].BaseFreq = %v, want 300", got.Layers[0].BaseFreq)
+	}
+	if got.Layers[3].Threshold != 0.6 {
+		t.Errorf("Layers[3].Threshold = %v, want 0.6", got.Layers[3].Threshold)
+	}
+	if got.Filter.LowpassCutoff != 5000 {
+		t.Errorf("Filter.LowpassCutoff = %v, want 5000", got.Filter.LowpassCutoff)
+	}
+	if got.Width != 0.25 {
+		t.Errorf("Width = %v, want 0.25", got.Width)
+	}
+}
+
+func TestInterpolate_RoundsCrusherBits(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	a.Filter.CrusherBits = 6
+	b.Filter.CrusherBits = 12
+
+	tests := []struct {
+		mix  float64
+		want int
+	}{
+		{0, 6},
+		{0.1, 7},
+		{0.3, 8},
+		{0.5, 9},
+		{0.7, 10},
+		{1, 12},
+	}
+	for _, tt := range tests {
+		if got := Interpolate(a, b, tt.mix).Filter.CrusherBits; got != tt.want {
+			t.Errorf("mix %v: CrusherBits = %d, want %d", tt.mix, got, tt.want)
+		}
+	}
+}
+
+func TestInterpolate_SeedsFromNearerParams(t *testing.T) {
+	a := Randomize(1)
+	b := Randomize(2)
+	b.Layers[3].Type = LayerHighShriek
+
+	for _, mix := range []float64{0, 0.3, 0.5} {
+		got := Interpolate(a, b, mix)
+		if got.Seed != a.Seed || got.Layers[0].Seed != a.Layers[0].Seed || got.Layers[4].Seed != a.Layers[4].Seed {
+			t.Errorf("mix %v: seeds should come from a", mix)
+		}
+		if got.Layers[3].Type != a.Layers[3].Type {
+			t.Errorf("mix %v: Layers[3].Type = %v, want %v", mix, got.Layers[3].Type, a.Layers[3].Type)
+		}
+	}
+	for _, mix := range []float64{0.51, 0.7, 1} {
+		got := Interpolate(a, b, mix)
+		if got.Seed != b.Seed || got.Layers[0].Seed != b.Layers[0].Seed || got.Layers[4].Seed != b.Layers[4].Seed {
+			t.Errorf("mix %v: seeds should come from b", mix)
+		}
+		if got.Layers[3].Type != LayerHighShriek {
+			t.Errorf("mix %v: Layers[3].Type = %v, want %v", mix, got.Layers[3].Type, LayerHighShriek)
+		}
+	}
+}
+
+func TestInterpolate_DifferentLayerCounts(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	a.Layers = a.Layers[:2]
+	b.Layers = append(b.Layers, LayerParams{Type: LayerVocal, Amplitude: 0.4})
+
+	tests := []struct {
+		mix        float64
+		shriekAmp  float64 // Layers[2], which only b has
+		vocalAmp   float64 // Layers[5], which only b has
+		wantLayers int
+	}{
+		{0, 0, 0, 6},
+		{0.5, b.Layers[2].Amplitude / 2, 0.2, 6},
+		{1, b.Layers[2].Amplitude, 0.4, 6},
+	}
+	for _, tt := range tests {
+		got := Interpolate(a, b, tt.mix)
+		if len(got.Layers) != tt.wantLayers {
+			t.Fatalf("mix %v: %d layers, want %d", tt.mix, len(got.Layers), tt.wantLayers)
+		}
+		if got.Layers[2].Amplitude != tt.shriekAmp || got.Layers[5].Amplitude != tt.vocalAmp {
+			t.Errorf("mix %v: extra layer amplitudes = %v, %v, want %v, %v",
+				tt.mix, got.Layers[2].Amplitude, got.Layers[5].Amplitude, tt.shriekAmp, tt.vocalAmp)
+		}
+		if got.Layers[5].Type != LayerVocal {
+			t.Errorf("mix %v: Layers[5].Type = %v, want vocal", tt.mix, got.Layers[5].Type)
+		}
+	}
+
+	// Layers only a has fade out towards b.
+	got := Interpolate(b, a, 0.25)
+	if want := b.Layers[5].Amplitude * 0.75; got.Layers[5].Amplitude != want {
+		t.Errorf("fading layer amplitude = %v, want %v", got.Layers[5].Amplitude, want)
+	}
+}
+
+func TestInterpolate_Levels(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	a.Layers[0].Levels = []float64{0, 1}
+	b.Layers[0].Levels = []float64{1, 0}
+	b.Layers[1].Levels = []float64{0.5, 1, 0.5}
+
+	got := Interpolate(a, b, 0.25)
+	if want := []float64{0.25, 0.75}; !slices.Equal(got.Layers[0].Levels, want) {
+		t.Errorf("blended levels = %v, want %v", got.Layers[0].Levels, want)
+	}
+	if got.Layers[1].Levels != nil {
+		t.Errorf("levels only b has = %v at mix 0.25, want nil", got.Layers[1].Levels)
+	}
+	got = Interpolate(a, b, 0.75)
+	if !slices.Equal(got.Layers[1].Levels, b.Layers[1].Levels) {
+		t.Errorf("levels only b has = %v at mix 0.75, want %v", got.Layers[1].Levels, b.Layers[1].Levels)
+	}
+
+	got.Layers[1].Levels[0] = 0
+	if b.Layers[1].Levels[0] == 0 {
+		t.Error("modifying the blend changed b's levels")
+	}
+}
+
+func TestInterpolate_FormatFromFirst(t *testing.T) {
+	a := Randomize(1)
+	b := Randomize(2)
+	b.SampleRate = 44100
+	b.Channels = 1
+	b.MorphTo = &a
+
+	got := Interpolate(a, b, 1)
+	if got.SampleRate != a.SampleRate || got.Channels != a.Channels {
+		t.Errorf("format = %d Hz x %d, want %d Hz x %d", got.SampleRate, got.Channels, a.SampleRate, a.Channels)
+	}
+	if got.MorphTo != nil {
+		t.Error("Interpolate() result has a MorphTo")
+	}
+}
+
+func TestInterpolate_BlendIsValid(t *testing.T) {
+	for _, a := range AllPresets() {
+		for _, b := range AllPresets() {
+			pa, _ := GetPreset(a)
+			pb, _ := GetPreset(b)
+			for _, mix := range []float64{0.25, 0.5, 0.75} {
+				if err := Interpolate(pa, pb, mix).Validate(); err != nil {
+					t.Errorf("Interpolate(%s, %s, %v) invalid: %v", a, b, mix, err)
+				}
+			}
+		}
+	}
+}
+
+func TestMorphEnd(t *testing.T) {
+	a := Randomize(1)
+	b := Randomize(2)
+	b.Channels = 1
+
+	if end := a.MorphEnd(); !reflect.DeepEqual(end, a) {
+		t.Error("MorphEnd() of params without MorphTo should be the params")
+	}
+
+	a.MorphTo = &b
+	end := a.MorphEnd()
+	if end.Duration != a.Duration || end.SampleRate != a.SampleRate || end.Channels != a.Channels {
+		t.Error("MorphEnd() should take its duration and format from the outer params")
+	}
+	if !reflect.DeepEqual(end.Layers, b.Layers) || !reflect.DeepEqual(end.Filter, b.Filter) {
+		t.Error("MorphEnd() should take its sound from MorphTo")
+	}
+	if start := a.MorphStart(); start.MorphTo != nil {
+		t.Error("MorphStart() has a MorphTo")
+	}
+}
+
+func TestValidate_Morph(t *testing.T) {
+	a := Randomize(1)
+	b := Randomize(2)
+	b.Duration = 0 // ignored
+	a.MorphTo = &b
+	if err := a.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	bad := b.Clone()
+	bad.Layers[1].Amplitude = 2
+	a.MorphTo = &bad
+	if err := a.Validate(); !errors.Is(err, ErrInvalidMorph) || !errors.Is(err, ErrInvalidAmplitude) {
+		t.Errorf("Validate() error = %v, want ErrInvalidMorph wrapping ErrInvalidAmplitude", err)
+	}
+
+	nested := b
+	nested.MorphTo = &b
+	a.MorphTo = &nested
+	if err := a.Validate(); !errors.Is(err, ErrInvalidMorph) {
+		t.Errorf("Validate() error = %v, want ErrInvalidMorph", err)
+	}
+}
+
+func TestInterpolate_Envelope(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	a.Envelope = Envelope{Attack: 0.1, Release: 1}
+	b.Envelope = Envelope{Attack: 0.5, Decay: 1, Sustain: 0.4}
+	a.Layers[0].Envelope.Points = []EnvelopePoint{{0, 0}, {1, 1}}
+	b.Layers[0].Envelope.Points = []EnvelopePoint{{1, 1}, {2, 0}}
+	b.Layers[1].Envelope.Points = []EnvelopePoint{{0, 1}}
+
+	got := Interpolate(a, b, 0.25)
+	want := Envelope{Attack: 0.2, Decay: 0.25, Sustain: 0.1, Release: 0.75}
+	if !reflect.DeepEqual(got.Envelope, want) {
+		t.Errorf("blended envelope = %+v, want %+v", got.Envelope, want)
+	}
+	if want := []EnvelopePoint{{0.25, 0.25}, {1.25, 0.75}}; !reflect.DeepEqual(got.Layers[0].Envelope.Points, want) {
+		t.Errorf("blended points = %v, want %v", got.Layers[0].Envelope.Points, want)
+	}
+	if got.Layers[1].Envelope.Points != nil {
+		t.Errorf("points only b has = %v at mix 0.25, want nil", got.Layers[1].Envelope.Points)
+	}
+	got = Interpolate(a, b, 0.75)
+	if !reflect.DeepEqual(got.Layers[1].Envelope.Points, b.Layers[1].Envelope.Points) {
+		t.Errorf("points only b has = %v at mix 0.75, want %v", got.Layers[1].Envelope.Points, b.Layers[1].Envelope.Points)
+	}
+}
+
+func TestInterpolate_Pitch(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	a.Layers[0].Pitch = PitchContour{Curve: PitchRising, Semitones: 4}
+	b.Layers[0].Pitch = PitchContour{Curve: PitchArch, Semitones: 12}
+	a.Layers[0].Vibrato = LFO{Rate: 4, Depth: 1}
+	b.Layers[0].Tremolo = LFO{Rate: 8, Depth: 0.4}
+	a.Layers[1].Pitch.Points = []PitchPoint{{0, 0}, {1, -4}}
+	b.Layers[1].Pitch.Points = []PitchPoint{{1, 4}, {2, 0}}
+
+	got := Interpolate(a, b, 0.25)
+	if want := (PitchContour{Curve: PitchRising, Semitones: 6}); !reflect.DeepEqual(got.Layers[0].Pitch, want) {
+		t.Errorf("blended pitch = %+v, want %+v", got.Layers[0].Pitch, want)
+	}
+	if want := (LFO{Rate: 3, Depth: 0.75}); got.Layers[0].Vibrato != want {
+		t.Errorf("blended vibrato = %+v, want %+v", got.Layers[0].Vibrato, want)
+	}
+	if want := (LFO{Rate: 2, Depth: 0.1}); got.Layers[0].Tremolo != want {
+		t.Errorf("blended tremolo = %+v, want %+v", got.Layers[0].Tremolo, want)
+	}
+	if want := []PitchPoint{{0.25, 1}, {1.25, -3}}; !reflect.DeepEqual(got.Layers[1].Pitch.Points, want) {
+		t.Errorf("blended pitch points = %v, want %v", got.Layers[1].Pitch.Points, want)
+	}
+
+	if got := Interpolate(a, b, 0.75); got.Layers[0].Pitch.Curve != PitchArch {
+		t.Errorf("curve at mix 0.75 = %q, want %q", got.Layers[0].Pitch.Curve, PitchArch)
+	}
+}
+
+func TestInterpolate_Waveform(t *testing.T) {
+	a, _ := GetPreset(PresetRobot)
+	b := a.Clone()
+	b.Layers[1].Waveform = WaveSupersaw
+	b.Layers[1].PulseWidth = 0
+	b.Layers[1].Detune = 40
+
+	got := Interpolate(a, b, 0.25)
+	if l := got.Layers[1]; l.Waveform != WavePulse || math.Abs(l.PulseWidth-0.15) > 1e-12 || l.Detune != 10 {
+		t.Errorf("layer at mix 0.25 = %q, width %v, detune %v; want pulse, 0.15, 10", l.Waveform, l.PulseWidth, l.Detune)
+	}
+	if got := Interpolate(a, b, 0.75); got.Layers[1].Waveform != WaveSupersaw {
+		t.Errorf("waveform at mix 0.75 = %q, want %q", got.Layers[1].Waveform, WaveSupersaw)
+	}
+}
+
+func TestInterpolate_Reverb(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b, _ := GetPreset(PresetBanshee)
+	b.Filter = a.Filter
+	b.Filter.ReverbMix = 0.4
+	b.Filter.ReverbRoom = 0.8
+	b.Filter.ReverbDamping = 0.2
+	b.Filter.ReverbPreDelay = 40
+
+	got := Interpolate(a, b, 0.5).Filter
+	if got.ReverbMix != 0.2 || got.ReverbRoom != 0.4 || got.ReverbDamping != 0.1 || got.ReverbPreDelay != 20 {
+		t.Errorf("reverb at mix 0.5 = %v, %v, %v, %v; want 0.2, 0.4, 0.1, 20",
+			got.ReverbMix, got.ReverbRoom, got.ReverbDamping, got.ReverbPreDelay)
+	}
+}
+
+func TestInterpolate_Delay(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	b.Filter.DelayMix = 0.5
+	b.Filter.DelayTime = 400
+	b.Filter.DelayFeedback = 0.4
+	b.Filter.DelayPingPong = true
+	b.Filter.DelayLowpass = 2000
+
+	got := Interpolate(a, b, 0.25).Filter
+	if got.DelayMix != 0.125 || got.DelayTime != 100 || got.DelayFeedback != 0.1 || got.DelayLowpass != 500 || got.DelayPingPong {
+		t.Errorf("delay at mix 0.25 = %v, %v, %v, %v, ping-pong %v; want 0.125, 100, 0.1, 500, false",
+			got.DelayMix, got.DelayTime, got.DelayFeedback, got.DelayLowpass, got.DelayPingPong)
+	}
+	if got := Interpolate(a, b, 0.75).Filter; !got.DelayPingPong {
+		t.Error("ping-pong at mix 0.75 = false, want true")
+	}
+}
+
+func TestInterpolate_Noise(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	a.Layers[3].Noise = NoiseParams{Color: NoisePink, Freq: 1000}
+	b.Layers[3].Noise = NoiseParams{Color: NoiseBand, Freq: 2000, Q: 4}
+
+	got := Interpolate(a, b, 0.25).Layers[3].Noise
+	want := NoiseParams{Color: NoisePink, Freq: 1250, Q: lerp(ButterworthQ, 4, 0.25)}
+	if got != want {
+		t.Errorf("noise at mix 0.25 = %+v, want %+v", got, want)
+	}
+	if got := Interpolate(a, b, 0.75).Layers[3].Noise; got.Color != NoiseBand {
+		t.Errorf("noise colour at mix 0.75 = %q, want %q", got.Color, NoiseBand)
+	}
+}
+
+func TestInterpolate_EQ(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	a.Filter.EQ = []EQBand{{Type: EQPeak, Freq: 1000, Gain: -6}}
+	b.Filter.Biquad = true
+	b.Filter.LowpassQ = 4
+	b.Filter.EQ = []EQBand{{Type: EQNotch, Freq: 2000, Q: 4, Gain: 6}}
+
+	got := Interpolate(a, b, 0.25).Filter
+	want := EQBand{Type: EQPeak, Freq: 1250, Q: lerp(ButterworthQ, 4, 0.25), Gain: -3}
+	if got.Biquad || len(got.EQ) != 1 || got.EQ[0] != want {
+		t.Errorf("EQ at mix 0.25 = %v %+v, want false [%+v]", got.Biquad, got.EQ, want)
+	}
+	if got.LowpassQ != lerp(ButterworthQ, 4, 0.25) || got.HighpassQ != 0 {
+		t.Errorf("Qs at mix 0.25 = %v, %v; want %v, 0", got.HighpassQ, got.LowpassQ, lerp(ButterworthQ, 4, 0.25))
+	}
+	if got := Interpolate(a, b, 0.75).Filter; !got.Biquad || got.EQ[0].Type != EQNotch {
+		t.Errorf("EQ at mix 0.75 = %v %+v, want true and a notch", got.Biquad, got.EQ)
+	}
+
+	// EQs with different numbers of bands are picked whole.
+	b.Filter.EQ = append(b.Filter.EQ, EQBand{Type: EQHighShelf, Freq: 8000, Gain: 3})
+	if got := Interpolate(a, b, 0.25).Filter.EQ; !reflect.DeepEqual(got, a.Filter.EQ) {
+		t.Errorf("EQ at mix 0.25 = %+v, want %+v", got, a.Filter.EQ)
+	}
+	got = Interpolate(a, b, 0.75).Filter
+	if !reflect.DeepEqual(got.EQ, b.Filter.EQ) {
+		t.Errorf("EQ at mix 0.75 = %+v, want %+v", got.EQ, b.Filter.EQ)
+	}
+	got.EQ[0].Freq = 1
+	if b.Filter.EQ[0].Freq == 1 {
+		t.Error("modifying the interpolated EQ changed b")
+	}
+}
+
+func TestInterpolate_Distortion(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b, _ := GetPreset(PresetDeathMetal)
+	b.Filter = a.Filter
+	b.Filter.Distortion = DistortionTube
+	b.Filter.DistortionDrive = 20
+	b.Filter.DistortionTone = 4000
+
+	got := Interpolate(a, b, 0.25).Filter
+	if got.Distortion != "" || got.DistortionDrive != 5 || got.DistortionTone != 1000 {
+		t.Errorf("distortion at mix 0.25 = %q, drive %v, tone %v; want none, 5, 1000", got.Distortion, got.DistortionDrive, got.DistortionTone)
+	}
+	if got := Interpolate(a, b, 0.75).Filter; got.Distortion != DistortionTube {
+		t.Errorf("distortion at mix 0.75 = %q, want %q", got.Distortion, DistortionTube)
+	}
+}
+
+func TestInterpolate_Limiter(t *testing.T) {
+	a, _ := GetPreset(PresetClassic)
+	b := a.Clone()
+	b.Filter.Limiter = LimiterLookahead
+	b.Filter.LimiterAttack = 5
+	b.Filter.LimiterRelease = 110
+
+	// An attack and release of 0 blend from the defaults they stand for.
+	got := Interpolate(a, b, 0.25).Filter
+	if got.Limiter != "" || got.LimiterAttack != 2 || got.LimiterRelease != 35 {
+		t.Errorf("limiter at mix 0.25 = %q, attack %v, release %v; want hard, 2, 35", got.Limiter, got.LimiterAttack, got.LimiterRelease)
+	}
+	if got := Interpolate(a, b, 0.75).Filter; got.Limiter != LimiterLookahead {
+		t.Errorf("limiter at mix 0.75 = %q, want %q", got.Limiter, LimiterLookahead)
+	}
+	if got := Interpolate(a, b, 0).Filter; got.LimiterAttack != 0 || got.LimiterRelease != 0 {
+		t.Errorf("limiter at mix 0: attack %v, release %v; want 0, 0", got.LimiterAttack, got.LimiterRelease)
+	}
+}
@@ -0,0 +1,168 @@
+package native
+
+import (
+	"math"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// biquad is a second-order IIR filter with any of the responses of
+// audio.EQType, from the coefficients of Robert Bristow-Johnson's Audio EQ
+// Cookbook. Its bandpass response peaks at unity gain.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// newBiquad creates a biquad filter with the response of kind at freq Hz, with
+// the given Q and, for peaks and shelves, gain in dB.
+func newBiquad(kind audio.EQType, freq, q, gain float64, sampleRate int) *biquad {
+	f := &biquad{}
+	f.set(kind, freq, q, gain, sampleRate)
+	return f
+}
+
+// set changes the response, keeping the filter state. The frequency is kept
+// below the Nyquist frequency; at 0 Hz or below the filter passes samples
+// through untouched. A q of 0 is audio.ButterworthQ.
+func (f *biquad) set(kind audio.EQType, freq, q, gain float64, sampleRate int) {
+	if freq <= 0 {
+		f.b0, f.b1, f.b2, f.a1, f.a2 = 1, 0, 0, 0, 0
+		return
+	}
+	freq = math.Min(freq, 0.49*float64(sampleRate))
+	w := 2 * math.Pi * freq / float64(sampleRate)
+	cos := math.Cos(w)
+	alpha := math.Sin(w) / (2 * audio.FilterQ(q))
+	amp := math.Pow(10, gain/40)
+	shelf := 2 * math.Sqrt(amp) * alpha
+
+	var b0, b1, b2, a0, a1, a2 float64
+	switch kind {
+	case audio.EQLowpass:
+		b0, b1, b2 = (1-cos)/2, 1-cos, (1-cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case audio.EQHighpass:
+		b0, b1, b2 = (1+cos)/2, -(1 + cos), (1+cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case audio.EQBandpass:
+		b0, b1, b2 = alpha, 0, -alpha
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case audio.EQNotch:
+		b0, b1, b2 = 1, -2*cos, 1
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case audio.EQLowShelf:
+		b0 = amp * ((amp + 1) - (amp-1)*cos + shelf)
+		b1 = 2 * amp * ((amp - 1) - (amp+1)*cos)
+		b2 = amp * ((amp + 1) - (amp-1)*cos - shelf)
+		a0 = (amp + 1) + (amp-1)*cos + shelf
+		a1 = -2 * ((amp - 1) + (amp+1)*cos)
+		a2 = (amp + 1) + (amp-1)*cos - shelf
+	case audio.EQHighShelf:
+		b0 = amp * ((amp + 1) + (amp-1)*cos + shelf)
+		b1 = -2 * amp * ((amp - 1) + (amp+1)*cos)
+		b2 = amp * ((amp + 1) + (amp-1)*cos - shelf)
+		a0 = (amp + 1) - (amp-1)*cos + shelf
+		a1 = 2 * ((amp - 1) - (amp+1)*cos)
+		a2 = (amp + 1) - (amp-1)*cos - shelf
+	default: // audio.EQPeak
+		b0, b1, b2 = 1+alpha*amp, -2*cos, 1-alpha*amp
+		a0, a1, a2 = 1+alpha/amp, -2*cos, 1-alpha/amp
+	}
+	f.b0, f.b1, f.b2 = b0/a0, b1/a0, b2/a0
+	f.a1, f.a2 = a1/a0, a2/a0
+}
+
+// Process filters a single sample.
+func (f *biquad) Process(sample float64) float64 {
+	out := f.b0*sample + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, sample
+	f.y2, f.y1 = f.y1, out
+	return out
+}
+
+// passFilter is the high- or low-pass filter at the head of the standard
+// chain: the one-pole filter by default, or a biquad with the Q of
+// audio.FilterParams while its Biquad is set. The biquad is allocated the
+// first time it is chosen, and from then on both run so that a morph can
+// switch between them without a click.
+type passFilter struct {
+	kind      audio.EQType // audio.EQHighpass or audio.EQLowpass
+	onePole   tunableFilter
+	biquad    *biquad
+	useBiquad bool
+}
+
+// newPassFilter creates the high- or low-pass filter of fp, as kind is
+// audio.EQHighpass or audio.EQLowpass.
+func newPassFilter(kind audio.EQType, fp audio.FilterParams, sampleRate int) *passFilter {
+	f := &passFilter{kind: kind}
+	if kind == audio.EQHighpass {
+		f.onePole = newHighpassFilter(fp.HighpassCutoff, sampleRate)
+	} else {
+		f.onePole = newLowpassFilter(fp.LowpassCutoff, sampleRate)
+	}
+	f.tune(fp, sampleRate)
+	return f
+}
+
+// tune implements tunableFilter, keeping the state of both filters.
+func (f *passFilter) tune(fp audio.FilterParams, sampleRate int) {
+	f.onePole.tune(fp, sampleRate)
+	f.useBiquad = fp.Biquad
+	if f.biquad == nil && !fp.Biquad {
+		return
+	}
+	if f.biquad == nil {
+		f.biquad = &biquad{}
+	}
+	if f.kind == audio.EQHighpass {
+		f.biquad.set(f.kind, fp.HighpassCutoff, fp.HighpassQ, 0, sampleRate)
+	} else {
+		f.biquad.set(f.kind, fp.LowpassCutoff, fp.LowpassQ, 0, sampleRate)
+	}
+}
+
+// Process applies the chosen filter to a single sample.
+func (f *passFilter) Process(sample float64) float64 {
+	out := f.onePole.Process(sample)
+	if f.biquad == nil {
+		return out
+	}
+	if b := f.biquad.Process(sample); f.useBiquad {
+		return b
+	}
+	return out
+}
+
+// equalizer is a parametric EQ: the biquad bands of audio.FilterParams.EQ in
+// series. It passes samples through untouched while it has no bands.
+type equalizer struct {
+	bands []biquad
+}
+
+// newEqualizer creates an equalizer with the EQ of fp.
+func newEqualizer(fp audio.FilterParams, sampleRate int) *equalizer {
+	e := &equalizer{}
+	e.tune(fp, sampleRate)
+	return e
+}
+
+// tune implements tunableFilter. The filter state is kept unless the number
+// of bands changes.
+func (e *equalizer) tune(fp audio.FilterParams, sampleRate int) {
+	if len(e.bands) != len(fp.EQ) {
+		e.bands = make([]biquad, len(fp.EQ))
+	}
+	for i, b := range fp.EQ {
+		e.bands[i].set(b.Type, b.Freq, b.Q, b.Gain, sampleRate)
+	}
+}
+
+// Process applies every band to a single sample.
+func (e *equalizer) Process(sample float64) float64 {
+	for i := range e.bands {
+		sample = e.bands[i].Process(sample)
+	}
+	return sample
+}
@@ -0,0 +1,184 @@
+package native
+
+import (
+	"math"
+	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// responseDB measures the gain of f in dB at freq Hz: the RMS of its output
+// against that of a sine at freq, over half a second after half a second
+// for the filter to settle.
+func responseDB(f filter, freq float64, sampleRate int) float64 {
+	osc := newOscillator(sampleRate)
+	var in, out float64
+	for i := range sampleRate {
+		x := osc.sin(freq)
+		y := f.Process(x)
+		if i >= sampleRate/2 {
+			in += x * x
+			out += y * y
+		}
+	}
+	return 10 * math.Log10(out/in)
+}
+
+func TestBiquad_FrequencyResponse(t *testing.T) {
+	const sr = 48000
+	tests := []struct {
+		name   string
+		kind   audio.EQType
+		freq   float64
+		q      float64
+		gain   float64
+		at     float64 // Hz
+		lo, hi float64 // dB
+	}{
+		{"lowpass passes below cutoff", audio.EQLowpass, 1000, 0, 0, 100, -0.5, 0.5},
+		{"lowpass is 3 dB down at cutoff", audio.EQLowpass, 1000, 0, 0, 1000, -3.5, -2.5},
+		{"lowpass cuts 3 octaves up", audio.EQLowpass, 1000, 0, 0, 8000, -39, -36},
+		{"resonant lowpass peaks at cutoff", audio.EQLowpass, 1000, 4, 0, 1000, 11.5, 12.5},
+		{"highpass cuts 3 octaves down", audio.EQHighpass, 1000, 0, 0, 125, -37.5, -35},
+		{"highpass passes above cutoff", audio.EQHighpass, 1000, 0, 0, 8000, -0.5, 0.5},
+		{"bandpass passes centre", audio.EQBandpass, 1000, 2, 0, 1000, -0.5, 0.5},
+		{"bandpass cuts 2 octaves down", audio.EQBandpass, 1000, 2, 0, 250, -19, -16},
+		{"bandpass cuts 2 octaves up", audio.EQBandpass, 1000, 2, 0, 4000, -19, -16},
+		{"notch removes centre", audio.EQNotch, 1000, 2, 0, 1000, math.Inf(-1), -40},
+		{"notch passes 2 octaves up", audio.EQNotch, 1000, 2, 0, 4000, -0.5, 0.5},
+		{"wide notch cuts nearby", audio.EQNotch, 1000, 2, 0, 900, -9, -7.5},
+		{"narrow notch spares nearby", audio.EQNotch, 1000, 8, 0, 900, -2, -0.8},
+		{"peak boosts centre", audio.EQPeak, 1000, 1, 6, 1000, 5.5, 6.5},
+		{"peak cuts centre", audio.EQPeak, 1000, 1, -12, 1000, -12.5, -11.5},
+		{"peak spares far below", audio.EQPeak, 1000, 1, 6, 100, -0.5, 0.5},
+		{"low shelf boosts lows", audio.EQLowShelf, 200, 0, 6, 30, 5.5, 6.5},
+		{"low shelf spares highs", audio.EQLowShelf, 200, 0, 6, 5000, -0.5, 0.5},
+		{"high shelf cuts highs", audio.EQHighShelf, 5000, 0, -6, 15000, -6.5, -5.5},
+		{"high shelf spares lows", audio.EQHighShelf, 5000, 0, -6, 200, -0.5, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := responseDB(newBiquad(tt.kind, tt.freq, tt.q, tt.gain, sr), tt.at, sr)
+			if got < tt.lo || got > tt.hi {
+				t.Errorf("gain at %v Hz = %.2f dB, want between %v and %v dB", tt.at, got, tt.lo, tt.hi)
+			}
+		})
+	}
+}
+
+func TestBiquad_ZeroFrequencyIsTransparent(t *testing.T) {
+	f := newBiquad(audio.EQHighpass, 0, 0, 0, 48000)
+	for i, in := range []float64{1, -0.5, 0.25, 0} {
+		if got := f.Process(in); got != in {
+			t.Errorf("sample %d = %v, want %v", i, got, in)
+		}
+	}
+}
+
+func TestPassFilter_DefaultsToOnePole(t *testing.T) {
+	fp := audio.FilterParams{HighpassCutoff: 100, LowpassCutoff: 1000}
+	hp := newPassFilter(audio.EQHighpass, fp, 48000)
+	lp := newPassFilter(audio.EQLowpass, fp, 48000)
+	wantHP := newHighpassFilter(100, 48000)
+	wantLP := newLowpassFilter(1000, 48000)
+
+	osc := newOscillator(48000)
+	for i := range 1000 {
+		in := osc.sin(440)
+		if got, want := hp.Process(in), wantHP.Process(in); got != want {
+			t.Fatalf("highpass sample %d = %v, want %v", i, got, want)
+		}
+		if got, want := lp.Process(in), wantLP.Process(in); got != want {
+			t.Fatalf("lowpass sample %d = %v, want %v", i, got, want)
+		}
+	}
+	if hp.biquad != nil || lp.biquad != nil {
+		t.Error("a one-pole pass filter allocated a biquad")
+	}
+}
+
+func TestPassFilter_BiquadIsSteeper(t *testing.T) {
+	fp := audio.FilterParams{LowpassCutoff: 1000}
+	onePole := responseDB(newPassFilter(audio.EQLowpass, fp, 48000), 8000, 48000)
+	fp.Biquad = true
+	biquad := responseDB(newPassFilter(audio.EQLowpass, fp, 48000), 8000, 48000)
+
+	// Three octaves above the cutoff, 6 dB/octave gives about -18 dB and 12
+	// dB/octave about -36 dB.
+	if onePole < -20 || onePole > -16 {
+		t.Errorf("one-pole gain 3 octaves up = %.1f dB, want about -18 dB", onePole)
+	}
+	if biquad > onePole-15 {
+		t.Errorf("biquad gain 3 octaves up = %.1f dB, want at least 15 dB below the one-pole %.1f dB", biquad, onePole)
+	}
+}
+
+func TestPassFilter_SwitchesBackToOnePole(t *testing.T) {
+	fp := audio.FilterParams{LowpassCutoff: 1000, Biquad: true}
+	f := newPassFilter(audio.EQLowpass, fp, 48000)
+	want := newLowpassFilter(1000, 48000)
+
+	// The one-pole filter keeps running while the biquad is heard, so that
+	// switching back to it carries on where it would have been.
+	osc := newOscillator(48000)
+	for i := range 2000 {
+		if i == 1000 {
+			fp.Biquad = false
+			f.tune(fp, 48000)
+		}
+		in := osc.sin(440)
+		got, w := f.Process(in), want.Process(in)
+		if i >= 1000 && got != w {
+			t.Fatalf("sample %d = %v, want %v", i, got, w)
+		}
+	}
+}
+
+func TestEqualizer_NoBandsIsTransparent(t *testing.T) {
+	e := newEqualizer(audio.FilterParams{}, 48000)
+	for i, in := range []float64{1, -0.5, 0.25, 0} {
+		if got := e.Process(in); got != in {
+			t.Errorf("sample %d = %v, want %v", i, got, in)
+		}
+	}
+}
+
+func TestEqualizer_BandsInSeries(t *testing.T) {
+	boost := audio.EQBand{Type: audio.EQPeak, Freq: 1000, Q: 1, Gain: 6}
+	e := newEqualizer(audio.FilterParams{EQ: []audio.EQBand{boost, boost}}, 48000)
+	if got := responseDB(e, 1000, 48000); math.Abs(got-12) > 0.5 {
+		t.Errorf("two 6 dB peaks at 1000 Hz = %.2f dB, want 12 dB", got)
+	}
+}
+
+func TestGenerator_EQ(t *testing.T) {
+	params := toneParams(440)
+	plain := renderPCM(t, params)
+	params.Filter.EQ = []audio.EQBand{{Type: audio.EQNotch, Freq: 440, Q: 2}}
+	notched := renderPCM(t, params)
+
+	before, after := rms(plain, params.SampleRate, 0.2, 0.8), rms(notched, params.SampleRate, 0.2, 0.8)
+	if after > before/30 {
+		t.Errorf("RMS with a notch at the tone = %v, want under a thirtieth of %v", after, before)
+	}
+}
+
+func TestBiquad_ImplementsFilter(t *testing.T) {
+	var _ filter = newBiquad(audio.EQPeak, 1000, 1, 6, 48000)
+	var _ tunableFilter = newPassFilter(audio.EQHighpass, audio.FilterParams{}, 48000)
+	var _ tunableFilter = newEqualizer(audio.FilterParams{}, 48000)
+}
+
+func BenchmarkEqualizer(b *testing.B) {
+	e := newEqualizer(audio.FilterParams{EQ: []audio.EQBand{
+		{Type: audio.EQLowShelf, Freq: 200, Gain: 3},
+		{Type: audio.EQPeak, Freq: 3000, Q: 2, Gain: -6},
+		{Type: audio.EQHighShelf, Freq: 8000, Gain: 3},
+	}}, 48000)
+	osc := newOscillator(48000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Process(osc.sin(500))
+	}
+}
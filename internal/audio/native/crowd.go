@@ -0,0 +1,118 @@
+package native
+
+import (
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// crowdRenderer renders a crowd: it mixes the frames of a voice renderer for
+// each voice, placed in the stereo field and scaled by the voice's gain, and
+// limits the mix again so that voices peaking together cannot clip.
+type crowdRenderer struct {
+	voices      []crowdVoice
+	left, right filter // the limiters of the mix; right is nil in mono
+	channels    int
+	sampleRate  int
+	n           int         // index of the next frame
+	lefts       [][]float64 // by voice; scratch for renderBlock
+	rights      [][]float64
+}
+
+// crowdVoice is one voice of a crowdRenderer.
+type crowdVoice struct {
+	render voiceRenderer
+	start  int     // index of the frame the voice starts on
+	gl, gr float64 // left and right gains, for its pan and gain
+}
+
+// newCrowdRenderer builds a crowdRenderer for the voices of params. Each
+// voice keeps its own time, starting from 0 at its offset, so that it
+// sounds exactly as it would alone. A mono crowd ignores the voices' pans.
+func newCrowdRenderer(params audio.ScreamParams) *crowdRenderer {
+	sampleRate := params.SampleRate
+	voices := params.CrowdVoices()
+	r := &crowdRenderer{
+		voices:     make([]crowdVoice, len(voices)),
+		channels:   params.Channels,
+		sampleRate: sampleRate,
+	}
+	if params.Channels == 2 {
+		r.left, r.right = newLimiterPair(params.Filter, sampleRate)
+	} else {
+		r.left = newLimiterFromParams(params.Filter, sampleRate)
+	}
+	for i, v := range voices {
+		gl, gr := v.Gain, v.Gain
+		if params.Channels == 2 {
+			pl, pr := panner{pan: v.Pan}.gains(0)
+			gl, gr = gl*pl, gr*pr
+		}
+		r.voices[i] = crowdVoice{
+			render: newVoiceRenderer(v.Params),
+			start:  int(v.Offset.Seconds() * float64(sampleRate)),
+			gl:     gl,
+			gr:     gr,
+		}
+	}
+	return r
+}
+
+// appendFrame implements frameRenderer. The time of the frame is ignored in
+// favour of the frame count, from which each voice's own time is derived.
+// As in layerMixer.Sample, each voice's scaled sample is rounded, by the
+// float64 conversion, before it is added, so that no fused multiply-add sets
+// the mix apart from that of renderBlock.
+func (r *crowdRenderer) appendFrame(buf []byte, _ float64) []byte {
+	var l, rr float64
+	for _, v := range r.voices {
+		if r.n < v.start {
+			continue
+		}
+		vl, vr := v.render.frame(float64(r.n-v.start) / float64(r.sampleRate))
+		l += float64(v.gl * vl)
+		rr += float64(v.gr * vr)
+	}
+	r.n++
+
+	buf = appendS16(buf, r.left.Process(l))
+	if r.channels == 2 {
+		buf = appendS16(buf, r.right.Process(rr))
+	}
+	return buf
+}
+
+// renderBlock implements frameRenderer. The voices are rendered on up to
+// workers goroutines at once, sharing out any spare workers between their
+// layers, each into buffers of its own, and then mixed in order as
+// appendFrame mixes them, rounding each scaled sample in the same way. A mono
+// crowd leaves rr as it was.
+func (r *crowdRenderer) renderBlock(l, rr []float64, start, workers int) {
+	n := len(l)
+	r.lefts = growBlocks(r.lefts, len(r.voices), n)
+	r.rights = growBlocks(r.rights, len(r.voices), n)
+	inner := max(1, workers/len(r.voices))
+	forEach(len(r.voices), workers, func(i int) {
+		v := r.voices[i]
+		// The voice's first frame in the block, counted in its own frames
+		// from there on.
+		from := min(max(0, v.start-start), n)
+		if from < n {
+			v.render.renderBlock(r.lefts[i][from:], r.rights[i][from:], start+from-v.start, inner)
+		}
+	})
+
+	for k := range n {
+		var sl, sr float64
+		for i, v := range r.voices {
+			if start+k < v.start {
+				continue
+			}
+			sl += float64(v.gl * r.lefts[i][k])
+			sr += float64(v.gr * r.rights[i][k])
+		}
+		l[k] = r.left.Process(sl)
+		if r.channels == 2 {
+			rr[k] = r.right.Process(sr)
+		}
+	}
+	r.n += n
+}
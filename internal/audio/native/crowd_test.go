@@ -0,0 +1,89 @@
+package native
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+func TestGenerator_CrowdVoiceSoundsAsAlone(t *testing.T) {
+	// The first voice of a crowd, with a silent voice beside it, is the
+	// scream alone scaled by the crowd's gain of 1/√2.
+	params := toneParams(440)
+	alone := renderPCM(t, params)
+
+	silent := toneParams(440)
+	silent.Layers[0].Amplitude = 0
+	params.Voices = 2
+	params.VoiceMix = []audio.ScreamParams{silent}
+	crowd := renderPCM(t, params)
+
+	if len(crowd) < len(alone) {
+		t.Fatalf("crowd of %d bytes is shorter than the scream alone, %d", len(crowd), len(alone))
+	}
+	for i := 0; i+1 < len(alone); i += 2 {
+		want := float64(int16(binary.LittleEndian.Uint16(alone[i:]))) / math.Sqrt2
+		got := float64(int16(binary.LittleEndian.Uint16(crowd[i:])))
+		if math.Abs(got-want) > 1 {
+			t.Fatalf("sample %d = %v, want %v", i/2, got, want)
+		}
+	}
+}
+
+func TestGenerator_CrowdLength(t *testing.T) {
+	params := testScreamParams()
+	params.Voices = 6
+	params.Spread = 1
+
+	pcm := renderPCM(t, params)
+	frames := int((params.Duration + params.Tail()).Seconds() * float64(params.SampleRate))
+	if want := frames * params.Channels * 2; len(pcm) != want {
+		t.Errorf("output is %d bytes, want %d", len(pcm), want)
+	}
+	if params.Tail() <= params.Filter.Tail() {
+		t.Errorf("Tail() = %v, want longer than the filter's %v for the late voices", params.Tail(), params.Filter.Tail())
+	}
+	if !bytes.Equal(pcm, renderPCM(t, params)) {
+		t.Error("crowd output is not deterministic")
+	}
+}
+
+func TestGenerator_CrowdSpreadsStereo(t *testing.T) {
+	params := testScreamParams()
+	params.Channels = 2
+	params.Width = 0 // every voice alone is mono
+
+	differ := func(pcm []byte) bool {
+		for i := 0; i+3 < len(pcm); i += 4 {
+			if pcm[i] != pcm[i+2] || pcm[i+1] != pcm[i+3] {
+				return true
+			}
+		}
+		return false
+	}
+	if differ(renderPCM(t, params)) {
+		t.Fatal("single voice at width 0 has differing channels")
+	}
+	params.Voices = 4
+	if !differ(renderPCM(t, params)) {
+		t.Error("crowd has identical channels, want its voices spread across them")
+	}
+}
+
+func TestGenerator_CrowdGainCompensation(t *testing.T) {
+	params, _ := audio.GetPreset(audio.PresetClassic)
+	params.Channels = 1
+	one := rmsS16(renderPCM(t, params))
+
+	params.Voices = 8
+	params.Spread = 1
+	crowd := rmsS16(renderPCM(t, params))
+
+	// Eight voices at unity gain would be about 9 dB louder than one.
+	if db := 20 * math.Log10(crowd/one); math.Abs(db) > 3 {
+		t.Errorf("crowd is %.1f dB louder than one voice, want within 3 dB", db)
+	}
+}
@@ -0,0 +1,100 @@
+package native
+
+import "github.com/JamesPrial/go-scream/internal/audio"
+
+// echoMaxTime is the longest delay time in seconds that
+// audio.ScreamParams.Validate accepts.
+const echoMaxTime = 2.0
+
+// echo is a feedback delay: the input comes back after the delay time, and
+// again and again, each repeat feedback times the level of the last and, if
+// a low-pass tone is set, darker too. Its delay line is allocated the first
+// time it is heard, so that a chain without a delay costs nothing.
+//
+// The echoes of a stereo scream can ping-pong: both channels are fed into
+// the left channel's delay line and each channel's echoes are fed back into
+// the other's, so that they alternate left and right. The left channel's
+// echo must process each frame before the right channel's.
+type echo struct {
+	mix, feedback float64
+	delay         int // samples
+	pingPong      bool
+	tone          *lowpassFilter // nil when the repeats are not filtered
+	sampleRate    int
+
+	buf   []float64 // delay line, long enough for echoMaxTime
+	pos   int
+	other *echo // the other channel's echo of a stereo scream, or nil
+	left  bool  // whether this is the left channel's echo of a stereo scream
+}
+
+// newEcho creates an echo with the delay settings of fp.
+func newEcho(fp audio.FilterParams, sampleRate int) *echo {
+	e := &echo{}
+	e.tune(fp, sampleRate)
+	return e
+}
+
+// newEchoPair creates the linked echoes of the left and right channels of a
+// stereo scream.
+func newEchoPair(fp audio.FilterParams, sampleRate int) (left, right *echo) {
+	left, right = newEcho(fp, sampleRate), newEcho(fp, sampleRate)
+	left.other, right.other = right, left
+	left.left = true
+	return left, right
+}
+
+// tune implements tunableFilter, keeping the echoes already in flight.
+func (e *echo) tune(fp audio.FilterParams, sampleRate int) {
+	e.mix = fp.DelayMix
+	e.feedback = fp.DelayFeedback
+	e.delay = max(1, int(fp.DelayTime/1000*float64(sampleRate)))
+	e.pingPong = fp.DelayPingPong
+	e.sampleRate = sampleRate
+	switch {
+	case fp.DelayLowpass <= 0:
+		e.tone = nil
+	case e.tone == nil:
+		e.tone = newLowpassFilter(fp.DelayLowpass, sampleRate)
+	default:
+		e.tone.setCutoff(fp.DelayLowpass, sampleRate)
+	}
+}
+
+// Process applies the delay to a single sample.
+func (e *echo) Process(sample float64) float64 {
+	if e.mix == 0 {
+		return sample
+	}
+	if e.buf == nil {
+		e.buf = make([]float64, int(echoMaxTime*float64(e.sampleRate))+1)
+	}
+
+	n := len(e.buf)
+	read := (e.pos - min(e.delay, n-1) + n) % n
+	out := e.buf[read]
+
+	in, back := sample, out
+	if e.pingPong && e.other != nil {
+		back = 0
+		if e.other.buf != nil {
+			back = e.other.buf[read]
+		}
+		if e.left {
+			in = sample / 2
+		} else {
+			// The left channel's echo has just written this frame.
+			if e.other.buf != nil {
+				e.other.buf[e.pos] += sample / 2
+			}
+			in = 0
+		}
+	}
+	if e.tone != nil {
+		back = e.tone.Process(back)
+	}
+	e.buf[e.pos] = in + e.feedback*back
+	e.pos = (e.pos + 1) % n
+
+	return sample + e.mix*out
+}
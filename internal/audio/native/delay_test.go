@@ -0,0 +1,174 @@
+package native
+
+import (
+	"math"
+	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// impulse returns the first n samples of the response of f to a unit
+// impulse.
+func impulse(f filter, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		in := 0.0
+		if i == 0 {
+			in = 1
+		}
+		out[i] = f.Process(in)
+	}
+	return out
+}
+
+func TestEcho_Repeats(t *testing.T) {
+	// At 8 kHz a delay of 1ms is 8 samples.
+	tests := []struct {
+		name     string
+		feedback float64
+		want     map[int]float64 // every other sample is 0
+	}{
+		{"single echo", 0, map[int]float64{0: 1, 8: 0.5}},
+		{"feedback", 0.5, map[int]float64{0: 1, 8: 0.5, 16: 0.25, 24: 0.125, 32: 0.0625}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEcho(audio.FilterParams{DelayMix: 0.5, DelayTime: 1, DelayFeedback: tt.feedback}, 8000)
+			for i, got := range impulse(e, 40) {
+				if want := tt.want[i]; math.Abs(got-want) > 1e-12 {
+					t.Errorf("sample %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEcho_ZeroMixIsTransparent(t *testing.T) {
+	e := newEcho(audio.FilterParams{DelayTime: 1, DelayFeedback: 0.5}, 8000)
+	for i, got := range impulse(e, 20) {
+		if want := float64(1 - min(i, 1)); got != want {
+			t.Errorf("sample %d = %v, want %v", i, got, want)
+		}
+	}
+	if e.buf != nil {
+		t.Error("an echo with no mix allocated its delay line")
+	}
+}
+
+func TestEcho_LowpassDarkensRepeats(t *testing.T) {
+	fp := audio.FilterParams{DelayMix: 1, DelayTime: 1, DelayFeedback: 0.8, DelayLowpass: 500}
+	out := impulse(newEcho(fp, 8000), 40)
+
+	// The first echo is clean; the low-pass filter smears the repeats after
+	// it across the samples between them.
+	if out[8] != 1 {
+		t.Errorf("first echo = %v, want 1", out[8])
+	}
+	if out[16] >= 0.8 || out[17] == 0 {
+		t.Errorf("second echo = %v then %v, want less than 0.8 followed by its smear", out[16], out[17])
+	}
+}
+
+func TestEcho_PingPong(t *testing.T) {
+	fp := audio.FilterParams{DelayMix: 1, DelayTime: 1, DelayFeedback: 0.5, DelayPingPong: true}
+
+	// Whichever channel the scream is in, its echoes start on the left and
+	// alternate.
+	for _, side := range []string{"left", "right"} {
+		t.Run(side, func(t *testing.T) {
+			left, right := newEchoPair(fp, 8000)
+			var l, r [40]float64
+			for i := range l {
+				in := 0.0
+				if i == 0 {
+					in = 1
+				}
+				if side == "left" {
+					l[i], r[i] = left.Process(in), right.Process(0)
+				} else {
+					l[i], r[i] = left.Process(0), right.Process(in)
+				}
+			}
+
+			wantL := map[int]float64{8: 0.5, 24: 0.125}
+			wantR := map[int]float64{16: 0.25, 32: 0.0625}
+			if side == "left" {
+				wantL[0] = 1
+			} else {
+				wantR[0] = 1
+			}
+			for i := range l {
+				if math.Abs(l[i]-wantL[i]) > 1e-12 || math.Abs(r[i]-wantR[i]) > 1e-12 {
+					t.Errorf("sample %d = %v, %v; want %v, %v", i, l[i], r[i], wantL[i], wantR[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEcho_PingPongNeedsStereo(t *testing.T) {
+	fp := audio.FilterParams{DelayMix: 0.5, DelayTime: 1, DelayFeedback: 0.5}
+	plain := impulse(newEcho(fp, 8000), 40)
+	fp.DelayPingPong = true
+	mono := impulse(newEcho(fp, 8000), 40)
+	for i := range plain {
+		if mono[i] != plain[i] {
+			t.Fatalf("sample %d of a mono ping-pong echo = %v, want %v as without ping-pong", i, mono[i], plain[i])
+		}
+	}
+}
+
+func TestGenerator_DelayTail(t *testing.T) {
+	// A burst of tone for the first 0.1s of the scream.
+	params := toneParams(440)
+	params.Envelope = audio.Envelope{Points: []audio.EnvelopePoint{{Time: 0, Level: 1}, {Time: 0.1, Level: 1}, {Time: 0.1, Level: 0}}}
+	params.Filter.DelayMix = 0.5
+	params.Filter.DelayTime = 300
+	params.Filter.DelayFeedback = 0.3
+	pcm := renderPCM(t, params)
+
+	length := (params.Duration + params.Tail()).Seconds()
+	if want := int(length*float64(params.SampleRate)) * 2; len(pcm) != want {
+		t.Fatalf("byte count = %d, want %d", len(pcm), want)
+	}
+
+	// The burst comes back every 0.3s at the level of the delay, then
+	// quieter by the feedback each time, past the end of the scream at 1s,
+	// and has died away by the end of the tail.
+	burst := rms(pcm, params.SampleRate, 0.02, 0.08)
+	for i, want := range []float64{0.5, 0.15, 0.045, 0.0135} {
+		from := 0.3*float64(i+1) + 0.02
+		if got := rms(pcm, params.SampleRate, from, from+0.06) / burst; math.Abs(got-want) > want*0.05 {
+			t.Errorf("echo %d = %.4f of the burst, want %.4f", i+1, got, want)
+		}
+	}
+	if level := rms(pcm, params.SampleRate, length-0.1, length); level > burst/1000 {
+		t.Errorf("RMS at the end of the tail = %v, want near silence", level)
+	}
+}
+
+func TestGenerator_DelayPingPong(t *testing.T) {
+	// A centred burst of tone for the first 0.1s of a stereo scream.
+	params := toneParams(440)
+	params.Channels = 2
+	params.Width = 1
+	params.Envelope = audio.Envelope{Points: []audio.EnvelopePoint{{Time: 0, Level: 1}, {Time: 0.1, Level: 1}, {Time: 0.1, Level: 0}}}
+	params.Filter.DelayMix = 1
+	params.Filter.DelayTime = 300
+	params.Filter.DelayFeedback = 0.5
+	params.Filter.DelayPingPong = true
+	left, right := renderStereo(t, params)
+
+	// window returns the samples of ch between from and from+0.06 seconds.
+	window := func(ch []float64, from float64) []float64 {
+		i := int(from * float64(params.SampleRate))
+		return ch[i : i+params.SampleRate*6/100]
+	}
+	for i, wantLeft := range []bool{true, false, true} {
+		from := 0.3*float64(i+1) + 0.02
+		el, er := energy(window(left, from)), energy(window(right, from))
+		if gotLeft := el > 100*er; gotLeft != wantLeft || (!gotLeft && er < 100*el) {
+			t.Errorf("echo %d: left energy %.3g, right energy %.3g; want it on the left: %v", i+1, el, er, wantLeft)
+		}
+	}
+}
@@ -12,6 +12,14 @@ type filter interface {
 	Process(sample float64) float64
 }
 
+// tunableFilter is implemented by filters whose settings can be changed
+// between samples without resetting their state. tune reads the filter's own
+// fields from fp.
+type tunableFilter interface {
+	filter
+	tune(fp audio.FilterParams, sampleRate int)
+}
+
 // highpassFilter implements a first-order IIR high-pass filter.
 // It removes low-frequency content (including DC offset) from the signal.
 type highpassFilter struct {
@@ -23,10 +31,21 @@ type highpassFilter struct {
 // newHighpassFilter creates a high-pass filter with the given cutoff frequency and sample rate.
 // alpha = RC / (RC + dt) where RC = 1/(2*pi*cutoff) and dt = 1/sampleRate.
 func newHighpassFilter(cutoff float64, sampleRate int) *highpassFilter {
+	f := &highpassFilter{}
+	f.setCutoff(cutoff, sampleRate)
+	return f
+}
+
+// setCutoff changes the cutoff frequency, keeping the filter state.
+func (f *highpassFilter) setCutoff(cutoff float64, sampleRate int) {
 	dt := 1.0 / float64(sampleRate)
 	rc := 1.0 / (2 * math.Pi * cutoff)
-	alpha := rc / (rc + dt)
-	return &highpassFilter{alpha: alpha}
+	f.alpha = rc / (rc + dt)
+}
+
+// tune implements tunableFilter.
+func (f *highpassFilter) tune(fp audio.FilterParams, sampleRate int) {
+	f.setCutoff(fp.HighpassCutoff, sampleRate)
 }
 
 // Process applies the high-pass filter to a single sample.
@@ -47,10 +66,21 @@ type lowpassFilter struct {
 // newLowpassFilter creates a low-pass filter with the given cutoff frequency and sample rate.
 // alpha = dt / (RC + dt) where RC = 1/(2*pi*cutoff) and dt = 1/sampleRate.
 func newLowpassFilter(cutoff float64, sampleRate int) *lowpassFilter {
+	f := &lowpassFilter{}
+	f.setCutoff(cutoff, sampleRate)
+	return f
+}
+
+// setCutoff changes the cutoff frequency, keeping the filter state.
+func (f *lowpassFilter) setCutoff(cutoff float64, sampleRate int) {
 	dt := 1.0 / float64(sampleRate)
 	rc := 1.0 / (2 * math.Pi * cutoff)
-	alpha := dt / (rc + dt)
-	return &lowpassFilter{alpha: alpha}
+	f.alpha = dt / (rc + dt)
+}
+
+// tune implements tunableFilter.
+func (f *lowpassFilter) tune(fp audio.FilterParams, sampleRate int) {
+	f.setCutoff(fp.LowpassCutoff, sampleRate)
 }
 
 // Process applies the low-pass filter to a single sample.
@@ -59,6 +89,128 @@ func (f *lowpassFilter) Process(sample float64) float64 {
 	return f.prev
 }
 
+// Oversampling of the distortion. Bending a signal adds harmonics far above
+// its own, which would fold back below the Nyquist frequency as aliases, so
+// the distortion bends a copy upsampled distortionOversample times and
+// filters the harmonics out before it downsamples again. Both are done with
+// distortionFIR, distortionTaps taps per phase.
+const (
+	distortionOversample = 4
+	distortionTaps       = 16
+)
+
+// distortionFIR is a windowed-sinc low-pass filter at the oversampled rate,
+// cutting off at the Nyquist frequency of the original rate.
+var distortionFIR = newDistortionFIR()
+
+// newDistortionFIR computes distortionFIR: a sinc windowed by a Blackman
+// window, normalized to unity gain at DC.
+func newDistortionFIR() [distortionOversample * distortionTaps]float64 {
+	var h [distortionOversample * distortionTaps]float64
+	n := float64(len(h) - 1)
+	cutoff := 0.5 / distortionOversample // cycles per oversampled sample
+	var sum float64
+	for i := range h {
+		x := float64(i) - n/2
+		sinc := 2 * cutoff
+		if x != 0 {
+			sinc = math.Sin(2*math.Pi*cutoff*x) / (math.Pi * x)
+		}
+		window := 0.42 - 0.5*math.Cos(2*math.Pi*float64(i)/n) + 0.08*math.Cos(4*math.Pi*float64(i)/n)
+		h[i] = sinc * window
+		sum += h[i]
+	}
+	for i := range h {
+		h[i] /= sum
+	}
+	return h
+}
+
+// distortion is a waveshaper: it drives the signal into one of the curves
+// of audio.Distortion, oversampled to keep aliasing down, then optionally
+// tames the fizz with a low-pass tone filter. It passes samples through
+// untouched while it has no curve.
+type distortion struct {
+	shape audio.Distortion
+	gain  float64        // linear drive
+	tone  *lowpassFilter // nil when the output is not filtered
+
+	in    [distortionTaps]float64                        // recent input samples
+	inPos int                                            // index of the newest in in
+	up    [distortionOversample * distortionTaps]float64 // recent bent samples at the oversampled rate
+	upPos int                                            // index of the newest in up
+}
+
+// newDistortion creates a distortion with the distortion settings of fp.
+func newDistortion(fp audio.FilterParams, sampleRate int) *distortion {
+	d := &distortion{}
+	d.tune(fp, sampleRate)
+	return d
+}
+
+// tune implements tunableFilter, keeping the filter state.
+func (d *distortion) tune(fp audio.FilterParams, sampleRate int) {
+	d.shape = fp.Distortion
+	d.gain = math.Pow(10, fp.DistortionDrive/20)
+	switch {
+	case fp.DistortionTone <= 0:
+		d.tone = nil
+	case d.tone == nil:
+		d.tone = newLowpassFilter(fp.DistortionTone, sampleRate)
+	default:
+		d.tone.setCutoff(fp.DistortionTone, sampleRate)
+	}
+}
+
+// Process applies the distortion to a single sample.
+func (d *distortion) Process(sample float64) float64 {
+	if d.shape == "" {
+		return sample
+	}
+
+	d.inPos = (d.inPos + 1) % len(d.in)
+	d.in[d.inPos] = sample
+	// Upsample by filtering the input as if zeros were stuffed between its
+	// samples: each phase of the output takes every distortionOversample-th
+	// tap, scaled up for the zeros.
+	for p := range distortionOversample {
+		var v float64
+		for k := range distortionTaps {
+			v += distortionFIR[p+k*distortionOversample] * d.in[(d.inPos-k+len(d.in))%len(d.in)]
+		}
+		d.upPos = (d.upPos + 1) % len(d.up)
+		d.up[d.upPos] = d.bend(d.gain * distortionOversample * v)
+	}
+
+	var out float64
+	for j, h := range distortionFIR {
+		out += h * d.up[(d.upPos-j+len(d.up))%len(d.up)]
+	}
+	if d.tone != nil {
+		out = d.tone.Process(out)
+	}
+	return out
+}
+
+// bend passes x through the curve of d. Every curve keeps the output within
+// [-1, 1] and silence silent.
+func (d *distortion) bend(x float64) float64 {
+	switch d.shape {
+	case audio.DistortionHard:
+		return math.Max(-1, math.Min(1, x))
+	case audio.DistortionTube:
+		bias := math.Tanh(audio.DistortionTubeBias)
+		return (math.Tanh(x+audio.DistortionTubeBias) - bias) / (1 + bias)
+	case audio.DistortionFold:
+		// A triangle wave of x: the identity on [-1, 1], folding back
+		// from each peak.
+		u := (x + 1) / 4
+		return 1 - 4*math.Abs(u-math.Floor(u)-0.5)
+	default:
+		return math.Tanh(x)
+	}
+}
+
 // bitcrusher reduces the bit depth of a signal, creating a lo-fi effect.
 // It blends the quantized signal with the original clean signal.
 type bitcrusher struct {
@@ -84,6 +236,11 @@ func (f *bitcrusher) Process(sample float64) float64 {
 	return f.mix*crushed + (1-f.mix)*sample
 }
 
+// tune implements tunableFilter.
+func (f *bitcrusher) tune(fp audio.FilterParams, _ int) {
+	*f = *newBitcrusher(fp.CrusherBits, fp.CrusherMix)
+}
+
 // compressor implements dynamic range compression.
 // It tracks the signal envelope and reduces gain when the signal exceeds the threshold.
 type compressor struct {
@@ -101,20 +258,27 @@ type compressor struct {
 // attackMs and releaseMs control how fast the envelope responds in milliseconds.
 // sampleRate is the audio sample rate in Hz.
 func newCompressor(ratio, thresholdDB, attackMs, releaseMs float64, sampleRate int) *compressor {
+	f := &compressor{}
+	f.set(ratio, thresholdDB, attackMs, releaseMs, sampleRate)
+	return f
+}
+
+// set changes the compressor settings, keeping the tracked envelope.
+func (f *compressor) set(ratio, thresholdDB, attackMs, releaseMs float64, sampleRate int) {
 	// Convert dB threshold to linear amplitude
-	threshold := math.Pow(10, thresholdDB/20.0)
+	f.threshold = math.Pow(10, thresholdDB/20.0)
 	// Time constants: coefficient = exp(-1 / (time_in_samples))
 	attackSamples := (attackMs / 1000.0) * float64(sampleRate)
 	releaseSamples := (releaseMs / 1000.0) * float64(sampleRate)
-	attackCoef := math.Exp(-1.0 / attackSamples)
-	releaseCoef := math.Exp(-1.0 / releaseSamples)
-	return &compressor{
-		ratio:       ratio,
-		threshold:   threshold,
-		attackCoef:  attackCoef,
-		releaseCoef: releaseCoef,
-		ratioExp:    1.0/ratio - 1.0,
-	}
+	f.attackCoef = math.Exp(-1.0 / attackSamples)
+	f.releaseCoef = math.Exp(-1.0 / releaseSamples)
+	f.ratio = ratio
+	f.ratioExp = 1.0/ratio - 1.0
+}
+
+// tune implements tunableFilter.
+func (f *compressor) tune(fp audio.FilterParams, sampleRate int) {
+	f.set(fp.CompRatio, fp.CompThreshold, fp.CompAttack, fp.CompRelease, sampleRate)
 }
 
 // Process applies the compressor to a single sample.
@@ -156,6 +320,11 @@ func (f *volumeBoost) Process(sample float64) float64 {
 	return sample * f.gain
 }
 
+// tune implements tunableFilter.
+func (f *volumeBoost) tune(fp audio.FilterParams, _ int) {
+	*f = *newVolumeBoost(fp.VolumeBoostDB)
+}
+
 // limiter implements a hard clipper that limits the signal to ±level.
 type limiter struct {
 	level float64
@@ -171,6 +340,11 @@ func (f *limiter) Process(sample float64) float64 {
 	return clamp(sample, -f.level, f.level)
 }
 
+// tune implements tunableFilter.
+func (f *limiter) tune(fp audio.FilterParams, _ int) {
+	f.level = fp.LimiterLevel
+}
+
 // filterChain applies multiple filters in sequence.
 type filterChain struct {
 	filters []filter
@@ -191,15 +365,48 @@ func (f *filterChain) Process(sample float64) float64 {
 	return out
 }
 
+// tune retunes every filter in the chain that implements tunableFilter to fp.
+func (f *filterChain) tune(fp audio.FilterParams, sampleRate int) {
+	for _, flt := range f.filters {
+		if tf, ok := flt.(tunableFilter); ok {
+			tf.tune(fp, sampleRate)
+		}
+	}
+}
+
 // newFilterChainFromParams builds the standard processing chain from FilterParams.
-// The chain order is: highpass -> lowpass -> bitcrusher -> compressor -> volumeBoost -> limiter.
+// The chain order is: highpass -> lowpass -> equalizer -> distortion -> bitcrusher -> compressor -> echo -> reverb -> volumeBoost -> limiter,
+// where the limiter is the hard clipper or the lookaheadLimiter selected by fp.Limiter.
+// The equalizer, distortion, echo and reverb are always present, passing
+// samples through untouched while they are off, so that a morph can bring
+// them in.
 func newFilterChainFromParams(fp audio.FilterParams, sampleRate int) *filterChain {
+	return newFilterChainWithEcho(fp, sampleRate, newEcho(fp, sampleRate), newLimiterFromParams(fp, sampleRate))
+}
+
+// newStereoFilterChains builds the standard processing chains of the left and
+// right channels of a stereo scream, whose echoes can ping-pong between them
+// and whose limiters share their gain. The left chain must process each frame
+// before the right chain.
+func newStereoFilterChains(fp audio.FilterParams, sampleRate int) (left, right *filterChain) {
+	le, re := newEchoPair(fp, sampleRate)
+	ll, rl := newLimiterPair(fp, sampleRate)
+	return newFilterChainWithEcho(fp, sampleRate, le, ll), newFilterChainWithEcho(fp, sampleRate, re, rl)
+}
+
+// newFilterChainWithEcho builds the standard processing chain around e,
+// ending in lim.
+func newFilterChainWithEcho(fp audio.FilterParams, sampleRate int, e *echo, lim filter) *filterChain {
 	return newFilterChain(
-		newHighpassFilter(fp.HighpassCutoff, sampleRate),
-		newLowpassFilter(fp.LowpassCutoff, sampleRate),
+		newPassFilter(audio.EQHighpass, fp, sampleRate),
+		newPassFilter(audio.EQLowpass, fp, sampleRate),
+		newEqualizer(fp, sampleRate),
+		newDistortion(fp, sampleRate),
 		newBitcrusher(fp.CrusherBits, fp.CrusherMix),
 		newCompressor(fp.CompRatio, fp.CompThreshold, fp.CompAttack, fp.CompRelease, sampleRate),
+		e,
+		newReverb(fp, sampleRate),
 		newVolumeBoost(fp.VolumeBoostDB),
-		newLimiter(fp.LimiterLevel),
+		lim,
 	)
 }
@@ -106,6 +106,106 @@ func TestLowpassFilter_AttenuatesHighFreq(t *testing.T) {
 	}
 }
 
+// --- Distortion Tests ---
+
+func TestDistortion_Curves(t *testing.T) {
+	tests := []struct {
+		shape audio.Distortion
+		in    float64
+		want  float64
+	}{
+		{audio.DistortionSoft, 0.5, math.Tanh(0.5)},
+		{audio.DistortionSoft, -20, -1},
+		{audio.DistortionHard, 0.5, 0.5},
+		{audio.DistortionHard, 2, 1},
+		{audio.DistortionHard, -3, -1},
+		{audio.DistortionTube, 0, 0},
+		{audio.DistortionTube, -20, -1},
+		{audio.DistortionTube, 20, (1 - math.Tanh(0.3)) / (1 + math.Tanh(0.3))},
+		{audio.DistortionFold, 0.5, 0.5},
+		{audio.DistortionFold, 1.5, 0.5},
+		{audio.DistortionFold, 3, -1},
+		{audio.DistortionFold, -2.5, 0.5},
+	}
+	for _, tt := range tests {
+		d := newDistortion(audio.FilterParams{Distortion: tt.shape}, 48000)
+		if got := d.bend(tt.in); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s bend(%v) = %v, want %v", tt.shape, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDistortion_OffIsTransparent(t *testing.T) {
+	d := newDistortion(audio.FilterParams{DistortionDrive: 24, DistortionTone: 1000}, 48000)
+	for _, in := range []float64{0.5, -1, 0.25} {
+		if got := d.Process(in); got != in {
+			t.Errorf("Process(%v) = %v, want it untouched", in, got)
+		}
+	}
+}
+
+func TestDistortion_QuietSignalPasses(t *testing.T) {
+	// A quiet tone sits on the straight part of the soft curve, so it comes
+	// out at the same level once the oversampling filters have filled.
+	d := newDistortion(audio.FilterParams{Distortion: audio.DistortionSoft}, 48000)
+	osc := newOscillator(48000)
+	var in, out float64
+	for i := range 4800 {
+		x := 0.01 * osc.sin(1000)
+		y := d.Process(x)
+		if i >= 480 {
+			in += x * x
+			out += y * y
+		}
+	}
+	if ratio := math.Sqrt(out / in); math.Abs(ratio-1) > 0.01 {
+		t.Errorf("output level = %.4f of the input, want 1", ratio)
+	}
+}
+
+func TestDistortion_OversamplingLimitsAliasing(t *testing.T) {
+	const (
+		sampleRate = 48000
+		freq       = 3111
+	)
+	for _, shape := range []audio.Distortion{audio.DistortionSoft, audio.DistortionHard, audio.DistortionTube, audio.DistortionFold} {
+		t.Run(string(shape), func(t *testing.T) {
+			fp := audio.FilterParams{Distortion: shape, DistortionDrive: 18}
+			d := newDistortion(fp, sampleRate)
+			osc := newOscillator(sampleRate)
+			got := aliasedPower(func() float64 { return d.Process(0.9 * osc.sin(freq)) }, freq, sampleRate)
+
+			// The same curve bent at the original rate.
+			naive := newDistortion(fp, sampleRate)
+			ref := newOscillator(sampleRate)
+			want := aliasedPower(func() float64 { return naive.bend(naive.gain * 0.9 * ref.sin(freq)) }, freq, sampleRate)
+
+			if got > want/8 {
+				t.Errorf("aliased power = %.5f, want well below the %.5f of bending without oversampling", got, want)
+			}
+		})
+	}
+}
+
+func TestDistortion_ToneDarkens(t *testing.T) {
+	// A hard-clipped tone is rich in harmonics, which the tone filter cuts.
+	brightness := func(tone float64) float64 {
+		d := newDistortion(audio.FilterParams{Distortion: audio.DistortionHard, DistortionDrive: 24, DistortionTone: tone}, 48000)
+		osc := newOscillator(48000)
+		var diff, level, prev float64
+		for range 4800 {
+			out := d.Process(osc.sin(200))
+			diff += (out - prev) * (out - prev)
+			level += out * out
+			prev = out
+		}
+		return diff / level
+	}
+	if open, dark := brightness(0), brightness(500); dark >= open/2 {
+		t.Errorf("brightness with a 500 Hz tone = %v, want well below the %v without", dark, open)
+	}
+}
+
 // --- Bitcrusher Tests ---
 
 func TestBitcrusher_FullMix(t *testing.T) {
@@ -357,6 +457,10 @@ func TestLowpassFilter_ImplementsFilter(t *testing.T) {
 	var _ filter = newLowpassFilter(8000, 48000)
 }
 
+func TestDistortion_ImplementsFilter(t *testing.T) {
+	var _ tunableFilter = newDistortion(audio.FilterParams{}, 48000)
+}
+
 func TestBitcrusher_ImplementsFilter(t *testing.T) {
 	var _ filter = newBitcrusher(8, 0.5)
 }
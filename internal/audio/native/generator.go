@@ -2,11 +2,11 @@
 package native
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
-	"math"
+	"runtime"
 
 	"github.com/JamesPrial/go-scream/internal/audio"
 )
@@ -16,113 +16,199 @@ var _ audio.Generator = (*Generator)(nil)
 
 // Prime multipliers used to decorrelate per-layer seeds from the global seed.
 // Each constant is a distinct prime, ensuring the XOR mixes are independent.
-const (
-	seedMixLayer0 int64 = 1000003
-	seedMixLayer1 int64 = 1000033
-	seedMixLayer2 int64 = 1000037
-	seedMixLayer3 int64 = 1000039
-	seedMixNoise  int64 = 1000081
-)
+// Layers beyond the table use further odd multipliers (see layerSeedMix).
+var seedMixLayers = [...]int64{1000003, 1000033, 1000037, 1000039, 1000099}
+
+// seedMixNoise is the multiplier for noise layers, which all mix the global
+// seed the same way so that screams made when the noise layers shared one
+// seed still sound the same.
+const seedMixNoise int64 = 1000081
 
 // Generator implements audio.Generator using pure Go synthesis.
 // It produces s16le PCM audio with a configurable sample rate and channel count.
 type Generator struct {
-	logger *slog.Logger
+	logger  *slog.Logger
+	workers int // 0 picks the number for each scream with defaultWorkers
 }
 
-// NewGenerator creates a new Generator using the provided logger.
+// NewGenerator creates a new Generator using the provided logger. It renders
+// the voices of a crowd in parallel, on up to runtime.GOMAXPROCS goroutines at
+// once, and other screams frame by frame (see defaultWorkers).
 func NewGenerator(logger *slog.Logger) *Generator {
 	return &Generator{logger: logger}
 }
 
-// Generate produces PCM audio data in s16le format (little-endian signed 16-bit).
-// The output byte count is: totalSamples * channels * 2, where
-// totalSamples = int(duration.Seconds() * float64(sampleRate)).
-// Returns an error if params fail validation.
-func (g *Generator) Generate(params audio.ScreamParams) (io.Reader, error) {
-	if err := params.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid params: %w", err)
+// defaultWorkers returns the number of goroutines to render params on when
+// the Generator was not given one. Each voice of a crowd costs as much as a
+// whole scream, so a crowd is rendered in blocks on at least two, which is
+// faster even on a single CPU; the few layers of a single scream are not
+// worth the goroutines, and are rendered frame by frame.
+func defaultWorkers(params audio.ScreamParams) int {
+	if params.IsCrowd() {
+		return max(2, runtime.GOMAXPROCS(0))
 	}
+	return 1
+}
 
-	g.logger.Debug("generating PCM audio", "duration", params.Duration, "sample_rate", params.SampleRate, "channels", params.Channels)
-
-	sampleRate := params.SampleRate
-	totalSamples := int(params.Duration.Seconds() * float64(sampleRate))
-	channels := params.Channels
+// WithWorkers returns a copy of g that renders on up to n goroutines at once.
+// With n of 1 or less, every frame is rendered in turn on the goroutine
+// reading the PCM. The PCM is the same whatever n.
+func (g *Generator) WithWorkers(n int) *Generator {
+	c := *g
+	c.workers = max(1, n)
+	return &c
+}
 
-	// Build the 5 synthesis layers from params.
-	layers := buildLayers(params, sampleRate)
+// Generate returns a reader of PCM audio in s16le format (little-endian
+// signed 16-bit). Samples are synthesized lazily as the reader is consumed,
+// so the first frame is available immediately and memory use does not grow
+// with duration. The output byte count is: totalSamples * channels * 2, where
+// totalSamples = int((duration + tail).Seconds() * float64(sampleRate)) and
+// tail is params.Tail(), the time the reverb rings on after the scream.
+// Returns an error if params fail validation. Once ctx is done, the reader
+// stops synthesizing and its next Read returns an error wrapping
+// audio.ErrCancelled.
+func (g *Generator) Generate(ctx context.Context, params audio.ScreamParams) (io.Reader, error) {
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
 
-	// Create the mixer from all layers.
-	mixer := newLayerMixer(layers...)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", audio.ErrCancelled, err)
+	}
 
-	// Create the filter chain from params.
-	chain := newFilterChainFromParams(params.Filter, sampleRate)
+	g.logger.Debug("generating PCM audio", "duration", params.Duration, "sample_rate", params.SampleRate, "channels", params.Channels, "stereo", params.IsStereo(), "morph", params.MorphTo != nil, "voices", max(1, params.Voices), "tail", params.Tail())
 
-	// Allocate output buffer: totalSamples * channels * 2 bytes per sample.
-	out := make([]byte, totalSamples*channels*2)
-	pos := 0
+	sampleRate := params.SampleRate
+	totalSamples := int((params.Duration + params.Tail()).Seconds() * float64(sampleRate))
 
-	for i := 0; i < totalSamples; i++ {
-		t := float64(i) / float64(sampleRate)
+	workers := g.workers
+	if workers == 0 {
+		workers = defaultWorkers(params)
+	}
 
-		// Mix all layers at time t.
-		raw := mixer.Sample(t)
+	return newPCMStream(ctx, newFrameRenderer(params), sampleRate, params.Channels, totalSamples, workers, g.logger), nil
+}
 
-		// Apply the filter chain.
-		filtered := chain.Process(raw)
+// newFrameRenderer builds the synthesis layers and filter chains for params.
+// A crowd gets a renderer mixing a voice renderer for each of its voices.
+func newFrameRenderer(params audio.ScreamParams) frameRenderer {
+	if params.IsCrowd() {
+		return newCrowdRenderer(params)
+	}
+	return newVoiceRenderer(params)
+}
 
-		// Convert to int16 by scaling and clamping.
-		scaled := filtered * 32767.0
-		clamped := math.Max(-32768, math.Min(32767, scaled))
-		s16 := int16(math.Round(clamped))
+// newVoiceRenderer builds the synthesis layers and filter chains for params,
+// ignoring any crowd. Stereo params get independently panned and filtered
+// channels; otherwise a single mono signal is written to every channel.
+// Params with a MorphTo get a renderer whose parameters are retuned as the
+// scream plays.
+func newVoiceRenderer(params audio.ScreamParams) voiceRenderer {
+	if params.MorphTo != nil {
+		return newMorphRenderer(params)
+	}
+	if params.IsStereo() {
+		return newStereoRenderer(params)
+	}
+	return newMonoRenderer(params)
+}
 
-		// Encode the sample as little-endian int16 for each channel.
-		lo := byte(uint16(s16))
-		hi := byte(uint16(s16) >> 8)
-		for range channels {
-			out[pos] = lo
-			out[pos+1] = hi
-			pos += 2
-		}
+// newStereoRenderer builds a stereoRenderer for params.
+func newStereoRenderer(params audio.ScreamParams) *stereoRenderer {
+	sampleRate := params.SampleRate
+	left, right := newStereoFilterChains(params.Filter, sampleRate)
+	return &stereoRenderer{
+		mixer:      buildStereoMixer(params, sampleRate),
+		left:       left,
+		right:      right,
+		sampleRate: sampleRate,
+		end:        params.Duration.Seconds(),
 	}
+}
 
-	g.logger.Debug("PCM generation complete", "bytes", len(out))
-	return bytes.NewReader(out), nil
+// newMonoRenderer builds a monoRenderer for params.
+func newMonoRenderer(params audio.ScreamParams) *monoRenderer {
+	sampleRate := params.SampleRate
+	mixer := newLayerMixer(buildLayers(params, sampleRate)...)
+	mixer.contours = buildContours(params)
+	mixer.master = newLevelContour(nil, params.Envelope, params.Duration.Seconds())
+	return &monoRenderer{
+		mixer:      mixer,
+		chain:      newFilterChainFromParams(params.Filter, sampleRate),
+		channels:   params.Channels,
+		sampleRate: sampleRate,
+		end:        params.Duration.Seconds(),
+	}
 }
 
-// buildLayers creates all 5 synthesis layers from ScreamParams.
-// The global params.Seed is mixed into each layer's seed so that different
-// top-level seeds produce different audio even when LayerParams seeds are identical.
+// buildLayers creates the synthesis layers from ScreamParams, each
+// according to its Type. The global params.Seed is mixed into each layer's
+// seed so that different top-level seeds produce different audio even when
+// LayerParams seeds are identical.
 func buildLayers(params audio.ScreamParams, sampleRate int) []layer {
-	lp := params.Layers
-	noise := params.Noise
-	globalSeed := params.Seed
-
-	// Derive per-layer seeds that incorporate the global seed.
-	// Using XOR with prime multiples of globalSeed ensures decorrelation.
-	p0 := lp[0]
-	p0.Seed = lp[0].Seed ^ (globalSeed * seedMixLayer0)
-
-	p1 := lp[1]
-	p1.Seed = lp[1].Seed ^ (globalSeed * seedMixLayer1)
-
-	p2 := lp[2]
-	p2.Seed = lp[2].Seed ^ (globalSeed * seedMixLayer2)
+	lp := mixSeeds(params)
+	layers := make([]layer, len(lp))
+	for i, p := range lp {
+		layers[i] = newLayer(p, sampleRate, params.Duration.Seconds())
+	}
+	return layers
+}
 
-	p3 := lp[3]
-	p3.Seed = lp[3].Seed ^ (globalSeed * seedMixLayer3)
+// newLayer creates the synthesis layer for p according to p.Type. duration
+// is the length of the scream in seconds. Unknown types produce silence.
+func newLayer(p audio.LayerParams, sampleRate int, duration float64) layer {
+	switch p.Type {
+	case audio.LayerPrimaryScream:
+		return newPrimaryScreamLayer(p, sampleRate, duration)
+	case audio.LayerHarmonicSweep:
+		return newHarmonicSweepLayer(p, sampleRate, duration)
+	case audio.LayerHighShriek:
+		return newHighShriekLayer(p, sampleRate, duration)
+	case audio.LayerNoiseBurst:
+		return newNoiseBurstLayer(p, sampleRate)
+	case audio.LayerBackgroundNoise:
+		return newBackgroundNoiseLayer(p, sampleRate)
+	case audio.LayerVocal:
+		return newVocalLayer(p, sampleRate, duration)
+	default:
+		return silentLayer{}
+	}
+}
 
-	noiseWithSeed := noise
-	noiseWithSeed.BurstSeed = noise.BurstSeed ^ (globalSeed * seedMixNoise)
+// tuneLayers changes the parameters of layers built by buildLayers to those
+// of params, keeping their oscillator phases and noise sources. params must
+// have as many layers as the params the layers were built from.
+func tuneLayers(layers []layer, params audio.ScreamParams) {
+	lp := mixSeeds(params)
+	for i, l := range layers {
+		if tl, ok := l.(tunableLayer); ok {
+			tl.tune(lp[i])
+		}
+	}
+}
 
-	layers := []layer{
-		newPrimaryScreamLayer(p0, sampleRate),
-		newHarmonicSweepLayer(p1, sampleRate),
-		newHighShriekLayer(p2, sampleRate),
-		newNoiseBurstLayer(p3, noiseWithSeed),
-		newBackgroundNoiseLayer(noiseWithSeed),
+// mixSeeds returns the layer parameters of params with the global
+// params.Seed mixed into their seeds.
+func mixSeeds(params audio.ScreamParams) []audio.LayerParams {
+	lp := make([]audio.LayerParams, len(params.Layers))
+	for i, p := range params.Layers {
+		// XOR with prime multiples of the global seed decorrelates layers.
+		p.Seed ^= params.Seed * layerSeedMix(p.Type, i)
+		lp[i] = p
 	}
+	return lp
+}
 
-	return layers
+// layerSeedMix returns the multiplier of the global seed mixed into the seed
+// of the layer of type t at index i.
+func layerSeedMix(t audio.LayerType, i int) int64 {
+	switch {
+	case t == audio.LayerNoiseBurst || t == audio.LayerBackgroundNoise:
+		return seedMixNoise
+	case i < len(seedMixLayers):
+		return seedMixLayers[i]
+	default:
+		return seedMixLayers[len(seedMixLayers)-1] + 2*int64(i-len(seedMixLayers)+1)
+	}
 }
@@ -2,9 +2,14 @@ package native
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"io"
 	"log/slog"
+	"math"
+	"runtime"
 	"testing"
 	"time"
 
@@ -20,14 +25,13 @@ func testScreamParams() audio.ScreamParams {
 		SampleRate: 48000,
 		Channels:   2,
 		Seed:       12345,
-		Layers: [5]audio.LayerParams{
+		Layers: []audio.LayerParams{
 			{Type: audio.LayerPrimaryScream, BaseFreq: 500, FreqRange: 1500, JumpRate: 10, Amplitude: 0.4, Rise: 1.2, Seed: 4242},
 			{Type: audio.LayerHarmonicSweep, BaseFreq: 350, SweepRate: 500, FreqRange: 800, JumpRate: 6, Amplitude: 0.25, Seed: 3000},
 			{Type: audio.LayerHighShriek, BaseFreq: 1200, FreqRange: 1600, JumpRate: 20, Amplitude: 0.25, Rise: 2.5, Seed: 7000},
-			{Type: audio.LayerNoiseBurst, Amplitude: 0.18, Seed: 4000},
-			{Type: audio.LayerBackgroundNoise, Amplitude: 0.1},
+			{Type: audio.LayerNoiseBurst, BurstRate: 8, Threshold: 0.7, Amplitude: 0.18, Seed: 4000},
+			{Type: audio.LayerBackgroundNoise, Amplitude: 0.1, Seed: 4000},
 		},
-		Noise: audio.NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000},
 		Filter: audio.FilterParams{
 			HighpassCutoff: 120, LowpassCutoff: 8000,
 			CrusherBits: 8, CrusherMix: 0.5,
@@ -41,7 +45,7 @@ func TestGenerator_CorrectByteCount(t *testing.T) {
 	gen := NewGenerator(discardLogger)
 	params := testScreamParams()
 
-	reader, err := gen.Generate(params)
+	reader, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -62,7 +66,7 @@ func TestGenerator_NonSilent(t *testing.T) {
 	gen := NewGenerator(discardLogger)
 	params := testScreamParams()
 
-	reader, err := gen.Generate(params)
+	reader, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -89,7 +93,7 @@ func TestGenerator_Deterministic(t *testing.T) {
 	gen := NewGenerator(discardLogger)
 	params := testScreamParams()
 
-	reader1, err := gen.Generate(params)
+	reader1, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() #1 error = %v", err)
 	}
@@ -98,7 +102,7 @@ func TestGenerator_Deterministic(t *testing.T) {
 		t.Fatalf("ReadAll() #1 error = %v", err)
 	}
 
-	reader2, err := gen.Generate(params)
+	reader2, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() #2 error = %v", err)
 	}
@@ -112,6 +116,45 @@ func TestGenerator_Deterministic(t *testing.T) {
 	}
 }
 
+// TestGenerator_RandomizeGolden pins the PCM of random screams for a few
+// seeds, in mono and stereo, so that a seed printed for a random scream keeps
+// replaying the same scream. A change that alters them must be deliberate:
+// update the hashes and call it out. The compiler may fuse multiply-adds on
+// other architectures, which changes the last bits of the samples.
+func TestGenerator_RandomizeGolden(t *testing.T) {
+	if runtime.GOARCH != "amd64" {
+		t.Skip("golden hashes are recorded on amd64")
+	}
+	tests := []struct {
+		seed     int64
+		channels int
+		want     string // first 8 bytes of the SHA-256 of the PCM
+	}{
+		{1, 1, "5d2cf0da94be092d"},
+		{1, 2, "3fb448ff343bf5a2"},
+		{42, 1, "5fc7ddd6549f66e0"},
+		{42, 2, "7f698caa7b11acfb"},
+		{777, 1, "fc39f078cfffb3b5"},
+		{777, 2, "92beccaf06e67fb3"},
+	}
+	gen := NewGenerator(discardLogger)
+	for _, tt := range tests {
+		params := audio.Randomize(tt.seed)
+		params.Channels = tt.channels
+		reader, err := gen.Generate(context.Background(), params)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, reader); err != nil {
+			t.Fatalf("io.Copy failed: %v", err)
+		}
+		if got := hex.EncodeToString(h.Sum(nil)[:8]); got != tt.want {
+			t.Errorf("Randomize(%d) with %d channels hashes to %s, want %s", tt.seed, tt.channels, got, tt.want)
+		}
+	}
+}
+
 func TestGenerator_DifferentSeeds(t *testing.T) {
 	gen := NewGenerator(discardLogger)
 
@@ -121,7 +164,7 @@ func TestGenerator_DifferentSeeds(t *testing.T) {
 	params2 := testScreamParams()
 	params2.Seed = 222
 
-	reader1, err := gen.Generate(params1)
+	reader1, err := gen.Generate(context.Background(), params1)
 	if err != nil {
 		t.Fatalf("Generate() #1 error = %v", err)
 	}
@@ -130,7 +173,7 @@ func TestGenerator_DifferentSeeds(t *testing.T) {
 		t.Fatalf("ReadAll() #1 error = %v", err)
 	}
 
-	reader2, err := gen.Generate(params2)
+	reader2, err := gen.Generate(context.Background(), params2)
 	if err != nil {
 		t.Fatalf("Generate() #2 error = %v", err)
 	}
@@ -154,7 +197,7 @@ func TestGenerator_AllPresets(t *testing.T) {
 				t.Fatalf("GetPreset(%q) returned false", name)
 			}
 
-			reader, err := gen.Generate(params)
+			reader, err := gen.Generate(context.Background(), params)
 			if err != nil {
 				t.Fatalf("Generate(%q) error = %v", name, err)
 			}
@@ -168,10 +211,12 @@ func TestGenerator_AllPresets(t *testing.T) {
 				t.Error("Generate() produced empty output")
 			}
 
-			// Verify byte count matches expected for preset's parameters
-			expectedBytes := int(params.Duration.Seconds()) * params.SampleRate * params.Channels * 2
+			// Verify byte count matches expected for preset's parameters,
+			// including any reverb tail
+			length := params.Duration + params.Tail()
+			expectedBytes := int(length.Seconds()) * params.SampleRate * params.Channels * 2
 			// Use approximate check since Duration may not be exact seconds
-			totalSamples := int(params.Duration.Seconds() * float64(params.SampleRate))
+			totalSamples := int(length.Seconds() * float64(params.SampleRate))
 			expectedBytesExact := totalSamples * params.Channels * 2
 			if len(data) != expectedBytesExact {
 				t.Errorf("byte count = %d, want %d (approx %d)", len(data), expectedBytesExact, expectedBytes)
@@ -180,13 +225,120 @@ func TestGenerator_AllPresets(t *testing.T) {
 	}
 }
 
+func TestGenerator_LayerStack(t *testing.T) {
+	params := testScreamParams()
+	params.Duration = 500 * time.Millisecond
+	shriek := params.Layers[2]
+
+	// Appending a silent layer leaves the output unchanged.
+	want := renderPCM(t, params)
+	params.Layers = append(params.Layers, audio.LayerParams{Type: audio.LayerHighShriek})
+	if got := renderPCM(t, params); !bytes.Equal(got, want) {
+		t.Error("appending a silent layer changed the output")
+	}
+
+	// A second shriek with its own seed is heard, and dropping the
+	// background noise leaves a valid scream.
+	params.Layers[5] = shriek
+	params.Layers[5].Seed = shriek.Seed + 1
+	two := renderPCM(t, params)
+	if bytes.Equal(two, want) {
+		t.Error("a second shriek did not change the output")
+	}
+	params.Layers = append(params.Layers[:4], params.Layers[5])
+	if bytes.Equal(renderPCM(t, params), two) {
+		t.Error("dropping the background noise did not change the output")
+	}
+}
+
+func TestGenerator_Levels(t *testing.T) {
+	params := testScreamParams()
+	params.Duration = 500 * time.Millisecond
+	want := renderPCM(t, params)
+
+	// Full levels leave the output unchanged, and zero levels silence it.
+	for i := range params.Layers {
+		params.Layers[i].Levels = []float64{1, 1}
+	}
+	if got := renderPCM(t, params); !bytes.Equal(got, want) {
+		t.Error("full levels changed the output")
+	}
+	for i := range params.Layers {
+		params.Layers[i].Levels = []float64{0}
+	}
+	for i, b := range renderPCM(t, params) {
+		if b != 0 {
+			t.Fatalf("zero levels: byte %d = %d, want silence", i, b)
+		}
+	}
+
+	// Levels rising from silence make the end louder than the start.
+	params.Filter = toneParams(440).Filter
+	for i := range params.Layers {
+		params.Layers[i].Levels = []float64{0, 1}
+	}
+	pcm := renderPCM(t, params)
+	quarter := len(pcm) / 16 * 4 // whole stereo frames
+	if start, end := rmsS16(pcm[:quarter]), rmsS16(pcm[3*quarter:]); start >= end/2 {
+		t.Errorf("rising levels: start RMS %v, end RMS %v; want a quieter start", start, end)
+	}
+}
+
+func TestGenerator_PresetsFadeInAndOut(t *testing.T) {
+	for _, name := range audio.AllPresets() {
+		t.Run(string(name), func(t *testing.T) {
+			params, _ := audio.GetPreset(name)
+			pcm := renderPCM(t, params)
+			frame := 2 * params.Channels
+			tail := frame * params.SampleRate / 100 // the last 10ms
+
+			for i, b := range pcm[:frame] {
+				if b != 0 {
+					t.Fatalf("first frame: byte %d = %d, want silence", i, b)
+				}
+			}
+			mid := len(pcm) / 2 / frame * frame
+			if end, body := rmsS16(pcm[len(pcm)-tail:]), rmsS16(pcm[mid:mid+tail]); end >= body/4 {
+				t.Errorf("last 10ms RMS %v, middle RMS %v; want a fade out", end, body)
+			}
+		})
+	}
+}
+
+// rmsS16 returns the RMS of the s16le samples in pcm, scaled to [0, 1].
+func rmsS16(pcm []byte) float64 {
+	n := len(pcm) / 2
+	var sumSq float64
+	for i := range n {
+		s := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) / 32768
+		sumSq += s * s
+	}
+	return math.Sqrt(sumSq / float64(n))
+}
+
+func TestLayerSeedMix_Distinct(t *testing.T) {
+	seen := make(map[int64]int)
+	for i := range 12 {
+		m := layerSeedMix(audio.LayerPrimaryScream, i)
+		if j, ok := seen[m]; ok {
+			t.Errorf("layers %d and %d share seed multiplier %d", j, i, m)
+		}
+		seen[m] = i
+	}
+	for _, typ := range []audio.LayerType{audio.LayerNoiseBurst, audio.LayerBackgroundNoise} {
+		if m := layerSeedMix(typ, 7); m != seedMixNoise {
+			t.Errorf("layerSeedMix(%v, 7) = %d, want seedMixNoise", typ, m)
+		}
+	}
+}
+
 func TestGenerator_InvalidParams(t *testing.T) {
 	gen := NewGenerator(discardLogger)
 
 	params := testScreamParams()
 	params.Duration = 0
 
-	_, err := gen.Generate(params)
+	_, err := gen.Generate(context.Background(), params)
 	if err == nil {
 		t.Error("Generate() with Duration=0 should return error, got nil")
 	}
@@ -198,7 +350,7 @@ func TestGenerator_MonoOutput(t *testing.T) {
 	params := testScreamParams()
 	params.Channels = 1
 
-	reader, err := gen.Generate(params)
+	reader, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -219,7 +371,7 @@ func TestGenerator_S16LERange(t *testing.T) {
 	gen := NewGenerator(discardLogger)
 	params := testScreamParams()
 
-	reader, err := gen.Generate(params)
+	reader, err := gen.Generate(context.Background(), params)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -276,7 +428,7 @@ func BenchmarkGenerator_Classic(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		reader, err := gen.Generate(params)
+		reader, err := gen.Generate(context.Background(), params)
 		if err != nil {
 			b.Fatal(err)
 		}
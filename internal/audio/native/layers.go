@@ -6,8 +6,8 @@ import (
 	"github.com/JamesPrial/go-scream/internal/audio"
 )
 
-// backgroundNoiseSeedXOR is XORed into the burst seed to produce a distinct
-// seed for the background noise RNG, decorrelating it from the burst layer.
+// backgroundNoiseSeedXOR is XORed into a background noise layer's seed to
+// seed its RNG, decorrelating it from a burst layer with the same seed.
 const backgroundNoiseSeedXOR int64 = 0x5a5a5a5a5a5a5a5a
 
 // layer generates audio samples for a single synthesis layer.
@@ -15,11 +15,20 @@ type layer interface {
 	Sample(t float64) float64
 }
 
+// tunableLayer is implemented by layers whose parameters can be changed while
+// they play, as a morphing scream does. tune takes the layer's params with
+// the global seed already mixed in, and keeps the layer's oscillator phase and
+// noise state so that the output stays continuous.
+type tunableLayer interface {
+	layer
+	tune(p audio.LayerParams)
+}
+
 // sweepJumpLayer generates a scream tone with frequency jumps, parameterised
 // by a coprime constant for deterministic stepping. It is used for both the
 // primary scream and high-shriek synthesis layers.
 type sweepJumpLayer struct {
-	osc       *oscillator
+	src       waveSource
 	seed      int64
 	base      float64
 	freqRange float64
@@ -29,14 +38,17 @@ type sweepJumpLayer struct {
 	coprime   int64
 	curStep   int64
 	curFreq   float64
+	bend      pitchBend
 }
 
 // newSweepJumpLayer constructs a sweepJumpLayer with the given params, sample
-// rate, and coprime constant. It is the shared implementation for both the
-// primary scream and high-shriek layer constructors.
-func newSweepJumpLayer(p audio.LayerParams, sampleRate int, coprime int64) *sweepJumpLayer {
+// rate, scream duration in seconds, and coprime constant. It is the shared
+// implementation for both the primary scream and high-shriek layer
+// constructors.
+func newSweepJumpLayer(p audio.LayerParams, sampleRate int, duration float64, coprime int64) *sweepJumpLayer {
 	return &sweepJumpLayer{
-		osc:       newOscillator(sampleRate),
+		src:       newWaveSource(p, sampleRate),
+		bend:      newPitchBend(p, sampleRate, duration),
 		seed:      p.Seed,
 		base:      p.BaseFreq,
 		freqRange: p.FreqRange,
@@ -49,12 +61,14 @@ func newSweepJumpLayer(p audio.LayerParams, sampleRate int, coprime int64) *swee
 }
 
 // newPrimaryScreamLayer creates a primary scream layer from params.
-func newPrimaryScreamLayer(p audio.LayerParams, sampleRate int) *sweepJumpLayer {
-	return newSweepJumpLayer(p, sampleRate, audio.CoprimePrimaryScream)
+// duration is the length of the scream in seconds.
+func newPrimaryScreamLayer(p audio.LayerParams, sampleRate int, duration float64) *sweepJumpLayer {
+	return newSweepJumpLayer(p, sampleRate, duration, audio.CoprimePrimaryScream)
 }
 
 // Sample returns the audio sample at time t for the sweep-jump layer.
-// The frequency jumps at discrete steps determined by the layer seed and coprime.
+// The frequency jumps at discrete steps determined by the layer seed and
+// coprime, and is bent by the layer's pitch contour and vibrato.
 func (l *sweepJumpLayer) Sample(t float64) float64 {
 	step := int64(t * l.jump)
 	if step != l.curStep {
@@ -62,12 +76,26 @@ func (l *sweepJumpLayer) Sample(t float64) float64 {
 		l.curFreq = l.base + l.freqRange*seededRandom(l.seed, step, l.coprime)
 	}
 	envelope := l.amp * (1 + l.rise*t)
-	return envelope * l.osc.sin(l.curFreq)
+	return envelope * l.src.sample(l.curFreq*l.bend.ratio(t))
+}
+
+// tune implements tunableLayer. The current frequency is recomputed on the
+// next sample so that it follows the new base frequency and range.
+func (l *sweepJumpLayer) tune(p audio.LayerParams) {
+	l.seed = p.Seed
+	l.base = p.BaseFreq
+	l.freqRange = p.FreqRange
+	l.jump = p.JumpRate
+	l.amp = p.Amplitude
+	l.rise = p.Rise
+	l.src.tune(p)
+	l.bend.tune(p)
+	l.curStep = -1
 }
 
 // harmonicSweepLayer generates a harmonic tone with linear frequency sweep plus jumps.
 type harmonicSweepLayer struct {
-	osc       *oscillator
+	src       waveSource
 	seed      int64
 	base      float64
 	sweep     float64
@@ -76,12 +104,15 @@ type harmonicSweepLayer struct {
 	amp       float64
 	curStep   int64
 	curFreq   float64
+	bend      pitchBend
 }
 
 // newHarmonicSweepLayer creates a harmonic sweep layer from params.
-func newHarmonicSweepLayer(p audio.LayerParams, sampleRate int) *harmonicSweepLayer {
+// duration is the length of the scream in seconds.
+func newHarmonicSweepLayer(p audio.LayerParams, sampleRate int, duration float64) *harmonicSweepLayer {
 	return &harmonicSweepLayer{
-		osc:       newOscillator(sampleRate),
+		src:       newWaveSource(p, sampleRate),
+		bend:      newPitchBend(p, sampleRate, duration),
 		seed:      p.Seed,
 		base:      p.BaseFreq,
 		sweep:     p.SweepRate,
@@ -93,7 +124,8 @@ func newHarmonicSweepLayer(p audio.LayerParams, sampleRate int) *harmonicSweepLa
 }
 
 // Sample returns the audio sample at time t for the harmonic sweep layer.
-// The frequency sweeps linearly over time with discrete jumps.
+// The frequency sweeps linearly over time with discrete jumps, and is bent by
+// the layer's pitch contour and vibrato.
 func (l *harmonicSweepLayer) Sample(t float64) float64 {
 	step := int64(t * l.jump)
 	if step != l.curStep {
@@ -101,78 +133,229 @@ func (l *harmonicSweepLayer) Sample(t float64) float64 {
 		l.curFreq = l.freqRange * seededRandom(l.seed, step, audio.CoprimeHarmonicSweep)
 	}
 	freq := l.base + l.sweep*t + l.curFreq
-	return l.amp * l.osc.sin(freq)
+	return l.amp * l.src.sample(freq*l.bend.ratio(t))
 }
 
-// newHighShriekLayer creates a high shriek layer from params.
-// It returns a *sweepJumpLayer configured with CoprimeHighShriek.
-func newHighShriekLayer(p audio.LayerParams, sampleRate int) *sweepJumpLayer {
-	return newSweepJumpLayer(p, sampleRate, audio.CoprimeHighShriek)
+// tune implements tunableLayer.
+func (l *harmonicSweepLayer) tune(p audio.LayerParams) {
+	l.seed = p.Seed
+	l.base = p.BaseFreq
+	l.sweep = p.SweepRate
+	l.freqRange = p.FreqRange
+	l.jump = p.JumpRate
+	l.amp = p.Amplitude
+	l.src.tune(p)
+	l.bend.tune(p)
+	l.curStep = -1
+}
+
+// newHighShriekLayer creates a high shriek layer from params. duration is the
+// length of the scream in seconds. It returns a *sweepJumpLayer configured
+// with CoprimeHighShriek.
+func newHighShriekLayer(p audio.LayerParams, sampleRate int, duration float64) *sweepJumpLayer {
+	return newSweepJumpLayer(p, sampleRate, duration, audio.CoprimeHighShriek)
 }
 
 // noiseBurstLayer generates gated noise bursts.
 type noiseBurstLayer struct {
-	burstSeed int64
-	noiseRng  *rand.Rand
-	burstRate float64
-	threshold float64
-	amp       float64
-	curStep   int64
-	curGate   float64
+	burstSeed  int64
+	noiseRng   *rand.Rand
+	burstRate  float64
+	threshold  float64
+	amp        float64
+	curStep    int64
+	curGate    float64
+	stereo     stereoNoise
+	color      noiseShaper // of the noise, or of the left channel's in stereo
+	rightColor noiseShaper // of the right channel's noise
+	sampleRate int
 }
 
 // newNoiseBurstLayer creates a noise burst layer from params.
-func newNoiseBurstLayer(p audio.LayerParams, noise audio.NoiseParams) *noiseBurstLayer {
+func newNoiseBurstLayer(p audio.LayerParams, sampleRate int) *noiseBurstLayer {
 	return &noiseBurstLayer{
-		burstSeed: noise.BurstSeed,
-		noiseRng:  rand.New(rand.NewSource(noise.BurstSeed)),
-		burstRate: noise.BurstRate,
-		threshold: noise.Threshold,
-		amp:       noise.BurstAmp,
-		curStep:   -1,
+		burstSeed:  p.Seed,
+		noiseRng:   rand.New(rand.NewSource(p.Seed)),
+		burstRate:  p.BurstRate,
+		threshold:  p.Threshold,
+		amp:        p.Amplitude,
+		curStep:    -1,
+		color:      newNoiseShaper(p.Noise, sampleRate),
+		rightColor: newNoiseShaper(p.Noise, sampleRate),
+		sampleRate: sampleRate,
 	}
 }
 
 // Sample returns the audio sample at time t for the noise burst layer.
-// The gate opens at discrete burst steps; when open, white noise is output.
+// The gate opens at discrete burst steps; when open, noise of the layer's
+// colour is output.
 func (l *noiseBurstLayer) Sample(t float64) float64 {
+	if !l.open(t) {
+		return 0
+	}
+	// White noise when gate is open; use stateful RNG for continuous noise.
+	noise := 2*l.noiseRng.Float64() - 1
+	return l.amp * l.color.shape(noise)
+}
+
+// SampleStereo returns left and right samples at time t. Both channels share
+// the burst gate; the right channel noise is decorrelated by l.stereo.
+func (l *noiseBurstLayer) SampleStereo(t float64) (float64, float64) {
+	if !l.open(t) {
+		return 0, 0
+	}
+	noise := 2*l.noiseRng.Float64() - 1
+	return l.amp * l.color.shape(noise), l.amp * l.rightColor.shape(l.stereo.right(noise))
+}
+
+// open reports whether the burst gate is open at time t.
+func (l *noiseBurstLayer) open(t float64) bool {
 	step := int64(t * l.burstRate)
 	if step != l.curStep {
 		l.curStep = step
 		l.curGate = seededRandom(l.burstSeed, step, audio.CoprimeNoiseBurst)
 	}
-	if l.curGate <= l.threshold {
-		return 0
-	}
-	// White noise when gate is open; use stateful RNG for continuous noise.
-	noise := 2*l.noiseRng.Float64() - 1
-	return l.amp * noise
+	return l.curGate > l.threshold
+}
+
+// tune implements tunableLayer. The noise source is kept; only the gate,
+// amplitude and colour change.
+func (l *noiseBurstLayer) tune(p audio.LayerParams) {
+	l.burstSeed = p.Seed
+	l.burstRate = p.BurstRate
+	l.threshold = p.Threshold
+	l.amp = p.Amplitude
+	l.curStep = -1
+	l.color.tune(p.Noise, l.sampleRate)
+	l.rightColor.tune(p.Noise, l.sampleRate)
 }
 
 // backgroundNoiseLayer generates constant low-level background noise.
 type backgroundNoiseLayer struct {
-	noiseRng *rand.Rand
-	amp      float64
+	seed       int64
+	noiseRng   *rand.Rand
+	amp        float64
+	stereo     stereoNoise
+	color      noiseShaper // of the noise, or of the left channel's in stereo
+	rightColor noiseShaper // of the right channel's noise
+	sampleRate int
 }
 
 // newBackgroundNoiseLayer creates a background noise layer from params.
-func newBackgroundNoiseLayer(noise audio.NoiseParams) *backgroundNoiseLayer {
+func newBackgroundNoiseLayer(p audio.LayerParams, sampleRate int) *backgroundNoiseLayer {
+	seed := p.Seed ^ backgroundNoiseSeedXOR
 	return &backgroundNoiseLayer{
-		noiseRng: rand.New(rand.NewSource(noise.BurstSeed ^ backgroundNoiseSeedXOR)),
-		amp:      noise.FloorAmp,
+		seed:       seed,
+		noiseRng:   rand.New(rand.NewSource(seed)),
+		amp:        p.Amplitude,
+		color:      newNoiseShaper(p.Noise, sampleRate),
+		rightColor: newNoiseShaper(p.Noise, sampleRate),
+		sampleRate: sampleRate,
 	}
 }
 
 // Sample returns the audio sample at time t for the background noise layer.
-// Produces continuous low-level white noise at the configured amplitude.
+// Produces continuous low-level noise of the layer's colour at the
+// configured amplitude.
 func (l *backgroundNoiseLayer) Sample(_ float64) float64 {
 	noise := 2*l.noiseRng.Float64() - 1
-	return l.amp * noise
+	return l.amp * l.color.shape(noise)
+}
+
+// SampleStereo returns left and right background noise samples; the right
+// channel is decorrelated by l.stereo.
+func (l *backgroundNoiseLayer) SampleStereo(_ float64) (float64, float64) {
+	noise := 2*l.noiseRng.Float64() - 1
+	return l.amp * l.color.shape(noise), l.amp * l.rightColor.shape(l.stereo.right(noise))
+}
+
+// tune implements tunableLayer. Only the amplitude and colour change.
+func (l *backgroundNoiseLayer) tune(p audio.LayerParams) {
+	l.amp = p.Amplitude
+	l.color.tune(p.Noise, l.sampleRate)
+	l.rightColor.tune(p.Noise, l.sampleRate)
+}
+
+// silentLayer is a layer that produces no sound.
+type silentLayer struct{}
+
+// Sample returns 0.
+func (silentLayer) Sample(float64) float64 { return 0 }
+
+// levelContour scales a layer's output, or a whole mix, by its levels,
+// envelope and tremolo over the scream. The zero value leaves the output
+// unchanged.
+type levelContour struct {
+	levels   []float64             // see audio.LayerParams.Levels
+	shape    []audio.EnvelopePoint // see audio.Envelope.Shape
+	tremolo  tremolo
+	duration float64 // seconds, over which the levels are spread
+}
+
+// newLevelContour creates a contour from levels and env over duration
+// seconds.
+func newLevelContour(levels []float64, env audio.Envelope, duration float64) levelContour {
+	c := levelContour{duration: duration}
+	c.tune(levels, env)
+	return c
+}
+
+// tune changes the levels and envelope of the contour, keeping its duration.
+func (c *levelContour) tune(levels []float64, env audio.Envelope) {
+	c.levels = levels
+	c.shape = env.Shape(c.duration)
+}
+
+// gain returns the gain of the contour at time t. It advances the tremolo by
+// one sample, so it must be called once per sample.
+func (c levelContour) gain(t float64) float64 {
+	g := audio.ShapeAt(c.shape, t) * c.tremolo.gain()
+	if len(c.levels) == 0 {
+		return g
+	}
+	pos := 0.0
+	if c.duration > 0 {
+		pos = t / c.duration
+	}
+	return g * audio.LevelAt(c.levels, pos)
+}
+
+// buildContours returns the level contour of each layer of params, with the
+// layer's tremolo.
+func buildContours(params audio.ScreamParams) []levelContour {
+	contours := make([]levelContour, len(params.Layers))
+	for i, lp := range params.Layers {
+		contours[i] = newLevelContour(lp.Levels, lp.Envelope, params.Duration.Seconds())
+		contours[i].tremolo = tremolo{lfo: lp.Tremolo, osc: newOscillator(params.SampleRate)}
+	}
+	return contours
+}
+
+// tuneContours changes the levels, envelopes and tremolos of contours built
+// by buildContours to those of params, keeping their duration and tremolo
+// phase.
+func tuneContours(contours []levelContour, params audio.ScreamParams) {
+	for i := range contours {
+		contours[i].tune(params.Layers[i].Levels, params.Layers[i].Envelope)
+		contours[i].tremolo.lfo = params.Layers[i].Tremolo
+	}
+}
+
+// contourGain returns the gain of contours[i] at time t, or 1 if there is no
+// contour for layer i.
+func contourGain(contours []levelContour, i int, t float64) float64 {
+	if i >= len(contours) {
+		return 1
+	}
+	return contours[i].gain(t)
 }
 
 // layerMixer mixes multiple layers together, clamping to [-1, 1].
 type layerMixer struct {
-	layers []layer
+	layers   []layer
+	contours []levelContour // by layer; layers without one are unchanged
+	master   levelContour   // the envelope of the whole mix
+	blocks   [][]float64    // by layer; scratch for SampleBlock
 }
 
 // newLayerMixer creates a mixer with the given layers.
@@ -180,13 +363,39 @@ func newLayerMixer(layers ...layer) *layerMixer {
 	return &layerMixer{layers: layers}
 }
 
-// Sample returns the sum of all layer samples at time t, clamped to [-1, 1].
+// Sample returns the sum of all layer samples at time t, each scaled by its
+// level contour and the sum by the master contour, clamped to [-1, 1].
+// Each scaled sample is rounded before it is added, as SampleBlock rounds it
+// when storing it, so that no fused multiply-add sets the two apart.
 func (m *layerMixer) Sample(t float64) float64 {
 	var sum float64
-	for _, l := range m.layers {
-		sum += l.Sample(t)
+	for i, l := range m.layers {
+		sum += float64(contourGain(m.contours, i, t) * l.Sample(t))
+	}
+	return clamp(m.master.gain(t)*sum, -1, 1)
+}
+
+// SampleBlock fills out with the samples Sample would return, one after
+// another, for the frames from frame start at sampleRate. The layers are
+// rendered on up to workers goroutines at once, each into a buffer of its
+// own, and then summed in order, so out is the same whatever workers.
+func (m *layerMixer) SampleBlock(out []float64, start, sampleRate, workers int) {
+	m.blocks = growBlocks(m.blocks, len(m.layers), len(out))
+	forEach(len(m.layers), workers, func(i int) {
+		l, block := m.layers[i], m.blocks[i]
+		for k := range block {
+			t := float64(start+k) / float64(sampleRate)
+			block[k] = contourGain(m.contours, i, t) * l.Sample(t)
+		}
+	})
+	for k := range out {
+		var sum float64
+		for _, block := range m.blocks {
+			sum += block[k]
+		}
+		t := float64(start+k) / float64(sampleRate)
+		out[k] = clamp(m.master.gain(t)*sum, -1, 1)
 	}
-	return clamp(sum, -1, 1)
 }
 
 // splitmix64 is a stateless bijective hash function used for deterministic
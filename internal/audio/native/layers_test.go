@@ -49,25 +49,25 @@ func validHighShriekParams() audio.LayerParams {
 func validNoiseBurstParams() audio.LayerParams {
 	return audio.LayerParams{
 		Type:      audio.LayerNoiseBurst,
+		BurstRate: 8,
+		Threshold: 0.7,
 		Amplitude: 0.18,
 		Seed:      4000,
 	}
 }
 
-func validNoiseParams() audio.NoiseParams {
-	return audio.NoiseParams{
-		BurstRate: 8,
-		Threshold: 0.7,
-		BurstAmp:  0.18,
-		FloorAmp:  0.1,
-		BurstSeed: 4000,
+func validBackgroundNoiseParams() audio.LayerParams {
+	return audio.LayerParams{
+		Type:      audio.LayerBackgroundNoise,
+		Amplitude: 0.1,
+		Seed:      4000,
 	}
 }
 
 // --- SweepJumpLayer Tests (PrimaryScream constructor) ---
 
 func TestSweepJumpLayer_PrimaryScream_NonZeroOutput(t *testing.T) {
-	layer := newPrimaryScreamLayer(validPrimaryScreamParams(), testSampleRate)
+	layer := newPrimaryScreamLayer(validPrimaryScreamParams(), testSampleRate, 1)
 
 	hasNonZero := false
 	for i := 0; i < 1000; i++ {
@@ -85,7 +85,7 @@ func TestSweepJumpLayer_PrimaryScream_NonZeroOutput(t *testing.T) {
 
 func TestSweepJumpLayer_PrimaryScream_AmplitudeBounds(t *testing.T) {
 	p := validPrimaryScreamParams()
-	layer := newPrimaryScreamLayer(p, testSampleRate)
+	layer := newPrimaryScreamLayer(p, testSampleRate, 1)
 
 	// For t in [0, 3], envelope = amp * (1 + rise*t)
 	// Max envelope at t=3: 0.4 * (1 + 1.2*3) = 0.4 * 4.6 = 1.84
@@ -107,7 +107,7 @@ func TestSweepJumpLayer_PrimaryScream_AmplitudeBounds(t *testing.T) {
 // --- HarmonicSweepLayer Tests ---
 
 func TestHarmonicSweepLayer_NonZeroOutput(t *testing.T) {
-	layer := newHarmonicSweepLayer(validHarmonicSweepParams(), testSampleRate)
+	layer := newHarmonicSweepLayer(validHarmonicSweepParams(), testSampleRate, 1)
 
 	hasNonZero := false
 	for i := 0; i < 1000; i++ {
@@ -126,7 +126,7 @@ func TestHarmonicSweepLayer_NonZeroOutput(t *testing.T) {
 // --- SweepJumpLayer Tests (HighShriek constructor) ---
 
 func TestSweepJumpLayer_HighShriek_NonZeroOutput(t *testing.T) {
-	layer := newHighShriekLayer(validHighShriekParams(), testSampleRate)
+	layer := newHighShriekLayer(validHighShriekParams(), testSampleRate, 1)
 
 	hasNonZero := false
 	for i := 0; i < 1000; i++ {
@@ -143,7 +143,7 @@ func TestSweepJumpLayer_HighShriek_NonZeroOutput(t *testing.T) {
 }
 
 func TestSweepJumpLayer_HighShriek_EnvelopeRises(t *testing.T) {
-	layer := newHighShriekLayer(validHighShriekParams(), testSampleRate)
+	layer := newHighShriekLayer(validHighShriekParams(), testSampleRate, 1)
 
 	// Collect average absolute amplitude over two different time windows.
 	// Early window: t in [0.0, 0.5)
@@ -157,7 +157,7 @@ func TestSweepJumpLayer_HighShriek_EnvelopeRises(t *testing.T) {
 
 		// We need a fresh layer for each window since the oscillator is stateful.
 		// Instead, we'll generate all samples up to the end and measure the windows.
-		l := newHighShriekLayer(validHighShriekParams(), testSampleRate)
+		l := newHighShriekLayer(validHighShriekParams(), testSampleRate, 1)
 		for i := 0; i < endSample; i++ {
 			tSec := float64(i) / float64(testSampleRate)
 			s := l.Sample(tSec)
@@ -183,9 +183,8 @@ func TestSweepJumpLayer_HighShriek_EnvelopeRises(t *testing.T) {
 // --- NoiseBurstLayer Tests ---
 
 func TestNoiseBurstLayer_HasSilentAndActiveSegments(t *testing.T) {
-	np := validNoiseParams()
 	lp := validNoiseBurstParams()
-	layer := newNoiseBurstLayer(lp, np)
+	layer := newNoiseBurstLayer(lp, testSampleRate)
 
 	hasZero := false
 	hasNonZero := false
@@ -216,8 +215,7 @@ func TestNoiseBurstLayer_HasSilentAndActiveSegments(t *testing.T) {
 // --- BackgroundNoiseLayer Tests ---
 
 func TestBackgroundNoiseLayer_ContinuousOutput(t *testing.T) {
-	np := validNoiseParams()
-	layer := newBackgroundNoiseLayer(np)
+	layer := newBackgroundNoiseLayer(validBackgroundNoiseParams(), testSampleRate)
 
 	// Background noise should be (almost) always non-zero.
 	// With pseudo-random values, exact zero is extremely unlikely.
@@ -298,10 +296,53 @@ func TestLayerMixer_ZeroLayers(t *testing.T) {
 	}
 }
 
+func TestLayerMixer_LevelContours(t *testing.T) {
+	mixer := newLayerMixer(&mockLayer{value: 0.4}, &mockLayer{value: 0.2})
+	// The second layer has no contour and is unchanged.
+	mixer.contours = []levelContour{{levels: []float64{0, 1}, duration: 2}}
+
+	tests := []struct {
+		t    float64
+		want float64
+	}{
+		{0, 0.2},
+		{1, 0.4},
+		{2, 0.6},
+		{3, 0.6},
+	}
+	for _, tt := range tests {
+		if got := mixer.Sample(tt.t); math.Abs(got-tt.want) > 1e-10 {
+			t.Errorf("LayerMixer.Sample(%v) = %f, want %f", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestLayerMixer_Envelopes(t *testing.T) {
+	mixer := newLayerMixer(&mockLayer{value: 0.4}, &mockLayer{value: 0.2})
+	mixer.contours = []levelContour{newLevelContour(nil, audio.Envelope{Attack: 1}, 4)}
+	mixer.master = newLevelContour(nil, audio.Envelope{Release: 2}, 4)
+
+	tests := []struct {
+		t    float64
+		want float64
+	}{
+		{0, 0.2},
+		{0.5, 0.4},
+		{2, 0.6},
+		{3, 0.3},
+		{4, 0},
+	}
+	for _, tt := range tests {
+		if got := mixer.Sample(tt.t); math.Abs(got-tt.want) > 1e-10 {
+			t.Errorf("LayerMixer.Sample(%v) = %f, want %f", tt.t, got, tt.want)
+		}
+	}
+}
+
 // --- Benchmarks ---
 
 func BenchmarkSweepJumpLayer_PrimaryScream(b *testing.B) {
-	layer := newPrimaryScreamLayer(validPrimaryScreamParams(), testSampleRate)
+	layer := newPrimaryScreamLayer(validPrimaryScreamParams(), testSampleRate, 1)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		layer.Sample(float64(i) / float64(testSampleRate))
@@ -310,11 +351,11 @@ func BenchmarkSweepJumpLayer_PrimaryScream(b *testing.B) {
 
 func BenchmarkLayerMixer(b *testing.B) {
 	layers := []layer{
-		newPrimaryScreamLayer(validPrimaryScreamParams(), testSampleRate),
-		newHarmonicSweepLayer(validHarmonicSweepParams(), testSampleRate),
-		newHighShriekLayer(validHighShriekParams(), testSampleRate),
-		newNoiseBurstLayer(validNoiseBurstParams(), validNoiseParams()),
-		newBackgroundNoiseLayer(validNoiseParams()),
+		newPrimaryScreamLayer(validPrimaryScreamParams(), testSampleRate, 1),
+		newHarmonicSweepLayer(validHarmonicSweepParams(), testSampleRate, 1),
+		newHighShriekLayer(validHighShriekParams(), testSampleRate, 1),
+		newNoiseBurstLayer(validNoiseBurstParams(), testSampleRate),
+		newBackgroundNoiseLayer(validBackgroundNoiseParams(), testSampleRate),
 	}
 	mixer := newLayerMixer(layers...)
 	b.ResetTimer()
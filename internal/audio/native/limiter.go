@@ -0,0 +1,175 @@
+package native
+
+import (
+	"math"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// newLimiterFromParams creates the limiter selected by fp.Limiter for a
+// single channel. A morph keeps the limiter its scream starts with, since
+// switching to or from the lookahead limiter would shift the signal by its
+// lookahead.
+func newLimiterFromParams(fp audio.FilterParams, sampleRate int) filter {
+	if fp.Limiter == audio.LimiterLookahead {
+		return newLookaheadLimiter(fp, sampleRate, 1).channel(0)
+	}
+	return newLimiter(fp.LimiterLevel)
+}
+
+// newLimiterPair creates the limiters selected by fp.Limiter for the left and
+// right channels of a stereo scream. Lookahead limiters share one gain, so
+// that a peak on one side turns both down and the stereo image holds still.
+// The left channel's limiter must process each frame before the right
+// channel's.
+func newLimiterPair(fp audio.FilterParams, sampleRate int) (left, right filter) {
+	if fp.Limiter == audio.LimiterLookahead {
+		l := newLookaheadLimiter(fp, sampleRate, 2)
+		return l.channel(0), l.channel(1)
+	}
+	return newLimiter(fp.LimiterLevel), newLimiter(fp.LimiterLevel)
+}
+
+// lookaheadLimiter keeps the signal of one or more channels, including its
+// true peaks between samples, within ±level without clipping it. It delays
+// the signal by its lookahead plus audio.TruePeakDelay, and in the meantime
+// turns its gain down over the lookahead so that the gain has fallen far
+// enough by the time each peak comes out. The gain then recovers
+// exponentially over the release. All channels share the gain, driven by
+// the highest of their peaks, as the ffmpeg backend's alimiter does.
+//
+// The gain is worked out in three steps: the lowest gain any peak in the
+// lookahead window needs, a release smoothing its recovery, and a moving
+// average over the lookahead smoothing its fall. Averaging a window of gains
+// that are all at or below the one a peak needs keeps the average there too.
+//
+// Each channel is fed through a limiterChannel. A frame is limited once
+// every channel has been fed its sample, when the first channel is fed the
+// next frame's, which delays the signal by a further sample.
+type lookaheadLimiter struct {
+	level       float64
+	releaseCoef float64
+	peaks       []audio.TruePeakMeter // by channel
+
+	in  []float64 // by channel, the frame fed in so far
+	out []float64 // by channel, the last frame limited
+
+	delays   [][]float64 // by channel, the delayed signal
+	delayPos int         // index of the next sample to come out of the delays
+
+	// mins is a monotonic queue of the gains needed over the last window
+	// frames: their indexes into needed, in rising order of gain.
+	needed []float64
+	mins   []int
+	n      int // frames seen
+
+	release float64 // the needed gain after the release
+
+	smooth    []float64 // the last window released gains
+	smoothSum float64
+}
+
+// newLookaheadLimiter creates a lookaheadLimiter of channels channels with
+// the limiter settings of fp. Its lookahead, fp's attack, is fixed once
+// created; the signal is delayed by fp.LimiterDelay samples.
+func newLookaheadLimiter(fp audio.FilterParams, sampleRate, channels int) *lookaheadLimiter {
+	delay := fp.LimiterDelay(sampleRate) - 1
+	window := delay - audio.TruePeakDelay + 1
+	f := &lookaheadLimiter{
+		peaks:   make([]audio.TruePeakMeter, channels),
+		in:      make([]float64, channels),
+		out:     make([]float64, channels),
+		delays:  make([][]float64, channels),
+		needed:  make([]float64, window),
+		smooth:  make([]float64, window),
+		release: 1,
+	}
+	for ch := range f.delays {
+		f.delays[ch] = make([]float64, delay)
+	}
+	for i := range f.smooth {
+		f.smooth[i] = 1
+	}
+	f.smoothSum = float64(window)
+	f.tune(fp, sampleRate)
+	return f
+}
+
+// channel returns the filter feeding channel ch of f.
+func (f *lookaheadLimiter) channel(ch int) *limiterChannel {
+	return &limiterChannel{limiter: f, ch: ch}
+}
+
+// tune changes the level and release.
+func (f *lookaheadLimiter) tune(fp audio.FilterParams, sampleRate int) {
+	f.level = fp.LimiterLevel
+	releaseSamples := fp.LimiterReleaseMillis() / 1000 * float64(sampleRate)
+	f.releaseCoef = math.Exp(-1 / releaseSamples)
+}
+
+// limit limits the frame fed in, storing in out the limited frame from
+// len(delays[0]) frames before.
+func (f *lookaheadLimiter) limit() {
+	// The gain needed to bring the highest peak just known within the level.
+	var peak float64
+	for ch := range f.peaks {
+		peak = math.Max(peak, f.peaks[ch].Process(f.in[ch]))
+	}
+	need := 1.0
+	if peak > f.level {
+		need = f.level / peak
+	}
+
+	// Slide the window of needed gains on and take its lowest.
+	window := len(f.needed)
+	i := f.n % window
+	f.needed[i] = need
+	for len(f.mins) > 0 && f.needed[f.mins[len(f.mins)-1]%window] >= need {
+		f.mins = f.mins[:len(f.mins)-1]
+	}
+	f.mins = append(f.mins, f.n)
+	if f.mins[0] <= f.n-window {
+		f.mins = f.mins[1:]
+	}
+	lowest := f.needed[f.mins[0]%window]
+	f.n++
+
+	if lowest < f.release {
+		f.release = lowest
+	} else {
+		f.release = lowest + f.releaseCoef*(f.release-lowest)
+	}
+
+	f.smoothSum += f.release - f.smooth[i]
+	f.smooth[i] = f.release
+	gain := math.Min(1, f.smoothSum/float64(window))
+
+	for ch, delay := range f.delays {
+		f.out[ch] = delay[f.delayPos] * gain
+		delay[f.delayPos] = f.in[ch]
+	}
+	f.delayPos = (f.delayPos + 1) % len(f.delays[0])
+}
+
+// limiterChannel feeds one channel of a lookaheadLimiter.
+type limiterChannel struct {
+	limiter *lookaheadLimiter
+	ch      int
+}
+
+// tune implements tunableFilter, changing the level and release.
+func (c *limiterChannel) tune(fp audio.FilterParams, sampleRate int) {
+	c.limiter.tune(fp, sampleRate)
+}
+
+// Process feeds the channel a sample and returns its limited sample from
+// fp.LimiterDelay samples before. Feeding the first channel limits the last
+// frame.
+func (c *limiterChannel) Process(sample float64) float64 {
+	f := c.limiter
+	if c.ch == 0 {
+		f.limit()
+	}
+	f.in[c.ch] = sample
+	return f.out[c.ch]
+}
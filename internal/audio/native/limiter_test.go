@@ -0,0 +1,195 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// lookaheadParams returns filter params selecting a lookahead limiter at
+// level 0.9 with the given attack and release in ms.
+func lookaheadParams(attack, release float64) audio.FilterParams {
+	return audio.FilterParams{
+		LimiterLevel:   0.9,
+		Limiter:        audio.LimiterLookahead,
+		LimiterAttack:  attack,
+		LimiterRelease: release,
+	}
+}
+
+func TestNewLimiterFromParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		limiter   audio.Limiter
+		lookahead bool
+	}{
+		{"default", "", false},
+		{"hard", audio.LimiterHard, false},
+		{"lookahead", audio.LimiterLookahead, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := audio.FilterParams{LimiterLevel: 0.9, Limiter: tt.limiter}
+			_, got := newLimiterFromParams(fp, 48000).(*limiterChannel)
+			if got != tt.lookahead {
+				t.Errorf("lookahead limiter = %v, want %v", got, tt.lookahead)
+			}
+		})
+	}
+}
+
+func TestLookaheadLimiter_DelaysQuietSignal(t *testing.T) {
+	f := newLookaheadLimiter(lookaheadParams(1, 10), 48000, 1).channel(0)
+	delay := lookaheadParams(1, 10).LimiterDelay(48000)
+
+	osc := newOscillator(48000)
+	var in []float64
+	for i := range 4800 {
+		in = append(in, 0.5*osc.sin(440))
+		out := f.Process(in[i])
+		want := 0.0
+		if i >= delay {
+			want = in[i-delay]
+		}
+		if math.Abs(out-want) > 1e-12 {
+			t.Fatalf("sample %d = %v, want %v (the input %d samples before)", i, out, want, delay)
+		}
+	}
+}
+
+func TestLookaheadLimiter_KeepsTruePeaksWithinLevel(t *testing.T) {
+	for _, freq := range []float64{100, 1000, 5000, 11000} {
+		f := newLookaheadLimiter(lookaheadParams(1, 10), 48000, 1).channel(0)
+		var meter audio.TruePeakMeter
+		var peak float64
+		for i := range 48000 {
+			// Bursts well over the level every 100 ms, between quiet
+			// stretches.
+			amp := 0.5
+			if i%4800 > 2400 {
+				amp = 4
+			}
+			phase := 2*math.Pi*freq*float64(i)/48000 + 0.3
+			peak = math.Max(peak, meter.Process(f.Process(amp*math.Sin(phase))))
+		}
+		if peak > 0.9*1.01 {
+			t.Errorf("%v Hz: true peak %v, want at most the level 0.9", freq, peak)
+		}
+	}
+}
+
+func TestLookaheadLimiter_SmoothGain(t *testing.T) {
+	// A steady sine twice the level comes out as a scaled sine, not a
+	// clipped one: the ratio of output to input holds steady.
+	f := newLookaheadLimiter(lookaheadParams(5, 500), 48000, 1).channel(0)
+	delay := lookaheadParams(5, 500).LimiterDelay(48000)
+	var in []float64
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := range 48000 {
+		in = append(in, 1.8*math.Sin(2*math.Pi*440*float64(i)/48000))
+		out := f.Process(in[i])
+		if i < 24000 || math.Abs(in[i-delay]) < 0.5 {
+			continue
+		}
+		ratio := out / in[i-delay]
+		lo, hi = math.Min(lo, ratio), math.Max(hi, ratio)
+	}
+	if hi > 0.5+1e-3 || lo < 0.49 {
+		t.Errorf("gain ranged from %v to %v, want a steady 0.5", lo, hi)
+	}
+}
+
+func TestLookaheadLimiter_Releases(t *testing.T) {
+	f := newLookaheadLimiter(lookaheadParams(1, 10), 48000, 1).channel(0)
+	for i := range 4800 {
+		f.Process(2 * math.Sin(2*math.Pi*440*float64(i)/48000))
+	}
+	// 100 ms of quiet is ten release times: the gain is back to unity.
+	var out float64
+	for i := range 4800 {
+		out = f.Process(0.1 * math.Sin(2*math.Pi*440*float64(i)/48000+1))
+	}
+	delay := lookaheadParams(1, 10).LimiterDelay(48000)
+	want := 0.1 * math.Sin(2*math.Pi*440*float64(4799-delay)/48000+1)
+	if math.Abs(out-want) > 1e-4 {
+		t.Errorf("output after release = %v, want %v", out, want)
+	}
+}
+
+func TestLookaheadLimiter_LinksChannels(t *testing.T) {
+	// A peak on the left only turns both channels down by the same gain.
+	fp := lookaheadParams(1, 10)
+	l := newLookaheadLimiter(fp, 48000, 2)
+	left, right := l.channel(0), l.channel(1)
+	delay := fp.LimiterDelay(48000)
+	var inL, inR []float64
+	var squashed bool
+	for i := range 9600 {
+		amp := 0.5
+		if i >= 4800 {
+			amp = 2
+		}
+		inL = append(inL, amp*math.Sin(2*math.Pi*440*float64(i)/48000))
+		inR = append(inR, 0.3*math.Sin(2*math.Pi*660*float64(i)/48000))
+		outL, outR := left.Process(inL[i]), right.Process(inR[i])
+		if i < delay || math.Abs(inL[i-delay]) < 0.1 || math.Abs(inR[i-delay]) < 0.1 {
+			continue
+		}
+		gl, gr := outL/inL[i-delay], outR/inR[i-delay]
+		if math.Abs(gl-gr) > 1e-9 {
+			t.Fatalf("sample %d: left gain %v, right gain %v; want them equal", i, gl, gr)
+		}
+		squashed = squashed || gr < 0.5
+	}
+	if !squashed {
+		t.Error("the right channel was never turned down with the left")
+	}
+}
+
+func TestGenerator_LookaheadLimiter(t *testing.T) {
+	params, _ := audio.GetPreset(audio.PresetDeathMetal)
+	params.Filter.Limiter = audio.LimiterLookahead
+
+	r, err := NewGenerator(discardLogger).Generate(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	pcm, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	got := audio.MeasureLoudness(pcm, params.SampleRate, params.Channels).TruePeak
+	// Allow for rounding to s16.
+	if want := 20 * math.Log10(params.Filter.LimiterLevel); got > want+0.05 {
+		t.Errorf("true peak = %.2f dBTP, want at most the limiter level %.2f", got, want)
+	}
+}
+
+func TestGenerator_LookaheadLimiterKeepsEnd(t *testing.T) {
+	// A burst in the last millisecond of the scream, under the level so that
+	// neither limiter touches it: the lookahead limiter delays it, and the
+	// tail must leave room for it to come out.
+	params := toneParams(1000)
+	params.Envelope = audio.Envelope{Points: []audio.EnvelopePoint{{Time: 0.999, Level: 0}, {Time: 0.9992, Level: 1}}}
+	params.Filter.LimiterLevel = 0.9
+	hard := renderPCM(t, params)
+
+	params.Filter.Limiter = audio.LimiterLookahead
+	lookahead := renderPCM(t, params)
+
+	delay := params.Filter.LimiterDelay(params.SampleRate) * 2
+	if len(lookahead) < len(hard)+delay {
+		t.Fatalf("lookahead PCM is %d bytes, want at least %d: the hard limiter's %d plus the delay", len(lookahead), len(hard)+delay, len(hard))
+	}
+	if !bytes.Equal(lookahead[delay:delay+len(hard)], hard) {
+		t.Error("lookahead PCM is not the hard limiter's delayed by the lookahead")
+	}
+	if rmsS16(hard[len(hard)-96:]) == 0 {
+		t.Error("the burst at the end is silent")
+	}
+}
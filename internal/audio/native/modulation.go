@@ -0,0 +1,68 @@
+package native
+
+import (
+	"math"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// pitchBend bends the pitch of a tonal layer by its pitch contour and
+// vibrato. The layer multiplies its frequency by the bend before advancing
+// its oscillator, and the vibrato LFO is itself a phase-accumulating
+// oscillator, so the phase of the tone stays continuous however the bend,
+// or the vibrato rate, changes.
+type pitchBend struct {
+	shape    []audio.EnvelopePoint // bend in semitones; see audio.PitchContour.Shape
+	vibrato  audio.LFO
+	lfo      *oscillator
+	duration float64 // seconds, over which the contour is traced
+}
+
+// newPitchBend creates the pitch bend of a layer with params p in a scream
+// of duration seconds.
+func newPitchBend(p audio.LayerParams, sampleRate int, duration float64) pitchBend {
+	b := pitchBend{lfo: newOscillator(sampleRate), duration: duration}
+	b.tune(p)
+	return b
+}
+
+// tune changes the contour and vibrato to those of p, keeping the phase of
+// the vibrato LFO.
+func (b *pitchBend) tune(p audio.LayerParams) {
+	b.shape = p.Pitch.Shape(b.duration)
+	b.vibrato = p.Vibrato
+}
+
+// ratio returns the factor by which the pitch is bent at time t and advances
+// the vibrato LFO by one sample, so it must be called once per sample.
+func (b *pitchBend) ratio(t float64) float64 {
+	var semitones float64
+	if b.shape != nil {
+		semitones = audio.ShapeAt(b.shape, t)
+	}
+	if b.vibrato.On() {
+		semitones += b.vibrato.Depth * b.lfo.sin(b.vibrato.Rate)
+	}
+	if semitones == 0 {
+		return 1
+	}
+	return math.Exp2(semitones / 12)
+}
+
+// tremolo wobbles the loudness of a layer. Like the vibrato of a pitchBend,
+// its LFO is a phase-accumulating oscillator.
+// The zero value leaves the loudness unchanged.
+type tremolo struct {
+	lfo audio.LFO
+	osc *oscillator
+}
+
+// gain returns the gain of the tremolo, which falls from 1 by up to the LFO
+// depth, and advances the LFO by one sample, so it must be called once per
+// sample.
+func (m tremolo) gain() float64 {
+	if !m.lfo.On() {
+		return 1
+	}
+	return 1 - m.lfo.Depth*(0.5+0.5*m.osc.sin(m.lfo.Rate))
+}
@@ -0,0 +1,217 @@
+package native
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// instantFreq estimates the frequency of the mono s16le samples of pcm around
+// time at seconds, from the upward zero crossings in a window of 20ms
+// centred on it. Crossings are located between samples by linear
+// interpolation.
+func instantFreq(pcm []byte, sampleRate int, at float64) float64 {
+	sample := func(i int) float64 { return float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) }
+	half := sampleRate / 100
+	centre := int(at * float64(sampleRate))
+	from, to := max(1, centre-half), min(len(pcm)/2, centre+half)
+
+	var first, last float64
+	n := 0
+	for i := from; i < to; i++ {
+		a, b := sample(i-1), sample(i)
+		if a < 0 && b >= 0 {
+			x := float64(i-1) + a/(a-b)
+			if n == 0 {
+				first = x
+			}
+			last = x
+			n++
+		}
+	}
+	if n < 2 {
+		return 0
+	}
+	return float64(n-1) * float64(sampleRate) / (last - first)
+}
+
+// fundamental estimates the fundamental frequency, between 50 Hz and 2 kHz,
+// of the mono s16le samples of pcm around time at seconds, from the lag at
+// which a window of 40ms centred on it best matches itself. Unlike
+// instantFreq it is not fooled by strong overtones.
+func fundamental(pcm []byte, sampleRate int, at float64) float64 {
+	sample := func(i int) float64 { return float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) }
+	half := sampleRate / 50
+	centre := int(at * float64(sampleRate))
+	from, to := max(0, centre-half), min(len(pcm)/2, centre+half)
+
+	minLag, maxLag := sampleRate/2000, sampleRate/50
+	corr := make([]float64, maxLag+1)
+	best := math.Inf(-1)
+	for lag := minLag; lag <= maxLag; lag++ {
+		for i := from; i+lag < to; i++ {
+			corr[lag] += sample(i) * sample(i+lag)
+		}
+		corr[lag] /= float64(to - from - lag)
+		best = max(best, corr[lag])
+	}
+
+	// Take the peak nearest the shortest lag that comes close to the best
+	// match, so that two periods are not mistaken for one.
+	bestLag := minLag
+	for corr[bestLag] < 0.95*best {
+		bestLag++
+	}
+	for bestLag < maxLag && corr[bestLag+1] > corr[bestLag] {
+		bestLag++
+	}
+	return float64(sampleRate) / float64(bestLag)
+}
+
+// ---------------------------------------------------------------------------
+// Pitch contours
+// ---------------------------------------------------------------------------
+
+func TestGenerator_PitchContours(t *testing.T) {
+	// 440 Hz bent by s semitones.
+	bent := func(s float64) float64 { return 440 * math.Exp2(s/12) }
+
+	tests := []struct {
+		name  string
+		pitch audio.PitchContour
+		at    []float64 // seconds
+		want  []float64 // Hz
+	}{
+		{
+			"rising octave",
+			audio.PitchContour{Curve: audio.PitchRising, Semitones: 12},
+			[]float64{0.1, 0.5, 0.9},
+			[]float64{bent(1.2), bent(6), bent(10.8)},
+		},
+		{
+			"falling fifth",
+			audio.PitchContour{Curve: audio.PitchFalling, Semitones: 7},
+			[]float64{0.1, 0.5, 0.9},
+			[]float64{bent(-0.7), bent(-3.5), bent(-6.3)},
+		},
+		{
+			"arch",
+			audio.PitchContour{Curve: audio.PitchArch, Semitones: 12},
+			[]float64{0.25, 0.5, 0.75},
+			[]float64{bent(6), bent(12), bent(6)},
+		},
+		{
+			"breakpoints",
+			audio.PitchContour{Points: []audio.PitchPoint{{Time: 0.2, Semitones: 0}, {Time: 0.6, Semitones: -12}}},
+			[]float64{0.1, 0.4, 0.8},
+			[]float64{440, bent(-6), 220},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := toneParams(440)
+			params.Layers[0].Pitch = tt.pitch
+			pcm := renderPCM(t, params)
+			for i, at := range tt.at {
+				got := instantFreq(pcm, params.SampleRate, at)
+				if math.Abs(got-tt.want[i]) > tt.want[i]*0.01 {
+					t.Errorf("frequency at %vs = %.1f Hz, want %.1f Hz", at, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerator_PitchContourAllTonalLayers(t *testing.T) {
+	for _, lt := range []audio.LayerType{audio.LayerHarmonicSweep, audio.LayerHighShriek, audio.LayerVocal} {
+		t.Run(lt.String(), func(t *testing.T) {
+			params := toneParams(440)
+			params.Layers[0].Type = lt
+			plain := renderPCM(t, params)
+			params.Layers[0].Pitch = audio.PitchContour{Curve: audio.PitchRising, Semitones: 12}
+			pcm := renderPCM(t, params)
+
+			// Rising by 10.8 semitones at 0.9s scales the pitch by about 1.87.
+			plainFreq := fundamental(plain, params.SampleRate, 0.9)
+			got := fundamental(pcm, params.SampleRate, 0.9)
+			if ratio := got / plainFreq; ratio < 1.75 || ratio > 2 {
+				t.Errorf("fundamental at 0.9s = %.1f Hz, want about 1.87 times the unbent %.1f Hz", got, plainFreq)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Vibrato and tremolo
+// ---------------------------------------------------------------------------
+
+func TestGenerator_Vibrato(t *testing.T) {
+	params := toneParams(440)
+	params.Layers[0].Vibrato = audio.LFO{Rate: 2, Depth: 1}
+	pcm := renderPCM(t, params)
+
+	// The LFO starts at zero and peaks a quarter of a cycle later.
+	tests := []struct {
+		at   float64
+		want float64
+	}{
+		{0.125, 440 * math.Exp2(1.0/12)},
+		{0.375, 440 * math.Exp2(-1.0/12)},
+		{0.625, 440 * math.Exp2(1.0/12)},
+	}
+	for _, tt := range tests {
+		// A 20ms window averages over part of the wobble, so allow for it.
+		if got := instantFreq(pcm, params.SampleRate, tt.at); math.Abs(got-tt.want) > 2 {
+			t.Errorf("frequency at %vs = %.1f Hz, want %.1f Hz", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestGenerator_ModulationIsContinuous(t *testing.T) {
+	params := toneParams(440)
+	params.Layers[0].Pitch = audio.PitchContour{Points: []audio.PitchPoint{{Time: 0.5, Semitones: 0}, {Time: 0.5, Semitones: 12}}}
+	params.Layers[0].Vibrato = audio.LFO{Rate: 6, Depth: 2}
+	pcm := renderPCM(t, params)
+
+	// A sine of amplitude 0.5 at up to 880 Hz times the vibrato moves by at
+	// most 2*pi*f/sampleRate*0.5 per sample; a click would jump further.
+	maxStep := 2 * math.Pi * 880 * math.Exp2(2.0/12) / float64(params.SampleRate) * 0.5 * 32767 * 1.05
+	prev := int16(binary.LittleEndian.Uint16(pcm))
+	for i := 2; i+1 < len(pcm); i += 2 {
+		s := int16(binary.LittleEndian.Uint16(pcm[i:]))
+		if step := math.Abs(float64(s) - float64(prev)); step > maxStep {
+			t.Fatalf("sample %d jumps by %v, want at most %v", i/2, step, maxStep)
+		}
+		prev = s
+	}
+}
+
+func TestLayerMixer_Tremolo(t *testing.T) {
+	mixer := newLayerMixer(&mockLayer{value: 0.5})
+	mixer.contours = []levelContour{{tremolo: tremolo{lfo: audio.LFO{Rate: 1, Depth: 0.5}, osc: newOscillator(4)}}}
+
+	// At 4 samples per second the LFO is sampled at the start, peak, middle
+	// and trough of each cycle.
+	for i, want := range []float64{0.375, 0.25, 0.375, 0.5, 0.375} {
+		if got := mixer.Sample(float64(i) / 4); math.Abs(got-want) > 1e-10 {
+			t.Errorf("sample %d = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestPitchBend_Ratio(t *testing.T) {
+	p := audio.LayerParams{Pitch: audio.PitchContour{Curve: audio.PitchRising, Semitones: 12}}
+	b := newPitchBend(p, 4, 2)
+	for i, want := range []float64{1, math.Exp2(0.25), math.Sqrt2, math.Exp2(0.75), 2, 2} {
+		if got := b.ratio(float64(i) / 2); math.Abs(got-want) > 1e-12 {
+			t.Errorf("ratio(%v) = %v, want %v", float64(i)/2, got, want)
+		}
+	}
+
+	b = newPitchBend(audio.LayerParams{}, 4, 2)
+	if got := b.ratio(1); got != 1 {
+		t.Errorf("ratio() without a bend = %v, want 1", got)
+	}
+}
@@ -0,0 +1,81 @@
+package native
+
+import "github.com/JamesPrial/go-scream/internal/audio"
+
+// morphControlMillis is how often a morphing scream's parameters are
+// recomputed. Between updates they are held constant, which is inaudible at
+// this rate and keeps the per-sample cost the same as for a static scream.
+const morphControlMillis = 5
+
+// morphRenderer renders a scream whose parameters move from one set to
+// another over its duration. Every control period it interpolates the
+// parameters for the current position and retunes the wrapped renderer.
+type morphRenderer struct {
+	render   tunableRenderer
+	from, to audio.ScreamParams
+	total    int // total samples per channel
+	period   int // samples per control period
+	n        int // index of the next sample
+}
+
+// newMorphRenderer builds a morphRenderer for params, which must have a
+// MorphTo. The scream is rendered in stereo if either end of the morph is.
+// The renderer is built from the start of the morph as given by Interpolate,
+// which has a layer for every layer of either end.
+func newMorphRenderer(params audio.ScreamParams) *morphRenderer {
+	from, to := params.MorphStart(), params.MorphEnd()
+	start := audio.Interpolate(from, to, 0)
+
+	var render tunableRenderer
+	if from.IsStereo() || to.IsStereo() {
+		render = newStereoRenderer(start)
+	} else {
+		render = newMonoRenderer(start)
+	}
+
+	return &morphRenderer{
+		render: render,
+		from:   from,
+		to:     to,
+		total:  int(params.Duration.Seconds() * float64(params.SampleRate)),
+		period: max(1, params.SampleRate*morphControlMillis/1000),
+	}
+}
+
+func (r *morphRenderer) appendFrame(buf []byte, t float64) []byte {
+	r.step()
+	return r.render.appendFrame(buf, t)
+}
+
+// frame implements voiceRenderer.
+func (r *morphRenderer) frame(t float64) (l, rr float64) {
+	r.step()
+	return r.render.frame(t)
+}
+
+// renderBlock implements frameRenderer. The block is split at the start of
+// every control period, where the wrapped renderer is retuned as step would.
+func (r *morphRenderer) renderBlock(l, rr []float64, start, workers int) {
+	for k := 0; k < len(l); {
+		r.retune()
+		n := min(len(l)-k, r.period-r.n%r.period)
+		r.render.renderBlock(l[k:k+n], rr[k:k+n], start+k, workers)
+		r.n += n
+		k += n
+	}
+}
+
+// step advances the morph by a sample, retuning the wrapped renderer at the
+// start of every control period.
+func (r *morphRenderer) step() {
+	r.retune()
+	r.n++
+}
+
+// retune retunes the wrapped renderer to the parameters at the next sample if
+// it starts a control period.
+func (r *morphRenderer) retune() {
+	if r.n%r.period == 0 && r.total > 0 {
+		r.render.tune(audio.Interpolate(r.from, r.to, float64(r.n)/float64(r.total)))
+	}
+}
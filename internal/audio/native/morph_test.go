@@ -0,0 +1,139 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// renderPCM generates params and returns the raw s16le output.
+func renderPCM(t *testing.T, params audio.ScreamParams) []byte {
+	t.Helper()
+	reader, err := NewGenerator(discardLogger).Generate(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return data
+}
+
+// toneParams returns mono params with a single steady tone at freq Hz and a
+// transparent filter chain.
+func toneParams(freq float64) audio.ScreamParams {
+	params := testScreamParams()
+	params.Duration = time.Second
+	params.Channels = 1
+	params.Layers[0] = audio.LayerParams{Type: audio.LayerPrimaryScream, BaseFreq: freq, JumpRate: 1, Amplitude: 0.5}
+	for i := 1; i < len(params.Layers); i++ {
+		params.Layers[i].Amplitude = 0
+	}
+	params.Filter = audio.FilterParams{
+		HighpassCutoff: 10, LowpassCutoff: 20000,
+		CrusherBits: 16, CompRatio: 1, CompAttack: 5, CompRelease: 50,
+		LimiterLevel: 1,
+	}
+	return params
+}
+
+// zeroCrossings counts sign changes in the mono s16le samples of pcm.
+func zeroCrossings(pcm []byte) int {
+	n := 0
+	prev := int16(binary.LittleEndian.Uint16(pcm))
+	for i := 2; i+1 < len(pcm); i += 2 {
+		s := int16(binary.LittleEndian.Uint16(pcm[i:]))
+		if (prev < 0) != (s < 0) {
+			n++
+		}
+		prev = s
+	}
+	return n
+}
+
+func TestGenerator_MorphToSelfMatchesStatic(t *testing.T) {
+	for _, channels := range []int{1, 2} {
+		params := testScreamParams()
+		params.Duration = time.Second
+		params.Channels = channels
+		params.Width = 0.6
+		params.Layers[1].PanRate = 0.4
+		params.Layers[1].PanDepth = 0.5
+
+		want := renderPCM(t, params)
+		target := params
+		params.MorphTo = &target
+		if got := renderPCM(t, params); !bytes.Equal(got, want) {
+			t.Errorf("%d channels: morphing to the same params changed the output", channels)
+		}
+	}
+}
+
+func TestGenerator_MorphBetweenLayerCounts(t *testing.T) {
+	for _, channels := range []int{1, 2} {
+		params := testScreamParams()
+		params.Duration = 500 * time.Millisecond
+		params.Channels = channels
+		params.Width = 0.5
+		target := params.Clone()
+		target.Layers = append(target.Layers[:2], audio.LayerParams{
+			Type: audio.LayerVocal, BaseFreq: 200, JumpRate: 2, Amplitude: 0.4, Vowel: "ao",
+		})
+		params.MorphTo = &target
+
+		pcm := renderPCM(t, params)
+		if want := int(params.Duration.Seconds()*float64(params.SampleRate)) * channels * 2; len(pcm) != want {
+			t.Errorf("%d channels: %d bytes, want %d", channels, len(pcm), want)
+		}
+
+		// The reverse morph renders too.
+		from := target.Clone()
+		back := params.MorphStart()
+		from.MorphTo = &back
+		renderPCM(t, from)
+	}
+}
+
+func TestGenerator_MorphStartsAtFrom(t *testing.T) {
+	from, _ := audio.GetPreset(audio.PresetDeathMetal)
+	to, _ := audio.GetPreset(audio.PresetWhisper)
+	from.Duration = time.Second
+
+	static := renderPCM(t, from)
+	from.MorphTo = &to
+	morph := renderPCM(t, from)
+
+	// The first control period is rendered entirely with the start params.
+	n := from.SampleRate * morphControlMillis / 1000 * from.Channels * 2
+	if !bytes.Equal(morph[:n], static[:n]) {
+		t.Error("first control period of a morph differs from the start params")
+	}
+	if bytes.Equal(morph, static) {
+		t.Error("morph produced the same output as the start params")
+	}
+}
+
+func TestGenerator_MorphChangesPitch(t *testing.T) {
+	params := toneParams(200)
+	target := toneParams(2000)
+	params.MorphTo = &target
+
+	pcm := renderPCM(t, params)
+	tenth := len(pcm) / 10 &^ 1
+	start := zeroCrossings(pcm[:tenth])
+	end := zeroCrossings(pcm[len(pcm)-tenth:])
+
+	// 0.1s of a tone at f Hz has about 0.2*f zero crossings.
+	if start < 30 || start > 70 {
+		t.Errorf("start zero crossings = %d, want about 40 (around 200 Hz)", start)
+	}
+	if end < 300 || end > 420 {
+		t.Errorf("end zero crossings = %d, want about 380 (approaching 2000 Hz)", end)
+	}
+}
@@ -0,0 +1,156 @@
+package native
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// colouredNoise returns seconds of noise of the colour of n at 48 kHz,
+// coloured from the same white noise for every n.
+func colouredNoise(n audio.NoiseParams, seconds float64) []float64 {
+	rng := rand.New(rand.NewSource(1))
+	s := newNoiseShaper(n, 48000)
+	out := make([]float64, int(seconds*48000))
+	for i := range out {
+		out[i] = s.shape(2*rng.Float64() - 1)
+	}
+	return out
+}
+
+// bandPower returns the mean power of samples at 48 kHz in a narrow band
+// around freq Hz, through two band-pass filters so that little leaks in
+// from outside it.
+func bandPower(samples []float64, freq float64) float64 {
+	f1 := newBiquad(audio.EQBandpass, freq, 8, 0, 48000)
+	f2 := newBiquad(audio.EQBandpass, freq, 8, 0, 48000)
+	var sum float64
+	for _, s := range samples {
+		y := f2.Process(f1.Process(s))
+		sum += y * y
+	}
+	return sum / float64(len(samples))
+}
+
+// densityDB returns the power density of noise at freq Hz against that of
+// white noise, in dB.
+func densityDB(noise, white []float64, freq float64) float64 {
+	return 10 * math.Log10(bandPower(noise, freq)/bandPower(white, freq))
+}
+
+func TestNoiseShaper_SpectralSlope(t *testing.T) {
+	white := colouredNoise(audio.NoiseParams{}, 4)
+	tests := []struct {
+		color audio.NoiseColor
+		want  float64 // dB per octave
+	}{
+		{audio.NoiseWhite, 0},
+		{audio.NoisePink, -3},
+		{audio.NoiseBrown, -6},
+		{audio.NoiseBlue, 3},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.color), func(t *testing.T) {
+			noise := colouredNoise(audio.NoiseParams{Color: tt.color}, 4)
+			// Three octaves, above the corner of the brown noise's
+			// integrator and well below the Nyquist frequency.
+			slope := (densityDB(noise, white, 4000) - densityDB(noise, white, 500)) / 3
+			if math.Abs(slope-tt.want) > 0.75 {
+				t.Errorf("slope from 500 Hz to 4 kHz = %.2f dB/octave, want %v", slope, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoiseShaper_Band(t *testing.T) {
+	white := colouredNoise(audio.NoiseParams{}, 4)
+	noise := colouredNoise(audio.NoiseParams{Color: audio.NoiseBand, Freq: 1000, Q: 2}, 4)
+	centre := densityDB(noise, white, 1000)
+	for _, f := range []float64{250, 4000} {
+		if d := densityDB(noise, white, f); d > centre-15 {
+			t.Errorf("density at %v Hz = %.1f dB, want at least 15 dB below the %.1f dB at 1 kHz", f, d, centre)
+		}
+	}
+}
+
+func TestNoiseShaper_Breath(t *testing.T) {
+	white := colouredNoise(audio.NoiseParams{}, 4)
+	noise := colouredNoise(audio.NoiseParams{Color: audio.NoiseBreath}, 4)
+	throat := densityDB(noise, white, 1500)
+	for _, tt := range []struct {
+		freq  float64
+		below float64 // dB
+	}{
+		{100, 25}, // under the high-pass filter
+		{12000, 15},
+	} {
+		if d := densityDB(noise, white, tt.freq); d > throat-tt.below {
+			t.Errorf("density at %v Hz = %.1f dB, want at least %v dB below the %.1f dB at 1.5 kHz", tt.freq, d, tt.below, throat)
+		}
+	}
+}
+
+func TestNoiseShaper_AsLoudAsWhite(t *testing.T) {
+	rms := func(s []float64) float64 {
+		var sum float64
+		for _, v := range s {
+			sum += v * v
+		}
+		return math.Sqrt(sum / float64(len(s)))
+	}
+	white := rms(colouredNoise(audio.NoiseParams{}, 4))
+	for _, n := range []audio.NoiseParams{
+		{Color: audio.NoisePink},
+		{Color: audio.NoiseBrown},
+		{Color: audio.NoiseBlue},
+		{Color: audio.NoiseBand, Freq: 1000},
+		{Color: audio.NoiseBand, Freq: 3000, Q: 8},
+		{Color: audio.NoiseBreath},
+	} {
+		if got := rms(colouredNoise(n, 4)); math.Abs(20*math.Log10(got/white)) > 1 {
+			t.Errorf("RMS of %+v = %.3f, want within 1 dB of white noise's %.3f", n, got, white)
+		}
+	}
+}
+
+func TestNoiseShaper_WhiteIsUnchanged(t *testing.T) {
+	for _, c := range []audio.NoiseColor{"", audio.NoiseWhite} {
+		s := newNoiseShaper(audio.NoiseParams{Color: c}, 48000)
+		for i, in := range []float64{1, -0.5, 0.25, 0} {
+			if got := s.shape(in); got != in {
+				t.Errorf("%q sample %d = %v, want %v", c, i, got, in)
+			}
+		}
+	}
+}
+
+func TestGenerator_NoiseColor(t *testing.T) {
+	params := toneParams(440)
+	params.Layers[0] = audio.LayerParams{Type: audio.LayerBackgroundNoise, Amplitude: 0.2}
+	white := renderPCM(t, params)
+	params.Layers[0].Noise.Color = audio.NoiseBrown
+	brown := renderPCM(t, params)
+
+	// Brown noise changes far more slowly from sample to sample.
+	if w, b := roughness(white), roughness(brown); b > w/10 {
+		t.Errorf("roughness of brown noise = %v, want under a tenth of white noise's %v", b, w)
+	}
+}
+
+// roughness returns the mean squared difference between neighbouring mono
+// s16le samples of pcm.
+func roughness(pcm []byte) float64 {
+	samples := make([]float64, len(pcm)/2)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+	}
+	var sum float64
+	for i := 1; i < len(samples); i++ {
+		d := samples[i] - samples[i-1]
+		sum += d * d
+	}
+	return sum / float64(len(samples)-1)
+}
@@ -24,14 +24,87 @@ func (o *oscillator) sin(freq float64) float64 {
 	return sample
 }
 
-// saw generates a sawtooth wave sample at the given frequency and advances the phase.
+// saw generates a band-limited sawtooth wave sample at the given frequency
+// and advances the phase. The wave rises from -1 to 1 over each cycle, its
+// drop back smoothed by polyBLEP.
 func (o *oscillator) saw(freq float64) float64 {
-	sample := 2*o.pos - 1 // Maps [0,1) to [-1,1)
-	o.pos += freq / o.sampleRate
+	dt := freq / o.sampleRate
+	sample := 2*o.pos - 1 - polyBLEP(o.pos, dt)
+	o.advance(dt)
+	return sample
+}
+
+// pulse generates a band-limited pulse wave sample at the given frequency
+// and advances the phase. The wave is 1 for the first width of each cycle and
+// -1 for the rest, its steps smoothed by polyBLEP; a width of 0.5 gives a
+// square wave.
+func (o *oscillator) pulse(freq, width float64) float64 {
+	dt := freq / o.sampleRate
+	sample := -1.0
+	if o.pos < width {
+		sample = 1
+	}
+	sample += polyBLEP(o.pos, dt) - polyBLEP(wrapPhase(o.pos-width), dt)
+	o.advance(dt)
+	return sample
+}
+
+// triangle generates a band-limited triangle wave sample at the given
+// frequency and advances the phase. Like sin, the wave starts at 0 rising,
+// peaks at a quarter of each cycle and bottoms out at three quarters; its
+// corners are smoothed by polyBLAMP.
+func (o *oscillator) triangle(freq float64) float64 {
+	dt := freq / o.sampleRate
+	sample := 4*math.Abs(wrapPhase(o.pos+0.75)-0.5) - 1
+	// The slope changes by 8 per cycle at each corner.
+	sample += 4 * dt * (polyBLAMP(wrapPhase(o.pos-0.75), dt) - polyBLAMP(wrapPhase(o.pos-0.25), dt))
+	o.advance(dt)
+	return sample
+}
+
+// advance moves the phase on by dt cycles, keeping it in [0, 1).
+func (o *oscillator) advance(dt float64) {
+	o.pos += dt
 	if o.pos >= 1.0 {
 		o.pos -= 1.0
 	}
-	return sample
+}
+
+// polyBLEP returns the correction that turns a naive upward step of 2 at
+// phase 0 into a band-limited one, for a wave at phase t advancing dt cycles
+// per sample. It is nonzero only within one sample either side of the step.
+func polyBLEP(t, dt float64) float64 {
+	switch {
+	case t < dt:
+		x := t / dt
+		return 2*x - x*x - 1
+	case t > 1-dt:
+		x := (t - 1) / dt
+		return x*x + 2*x + 1
+	default:
+		return 0
+	}
+}
+
+// polyBLAMP returns the correction that turns a naive corner at phase 0,
+// where the slope rises by 2 per sample, into a band-limited one, for a wave
+// at phase t advancing dt cycles per sample. It is the integral of polyBLEP.
+func polyBLAMP(t, dt float64) float64 {
+	switch {
+	case t < dt:
+		x := 1 - t/dt
+		return x * x * x / 3
+	case t > 1-dt:
+		x := (t-1)/dt + 1
+		return x * x * x / 3
+	default:
+		return 0
+	}
+}
+
+// wrapPhase returns p wrapped into [0, 1).
+func wrapPhase(p float64) float64 {
+	return p - math.Floor(p)
 }
 
 // phase returns the current oscillator phase [0, 1).
@@ -179,3 +179,106 @@ func TestOscillator_Sin_KnownValues(t *testing.T) {
 		t.Errorf("sample 3: got %f, want -1", s3)
 	}
 }
+
+// aliasedPower returns the fraction of the power of one second of wave, at a
+// whole number freq Hz and sampleRate, that lies off the harmonics of freq
+// below the Nyquist frequency: the power folded back by aliasing.
+func aliasedPower(wave func() float64, freq, sampleRate int) float64 {
+	x := make([]float64, sampleRate)
+	var total, mean float64
+	for i := range x {
+		x[i] = wave()
+		mean += x[i] / float64(len(x))
+	}
+	for i := range x {
+		x[i] -= mean
+		total += x[i] * x[i]
+	}
+
+	// With one second of samples, each harmonic falls exactly on a DFT bin.
+	var harmonic float64
+	for f := freq; 2*f < sampleRate; f += freq {
+		var re, im float64
+		for i, v := range x {
+			phase := 2 * math.Pi * float64(f) * float64(i) / float64(sampleRate)
+			re += v * math.Cos(phase)
+			im += v * math.Sin(phase)
+		}
+		harmonic += 2 * (re*re + im*im) / float64(len(x))
+	}
+	return 1 - harmonic/total
+}
+
+func TestOscillator_BandLimited(t *testing.T) {
+	const (
+		sampleRate = 48000
+		freq       = 3111 // high enough that a naive wave aliases badly
+	)
+	tests := []struct {
+		name  string
+		wave  func(o *oscillator) float64
+		naive func(phase float64) float64
+	}{
+		{
+			"saw",
+			func(o *oscillator) float64 { return o.saw(freq) },
+			func(p float64) float64 { return 2*p - 1 },
+		},
+		{
+			"pulse",
+			func(o *oscillator) float64 { return o.pulse(freq, 0.3) },
+			func(p float64) float64 {
+				if p < 0.3 {
+					return 1
+				}
+				return -1
+			},
+		},
+		{
+			"triangle",
+			func(o *oscillator) float64 { return o.triangle(freq) },
+			func(p float64) float64 { return 4*math.Abs(wrapPhase(p+0.75)-0.5) - 1 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			osc := newOscillator(sampleRate)
+			got := aliasedPower(func() float64 { return tt.wave(osc) }, freq, sampleRate)
+
+			ref := newOscillator(sampleRate)
+			want := aliasedPower(func() float64 {
+				s := tt.naive(ref.phase())
+				ref.advance(float64(freq) / sampleRate)
+				return s
+			}, freq, sampleRate)
+
+			if got > want/10 {
+				t.Errorf("aliased power = %.5f, want well below the naive wave's %.5f", got, want)
+			}
+		})
+	}
+}
+
+func TestOscillator_WaveShapes(t *testing.T) {
+	// At sampleRate=8, freq=1, each sample advances phase by 0.125; the
+	// corrections only touch samples within one step of a jump or corner.
+	tests := []struct {
+		name string
+		wave func(o *oscillator) float64
+		want []float64
+	}{
+		{"saw", func(o *oscillator) float64 { return o.saw(1) }, []float64{0, -0.75, -0.5, -0.25, 0, 0.25, 0.5, 0.75}},
+		{"square", func(o *oscillator) float64 { return o.pulse(1, 0.5) }, []float64{0, 1, 1, 1, 0, -1, -1, -1}},
+		{"triangle", func(o *oscillator) float64 { return o.triangle(1) }, []float64{0, 0.5, 1 - 1.0/6, 0.5, 0, -0.5, -1 + 1.0/6, -0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			osc := newOscillator(8)
+			for i, want := range tt.want {
+				if got := tt.wave(osc); math.Abs(got-want) > 1e-12 {
+					t.Errorf("sample %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package native
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// forEach calls fn for every index in [0, n) on up to workers goroutines at
+// once, returning once every call has. With a single worker, or a single
+// index, fn is called for each index in turn on the calling goroutine.
+func forEach(n, workers int, fn func(i int)) {
+	if workers <= 1 || n <= 1 {
+		for i := range n {
+			fn(i)
+		}
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= n {
+					return
+				}
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// growBlocks returns count buffers of n samples each, reusing those of bufs
+// where they are big enough. The samples are not cleared.
+func growBlocks(bufs [][]float64, count, n int) [][]float64 {
+	for len(bufs) < count {
+		bufs = append(bufs, nil)
+	}
+	for i := range bufs[:count] {
+		if cap(bufs[i]) < n {
+			bufs[i] = make([]float64, n)
+		}
+		bufs[i] = bufs[i][:n]
+	}
+	return bufs[:count]
+}
+
+// liveFrames returns how many of the n frames from frame start fall before
+// end seconds, in the same arithmetic as the time of each frame.
+func liveFrames(start, n, sampleRate int, end float64) int {
+	live := 0
+	for live < n && float64(start+live)/float64(sampleRate) < end {
+		live++
+	}
+	return live
+}
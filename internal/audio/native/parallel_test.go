@@ -0,0 +1,157 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+func TestForEach_CallsEveryIndexOnce(t *testing.T) {
+	for _, workers := range []int{0, 1, 3, 16} {
+		var calls [10]atomic.Int32
+		forEach(len(calls), workers, func(i int) {
+			calls[i].Add(1)
+		})
+		for i := range calls {
+			if got := calls[i].Load(); got != 1 {
+				t.Errorf("workers %d: index %d called %d times, want 1", workers, i, got)
+			}
+		}
+	}
+}
+
+func TestGrowBlocks(t *testing.T) {
+	bufs := growBlocks(nil, 3, 10)
+	if len(bufs) != 3 || len(bufs[2]) != 10 {
+		t.Fatalf("growBlocks(nil, 3, 10) = %d buffers of %d, want 3 of 10", len(bufs), len(bufs[2]))
+	}
+	first := &bufs[0][0]
+	bufs = growBlocks(bufs, 3, 4)
+	if len(bufs[0]) != 4 || &bufs[0][0] != first {
+		t.Error("shrinking the buffers reallocated them, want them reused")
+	}
+}
+
+// parallelCases returns the params the parallel path is checked against the
+// serial one for: every preset in mono and stereo, a morph, and a crowd.
+func parallelCases() map[string]audio.ScreamParams {
+	cases := make(map[string]audio.ScreamParams)
+	for _, name := range audio.AllPresets() {
+		params, _ := audio.GetPreset(name)
+		params.Duration = 500 * time.Millisecond
+		cases[string(name)+"/stereo"] = params
+		params.Channels = 1
+		cases[string(name)+"/mono"] = params
+	}
+
+	from, _ := audio.GetPreset(audio.PresetWhisper)
+	to, _ := audio.GetPreset(audio.PresetDeathMetal)
+	from.Duration = 700 * time.Millisecond
+	from.MorphTo = &to
+	cases["morph"] = from
+
+	crowd, _ := audio.GetPreset(audio.PresetClassic)
+	banshee, _ := audio.GetPreset(audio.PresetBanshee)
+	crowd.Duration = 700 * time.Millisecond
+	crowd.Voices = 5
+	crowd.Spread = 1
+	crowd.VoiceMix = []audio.ScreamParams{banshee}
+	crowd.Filter.Limiter = audio.LimiterLookahead
+	cases["crowd"] = crowd
+	return cases
+}
+
+func TestGenerator_ParallelMatchesSerial(t *testing.T) {
+	for name, params := range parallelCases() {
+		t.Run(name, func(t *testing.T) {
+			want := renderBuffered(params)
+			for _, workers := range []int{2, 3, 8} {
+				reader, err := NewGenerator(discardLogger).WithWorkers(workers).Generate(context.Background(), params)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				got, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("ReadAll() error = %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("%d workers: PCM differs from the serial path", workers)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultWorkers(t *testing.T) {
+	classic, _ := audio.GetPreset(audio.PresetClassic)
+	if got := defaultWorkers(classic); got != 1 {
+		t.Errorf("defaultWorkers(classic) = %d, want 1", got)
+	}
+	crowd := classic
+	crowd.Voices = 4
+	if got, want := defaultWorkers(crowd), max(2, runtime.GOMAXPROCS(0)); got != want {
+		t.Errorf("defaultWorkers(crowd) = %d, want %d", got, want)
+	}
+}
+
+func TestGenerator_WithWorkers(t *testing.T) {
+	gen := NewGenerator(discardLogger)
+	serial := gen.WithWorkers(0)
+	if serial.workers != 1 {
+		t.Errorf("WithWorkers(0).workers = %d, want 1", serial.workers)
+	}
+	if gen.WithWorkers(4).workers != 4 || serial == gen {
+		t.Error("WithWorkers() did not return a copy with the worker count")
+	}
+}
+
+// --- Benchmarks ---
+
+// benchmarkGenerate renders params with the given number of workers, reporting
+// throughput in bytes of PCM per second. The parallel benchmarks use at least
+// two workers, so that they render in blocks even on a single CPU.
+func benchmarkGenerate(b *testing.B, params audio.ScreamParams, workers int) {
+	gen := NewGenerator(discardLogger).WithWorkers(workers)
+	b.SetBytes(int64((params.Duration+params.Tail()).Seconds()*float64(params.SampleRate)) * int64(params.Channels) * 2)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reader, err := gen.Generate(context.Background(), params)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, reader); err != nil {
+			b.Fatalf("io.Copy failed: %v", err)
+		}
+	}
+}
+
+func benchmarkCrowd() audio.ScreamParams {
+	params, _ := audio.GetPreset(audio.PresetClassic)
+	params.Voices = 8
+	return params
+}
+
+func BenchmarkGenerator_ClassicSerial(b *testing.B) {
+	params, _ := audio.GetPreset(audio.PresetClassic)
+	benchmarkGenerate(b, params, 1)
+}
+
+func BenchmarkGenerator_ClassicParallel(b *testing.B) {
+	params, _ := audio.GetPreset(audio.PresetClassic)
+	benchmarkGenerate(b, params, max(2, runtime.GOMAXPROCS(0)))
+}
+
+func BenchmarkGenerator_CrowdSerial(b *testing.B) {
+	benchmarkGenerate(b, benchmarkCrowd(), 1)
+}
+
+func BenchmarkGenerator_CrowdParallel(b *testing.B) {
+	benchmarkGenerate(b, benchmarkCrowd(), max(2, runtime.GOMAXPROCS(0)))
+}
@@ -0,0 +1,142 @@
+package native
+
+import (
+	"math"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// Freeverb's series allpass filters, in samples at 44.1 kHz, and their
+// feedback.
+var reverbAllpassDelays = [...]float64{556, 441, 341, 225}
+
+const reverbAllpassFeedback = 0.5
+
+// reverbMaxPreDelay is the longest pre-delay in seconds that
+// audio.ScreamParams.Validate accepts.
+const reverbMaxPreDelay = 0.5
+
+// Freeverb scales its damping setting down so that even full damping leaves
+// some brightness in the tail.
+const reverbDampingScale = 0.4
+
+// reverbWetGain scales the sum of the comb filters so that the reverberated
+// signal is about as loud as the dry one. Combs with more feedback ring
+// louder, so it is further scaled by the fourth root of 1-g^2 for a feedback
+// of g, which keeps rooms of every size about equally loud.
+const reverbWetGain = 0.063
+
+// reverb is a Freeverb-style room simulation: the input, after a pre-delay,
+// feeds parallel comb filters whose damped feedback makes the decaying tail,
+// then series allpass filters that diffuse it. The result is mixed with the
+// dry input. Its delay lines are allocated the first time it is heard, so
+// that a chain without reverb costs nothing.
+type reverb struct {
+	mix, room, damping float64
+	preDelay           int // samples
+	sampleRate         int
+	wetGain            float64
+
+	delay     []float64 // pre-delay line, long enough for reverbMaxPreDelay
+	pos       int
+	combs     [len(audio.ReverbCombDelays)]comb
+	allpasses [len(reverbAllpassDelays)]allpass
+}
+
+// newReverb creates a reverb with the reverb settings of fp.
+func newReverb(fp audio.FilterParams, sampleRate int) *reverb {
+	r := &reverb{}
+	r.tune(fp, sampleRate)
+	return r
+}
+
+// tune implements tunableFilter, keeping the tail already ringing.
+func (r *reverb) tune(fp audio.FilterParams, sampleRate int) {
+	r.mix = fp.ReverbMix
+	r.room = fp.ReverbRoom
+	r.damping = fp.ReverbDamping
+	r.preDelay = int(fp.ReverbPreDelay / 1000 * float64(sampleRate))
+	r.sampleRate = sampleRate
+	if r.delay != nil {
+		r.setCombs()
+	}
+}
+
+// init allocates the delay lines.
+func (r *reverb) init() {
+	r.delay = make([]float64, int(reverbMaxPreDelay*float64(r.sampleRate))+1)
+	for i, d := range audio.ReverbCombDelays {
+		r.combs[i].buf = make([]float64, max(1, int(d*float64(r.sampleRate))))
+	}
+	for i, d := range reverbAllpassDelays {
+		r.allpasses[i].buf = make([]float64, max(1, int(d*float64(r.sampleRate)/44100)))
+	}
+	r.setCombs()
+}
+
+// setCombs sets the feedback and damping of the comb filters, and the gain
+// of their sum.
+func (r *reverb) setCombs() {
+	g := audio.ReverbFeedback(r.room)
+	for i := range r.combs {
+		r.combs[i].feedback = g
+		r.combs[i].damp = r.damping * reverbDampingScale
+	}
+	r.wetGain = reverbWetGain * math.Pow(1-g*g, 0.25)
+}
+
+// Process applies the reverb to a single sample.
+func (r *reverb) Process(sample float64) float64 {
+	if r.mix == 0 {
+		return sample
+	}
+	if r.delay == nil {
+		r.init()
+	}
+
+	r.delay[r.pos] = sample
+	in := r.delay[(r.pos-min(r.preDelay, len(r.delay)-1)+len(r.delay))%len(r.delay)]
+	r.pos = (r.pos + 1) % len(r.delay)
+
+	var wet float64
+	for i := range r.combs {
+		wet += r.combs[i].process(in)
+	}
+	wet *= r.wetGain
+	for i := range r.allpasses {
+		wet = r.allpasses[i].process(wet)
+	}
+	return (1-r.mix)*sample + r.mix*wet
+}
+
+// comb is a feedback comb filter with a one-pole low-pass filter in its
+// feedback path, which damps high frequencies more on every echo.
+type comb struct {
+	buf      []float64
+	pos      int
+	feedback float64
+	damp     float64
+	store    float64 // state of the damping filter
+}
+
+func (c *comb) process(in float64) float64 {
+	out := c.buf[c.pos]
+	c.store = out*(1-c.damp) + c.store*c.damp
+	c.buf[c.pos] = in + c.store*c.feedback
+	c.pos = (c.pos + 1) % len(c.buf)
+	return out
+}
+
+// allpass is Freeverb's approximation of an allpass filter, which smears
+// echoes in time without much changing their spectrum.
+type allpass struct {
+	buf []float64
+	pos int
+}
+
+func (a *allpass) process(in float64) float64 {
+	delayed := a.buf[a.pos]
+	a.buf[a.pos] = in + delayed*reverbAllpassFeedback
+	a.pos = (a.pos + 1) % len(a.buf)
+	return delayed - in
+}
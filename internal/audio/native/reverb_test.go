@@ -0,0 +1,147 @@
+package native
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// rms returns the RMS level of the mono s16le samples of pcm between from
+// and to seconds, as a fraction of full scale.
+func rms(pcm []byte, sampleRate int, from, to float64) float64 {
+	lo, hi := int(from*float64(sampleRate)), min(len(pcm)/2, int(to*float64(sampleRate)))
+	var sum float64
+	for i := lo; i < hi; i++ {
+		s := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:]))) / 32767
+		sum += s * s
+	}
+	return math.Sqrt(sum / float64(hi-lo))
+}
+
+func TestReverb_ZeroMixIsTransparent(t *testing.T) {
+	r := newReverb(audio.FilterParams{ReverbRoom: 1, ReverbPreDelay: 100}, 48000)
+	for i, in := range []float64{1, -0.5, 0.25, 0} {
+		if got := r.Process(in); got != in {
+			t.Errorf("sample %d = %v, want %v", i, got, in)
+		}
+	}
+	if r.delay != nil {
+		t.Error("a reverb with no mix allocated its delay lines")
+	}
+}
+
+func TestReverb_PreDelay(t *testing.T) {
+	const sampleRate = 48000
+	r := newReverb(audio.FilterParams{ReverbMix: 1, ReverbRoom: 0.5, ReverbPreDelay: 20}, sampleRate)
+
+	// Nothing can come out before the pre-delay and the shortest comb.
+	quiet := int((0.020 + audio.ReverbCombDelays[0]) * sampleRate)
+	out := r.Process(1)
+	for i := 1; i < quiet; i++ {
+		out = math.Max(out, math.Abs(r.Process(0)))
+	}
+	if out != 0 {
+		t.Errorf("output before the first echo peaks at %v, want 0", out)
+	}
+
+	var peak float64
+	for range sampleRate / 10 {
+		peak = math.Max(peak, math.Abs(r.Process(0)))
+	}
+	if peak == 0 {
+		t.Error("reverb produced no echoes of an impulse")
+	}
+}
+
+func TestReverb_DecaysByReverbTime(t *testing.T) {
+	const sampleRate = 48000
+	for _, room := range []float64{0, 0.5, 1} {
+		fp := audio.FilterParams{ReverbMix: 1, ReverbRoom: room}
+		r := newReverb(fp, sampleRate)
+		r.Process(1)
+
+		// Compare the level of the echoes early on with that around the
+		// reverb time, which should be about 60 dB quieter.
+		rt := int(fp.ReverbTime() * sampleRate)
+		window := int(audio.ReverbCombDelays[len(audio.ReverbCombDelays)-1]*sampleRate) * 2
+		var early, late float64
+		for i := 1; i < rt+window; i++ {
+			out := r.Process(0)
+			switch {
+			case i < window:
+				early += out * out
+			case i >= rt-window:
+				late += out * out
+			}
+		}
+		if db := 10 * math.Log10(late/early); db > -45 || db < -70 {
+			t.Errorf("room %v: decay after the reverb time = %.1f dB, want about -60 dB", room, db)
+		}
+	}
+}
+
+func TestReverb_DampingDarkensTail(t *testing.T) {
+	// The tail of a Nyquist-rate input changes sign every sample; damping
+	// smooths it out.
+	roughness := func(damping float64) float64 {
+		r := newReverb(audio.FilterParams{ReverbMix: 1, ReverbRoom: 0.8, ReverbDamping: damping}, 48000)
+		for i := range 480 {
+			r.Process(float64(1 - 2*(i%2)))
+		}
+		var diff, level float64
+		prev := r.Process(0)
+		for range 24000 {
+			out := r.Process(0)
+			diff += math.Abs(out - prev)
+			level += math.Abs(out)
+			prev = out
+		}
+		return diff / level
+	}
+	if bright, dark := roughness(0), roughness(1); dark >= bright {
+		t.Errorf("tail roughness with full damping = %v, want less than %v without", dark, bright)
+	}
+}
+
+func TestGenerator_ReverbTail(t *testing.T) {
+	params := toneParams(440)
+	params.Filter.ReverbMix = 0.3
+	params.Filter.ReverbRoom = 0.5
+	pcm := renderPCM(t, params)
+
+	length := (params.Duration + params.Tail()).Seconds()
+	if want := int(length*float64(params.SampleRate)) * 2; len(pcm) != want {
+		t.Fatalf("byte count = %d, want %d", len(pcm), want)
+	}
+
+	// The tone stops at 1s; the reverb rings on after it and dies away by
+	// the end of the tail.
+	end := params.Duration.Seconds()
+	if level := rms(pcm, params.SampleRate, end+0.05, end+0.15); level < 0.01 {
+		t.Errorf("RMS just after the scream = %v, want the reverb to ring on", level)
+	}
+	if level := rms(pcm, params.SampleRate, length-0.1, length); level > 0.001 {
+		t.Errorf("RMS at the end of the tail = %v, want near silence", level)
+	}
+}
+
+func TestGenerator_MorphIntoReverb(t *testing.T) {
+	params := toneParams(440)
+	to := params.Clone()
+	to.Filter.ReverbMix = 0.5
+	to.Filter.ReverbRoom = 0.5
+	params.MorphTo = &to
+	pcm := renderPCM(t, params)
+
+	// The tail is that of the end of the morph, where the reverb is fully in.
+	length := (params.Duration + to.Filter.ReverbTail()).Seconds()
+	if want := int(length*float64(params.SampleRate)) * 2; len(pcm) != want {
+		t.Fatalf("byte count = %d, want %d", len(pcm), want)
+	}
+	end := params.Duration.Seconds()
+	if level := rms(pcm, params.SampleRate, end+0.05, end+0.15); level < 0.01 {
+		t.Errorf("RMS just after the scream = %v, want the reverb to ring on", level)
+	}
+}
@@ -0,0 +1,222 @@
+package native
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// rightNoiseSeedXOR is XORed into a noise layer's seed to produce the seed of
+// its independent right-channel noise source.
+const rightNoiseSeedXOR int64 = 0x3c3c3c3c3c3c3c3c
+
+// coprimePanPhase decorrelates the starting phase of each layer's auto-pan
+// LFO so that layers do not sweep across the field in lockstep.
+const coprimePanPhase int64 = 211
+
+// stereoSource is implemented by layers that render distinct left and right
+// samples themselves rather than being panned from a mono sample.
+type stereoSource interface {
+	layer
+	SampleStereo(t float64) (l, r float64)
+}
+
+// panner positions a mono layer in the stereo field using a balance pan law:
+// the nearer channel stays at unity gain and the farther one is attenuated
+// linearly, so a centered layer is unchanged in both channels.
+type panner struct {
+	pan   float64
+	depth float64
+	rate  float64
+	phase float64
+}
+
+// newPanner creates a panner for a layer. width scales both the static
+// position and the auto-pan depth; phase offsets the auto-pan LFO in cycles.
+func newPanner(p audio.LayerParams, width, phase float64) panner {
+	return panner{
+		pan:   p.Pan * width,
+		depth: p.PanDepth * width,
+		rate:  p.PanRate,
+		phase: phase,
+	}
+}
+
+// tune changes the pan position, auto-pan depth and rate to those of p at the
+// given width, keeping the LFO phase offset.
+func (p *panner) tune(lp audio.LayerParams, width float64) {
+	p.pan = lp.Pan * width
+	p.depth = lp.PanDepth * width
+	p.rate = lp.PanRate
+}
+
+// position returns the pan position in [-1, 1] at time t.
+func (p panner) position(t float64) float64 {
+	pos := p.pan
+	if p.rate > 0 && p.depth > 0 {
+		pos += p.depth * math.Sin(2*math.Pi*(p.rate*t+p.phase))
+	}
+	return clamp(pos, -1, 1)
+}
+
+// gains returns the left and right channel gains at time t.
+func (p panner) gains(t float64) (gl, gr float64) {
+	pos := p.position(t)
+	return math.Min(1, 1-pos), math.Min(1, 1+pos)
+}
+
+// stereoNoise derives right-channel noise from a layer's left-channel noise.
+// The right sample blends the left sample with an independent source so that
+// correlation falls from 1 at zero width to 0 at full width while the noise
+// power stays constant. The zero value copies the left channel unchanged.
+type stereoNoise struct {
+	rng  *rand.Rand
+	same float64 // weight of the left-channel noise
+	diff float64 // weight of the independent noise
+}
+
+// newStereoNoise creates a stereoNoise with an independent source seeded
+// from seed. A zero width returns the zero value, which draws no extra noise.
+func newStereoNoise(seed int64, width float64) stereoNoise {
+	if width <= 0 {
+		return stereoNoise{}
+	}
+	same := 1 - width
+	return stereoNoise{
+		rng:  rand.New(rand.NewSource(seed ^ rightNoiseSeedXOR)),
+		same: same,
+		diff: math.Sqrt(1 - same*same),
+	}
+}
+
+// setWidth changes the decorrelation to that of width, keeping the
+// independent source if there is one and creating it from seed otherwise.
+func (n *stereoNoise) setWidth(seed int64, width float64) {
+	if width <= 0 {
+		n.same, n.diff = 1, 0
+		return
+	}
+	if n.rng == nil {
+		*n = newStereoNoise(seed, width)
+		return
+	}
+	n.same = 1 - width
+	n.diff = math.Sqrt(1 - n.same*n.same)
+}
+
+// right returns the right-channel noise for the given left-channel noise
+// sample in [-1, 1].
+func (n *stereoNoise) right(left float64) float64 {
+	if n.rng == nil {
+		return left
+	}
+	return n.same*left + n.diff*(2*n.rng.Float64()-1)
+}
+
+// stereoMixer mixes layers into separate left and right channels, each
+// clamped to [-1, 1].
+type stereoMixer struct {
+	layers   []layer
+	panners  []panner
+	contours []levelContour // by layer; layers without one are unchanged
+	master   levelContour   // the envelope of the whole mix
+	lefts    [][]float64    // by layer; scratch for SampleBlock
+	rights   [][]float64
+}
+
+// newStereoMixer creates a mixer in which layers[i] is positioned by panners[i].
+func newStereoMixer(layers []layer, panners []panner) *stereoMixer {
+	return &stereoMixer{layers: layers, panners: panners}
+}
+
+// Sample returns the left and right mix at time t. As in layerMixer.Sample,
+// each scaled sample is rounded before it is added.
+func (m *stereoMixer) Sample(t float64) (l, r float64) {
+	for i := range m.layers {
+		sl, sr := m.layerSample(i, t)
+		l += float64(sl)
+		r += float64(sr)
+	}
+	g := m.master.gain(t)
+	return clamp(g*l, -1, 1), clamp(g*r, -1, 1)
+}
+
+// SampleBlock fills l and r with the samples Sample would return, one after
+// another, for the frames from frame start at sampleRate. As in
+// layerMixer.SampleBlock, the layers are rendered on up to workers
+// goroutines and summed in order, so l and r are the same whatever workers.
+func (m *stereoMixer) SampleBlock(l, r []float64, start, sampleRate, workers int) {
+	m.lefts = growBlocks(m.lefts, len(m.layers), len(l))
+	m.rights = growBlocks(m.rights, len(m.layers), len(r))
+	forEach(len(m.layers), workers, func(i int) {
+		lb, rb := m.lefts[i], m.rights[i]
+		for k := range lb {
+			lb[k], rb[k] = m.layerSample(i, float64(start+k)/float64(sampleRate))
+		}
+	})
+	for k := range l {
+		var sl, sr float64
+		for i := range m.layers {
+			sl += m.lefts[i][k]
+			sr += m.rights[i][k]
+		}
+		g := m.master.gain(float64(start+k) / float64(sampleRate))
+		l[k], r[k] = clamp(g*sl, -1, 1), clamp(g*sr, -1, 1)
+	}
+}
+
+// layerSample returns the left and right samples of layer i at time t,
+// panned and scaled by its level contour.
+func (m *stereoMixer) layerSample(i int, t float64) (l, r float64) {
+	var sl, sr float64
+	if src, ok := m.layers[i].(stereoSource); ok {
+		sl, sr = src.SampleStereo(t)
+	} else {
+		s := m.layers[i].Sample(t)
+		sl, sr = s, s
+	}
+	gl, gr := m.panners[i].gains(t)
+	g := contourGain(m.contours, i, t)
+	return g * gl * sl, g * gr * sr
+}
+
+// tune retunes the mixer's layers, panners and noise decorrelation to params,
+// which must have the same layers as the params the mixer was built from.
+func (m *stereoMixer) tune(params audio.ScreamParams) {
+	tuneLayers(m.layers, params)
+	tuneContours(m.contours, params)
+	m.master.tune(nil, params.Envelope)
+	for i, lay := range m.layers {
+		switch nl := lay.(type) {
+		case *noiseBurstLayer:
+			nl.stereo.setWidth(nl.burstSeed, params.Width)
+		case *backgroundNoiseLayer:
+			nl.stereo.setWidth(nl.seed, params.Width)
+		}
+		m.panners[i].tune(params.Layers[i], params.Width)
+	}
+}
+
+// buildStereoMixer creates the synthesis layers for params and positions
+// them according to their pan settings and params.Width. Noise layers are
+// given decorrelated right channels.
+func buildStereoMixer(params audio.ScreamParams, sampleRate int) *stereoMixer {
+	layers := buildLayers(params, sampleRate)
+	panners := make([]panner, len(layers))
+	for i, lay := range layers {
+		switch nl := lay.(type) {
+		case *noiseBurstLayer:
+			nl.stereo = newStereoNoise(nl.burstSeed, params.Width)
+		case *backgroundNoiseLayer:
+			nl.stereo = newStereoNoise(nl.seed, params.Width)
+		}
+		lp := params.Layers[i]
+		phase := seededRandom(lp.Seed^params.Seed, int64(i), coprimePanPhase)
+		panners[i] = newPanner(lp, params.Width, phase)
+	}
+	m := newStereoMixer(layers, panners)
+	m.contours = buildContours(params)
+	m.master = newLevelContour(nil, params.Envelope, params.Duration.Seconds())
+	return m
+}
@@ -0,0 +1,291 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// constLayer is a layer that always returns the same sample.
+type constLayer float64
+
+func (c constLayer) Sample(float64) float64 { return float64(c) }
+
+// renderStereo generates params and splits the interleaved s16le output into
+// left and right channels.
+func renderStereo(t *testing.T, params audio.ScreamParams) (left, right []float64) {
+	t.Helper()
+	reader, err := NewGenerator(discardLogger).Generate(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	for i := 0; i+3 < len(data); i += 4 {
+		left = append(left, float64(int16(binary.LittleEndian.Uint16(data[i:]))))
+		right = append(right, float64(int16(binary.LittleEndian.Uint16(data[i+2:]))))
+	}
+	return left, right
+}
+
+// correlation returns the Pearson correlation of a and b.
+func correlation(a, b []float64) float64 {
+	var ma, mb float64
+	for i := range a {
+		ma += a[i]
+		mb += b[i]
+	}
+	ma /= float64(len(a))
+	mb /= float64(len(b))
+	var cov, va, vb float64
+	for i := range a {
+		da, db := a[i]-ma, b[i]-mb
+		cov += da * db
+		va += da * da
+		vb += db * db
+	}
+	return cov / math.Sqrt(va*vb)
+}
+
+func energy(x []float64) float64 {
+	var e float64
+	for _, v := range x {
+		e += v * v
+	}
+	return e
+}
+
+// ---------------------------------------------------------------------------
+// panner tests
+// ---------------------------------------------------------------------------
+
+func TestPanner_Gains(t *testing.T) {
+	tests := []struct {
+		name   string
+		pan    float64
+		width  float64
+		wantGL float64
+		wantGR float64
+	}{
+		{"center", 0, 1, 1, 1},
+		{"hard left", -1, 1, 1, 0},
+		{"hard right", 1, 1, 0, 1},
+		{"half right", 0.5, 1, 0.5, 1},
+		{"width scales pan", -1, 0.5, 1, 0.5},
+		{"zero width centers", 1, 0, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPanner(audio.LayerParams{Pan: tt.pan}, tt.width, 0)
+			gl, gr := p.gains(0.7)
+			if math.Abs(gl-tt.wantGL) > 1e-12 || math.Abs(gr-tt.wantGR) > 1e-12 {
+				t.Errorf("gains() = (%v, %v), want (%v, %v)", gl, gr, tt.wantGL, tt.wantGR)
+			}
+		})
+	}
+}
+
+func TestPanner_AutoPanSweeps(t *testing.T) {
+	p := newPanner(audio.LayerParams{PanRate: 1, PanDepth: 1}, 1, 0)
+
+	// A 1 Hz LFO starting at phase 0 is hard right at 0.25s and hard left at 0.75s.
+	if pos := p.position(0.25); math.Abs(pos-1) > 1e-9 {
+		t.Errorf("position(0.25) = %v, want 1", pos)
+	}
+	if pos := p.position(0.75); math.Abs(pos+1) > 1e-9 {
+		t.Errorf("position(0.75) = %v, want -1", pos)
+	}
+}
+
+func TestPanner_AutoPanClamped(t *testing.T) {
+	p := newPanner(audio.LayerParams{Pan: 0.8, PanRate: 1, PanDepth: 1}, 1, 0)
+	for i := 0; i < 100; i++ {
+		if pos := p.position(float64(i) / 100); pos < -1 || pos > 1 {
+			t.Fatalf("position(%v) = %v, want within [-1, 1]", float64(i)/100, pos)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// stereoNoise tests
+// ---------------------------------------------------------------------------
+
+func TestStereoNoise_ZeroWidthCopiesLeft(t *testing.T) {
+	n := newStereoNoise(42, 0)
+	for _, v := range []float64{-1, -0.3, 0, 0.7, 1} {
+		if got := n.right(v); got != v {
+			t.Errorf("right(%v) = %v, want unchanged", v, got)
+		}
+	}
+}
+
+func TestStereoNoise_Correlation(t *testing.T) {
+	tests := []struct {
+		width   float64
+		wantMin float64
+		wantMax float64
+	}{
+		{0.25, 0.65, 0.85},
+		{0.5, 0.4, 0.6},
+		{1, -0.1, 0.1},
+	}
+	for _, tt := range tests {
+		n := newStereoNoise(99, tt.width)
+		left := make([]float64, 20000)
+		right := make([]float64, len(left))
+		src := newStereoNoise(7, 1) // independent source for the left channel
+		for i := range left {
+			left[i] = 2*src.rng.Float64() - 1
+			right[i] = n.right(left[i])
+		}
+		c := correlation(left, right)
+		if c < tt.wantMin || c > tt.wantMax {
+			t.Errorf("width %v: correlation = %.3f, want in [%v, %v]", tt.width, c, tt.wantMin, tt.wantMax)
+		}
+		if ratio := energy(right) / energy(left); ratio < 0.9 || ratio > 1.1 {
+			t.Errorf("width %v: right/left power = %.3f, want about 1", tt.width, ratio)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// stereoMixer tests
+// ---------------------------------------------------------------------------
+
+func TestStereoMixer_PansAndClamps(t *testing.T) {
+	m := newStereoMixer(
+		[]layer{constLayer(0.5), constLayer(0.8)},
+		[]panner{
+			newPanner(audio.LayerParams{Pan: -1}, 1, 0),
+			newPanner(audio.LayerParams{Pan: 0.5}, 1, 0),
+		},
+	)
+	l, r := m.Sample(0)
+	if want := 0.5 + 0.5*0.8; math.Abs(l-want) > 1e-12 {
+		t.Errorf("left = %v, want %v", l, want)
+	}
+	if want := 0.8; math.Abs(r-want) > 1e-12 {
+		t.Errorf("right = %v, want %v", r, want)
+	}
+
+	loud := newStereoMixer([]layer{constLayer(1), constLayer(1)}, []panner{{}, {}})
+	if l, r := loud.Sample(0); l != 1 || r != 1 {
+		t.Errorf("Sample() = (%v, %v), want clamped to (1, 1)", l, r)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Generator stereo tests
+// ---------------------------------------------------------------------------
+
+func TestGenerator_ZeroWidthMatchesMono(t *testing.T) {
+	params := testScreamParams()
+	params.Layers[0].Pan = -0.8
+	params.Layers[2].PanRate = 1
+	params.Layers[2].PanDepth = 1
+	params.Width = 0
+
+	left, right := renderStereo(t, params)
+	for i := range left {
+		if left[i] != right[i] {
+			t.Fatalf("sample %d: left %v != right %v with zero width", i, left[i], right[i])
+		}
+	}
+
+	params.Channels = 1
+	reader, err := NewGenerator(discardLogger).Generate(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	mono, _ := io.ReadAll(reader)
+	for i := range left {
+		if got := float64(int16(binary.LittleEndian.Uint16(mono[2*i:]))); got != left[i] {
+			t.Fatalf("sample %d: stereo %v != mono %v", i, left[i], got)
+		}
+	}
+}
+
+func TestGenerator_StereoChannelsDiffer(t *testing.T) {
+	for _, name := range audio.AllPresets() {
+		t.Run(string(name), func(t *testing.T) {
+			params, _ := audio.GetPreset(name)
+			if !params.IsStereo() {
+				t.Fatalf("preset %q should have a stereo default", name)
+			}
+			left, right := renderStereo(t, params)
+			if c := correlation(left, right); c > 0.999 {
+				t.Errorf("left/right correlation = %.4f, want distinct channels", c)
+			}
+		})
+	}
+}
+
+func TestGenerator_StaticPanShiftsEnergy(t *testing.T) {
+	params := testScreamParams()
+	params.Duration = time.Second
+	for i := range params.Layers {
+		params.Layers[i].Pan = -0.9
+	}
+	params.Width = 1
+
+	left, right := renderStereo(t, params)
+	if el, er := energy(left), energy(right); el <= 2*er {
+		t.Errorf("left energy %.3g, right energy %.3g; want a left-panned mix", el, er)
+	}
+}
+
+func TestGenerator_AutoPanMoves(t *testing.T) {
+	params := testScreamParams()
+	params.Duration = 2 * time.Second
+	// A single auto-panned layer; the others have distinct LFO phases and
+	// would smear the balance.
+	for i := 1; i < len(params.Layers); i++ {
+		params.Layers[i].Amplitude = 0
+	}
+	params.Layers[0].PanRate = 0.5
+	params.Layers[0].PanDepth = 1
+	params.Width = 1
+
+	left, right := renderStereo(t, params)
+
+	// Compare the left/right balance over successive 100ms windows; with a
+	// 0.5 Hz LFO the mix must lean both ways during two seconds.
+	window := params.SampleRate / 10
+	var leanLeft, leanRight bool
+	for start := 0; start+window <= len(left); start += window {
+		el, er := energy(left[start:start+window]), energy(right[start:start+window])
+		if el > 2*er {
+			leanLeft = true
+		}
+		if er > 2*el {
+			leanRight = true
+		}
+	}
+	if !leanLeft || !leanRight {
+		t.Errorf("auto-pan: leaned left=%v right=%v, want both", leanLeft, leanRight)
+	}
+}
+
+func TestGenerator_StereoDeterministic(t *testing.T) {
+	params, _ := audio.GetPreset(audio.PresetBanshee)
+	l1, r1 := renderStereo(t, params)
+	l2, r2 := renderStereo(t, params)
+	if !floatsEqual(l1, l2) || !floatsEqual(r1, r2) {
+		t.Error("same stereo params produced different output")
+	}
+}
+
+func floatsEqual(a, b []float64) bool {
+	var ba, bb bytes.Buffer
+	_ = binary.Write(&ba, binary.LittleEndian, a)
+	_ = binary.Write(&bb, binary.LittleEndian, b)
+	return bytes.Equal(ba.Bytes(), bb.Bytes())
+}
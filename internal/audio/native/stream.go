@@ -0,0 +1,247 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// streamChunkMillis is the amount of audio synthesized per refill of a
+// pcmStream. It matches the 20ms Opus frame size so that the first frame is
+// available after a single chunk of work.
+const streamChunkMillis = 20
+
+// frameRenderer synthesizes one sample frame (one sample per output channel)
+// and appends it to buf as s16le. It can instead synthesize a block of frames
+// at once with renderBlock, on up to workers goroutines, into the left and
+// right samples of each frame before they are converted to s16le; a mono
+// renderer writes its samples to both. A block of frames from frame start is
+// the same as the frames appendFrame would synthesize at the times
+// start/sampleRate, (start+1)/sampleRate and so on, whatever workers.
+type frameRenderer interface {
+	appendFrame(buf []byte, t float64) []byte
+	renderBlock(l, r []float64, start, workers int)
+}
+
+// voiceRenderer is a frameRenderer that can also return its next frame as
+// left and right samples, before they are converted to s16le, so that a
+// crowdRenderer can mix several voices. A mono renderer returns its sample
+// in both.
+type voiceRenderer interface {
+	frameRenderer
+	frame(t float64) (l, r float64)
+}
+
+// tunableRenderer is a voiceRenderer whose layers and filters can be retuned
+// to new parameters between frames.
+type tunableRenderer interface {
+	voiceRenderer
+	tune(params audio.ScreamParams)
+}
+
+// monoRenderer mixes all layers to a single signal, filters it, and writes
+// the same sample to every output channel.
+type monoRenderer struct {
+	mixer      *layerMixer
+	chain      *filterChain
+	channels   int
+	sampleRate int
+	end        float64 // seconds; after it the chain is fed silence
+}
+
+func (r *monoRenderer) appendFrame(buf []byte, t float64) []byte {
+	filtered, _ := r.frame(t)
+	for range r.channels {
+		buf = appendS16(buf, filtered)
+	}
+	return buf
+}
+
+// frame implements voiceRenderer.
+func (r *monoRenderer) frame(t float64) (l, rr float64) {
+	// Mix all layers at time t, then apply the filter chain. Past the end of
+	// the scream only the reverb's tail is left to hear.
+	var in float64
+	if t < r.end {
+		in = r.mixer.Sample(t)
+	}
+	filtered := r.chain.Process(in)
+	return filtered, filtered
+}
+
+// renderBlock implements frameRenderer.
+func (r *monoRenderer) renderBlock(l, rr []float64, start, workers int) {
+	live := liveFrames(start, len(l), r.sampleRate, r.end)
+	r.mixer.SampleBlock(l[:live], start, r.sampleRate, workers)
+	clear(l[live:])
+	for k, in := range l {
+		l[k] = r.chain.Process(in)
+	}
+	copy(rr, l)
+}
+
+// tune implements tunableRenderer.
+func (r *monoRenderer) tune(params audio.ScreamParams) {
+	tuneLayers(r.mixer.layers, params)
+	tuneContours(r.mixer.contours, params)
+	r.mixer.master.tune(nil, params.Envelope)
+	r.chain.tune(params.Filter, r.sampleRate)
+}
+
+// stereoRenderer mixes layers into independent left and right signals, each
+// with its own filter chain.
+type stereoRenderer struct {
+	mixer      *stereoMixer
+	left       *filterChain
+	right      *filterChain
+	sampleRate int
+	end        float64 // seconds; after it the chains are fed silence
+}
+
+func (r *stereoRenderer) appendFrame(buf []byte, t float64) []byte {
+	l, rr := r.frame(t)
+	buf = appendS16(buf, l)
+	return appendS16(buf, rr)
+}
+
+// frame implements voiceRenderer.
+func (r *stereoRenderer) frame(t float64) (l, rr float64) {
+	if t < r.end {
+		l, rr = r.mixer.Sample(t)
+	}
+	return r.left.Process(l), r.right.Process(rr)
+}
+
+// renderBlock implements frameRenderer.
+func (r *stereoRenderer) renderBlock(l, rr []float64, start, workers int) {
+	live := liveFrames(start, len(l), r.sampleRate, r.end)
+	r.mixer.SampleBlock(l[:live], rr[:live], start, r.sampleRate, workers)
+	clear(l[live:])
+	clear(rr[live:])
+	for k := range l {
+		l[k], rr[k] = r.left.Process(l[k]), r.right.Process(rr[k])
+	}
+}
+
+// tune implements tunableRenderer.
+func (r *stereoRenderer) tune(params audio.ScreamParams) {
+	r.mixer.tune(params)
+	r.left.tune(params.Filter, r.sampleRate)
+	r.right.tune(params.Filter, r.sampleRate)
+}
+
+// appendS16 converts v to int16 by scaling, clamping, and rounding, and
+// appends it to buf in little-endian order.
+func appendS16(buf []byte, v float64) []byte {
+	scaled := v * 32767.0
+	clamped := math.Max(-32768, math.Min(32767, scaled))
+	s16 := int16(math.Round(clamped))
+	return append(buf, byte(uint16(s16)), byte(uint16(s16)>>8))
+}
+
+// pcmStream is an io.Reader that synthesizes s16le PCM lazily, one chunk at a
+// time. Memory use is bounded by the chunk size regardless of duration, and
+// the bytes produced are identical to rendering the whole buffer up front
+// because samples are still generated strictly in order. The context is
+// checked before every chunk, so cancellation takes effect within one chunk.
+// With more than one worker, each chunk is rendered as a block, spread over
+// up to workers goroutines; otherwise frame by frame.
+type pcmStream struct {
+	ctx        context.Context
+	render     frameRenderer
+	sampleRate int
+	channels   int
+	workers    int
+	left       []float64 // the chunk's samples, when rendered as a block
+	right      []float64
+	total      int // total samples per channel
+	next       int // index of the next sample to synthesize
+	buf        []byte
+	off        int // read offset into buf
+	err        error
+	logger     *slog.Logger
+}
+
+// newPCMStream returns a pcmStream producing total frames of channels
+// samples each from render, on up to workers goroutines at once.
+func newPCMStream(ctx context.Context, render frameRenderer, sampleRate, channels, total, workers int, logger *slog.Logger) *pcmStream {
+	chunk := sampleRate * streamChunkMillis / 1000
+	if chunk < 1 {
+		chunk = 1
+	}
+	s := &pcmStream{
+		ctx:        ctx,
+		render:     render,
+		sampleRate: sampleRate,
+		channels:   channels,
+		workers:    workers,
+		total:      total,
+		buf:        make([]byte, 0, chunk*channels*2),
+		logger:     logger,
+	}
+	if workers > 1 {
+		s.left = make([]float64, chunk)
+		s.right = make([]float64, chunk)
+	}
+	return s
+}
+
+// Read implements io.Reader. It returns io.EOF once every sample has been
+// synthesized and consumed, or an error wrapping audio.ErrCancelled if the
+// context is done before then. Errors are sticky.
+func (s *pcmStream) Read(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if s.off == len(s.buf) {
+		if s.next >= s.total {
+			return 0, io.EOF
+		}
+		if err := s.ctx.Err(); err != nil {
+			s.logger.Debug("PCM generation cancelled", "sample", s.next, "total", s.total)
+			s.err = fmt.Errorf("%w: %w", audio.ErrCancelled, err)
+			return 0, s.err
+		}
+		s.fill()
+	}
+	n := copy(p, s.buf[s.off:])
+	s.off += n
+	return n, nil
+}
+
+// fill synthesizes the next chunk of samples into buf, replacing its
+// previous contents.
+func (s *pcmStream) fill() {
+	chunk := cap(s.buf) / (s.channels * 2)
+	end := min(s.next+chunk, s.total)
+
+	s.buf = s.buf[:0]
+	s.off = 0
+	if s.workers > 1 {
+		l, r := s.left[:end-s.next], s.right[:end-s.next]
+		s.render.renderBlock(l, r, s.next, s.workers)
+		for k := range l {
+			s.buf = appendS16(s.buf, l[k])
+			if s.channels == 2 {
+				s.buf = appendS16(s.buf, r[k])
+			}
+		}
+	} else {
+		for i := s.next; i < end; i++ {
+			t := float64(i) / float64(s.sampleRate)
+			s.buf = s.render.appendFrame(s.buf, t)
+		}
+	}
+	s.next = end
+
+	if s.next >= s.total {
+		s.logger.Debug("PCM generation complete", "bytes", s.total*s.channels*2)
+	}
+}
@@ -0,0 +1,214 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// renderBuffered synthesizes params into a single buffer with a plain loop
+// over the same frame renderer. It is the reference the streaming reader must
+// match byte for byte.
+func renderBuffered(params audio.ScreamParams) []byte {
+	totalSamples := int((params.Duration + params.Tail()).Seconds() * float64(params.SampleRate))
+	render := newFrameRenderer(params)
+
+	out := make([]byte, 0, totalSamples*params.Channels*2)
+	for i := 0; i < totalSamples; i++ {
+		out = render.appendFrame(out, float64(i)/float64(params.SampleRate))
+	}
+	return out
+}
+
+func TestPCMStream_MatchesBufferedOutput(t *testing.T) {
+	gen := NewGenerator(discardLogger)
+
+	for _, name := range audio.AllPresets() {
+		for _, channels := range []int{1, 2} {
+			params, _ := audio.GetPreset(name)
+			params.Channels = channels
+			want := renderBuffered(params)
+
+			t.Run(fmt.Sprintf("%s/%dch", name, channels), func(t *testing.T) {
+				reader, err := gen.Generate(context.Background(), params)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				got, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("ReadAll() error = %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("streamed output (%d bytes) differs from buffered output (%d bytes)", len(got), len(want))
+				}
+			})
+		}
+	}
+}
+
+func TestPCMStream_ReadSizesDoNotAffectOutput(t *testing.T) {
+	gen := NewGenerator(discardLogger)
+	params := testScreamParams()
+	params.Duration = 250 * time.Millisecond
+	want := renderBuffered(params)
+
+	readers := map[string]func(io.Reader) io.Reader{
+		"one byte": iotest.OneByteReader,
+		"half":     iotest.HalfReader,
+		"odd 7":    func(r io.Reader) io.Reader { return &fixedReader{r: r, n: 7} },
+		"frame":    func(r io.Reader) io.Reader { return &fixedReader{r: r, n: 960 * 2 * 2} },
+	}
+	for name, wrap := range readers {
+		t.Run(name, func(t *testing.T) {
+			reader, err := gen.Generate(context.Background(), params)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			got, err := io.ReadAll(wrap(reader))
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Error("output depends on read size")
+			}
+		})
+	}
+}
+
+func TestPCMStream_IsLazy(t *testing.T) {
+	gen := NewGenerator(discardLogger)
+	params := testScreamParams()
+	params.Duration = 60 * time.Second
+
+	reader, err := gen.Generate(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	stream, ok := reader.(*pcmStream)
+	if !ok {
+		t.Fatalf("Generate() returned %T, want *pcmStream", reader)
+	}
+	if stream.next != 0 {
+		t.Errorf("synthesized %d samples before the first Read, want 0", stream.next)
+	}
+
+	frame := make([]byte, 960*params.Channels*2)
+	if _, err := io.ReadFull(reader, frame); err != nil {
+		t.Fatalf("ReadFull() error = %v", err)
+	}
+
+	chunk := params.SampleRate * streamChunkMillis / 1000
+	if stream.next != chunk {
+		t.Errorf("synthesized %d samples for one frame, want %d", stream.next, chunk)
+	}
+	if got, limit := cap(stream.buf), chunk*params.Channels*2; got > limit {
+		t.Errorf("buffer capacity = %d bytes, want at most %d", got, limit)
+	}
+}
+
+func TestPCMStream_EOF(t *testing.T) {
+	gen := NewGenerator(discardLogger)
+	params := testScreamParams()
+	params.Duration = 30 * time.Millisecond
+
+	reader, err := gen.Generate(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+
+	n, err := reader.Read(make([]byte, 16))
+	if n != 0 || err != io.EOF {
+		t.Errorf("Read after drain = (%d, %v), want (0, io.EOF)", n, err)
+	}
+}
+
+// fixedReader reads at most n bytes per call from r.
+type fixedReader struct {
+	r io.Reader
+	n int
+}
+
+func (f *fixedReader) Read(p []byte) (int, error) {
+	if len(p) > f.n {
+		p = p[:f.n]
+	}
+	return f.r.Read(p)
+}
+
+// --- Benchmarks ---
+
+func BenchmarkPCMStream_FirstFrame(b *testing.B) {
+	gen := NewGenerator(discardLogger)
+	params, _ := audio.GetPreset(audio.PresetClassic)
+	params.Duration = 60 * time.Second
+	frame := make([]byte, 960*params.Channels*2)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reader, err := gen.Generate(context.Background(), params)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGenerator_CancelledBeforeStart(t *testing.T) {
+	gen := NewGenerator(discardLogger)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := gen.Generate(ctx, testScreamParams())
+	if !errors.Is(err, audio.ErrCancelled) {
+		t.Errorf("Generate() error = %v, want audio.ErrCancelled", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Generate() error = %v, want wrapping context.Canceled", err)
+	}
+}
+
+func TestPCMStream_CancelStopsSynthesis(t *testing.T) {
+	gen := NewGenerator(discardLogger)
+	params := testScreamParams()
+	params.Duration = 60 * time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader, err := gen.Generate(ctx, params)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	frame := make([]byte, 960*params.Channels*2)
+	if _, err := io.ReadFull(reader, frame); err != nil {
+		t.Fatalf("ReadFull() before cancel error = %v", err)
+	}
+
+	cancel()
+
+	_, err = io.Copy(io.Discard, reader)
+	if !errors.Is(err, audio.ErrCancelled) {
+		t.Fatalf("Copy() after cancel error = %v, want audio.ErrCancelled", err)
+	}
+	stream := reader.(*pcmStream)
+	if chunk := params.SampleRate * streamChunkMillis / 1000; stream.next != chunk {
+		t.Errorf("synthesized %d samples, want synthesis to stop after %d", stream.next, chunk)
+	}
+
+	// The error is sticky.
+	if _, err := reader.Read(frame); !errors.Is(err, audio.ErrCancelled) {
+		t.Errorf("second Read() error = %v, want audio.ErrCancelled", err)
+	}
+}
@@ -0,0 +1,187 @@
+package native
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// Glottal pulse shape, as fractions of one period, after Rosenberg: the
+// glottis opens over glottalOpen, closes over glottalClose, and stays shut
+// for the rest of the period.
+const (
+	glottalOpen  = 0.4
+	glottalClose = 0.16
+)
+
+// glottalSlope is the steepest slope of the glottal flow per period, at the
+// moment of closure. Dividing by it scales the flow derivative to [-1, 1].
+const glottalSlope = math.Pi / (2 * glottalClose)
+
+// vocalGain brings the formant-filtered source up to roughly the loudness of
+// a sine layer of the same amplitude.
+const vocalGain = 3.0
+
+// vocalJitterSeedXOR is XORed into the layer seed to seed the per-pulse
+// jitter and shimmer RNG, decorrelating it from the pitch jumps.
+const vocalJitterSeedXOR int64 = 0x2d2d2d2d2d2d2d2d
+
+// formantUpdateSamples is how often a vocal layer moves its formants along
+// the vowel sequence.
+const formantUpdateSamples = 64
+
+// vocalLayer sings a vowel: a glottal pulse train at a jumping pitch,
+// filtered through a bank of formant resonators. Jitter and shimmer randomly
+// vary the length and loudness of each pulse.
+type vocalLayer struct {
+	sampleRate float64
+	duration   float64 // seconds, over which the vowel sequence is sung
+	seed       int64
+	base       float64
+	freqRange  float64
+	jump       float64
+	amp        float64
+	rise       float64
+	vowel      string
+	jitter     float64
+	shimmer    float64
+	curStep    int64
+	curFreq    float64
+
+	rng       *rand.Rand // per-pulse jitter and shimmer
+	phase     float64    // position in the current glottal period [0, 1)
+	pulseRate float64    // pitch multiplier for the current period, from jitter
+	pulseAmp  float64    // loudness of the current pulse, from shimmer
+	prevFlow  float64
+	formants  [audio.FormantCount]resonator
+	gains     [audio.FormantCount]float64
+	n         int // samples rendered, for formant updates
+	bend      pitchBend
+}
+
+// newVocalLayer creates a vocal layer from params. duration is the length of
+// the scream in seconds, over which p.Vowel is sung.
+func newVocalLayer(p audio.LayerParams, sampleRate int, duration float64) *vocalLayer {
+	l := &vocalLayer{
+		sampleRate: float64(sampleRate),
+		duration:   duration,
+		rng:        rand.New(rand.NewSource(p.Seed ^ vocalJitterSeedXOR)),
+		pulseRate:  1,
+		pulseAmp:   1,
+		bend:       newPitchBend(p, sampleRate, duration),
+	}
+	l.tune(p)
+	return l
+}
+
+// Sample returns the audio sample at time t for the vocal layer.
+func (l *vocalLayer) Sample(t float64) float64 {
+	step := int64(t * l.jump)
+	if step != l.curStep {
+		l.curStep = step
+		l.curFreq = l.base + l.freqRange*seededRandom(l.seed, step, audio.CoprimeVocal)
+	}
+	if l.n%formantUpdateSamples == 0 {
+		l.setFormants(t)
+	}
+	l.n++
+
+	f0 := l.curFreq * l.pulseRate * l.bend.ratio(t)
+	if f0 <= 0 {
+		return 0
+	}
+
+	// The derivative of the glottal flow is the excitation the vocal tract
+	// hears; scale it per period so the level does not depend on pitch.
+	flow := l.pulseAmp * glottalFlow(l.phase)
+	src := (flow - l.prevFlow) * l.sampleRate / f0 / glottalSlope
+	l.prevFlow = flow
+
+	l.phase += f0 / l.sampleRate
+	if l.phase >= 1 {
+		l.phase -= math.Floor(l.phase)
+		l.pulseRate = 1 + l.jitter*(2*l.rng.Float64()-1)
+		l.pulseAmp = 1 - l.shimmer*l.rng.Float64()
+	}
+
+	var out float64
+	for i := range l.formants {
+		out += l.gains[i] * l.formants[i].Process(src)
+	}
+	envelope := l.amp * (1 + l.rise*t)
+	return vocalGain * envelope * out
+}
+
+// tune implements tunableLayer. The formants follow the new vowel sequence
+// from the next update.
+func (l *vocalLayer) tune(p audio.LayerParams) {
+	l.seed = p.Seed
+	l.base = p.BaseFreq
+	l.freqRange = p.FreqRange
+	l.jump = p.JumpRate
+	l.amp = p.Amplitude
+	l.rise = p.Rise
+	l.vowel = p.Vowel
+	l.jitter = p.Jitter
+	l.shimmer = p.Shimmer
+	l.bend.tune(p)
+	l.curStep = -1
+	l.n = 0
+}
+
+// setFormants tunes the resonators to the vowel sung at time t. Formants
+// are widened to at least half the pitch, as a singer's are at high pitches,
+// so that the sparse harmonics of a high voice still excite them.
+func (l *vocalLayer) setFormants(t float64) {
+	pos := 0.0
+	if l.duration > 0 {
+		pos = t / l.duration
+	}
+	for i, f := range audio.VowelFormants(l.vowel, pos) {
+		l.formants[i].set(f.Freq, math.Max(f.Bandwidth, l.curFreq/2), l.sampleRate)
+		l.gains[i] = f.Gain
+	}
+}
+
+// glottalFlow returns the Rosenberg glottal flow in [0, 1] at position phase
+// in [0, 1) of a glottal period.
+func glottalFlow(phase float64) float64 {
+	switch {
+	case phase < glottalOpen:
+		return 0.5 * (1 - math.Cos(math.Pi*phase/glottalOpen))
+	case phase < glottalOpen+glottalClose:
+		return math.Cos(math.Pi * (phase - glottalOpen) / (2 * glottalClose))
+	default:
+		return 0
+	}
+}
+
+// resonator is a two-pole band-pass filter with unity gain at its centre
+// frequency, used to model a single formant.
+type resonator struct {
+	b0, b2, a1, a2 float64
+	x1, x2, y1, y2 float64
+}
+
+// set changes the centre frequency and bandwidth, keeping the filter state.
+// The centre frequency is kept below the Nyquist frequency.
+func (r *resonator) set(freq, bandwidth, sampleRate float64) {
+	freq = math.Min(freq, 0.45*sampleRate)
+	w := 2 * math.Pi * freq / sampleRate
+	q := freq / bandwidth
+	alpha := math.Sin(w) / (2 * q)
+	a0 := 1 + alpha
+	r.b0 = alpha / a0
+	r.b2 = -alpha / a0
+	r.a1 = -2 * math.Cos(w) / a0
+	r.a2 = (1 - alpha) / a0
+}
+
+// Process filters a single sample.
+func (r *resonator) Process(x float64) float64 {
+	y := r.b0*x + r.b2*r.x2 - r.a1*r.y1 - r.a2*r.y2
+	r.x2, r.x1 = r.x1, x
+	r.y2, r.y1 = r.y1, y
+	return y
+}
@@ -0,0 +1,170 @@
+package native
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+func validVocalParams() audio.LayerParams {
+	return audio.LayerParams{
+		Type:      audio.LayerVocal,
+		BaseFreq:  180,
+		FreqRange: 300,
+		JumpRate:  4,
+		Amplitude: 0.5,
+		Rise:      0.5,
+		Seed:      5150,
+		Vowel:     "aao",
+		Jitter:    0.02,
+		Shimmer:   0.1,
+	}
+}
+
+// renderVocal returns the first n samples of a vocal layer lasting one second.
+func renderVocal(p audio.LayerParams, n int) []float64 {
+	l := newVocalLayer(p, testSampleRate, 1)
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = l.Sample(float64(i) / testSampleRate)
+	}
+	return out
+}
+
+func TestVocalLayer_NonZeroAndBounded(t *testing.T) {
+	samples := renderVocal(validVocalParams(), testSampleRate)
+	var sumSq float64
+	for i, s := range samples {
+		if math.IsNaN(s) || math.Abs(s) > 2 {
+			t.Fatalf("sample %d = %v, want finite and within [-2, 2]", i, s)
+		}
+		sumSq += s * s
+	}
+	if rms := math.Sqrt(sumSq / float64(len(samples))); rms < 0.05 {
+		t.Errorf("RMS = %v, want audible output", rms)
+	}
+}
+
+func TestVocalLayer_VowelShapesSpectrum(t *testing.T) {
+	// The ratio of the energy of the first difference to that of the signal
+	// rises with spectral brightness. "a" has strong upper formants, "u"
+	// almost none.
+	brightness := func(vowel string) float64 {
+		p := validVocalParams()
+		p.FreqRange = 0
+		p.Jitter, p.Shimmer = 0, 0
+		p.Vowel = vowel
+		x := renderVocal(p, testSampleRate/2)
+		var sig, diff float64
+		for i := 1; i < len(x); i++ {
+			sig += x[i] * x[i]
+			d := x[i] - x[i-1]
+			diff += d * d
+		}
+		return diff / sig
+	}
+	if a, u := brightness("a"), brightness("u"); a <= 1.5*u {
+		t.Errorf("brightness of a = %v, u = %v; want a clearly brighter", a, u)
+	}
+}
+
+func TestVocalLayer_JitterChangesOutput(t *testing.T) {
+	p := validVocalParams()
+	p.Jitter, p.Shimmer = 0, 0
+	steady := renderVocal(p, 4800)
+	p.Jitter = 0.05
+	jittered := renderVocal(p, 4800)
+
+	var diff float64
+	for i := range steady {
+		diff += math.Abs(steady[i] - jittered[i])
+	}
+	if diff == 0 {
+		t.Error("jitter did not change the output")
+	}
+}
+
+func TestVocalLayer_TuneRestarts(t *testing.T) {
+	p := validVocalParams()
+	l := newVocalLayer(p, testSampleRate, 1)
+	for i := range 1000 {
+		l.Sample(float64(i) / testSampleRate)
+	}
+	p.Vowel = "i"
+	l.tune(p)
+	if l.vowel != "i" || l.curStep != -1 {
+		t.Errorf("after tune: vowel = %q, curStep = %d; want \"i\", -1", l.vowel, l.curStep)
+	}
+}
+
+func TestResonator_Response(t *testing.T) {
+	tests := []struct {
+		name string
+		freq float64
+		want float64
+	}{
+		{"centre", 1000, 1},
+		{"octave below", 500, 0.3},
+		{"two octaves above", 4000, 0.15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r resonator
+			r.set(1000, 100, testSampleRate)
+			peak := 0.0
+			for i := range testSampleRate {
+				y := r.Process(math.Sin(2 * math.Pi * tt.freq * float64(i) / testSampleRate))
+				if i > testSampleRate/2 {
+					peak = math.Max(peak, math.Abs(y))
+				}
+			}
+			if tt.want == 1 {
+				if math.Abs(peak-1) > 0.02 {
+					t.Errorf("gain at %v Hz = %v, want 1", tt.freq, peak)
+				}
+			} else if peak > tt.want {
+				t.Errorf("gain at %v Hz = %v, want below %v", tt.freq, peak, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildLayers_DispatchesOnType(t *testing.T) {
+	params := testScreamParams()
+	params.Layers[0] = validVocalParams()
+	layers := buildLayers(params, testSampleRate)
+	if _, ok := layers[0].(*vocalLayer); !ok {
+		t.Errorf("layer 0 = %T, want *vocalLayer", layers[0])
+	}
+	if _, ok := layers[1].(*harmonicSweepLayer); !ok {
+		t.Errorf("layer 1 = %T, want *harmonicSweepLayer", layers[1])
+	}
+}
+
+func TestGenerator_VocalDeterministic(t *testing.T) {
+	params := testScreamParams()
+	params.Layers[0] = validVocalParams()
+	if err := params.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	a := renderPCM(t, params)
+	b := renderPCM(t, params)
+	if !bytes.Equal(a, b) {
+		t.Error("same vocal params produced different output")
+	}
+}
+
+func TestGenerator_WailPreset(t *testing.T) {
+	params, _ := audio.GetPreset(audio.PresetWail)
+	pcm := renderPCM(t, params)
+
+	total := int((params.Duration + params.Tail()).Seconds() * float64(params.SampleRate))
+	if want := total * params.Channels * 2; len(pcm) != want {
+		t.Fatalf("len(pcm) = %d, want %d", len(pcm), want)
+	}
+	if rms := rmsS16(pcm); rms < 0.05 {
+		t.Errorf("RMS = %v, want an audible wail", rms)
+	}
+}
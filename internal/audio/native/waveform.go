@@ -0,0 +1,61 @@
+package native
+
+import (
+	"math"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// waveSource generates the wave of a tonal layer, as chosen by its
+// audio.LayerParams.Waveform. Every wave but the sine is band-limited so that
+// the high pitches of a shriek do not alias.
+type waveSource struct {
+	wave   audio.Waveform
+	width  float64
+	detune float64
+	oscs   []*oscillator // by supersaw voice; the other waves use only the first
+	norm   float64       // scales the supersaw voices to the loudness of one saw
+}
+
+// newWaveSource creates the wave source of a layer with params p.
+func newWaveSource(p audio.LayerParams, sampleRate int) waveSource {
+	s := waveSource{oscs: make([]*oscillator, len(audio.SupersawVoices))}
+	var power float64
+	for i, v := range audio.SupersawVoices {
+		s.oscs[i] = newOscillator(sampleRate)
+		s.oscs[i].pos = v.Phase
+		power += v.Gain * v.Gain
+	}
+	s.norm = 1 / math.Sqrt(power)
+	s.tune(p)
+	return s
+}
+
+// tune changes the wave of s to that of p, keeping the phase of each voice.
+func (s *waveSource) tune(p audio.LayerParams) {
+	s.wave = p.Waveform
+	s.width = p.PulseWidth
+	s.detune = p.Detune
+}
+
+// sample returns the next sample of the wave at the given frequency.
+func (s *waveSource) sample(freq float64) float64 {
+	switch s.wave {
+	case audio.WaveSaw:
+		return s.oscs[0].saw(freq)
+	case audio.WaveSquare:
+		return s.oscs[0].pulse(freq, 0.5)
+	case audio.WaveTriangle:
+		return s.oscs[0].triangle(freq)
+	case audio.WavePulse:
+		return s.oscs[0].pulse(freq, s.width)
+	case audio.WaveSupersaw:
+		var sum float64
+		for i, v := range audio.SupersawVoices {
+			sum += v.Gain * s.oscs[i].saw(freq*math.Exp2(v.Detune*s.detune/1200))
+		}
+		return s.norm * sum
+	default:
+		return s.oscs[0].sin(freq)
+	}
+}
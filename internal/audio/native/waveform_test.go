@@ -0,0 +1,90 @@
+package native
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+func TestWaveSource_SineMatchesOscillator(t *testing.T) {
+	for _, wave := range []audio.Waveform{"", audio.WaveSine} {
+		src := newWaveSource(audio.LayerParams{Waveform: wave}, 48000)
+		osc := newOscillator(48000)
+		for i := 0; i < 1000; i++ {
+			if got, want := src.sample(440), osc.sin(440); got != want {
+				t.Fatalf("waveform %q sample %d = %v, want %v", wave, i, got, want)
+			}
+		}
+	}
+}
+
+func TestWaveSource_SupersawLoudness(t *testing.T) {
+	rms := func(p audio.LayerParams) float64 {
+		src := newWaveSource(p, 48000)
+		var sum float64
+		for i := 0; i < 48000; i++ {
+			s := src.sample(220)
+			sum += s * s
+		}
+		return math.Sqrt(sum / 48000)
+	}
+
+	// A saw has an RMS of 1/sqrt(3); the detuned voices drift in and out of
+	// phase, so the supersaw only matches it on average.
+	saw := rms(audio.LayerParams{Waveform: audio.WaveSaw})
+	if want := 1 / math.Sqrt(3); math.Abs(saw-want) > 0.01 {
+		t.Errorf("saw RMS = %v, want %v", saw, want)
+	}
+	if got := rms(audio.LayerParams{Waveform: audio.WaveSupersaw, Detune: 30}); math.Abs(got-saw) > 0.2*saw {
+		t.Errorf("supersaw RMS = %v, want near the saw's %v", got, saw)
+	}
+}
+
+func TestGenerator_Waveforms(t *testing.T) {
+	sine := renderPCM(t, toneParams(440))
+	for _, layer := range []audio.LayerParams{
+		{Waveform: audio.WaveSaw},
+		{Waveform: audio.WaveSquare},
+		{Waveform: audio.WaveTriangle},
+		{Waveform: audio.WavePulse, PulseWidth: 0.2},
+		{Waveform: audio.WaveSupersaw, Detune: 20},
+	} {
+		t.Run(string(layer.Waveform), func(t *testing.T) {
+			for _, lt := range []audio.LayerType{audio.LayerPrimaryScream, audio.LayerHarmonicSweep, audio.LayerHighShriek} {
+				params := toneParams(440)
+				params.Layers[0].Type = lt
+				params.Layers[0].Waveform = layer.Waveform
+				params.Layers[0].PulseWidth = layer.PulseWidth
+				params.Layers[0].Detune = layer.Detune
+				pcm := renderPCM(t, params)
+
+				if bytes.Equal(pcm, sine) {
+					t.Errorf("%v: output is the same as a sine's", lt)
+				}
+				if got := fundamental(pcm, params.SampleRate, 0.5); math.Abs(got-440) > 10 {
+					t.Errorf("%v: fundamental = %.1f Hz, want 440 Hz", lt, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLayers_TuneWaveform(t *testing.T) {
+	p := audio.LayerParams{BaseFreq: 440, JumpRate: 1, Amplitude: 0.5}
+	l := newPrimaryScreamLayer(p, testSampleRate, 1)
+	for i := 0; i < 100; i++ {
+		l.Sample(float64(i) / testSampleRate)
+	}
+
+	// Changing the wave keeps the phase, so a square picks up where the sine
+	// left off instead of restarting its cycle.
+	p.Waveform = audio.WaveSquare
+	l.tune(p)
+	want := newOscillator(testSampleRate)
+	want.pos = l.src.oscs[0].pos
+	if got := l.Sample(100.0 / testSampleRate); got != 0.5*want.pulse(440, 0.5) {
+		t.Errorf("first sample after tune = %v, want %v", got, 0.5*want.pulse(440, 0.5))
+	}
+}
@@ -0,0 +1,83 @@
+package audio
+
+import "math"
+
+// NoiseColor names the spectrum of the noise of a noise layer.
+type NoiseColor string
+
+const (
+	NoiseWhite  NoiseColor = "white"  // the default; "" is also white. Flat: a harsh hiss
+	NoisePink   NoiseColor = "pink"   // falls by 3 dB per octave; rushing, like rain
+	NoiseBrown  NoiseColor = "brown"  // falls by 6 dB per octave; a low roar
+	NoiseBlue   NoiseColor = "blue"   // rises by 3 dB per octave; a thin sizzle
+	NoiseBand   NoiseColor = "band"   // white noise band-passed around NoiseParams.Freq
+	NoiseBreath NoiseColor = "breath" // white noise shaped by BreathSpectrum into a ragged breath
+)
+
+// NoiseParams sets the colour of the noise of a noise burst or background
+// noise layer. Every colour is about as loud as white noise of the same
+// amplitude.
+type NoiseParams struct {
+	Color NoiseColor `yaml:"color" json:"color"` // Spectrum of the noise; "" is white
+	Freq  float64    `yaml:"freq" json:"freq"`   // Centre of band noise in Hz, below the Nyquist frequency
+	Q     float64    `yaml:"q" json:"q"`         // Narrowness of band noise [0, 20]; 0 is ButterworthQ
+}
+
+// BreathSpectrum is the spectral envelope that shapes white noise into
+// breath noise: little below a few hundred Hz, the broad resonances of an
+// open throat around 1.5 and 3 kHz, and a soft top.
+var BreathSpectrum = [...]EQBand{
+	{Type: EQHighpass, Freq: 400},
+	{Type: EQPeak, Freq: 1500, Q: 1.2, Gain: 8},
+	{Type: EQPeak, Freq: 3000, Q: 2, Gain: 6},
+	{Type: EQHighShelf, Freq: 6000, Gain: -12},
+}
+
+// breathNoiseGain brings breath noise at 48 kHz back to the loudness of the
+// white noise it is shaped from.
+const breathNoiseGain = 1.194
+
+// FilterGain returns the gain that brings band or breath noise at
+// sampleRate back to the loudness of the white noise it is filtered from,
+// or 1 for the other colours. A band-pass filter at frequency f with a given
+// Q passes white noise in a band about pi/2*f/Q Hz wide.
+func (n NoiseParams) FilterGain(sampleRate int) float64 {
+	switch n.Color {
+	case NoiseBand:
+		return math.Sqrt(FilterQ(n.Q) * float64(sampleRate) / (math.Pi * n.Freq))
+	case NoiseBreath:
+		return breathNoiseGain * math.Sqrt(float64(sampleRate)/48000)
+	default:
+		return 1
+	}
+}
+
+// Bands returns the biquad filters, which must not be modified, that band
+// or breath noise is put through, or nil for the other colours.
+func (n NoiseParams) Bands() []EQBand {
+	switch n.Color {
+	case NoiseBand:
+		return []EQBand{{Type: EQBandpass, Freq: n.Freq, Q: n.Q}}
+	case NoiseBreath:
+		return BreathSpectrum[:]
+	default:
+		return nil
+	}
+}
+
+// validNoise reports whether the noise of l has a known colour and, if it
+// is band noise, a frequency below the Nyquist frequency of sampleRate and
+// a Q in range.
+func validNoise(l LayerParams, sampleRate int) bool {
+	n := l.Noise
+	switch n.Color {
+	case "", NoiseWhite, NoisePink, NoiseBrown, NoiseBlue, NoiseBreath:
+	case NoiseBand:
+		if n.Freq <= 0 || n.Freq >= float64(sampleRate)/2 {
+			return false
+		}
+	default:
+		return false
+	}
+	return n.Freq >= 0 && validQ(n.Q)
+}
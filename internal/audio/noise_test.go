@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestValidate_Noise(t *testing.T) {
+	tests := []struct {
+		name  string
+		noise NoiseParams
+		ok    bool
+	}{
+		{"default white", NoiseParams{}, true},
+		{"white", NoiseParams{Color: NoiseWhite}, true},
+		{"pink", NoiseParams{Color: NoisePink}, true},
+		{"brown", NoiseParams{Color: NoiseBrown}, true},
+		{"blue", NoiseParams{Color: NoiseBlue}, true},
+		{"band", NoiseParams{Color: NoiseBand, Freq: 2000, Q: 20}, true},
+		{"breath", NoiseParams{Color: NoiseBreath}, true},
+		{"unknown", NoiseParams{Color: "grey"}, false},
+		{"band without frequency", NoiseParams{Color: NoiseBand}, false},
+		{"band at Nyquist", NoiseParams{Color: NoiseBand, Freq: 24000}, false},
+		{"negative Q", NoiseParams{Color: NoiseBand, Freq: 2000, Q: -1}, false},
+		{"Q too high", NoiseParams{Color: NoiseBand, Freq: 2000, Q: 21}, false},
+		{"negative frequency", NoiseParams{Color: NoisePink, Freq: -1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			p.Layers[1].Noise = tt.noise
+			err := p.Validate()
+			if tt.ok {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var lve *LayerValidationError
+			if !errors.As(err, &lve) || lve.Layer != 1 || !errors.Is(err, ErrInvalidNoise) {
+				t.Errorf("Validate() = %v, want layer 1 ErrInvalidNoise", err)
+			}
+		})
+	}
+}
+
+func TestNoiseParams_FilterGain(t *testing.T) {
+	tests := []struct {
+		name  string
+		noise NoiseParams
+		rate  int
+		want  float64
+	}{
+		{"white", NoiseParams{}, 48000, 1},
+		{"pink", NoiseParams{Color: NoisePink}, 48000, 1},
+		{"band", NoiseParams{Color: NoiseBand, Freq: 1000, Q: 2}, 48000, math.Sqrt(2 * 48000 / (math.Pi * 1000))},
+		{"narrower band is louder", NoiseParams{Color: NoiseBand, Freq: 1000, Q: 8}, 48000, math.Sqrt(8 * 48000 / (math.Pi * 1000))},
+		{"breath", NoiseParams{Color: NoiseBreath}, 48000, breathNoiseGain},
+		{"breath at a higher rate", NoiseParams{Color: NoiseBreath}, 96000, breathNoiseGain * math.Sqrt2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.noise.FilterGain(tt.rate); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("FilterGain(%d) = %v, want %v", tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoiseParams_Bands(t *testing.T) {
+	if got := (NoiseParams{Color: NoisePink}).Bands(); got != nil {
+		t.Errorf("Bands() of pink noise = %+v, want nil", got)
+	}
+	band := NoiseParams{Color: NoiseBand, Freq: 700, Q: 3}.Bands()
+	if len(band) != 1 || band[0] != (EQBand{Type: EQBandpass, Freq: 700, Q: 3}) {
+		t.Errorf("Bands() of band noise = %+v, want one band-pass at 700 Hz with Q 3", band)
+	}
+	if got := (NoiseParams{Color: NoiseBreath}).Bands(); len(got) != len(BreathSpectrum) {
+		t.Errorf("Bands() of breath noise has %d bands, want the %d of BreathSpectrum", len(got), len(BreathSpectrum))
+	}
+}
@@ -1,19 +1,136 @@
 package audio
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/rand"
 	"time"
 )
 
 // ScreamParams holds all parameters for generating a scream.
 type ScreamParams struct {
-	Duration   time.Duration
-	SampleRate int
-	Channels   int
-	Seed       int64
-	Layers     [5]LayerParams
-	Noise      NoiseParams
-	Filter     FilterParams
+	Duration   time.Duration `yaml:"duration" json:"duration"`
+	SampleRate int           `yaml:"sample_rate" json:"sample_rate"`
+	Channels   int           `yaml:"channels" json:"channels"`
+	Seed       int64         `yaml:"seed" json:"seed"`
+	Layers     []LayerParams `yaml:"layers" json:"layers"`
+	Filter     FilterParams  `yaml:"filter" json:"filter"`
+
+	// Width is the stereo width [0, 1]. It scales every layer's pan position
+	// and auto-pan depth, and sets how decorrelated the left and right noise
+	// are. Zero renders identical channels.
+	Width float64 `yaml:"width" json:"width"`
+
+	// Envelope shapes the loudness of the whole scream, on top of the
+	// envelope of each layer.
+	Envelope Envelope `yaml:"envelope" json:"envelope"`
+
+	// MorphTo, if set, makes the scream morph over its duration from these
+	// parameters to MorphTo, as if by Interpolate with a mix rising from 0 to
+	// 1. Its Duration, SampleRate and Channels are ignored in favour of the
+	// outer parameters. Only the native backend can render a morph.
+	MorphTo *ScreamParams `yaml:"morph_to,omitempty" json:"morph_to,omitempty"`
+
+	// Voices, if more than 1, makes the scream a crowd of that many voices
+	// [0, MaxVoices] screaming at once, each with its own seed (see
+	// CrowdVoices). Spread [0, 1] sets how far the voices are detuned, set
+	// apart in time and spread across the stereo field; 0 is DefaultSpread.
+	// VoiceMix lists other screams that take turns with this one to give
+	// voices; their Duration, SampleRate, Channels and crowd settings are
+	// ignored. Only the native backend can render a crowd.
+	Voices   int            `yaml:"voices" json:"voices"`
+	Spread   float64        `yaml:"spread" json:"spread"`
+	VoiceMix []ScreamParams `yaml:"voice_mix,omitempty" json:"voice_mix,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, writing Duration as a string such
+// as "3s" rather than integer nanoseconds so that it reads the same as in
+// YAML.
+func (p ScreamParams) MarshalJSON() ([]byte, error) {
+	type plain ScreamParams
+	return json.Marshal(struct {
+		plain
+		Duration string `json:"duration"`
+	}{plain(p), p.Duration.String()})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting the output of
+// MarshalJSON.
+func (p *ScreamParams) UnmarshalJSON(data []byte) error {
+	type plain ScreamParams
+	raw := struct {
+		*plain
+		Duration string `json:"duration"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	p.Duration = 0
+	if raw.Duration != "" {
+		d, err := time.ParseDuration(raw.Duration)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", raw.Duration, err)
+		}
+		p.Duration = d
+	}
+	return nil
+}
+
+// Clone returns a copy of p that shares no layers, levels, envelope or pitch
+// points, EQ bands, morph target or voice mix with p, so that either may be
+// modified without affecting the other.
+func (p ScreamParams) Clone() ScreamParams {
+	p.Layers = append([]LayerParams(nil), p.Layers...)
+	for i := range p.Layers {
+		if p.Layers[i].Levels != nil {
+			p.Layers[i].Levels = append([]float64(nil), p.Layers[i].Levels...)
+		}
+		p.Layers[i].Envelope = p.Layers[i].Envelope.clone()
+		p.Layers[i].Pitch = p.Layers[i].Pitch.clone()
+	}
+	p.Envelope = p.Envelope.clone()
+	if p.Filter.EQ != nil {
+		p.Filter.EQ = append([]EQBand(nil), p.Filter.EQ...)
+	}
+	if p.MorphTo != nil {
+		end := p.MorphTo.Clone()
+		p.MorphTo = &end
+	}
+	if p.VoiceMix != nil {
+		mix := make([]ScreamParams, len(p.VoiceMix))
+		for i, v := range p.VoiceMix {
+			mix[i] = v.Clone()
+		}
+		p.VoiceMix = mix
+	}
+	return p
+}
+
+// IsStereo reports whether p renders distinct left and right channels, which
+// requires two output channels and a non-zero Width.
+func (p ScreamParams) IsStereo() bool {
+	return p.Channels == 2 && p.Width > 0
+}
+
+// Tail returns how long the output of p rings on past its Duration, so
+// that echoes, the tail of a reverb and the signal held back by a lookahead
+// limiter are not cut off. A morphing scream rings on as long as the longer
+// of its two ends, keeping the limiter it starts with, and a crowd until its
+// last voice to start has rung out and come through the crowd's limiter.
+func (p ScreamParams) Tail() time.Duration {
+	limiter := p.Filter.LimiterTail(p.SampleRate)
+	if p.IsCrowd() {
+		var tail time.Duration
+		for _, v := range p.CrowdVoices() {
+			tail = max(tail, v.Offset+v.Params.Tail())
+		}
+		return tail + limiter
+	}
+	tail := p.Filter.Tail()
+	if p.MorphTo != nil {
+		tail = max(tail, p.MorphTo.Filter.Tail())
+	}
+	return tail + limiter
 }
 
 // LayerType identifies the synthesis method for a layer.
@@ -25,8 +142,51 @@ const (
 	LayerHighShriek
 	LayerNoiseBurst
 	LayerBackgroundNoise
+
+	// LayerVocal is a glottal pulse source shaped by formant resonators into
+	// a sung vowel (see LayerParams.Vowel).
+	LayerVocal
 )
 
+// layerTypeNames maps each LayerType to the name used in preset files.
+var layerTypeNames = map[LayerType]string{
+	LayerPrimaryScream:   "primary_scream",
+	LayerHarmonicSweep:   "harmonic_sweep",
+	LayerHighShriek:      "high_shriek",
+	LayerNoiseBurst:      "noise_burst",
+	LayerBackgroundNoise: "background_noise",
+	LayerVocal:           "vocal",
+}
+
+// String returns the preset-file name of t, or "LayerType(n)" if t is unknown.
+func (t LayerType) String() string {
+	if name, ok := layerTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("LayerType(%d)", int(t))
+}
+
+// MarshalText implements encoding.TextMarshaler so layer types are written to
+// YAML by name.
+func (t LayerType) MarshalText() ([]byte, error) {
+	if _, ok := layerTypeNames[t]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidLayerType, int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the names
+// returned by String.
+func (t *LayerType) UnmarshalText(text []byte) error {
+	for lt, name := range layerTypeNames {
+		if name == string(text) {
+			*t = lt
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidLayerType, text)
+}
+
 // DefaultSampleRate is the standard sample rate used by default.
 const DefaultSampleRate = 48000
 
@@ -40,6 +200,7 @@ const (
 	CoprimeHarmonicSweep int64 = 251
 	CoprimeHighShriek    int64 = 89
 	CoprimeNoiseBurst    int64 = 173
+	CoprimeVocal         int64 = 197
 )
 
 // DefaultFilterParams returns the filter parameters shared across all presets.
@@ -53,39 +214,104 @@ func DefaultFilterParams() FilterParams {
 	}
 }
 
-// LayerParams holds parameters for a single synthesis layer.
+// LayerParams holds parameters for a single synthesis layer. Fields that do
+// not apply to the layer's Type are ignored; noise layers use Seed for their
+// noise source.
 type LayerParams struct {
-	Type      LayerType
-	BaseFreq  float64 // Base frequency in Hz
-	FreqRange float64 // Frequency jump range in Hz
-	SweepRate float64 // Linear frequency sweep rate (Hz/s), used by harmonic sweep
-	JumpRate  float64 // How often frequency jumps (Hz)
-	Amplitude float64 // Layer amplitude [0, 1]
-	Rise      float64 // Exponential amplitude rise over time
-	Seed      int64   // RNG seed for this layer
-}
+	Type      LayerType `yaml:"type" json:"type"`
+	BaseFreq  float64   `yaml:"base_freq" json:"base_freq"`   // Base frequency in Hz
+	FreqRange float64   `yaml:"freq_range" json:"freq_range"` // Frequency jump range in Hz
+	SweepRate float64   `yaml:"sweep_rate" json:"sweep_rate"` // Linear frequency sweep rate (Hz/s), used by harmonic sweep
+	JumpRate  float64   `yaml:"jump_rate" json:"jump_rate"`   // How often frequency jumps (Hz)
+	Amplitude float64   `yaml:"amplitude" json:"amplitude"`   // Layer amplitude [0, 1]
+	Rise      float64   `yaml:"rise" json:"rise"`             // Exponential amplitude rise over time
+	Seed      int64     `yaml:"seed" json:"seed"`             // RNG seed for this layer
+	Pan       float64   `yaml:"pan" json:"pan"`               // Stereo position [-1 (left), 1 (right)], 0 is center
+	PanRate   float64   `yaml:"pan_rate" json:"pan_rate"`     // Auto-pan LFO rate (Hz); 0 disables auto-pan
+	PanDepth  float64   `yaml:"pan_depth" json:"pan_depth"`   // Auto-pan LFO depth [0, 1], added to Pan
+
+	// Levels, if set, scales the layer's loudness over the scream; see
+	// LevelAt. Each level is in [0, 1].
+	Levels []float64 `yaml:"levels,omitempty" json:"levels,omitempty"`
+
+	// Envelope shapes the layer's loudness over time, and Tremolo wobbles
+	// it by up to Tremolo.Depth [0, 1] of the full level.
+	Envelope Envelope `yaml:"envelope" json:"envelope"`
+	Tremolo  LFO      `yaml:"tremolo" json:"tremolo"`
+
+	// Tonal layers only (all but the noise layers).
+	Pitch   PitchContour `yaml:"pitch" json:"pitch"`     // Bend of the pitch over the scream
+	Vibrato LFO          `yaml:"vibrato" json:"vibrato"` // Pitch wobble; Depth in semitones [0, 12]
+
+	// Primary scream, harmonic sweep and high shriek layers only.
+	Waveform   Waveform `yaml:"waveform" json:"waveform"`       // Wave the layer is built from; "" is a sine
+	PulseWidth float64  `yaml:"pulse_width" json:"pulse_width"` // Duty cycle of a pulse wave (0, 1)
+	Detune     float64  `yaml:"detune" json:"detune"`           // Spread of a supersaw's outer voices in cents [0, 100]
 
-// NoiseParams holds parameters for the noise layers.
-type NoiseParams struct {
-	BurstRate float64 // Burst frequency for gated noise (Hz)
-	Threshold float64 // Gate threshold [0, 1]
-	BurstAmp  float64 // Amplitude for noise bursts
-	FloorAmp  float64 // Amplitude for background noise floor
-	BurstSeed int64   // RNG seed for burst gating
+	// Noise burst and background noise layers only.
+	Noise NoiseParams `yaml:"noise" json:"noise"` // Colour of the noise; white by default
+
+	// Noise burst layers only.
+	BurstRate float64 `yaml:"burst_rate" json:"burst_rate"` // Burst frequency for gated noise (Hz)
+	Threshold float64 `yaml:"threshold" json:"threshold"`   // Gate threshold [0, 1]
+
+	// Vocal layers only.
+	Vowel   string  `yaml:"vowel" json:"vowel"`     // Vowels sung over the scream, e.g. "aaao"; see VowelFormants
+	Jitter  float64 `yaml:"jitter" json:"jitter"`   // Random pitch variation per glottal pulse [0, 1]
+	Shimmer float64 `yaml:"shimmer" json:"shimmer"` // Random loudness variation per glottal pulse [0, 1]
 }
 
 // FilterParams holds post-processing filter parameters.
 type FilterParams struct {
-	HighpassCutoff float64 // High-pass filter cutoff (Hz)
-	LowpassCutoff  float64 // Low-pass filter cutoff (Hz)
-	CrusherBits    int     // Bit depth for bitcrusher (6-12)
-	CrusherMix     float64 // Mix of crushed vs clean signal [0, 1]
-	CompRatio      float64 // Compressor ratio
-	CompThreshold  float64 // Compressor threshold in dB
-	CompAttack     float64 // Compressor attack in ms
-	CompRelease    float64 // Compressor release in ms
-	VolumeBoostDB  float64 // Volume boost in dB
-	LimiterLevel   float64 // Hard limiter level [0, 1]
+	HighpassCutoff float64 `yaml:"highpass_cutoff" json:"highpass_cutoff"` // High-pass filter cutoff (Hz)
+	LowpassCutoff  float64 `yaml:"lowpass_cutoff" json:"lowpass_cutoff"`   // Low-pass filter cutoff (Hz)
+	CrusherBits    int     `yaml:"crusher_bits" json:"crusher_bits"`       // Bit depth for bitcrusher (6-12)
+	CrusherMix     float64 `yaml:"crusher_mix" json:"crusher_mix"`         // Mix of crushed vs clean signal [0, 1]
+	CompRatio      float64 `yaml:"comp_ratio" json:"comp_ratio"`           // Compressor ratio
+	CompThreshold  float64 `yaml:"comp_threshold" json:"comp_threshold"`   // Compressor threshold in dB
+	CompAttack     float64 `yaml:"comp_attack" json:"comp_attack"`         // Compressor attack in ms
+	CompRelease    float64 `yaml:"comp_release" json:"comp_release"`       // Compressor release in ms
+	VolumeBoostDB  float64 `yaml:"volume_boost_db" json:"volume_boost_db"` // Volume boost in dB
+	LimiterLevel   float64 `yaml:"limiter_level" json:"limiter_level"`     // Limiter level [0, 1]
+
+	// Limiter selects how the limiter keeps the signal within LimiterLevel.
+	// The attack and release only apply to the lookahead limiter, and the
+	// attack is also how far it looks ahead.
+	Limiter        Limiter `yaml:"limiter" json:"limiter"`                 // "" is LimiterHard
+	LimiterAttack  float64 `yaml:"limiter_attack" json:"limiter_attack"`   // Attack in ms [0, 20]; 0 is DefaultLimiterAttack
+	LimiterRelease float64 `yaml:"limiter_release" json:"limiter_release"` // Release in ms [0, 2000]; 0 is DefaultLimiterRelease
+
+	// Biquad makes the high- and low-pass filters 12 dB/octave biquads that
+	// can resonate at their cutoff, in place of the default gentle 6
+	// dB/octave one-pole filters.
+	Biquad    bool    `yaml:"biquad" json:"biquad"`
+	HighpassQ float64 `yaml:"highpass_q" json:"highpass_q"` // Q of the biquad high-pass filter [0, 20]; 0 is ButterworthQ
+	LowpassQ  float64 `yaml:"lowpass_q" json:"lowpass_q"`   // Q of the biquad low-pass filter [0, 20]; 0 is ButterworthQ
+
+	// EQ shapes the tone after the low-pass filter with up to 8 bands of
+	// biquad filters, applied in order.
+	EQ []EQBand `yaml:"eq,omitempty" json:"eq,omitempty"`
+
+	// Reverb places the scream in a room. Its tail rings on past the
+	// scream's duration; see ScreamParams.Tail.
+	ReverbMix      float64 `yaml:"reverb_mix" json:"reverb_mix"`             // Mix of reverberated vs dry signal [0, 1]; 0 disables the reverb
+	ReverbRoom     float64 `yaml:"reverb_room" json:"reverb_room"`           // Room size [0, 1]; larger rooms ring for longer
+	ReverbDamping  float64 `yaml:"reverb_damping" json:"reverb_damping"`     // How quickly high frequencies die away in the tail [0, 1]
+	ReverbPreDelay float64 `yaml:"reverb_pre_delay" json:"reverb_pre_delay"` // Delay before the reverb starts in ms [0, 500]
+
+	// Delay repeats the scream as a train of echoes, ahead of the reverb.
+	// Like the reverb's, its tail rings on past the scream's duration.
+	DelayMix      float64 `yaml:"delay_mix" json:"delay_mix"`             // Level of the echoes against the dry signal [0, 1]; 0 disables the delay
+	DelayTime     float64 `yaml:"delay_time" json:"delay_time"`           // Time between echoes in ms (0, 2000]
+	DelayFeedback float64 `yaml:"delay_feedback" json:"delay_feedback"`   // Level of each echo against the last [0, 0.9]; 0 gives a single echo
+	DelayPingPong bool    `yaml:"delay_ping_pong" json:"delay_ping_pong"` // Bounce the echoes between the left and right channels of a stereo scream
+	DelayLowpass  float64 `yaml:"delay_lowpass" json:"delay_lowpass"`     // Cutoff in Hz of a low-pass filter darkening each repeat; 0 leaves them unfiltered
+
+	// Distortion saturates the signal after the low-pass filter and ahead
+	// of the bit-crusher.
+	Distortion      Distortion `yaml:"distortion" json:"distortion"`             // Waveshaping curve; "" disables the distortion
+	DistortionDrive float64    `yaml:"distortion_drive" json:"distortion_drive"` // Gain into the curve in dB [0, 48]
+	DistortionTone  float64    `yaml:"distortion_tone" json:"distortion_tone"`   // Cutoff in Hz of a low-pass filter taming the distortion's fizz; 0 leaves it unfiltered
 }
 
 // Randomize fills ScreamParams with random values matching the original bot's ranges.
@@ -100,67 +326,85 @@ func Randomize(seed int64) ScreamParams {
 
 	dur := rf(2.5, 4.0)
 
-	return ScreamParams{
+	params := ScreamParams{
 		Duration:   time.Duration(dur * float64(time.Second)),
 		SampleRate: DefaultSampleRate,
 		Channels:   DefaultChannels,
 		Seed:       seed,
-		Layers: [5]LayerParams{
-			{
-				Type:      LayerPrimaryScream,
-				BaseFreq:  rf(300, 700),
-				FreqRange: rf(800, 2500),
-				JumpRate:  rf(5, 15),
-				Amplitude: rf(0.3, 0.5),
-				Rise:      rf(0.5, 2.0),
-				Seed:      ri(1, 9999),
-			},
-			{
-				Type:      LayerHarmonicSweep,
-				BaseFreq:  rf(200, 500),
-				SweepRate: rf(300, 900),
-				FreqRange: rf(400, 1200),
-				JumpRate:  rf(3, 10),
-				Amplitude: rf(0.15, 0.3),
-				Seed:      ri(1, 9999),
-			},
-			{
-				Type:      LayerHighShriek,
-				BaseFreq:  rf(900, 1800),
-				FreqRange: rf(800, 2400),
-				JumpRate:  rf(10, 25),
-				Amplitude: rf(0.15, 0.3),
-				Rise:      rf(1.0, 3.0),
-				Seed:      ri(1, 9999),
-			},
-			{
-				Type:      LayerNoiseBurst,
-				Amplitude: rf(0.1, 0.25),
-				Seed:      ri(1, 9999),
-			},
-			{
-				Type:      LayerBackgroundNoise,
-				Amplitude: rf(0.05, 0.15),
-			},
+	}
+	params.Layers = []LayerParams{
+		{
+			Type:      LayerPrimaryScream,
+			BaseFreq:  rf(300, 700),
+			FreqRange: rf(800, 2500),
+			JumpRate:  rf(5, 15),
+			Amplitude: rf(0.3, 0.5),
+			Rise:      rf(0.5, 2.0),
+			Seed:      ri(1, 9999),
 		},
-		Noise: NoiseParams{
-			BurstRate: rf(3, 12),
-			Threshold: rf(0.5, 0.85),
-			BurstAmp:  rf(0.1, 0.25),
-			FloorAmp:  rf(0.05, 0.15),
-			BurstSeed: ri(1, 9999),
+		{
+			Type:      LayerHarmonicSweep,
+			BaseFreq:  rf(200, 500),
+			SweepRate: rf(300, 900),
+			FreqRange: rf(400, 1200),
+			JumpRate:  rf(3, 10),
+			Amplitude: rf(0.15, 0.3),
+			Seed:      ri(1, 9999),
 		},
-		Filter: func() FilterParams {
-			f := DefaultFilterParams()
-			f.HighpassCutoff = rf(80, 200)
-			f.LowpassCutoff = rf(6000, 12000)
-			f.CrusherBits = int(ri(6, 12))
-			f.CrusherMix = rf(0.3, 0.7)
-			f.CompRatio = rf(4, 12)
-			f.VolumeBoostDB = rf(6, 12)
-			return f
-		}(),
+		{
+			Type:      LayerHighShriek,
+			BaseFreq:  rf(900, 1800),
+			FreqRange: rf(800, 2400),
+			JumpRate:  rf(10, 25),
+			Amplitude: rf(0.15, 0.3),
+			Rise:      rf(1.0, 3.0),
+			Seed:      ri(1, 9999),
+		},
+	}
+
+	// The noise layers were once configured by a separate set of noise
+	// parameters, drawn after the layers. Drawing in the same order keeps
+	// every value drawn for a given seed the same; the first three draws went
+	// to noise layer fields that are no longer used. The noise layers' seeds
+	// did change: the burst layer's own seed was the unused draw and the
+	// background layer had none, while the noise was seeded by the noise
+	// parameters. Both layers now take that noise seed, so the noise sounds
+	// the same, but in stereo the noise layers auto-pan from a different
+	// phase, which is derived from the layer seeds, than they did.
+	rf(0.1, 0.25)
+	ri(1, 9999)
+	rf(0.05, 0.15)
+	burst := LayerParams{
+		Type:      LayerNoiseBurst,
+		BurstRate: rf(3, 12),
+		Threshold: rf(0.5, 0.85),
+		Amplitude: rf(0.1, 0.25),
+	}
+	floor := LayerParams{Type: LayerBackgroundNoise, Amplitude: rf(0.05, 0.15)}
+	burst.Seed = ri(1, 9999)
+	floor.Seed = burst.Seed
+	params.Layers = append(params.Layers, burst, floor)
+
+	params.Filter = DefaultFilterParams()
+	params.Filter.HighpassCutoff = rf(80, 200)
+	params.Filter.LowpassCutoff = rf(6000, 12000)
+	params.Filter.CrusherBits = int(ri(6, 12))
+	params.Filter.CrusherMix = rf(0.3, 0.7)
+	params.Filter.CompRatio = rf(4, 12)
+	params.Filter.VolumeBoostDB = rf(6, 12)
+	params.Envelope = DefaultEnvelope()
+
+	// Stereo placement is drawn last so that the values above stay the same
+	// for a given seed.
+	params.Width = rf(0.4, 0.9)
+	for i := range params.Layers {
+		params.Layers[i].Pan = rf(-0.6, 0.6)
+		if r.Float64() < 0.5 {
+			params.Layers[i].PanRate = rf(0.1, 0.6)
+			params.Layers[i].PanDepth = rf(0.2, 0.5)
+		}
 	}
+	return params
 }
 
 // Validate checks that all parameters are within valid ranges.
@@ -174,10 +418,55 @@ func (p ScreamParams) Validate() error {
 	if p.Channels != 1 && p.Channels != 2 {
 		return ErrInvalidChannels
 	}
+	if len(p.Layers) == 0 {
+		return ErrNoLayers
+	}
 	for i, l := range p.Layers {
+		if _, ok := layerTypeNames[l.Type]; !ok {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidLayerType}
+		}
 		if l.Amplitude < 0 || l.Amplitude > 1 {
 			return &LayerValidationError{Layer: i, Err: ErrInvalidAmplitude}
 		}
+		if l.Pan < -1 || l.Pan > 1 {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidPan}
+		}
+		if l.PanRate < 0 || l.PanDepth < 0 || l.PanDepth > 1 {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidAutoPan}
+		}
+		if !validVowel(l.Vowel) {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidVowel}
+		}
+		if l.Jitter < 0 || l.Jitter > 1 || l.Shimmer < 0 || l.Shimmer > 1 {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidPerturbation}
+		}
+		for _, v := range l.Levels {
+			if v < 0 || v > 1 {
+				return &LayerValidationError{Layer: i, Err: ErrInvalidLevels}
+			}
+		}
+		if !l.Envelope.validate() {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidEnvelope}
+		}
+		if !l.Pitch.validate() {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidPitch}
+		}
+		if l.Vibrato.Rate < 0 || l.Vibrato.Depth < 0 || l.Vibrato.Depth > maxVibratoDepth ||
+			l.Tremolo.Rate < 0 || l.Tremolo.Depth < 0 || l.Tremolo.Depth > 1 {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidModulation}
+		}
+		if !validWaveform(l) {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidWaveform}
+		}
+		if !validNoise(l, p.SampleRate) {
+			return &LayerValidationError{Layer: i, Err: ErrInvalidNoise}
+		}
+	}
+	if p.Width < 0 || p.Width > 1 {
+		return ErrInvalidWidth
+	}
+	if !p.Envelope.validate() {
+		return ErrInvalidEnvelope
 	}
 	if p.Filter.HighpassCutoff < 0 {
 		return ErrInvalidFilterCutoff
@@ -191,5 +480,55 @@ func (p ScreamParams) Validate() error {
 	if p.Filter.LimiterLevel <= 0 || p.Filter.LimiterLevel > 1 {
 		return ErrInvalidLimiterLevel
 	}
+	if !p.Filter.validLimiter() {
+		return ErrInvalidLimiter
+	}
+	if !p.Filter.validEQ(p.SampleRate) {
+		return ErrInvalidEQ
+	}
+	if !p.Filter.validReverb() {
+		return ErrInvalidReverb
+	}
+	if !p.Filter.validDelay() {
+		return ErrInvalidDelay
+	}
+	if !p.Filter.validDistortion() {
+		return ErrInvalidDistortion
+	}
+	if p.MorphTo != nil {
+		if p.MorphTo.MorphTo != nil {
+			return ErrInvalidMorph
+		}
+		if err := p.MorphEnd().Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidMorph, err)
+		}
+	}
+	if !p.validCrowd() {
+		return ErrInvalidVoices
+	}
+	for i := range p.VoiceMix {
+		if err := p.crowdVoiceBase(i + 1).Validate(); err != nil {
+			return fmt.Errorf("%w: voice mix %d: %w", ErrInvalidVoices, i, err)
+		}
+	}
 	return nil
 }
+
+// LevelAt returns the level at position pos in [0, 1] along levels, as set in
+// LayerParams.Levels. The levels are spaced evenly from 0 to 1 and glide
+// linearly between neighbours, like the vowels of VowelFormants, so
+// [0, 1, 1] rises to full loudness over the first half and holds it. Empty
+// levels are 1 throughout.
+func LevelAt(levels []float64, pos float64) float64 {
+	switch {
+	case len(levels) == 0:
+		return 1
+	case len(levels) == 1 || pos <= 0:
+		return levels[0]
+	case pos >= 1:
+		return levels[len(levels)-1]
+	}
+	x := pos * float64(len(levels)-1)
+	i := int(x)
+	return lerp(levels[i], levels[i+1], x-float64(i))
+}
@@ -1,7 +1,10 @@
 package audio
 
 import (
+	"encoding/json"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -34,13 +37,16 @@ func TestRandomize_ProducesValidParams(t *testing.T) {
 	}
 
 	// Check layer types are assigned correctly
-	expectedTypes := [5]LayerType{
+	expectedTypes := []LayerType{
 		LayerPrimaryScream,
 		LayerHarmonicSweep,
 		LayerHighShriek,
 		LayerNoiseBurst,
 		LayerBackgroundNoise,
 	}
+	if len(p.Layers) != len(expectedTypes) {
+		t.Fatalf("len(Layers) = %d, want %d", len(p.Layers), len(expectedTypes))
+	}
 	for i, l := range p.Layers {
 		if l.Type != expectedTypes[i] {
 			t.Errorf("Layer[%d].Type = %d, want %d", i, l.Type, expectedTypes[i])
@@ -67,12 +73,22 @@ func TestRandomize_ProducesValidParams(t *testing.T) {
 		t.Errorf("VolumeBoostDB = %f, want in [6, 12]", p.Filter.VolumeBoostDB)
 	}
 
-	// Check noise params ranges
-	if p.Noise.BurstRate < 3 || p.Noise.BurstRate > 12 {
-		t.Errorf("Noise.BurstRate = %f, want in [3, 12]", p.Noise.BurstRate)
+	// Check noise burst ranges
+	if burst := p.Layers[3]; burst.BurstRate < 3 || burst.BurstRate > 12 {
+		t.Errorf("Layers[3].BurstRate = %f, want in [3, 12]", burst.BurstRate)
+	}
+	if burst := p.Layers[3]; burst.Threshold < 0.5 || burst.Threshold > 0.85 {
+		t.Errorf("Layers[3].Threshold = %f, want in [0.5, 0.85]", burst.Threshold)
 	}
-	if p.Noise.Threshold < 0.5 || p.Noise.Threshold > 0.85 {
-		t.Errorf("Noise.Threshold = %f, want in [0.5, 0.85]", p.Noise.Threshold)
+
+	// Check stereo ranges
+	if p.Width < 0.4 || p.Width > 0.9 {
+		t.Errorf("Width = %f, want in [0.4, 0.9]", p.Width)
+	}
+	for i, l := range p.Layers {
+		if l.Pan < -0.6 || l.Pan > 0.6 {
+			t.Errorf("Layer[%d].Pan = %f, want in [-0.6, 0.6]", i, l.Pan)
+		}
 	}
 }
 
@@ -87,14 +103,11 @@ func TestRandomize_Deterministic(t *testing.T) {
 		t.Errorf("Seed mismatch: %d vs %d", p1.Seed, p2.Seed)
 	}
 	for i := range p1.Layers {
-		if p1.Layers[i] != p2.Layers[i] {
+		if !reflect.DeepEqual(p1.Layers[i], p2.Layers[i]) {
 			t.Errorf("Layer[%d] mismatch: %+v vs %+v", i, p1.Layers[i], p2.Layers[i])
 		}
 	}
-	if p1.Noise != p2.Noise {
-		t.Errorf("Noise mismatch: %+v vs %+v", p1.Noise, p2.Noise)
-	}
-	if p1.Filter != p2.Filter {
+	if !reflect.DeepEqual(p1.Filter, p2.Filter) {
 		t.Errorf("Filter mismatch: %+v vs %+v", p1.Filter, p2.Filter)
 	}
 }
@@ -189,6 +202,174 @@ func TestValidate_InvalidAmplitude(t *testing.T) {
 	}
 }
 
+func TestValidate_InvalidStereo(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*ScreamParams)
+		want   error
+	}{
+		{"pan below -1", func(p *ScreamParams) { p.Layers[1].Pan = -1.1 }, ErrInvalidPan},
+		{"pan above 1", func(p *ScreamParams) { p.Layers[2].Pan = 1.1 }, ErrInvalidPan},
+		{"negative pan rate", func(p *ScreamParams) { p.Layers[0].PanRate = -1 }, ErrInvalidAutoPan},
+		{"pan depth above 1", func(p *ScreamParams) { p.Layers[3].PanDepth = 1.5 }, ErrInvalidAutoPan},
+		{"negative width", func(p *ScreamParams) { p.Width = -0.1 }, ErrInvalidWidth},
+		{"width above 1", func(p *ScreamParams) { p.Width = 1.1 }, ErrInvalidWidth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			tt.mutate(&p)
+			if err := p.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_InvalidVocal(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*ScreamParams)
+		want   error
+	}{
+		{"unknown layer type", func(p *ScreamParams) { p.Layers[2].Type = LayerType(99) }, ErrInvalidLayerType},
+		{"vowel with consonant", func(p *ScreamParams) { p.Layers[0].Vowel = "aah" }, ErrInvalidVowel},
+		{"negative jitter", func(p *ScreamParams) { p.Layers[0].Jitter = -0.1 }, ErrInvalidPerturbation},
+		{"shimmer above 1", func(p *ScreamParams) { p.Layers[0].Shimmer = 1.5 }, ErrInvalidPerturbation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			p.Layers[0].Type = LayerVocal
+			tt.mutate(&p)
+			if err := p.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	p := validBaseParams()
+	p.Layers[0] = LayerParams{Type: LayerVocal, Amplitude: 0.4, Vowel: "AaO", Jitter: 0.02, Shimmer: 0.1}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() of a valid vocal layer = %v, want nil", err)
+	}
+}
+
+func TestValidate_InvalidLevels(t *testing.T) {
+	for _, levels := range [][]float64{{0, 1.5}, {-0.1}} {
+		p := validBaseParams()
+		p.Layers[1].Levels = levels
+		if err := p.Validate(); !errors.Is(err, ErrInvalidLevels) {
+			t.Errorf("Validate() with levels %v = %v, want ErrInvalidLevels", levels, err)
+		}
+	}
+
+	p := validBaseParams()
+	p.Layers[1].Levels = []float64{0, 1, 0.5}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() with valid levels = %v, want nil", err)
+	}
+}
+
+func TestLevelAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []float64
+		pos    float64
+		want   float64
+	}{
+		{"empty is full", nil, 0.3, 1},
+		{"single level", []float64{0.4}, 0.9, 0.4},
+		{"start", []float64{0, 1}, 0, 0},
+		{"end", []float64{0, 1}, 1, 1},
+		{"past the end", []float64{0, 1}, 1.5, 1},
+		{"glide", []float64{0, 1}, 0.25, 0.25},
+		{"held level", []float64{0, 1, 1}, 0.75, 1},
+		{"second segment", []float64{1, 1, 0}, 0.75, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LevelAt(tt.levels, tt.pos); got != tt.want {
+				t.Errorf("LevelAt(%v, %v) = %v, want %v", tt.levels, tt.pos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_NoLayers(t *testing.T) {
+	p := validBaseParams()
+	p.Layers = nil
+	if err := p.Validate(); !errors.Is(err, ErrNoLayers) {
+		t.Errorf("Validate() = %v, want ErrNoLayers", err)
+	}
+
+	p.Layers = []LayerParams{{Type: LayerHighShriek, Amplitude: 0.3}, {Type: LayerHighShriek, Amplitude: 0.2}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() with two shrieks = %v, want nil", err)
+	}
+}
+
+func TestScreamParams_Clone(t *testing.T) {
+	a := validBaseParams()
+	end := validBaseParams()
+	a.MorphTo = &end
+
+	a.Layers[0].Levels = []float64{0, 1}
+	a.Layers[0].Envelope.Points = []EnvelopePoint{{0, 1}}
+	a.Envelope.Points = []EnvelopePoint{{0, 1}}
+	a.Layers[0].Pitch.Points = []PitchPoint{{0, 1}}
+	a.Filter.EQ = []EQBand{{Type: EQPeak, Freq: 1000}}
+	a.VoiceMix = []ScreamParams{validBaseParams()}
+
+	b := a.Clone()
+	b.Layers[0].Amplitude = 0.9
+	b.Layers[0].Levels[1] = 0.9
+	b.Layers[0].Envelope.Points[0].Level = 0.9
+	b.Envelope.Points[0].Level = 0.9
+	b.Layers[0].Pitch.Points[0].Semitones = 0.9
+	b.Filter.EQ[0].Gain = 0.9
+	b.MorphTo.Layers[0].Amplitude = 0.9
+	if a.Layers[0].Amplitude == 0.9 || a.Layers[0].Levels[1] == 0.9 || end.Layers[0].Amplitude == 0.9 {
+		t.Error("modifying a clone changed the original")
+	}
+	if a.Layers[0].Envelope.Points[0].Level == 0.9 || a.Envelope.Points[0].Level == 0.9 {
+		t.Error("modifying a clone's envelope points changed the original")
+	}
+	if a.Layers[0].Pitch.Points[0].Semitones == 0.9 {
+		t.Error("modifying a clone's pitch points changed the original")
+	}
+	if a.Filter.EQ[0].Gain == 0.9 {
+		t.Error("modifying a clone's EQ bands changed the original")
+	}
+	b.VoiceMix[0].Layers[0].Amplitude = 0.9
+	if a.VoiceMix[0].Layers[0].Amplitude == 0.9 {
+		t.Error("modifying a clone's voice mix changed the original")
+	}
+}
+
+func TestIsStereo(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels int
+		width    float64
+		want     bool
+	}{
+		{"stereo with width", 2, 0.5, true},
+		{"stereo without width", 2, 0, false},
+		{"mono with width", 1, 0.5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			p.Channels = tt.channels
+			p.Width = tt.width
+			if got := p.IsStereo(); got != tt.want {
+				t.Errorf("IsStereo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestValidate_InvalidLimiterLevel(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -217,7 +398,7 @@ func validBaseParams() ScreamParams {
 		Duration:   3 * time.Second,
 		SampleRate: 48000,
 		Channels:   2,
-		Layers: [5]LayerParams{
+		Layers: []LayerParams{
 			{Type: LayerPrimaryScream, Amplitude: 0.4},
 			{Type: LayerHarmonicSweep, Amplitude: 0.25},
 			{Type: LayerHighShriek, Amplitude: 0.25},
@@ -238,3 +419,65 @@ func validBaseParams() ScreamParams {
 		},
 	}
 }
+
+func TestLayerType_TextRoundTrip(t *testing.T) {
+	for lt := LayerPrimaryScream; lt <= LayerVocal; lt++ {
+		text, err := lt.MarshalText()
+		if err != nil {
+			t.Fatalf("%d.MarshalText() unexpected error: %v", lt, err)
+		}
+		var got LayerType
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%q) unexpected error: %v", text, err)
+		}
+		if got != lt {
+			t.Errorf("UnmarshalText(%q) = %d, want %d", text, got, lt)
+		}
+	}
+}
+
+func TestLayerType_InvalidText(t *testing.T) {
+	var lt LayerType
+	if err := lt.UnmarshalText([]byte("kazoo")); !errors.Is(err, ErrInvalidLayerType) {
+		t.Errorf("UnmarshalText(kazoo) error = %v, want ErrInvalidLayerType", err)
+	}
+	if _, err := LayerType(99).MarshalText(); !errors.Is(err, ErrInvalidLayerType) {
+		t.Errorf("LayerType(99).MarshalText() error = %v, want ErrInvalidLayerType", err)
+	}
+	if got := LayerType(99).String(); got != "LayerType(99)" {
+		t.Errorf("LayerType(99).String() = %q, want %q", got, "LayerType(99)")
+	}
+}
+
+func TestScreamParams_JSONRoundTrip(t *testing.T) {
+	cases := map[string]ScreamParams{"random": Randomize(987)}
+	for _, name := range AllPresets() {
+		cases[string(name)], _ = GetPreset(name)
+	}
+
+	for name, want := range cases {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(want)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			if !strings.Contains(string(data), `"duration":"`+want.Duration.String()+`"`) {
+				t.Errorf("JSON %s does not write duration as a string", data)
+			}
+			var got ScreamParams
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip:\n got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestScreamParams_UnmarshalJSONInvalidDuration(t *testing.T) {
+	var p ScreamParams
+	if err := json.Unmarshal([]byte(`{"duration":"forever"}`), &p); err == nil {
+		t.Error("json.Unmarshal with an invalid duration succeeded, want error")
+	}
+}
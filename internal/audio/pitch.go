@@ -0,0 +1,105 @@
+package audio
+
+// maxSemitones bounds how far a pitch contour may bend a layer's pitch, in
+// either direction.
+const maxSemitones = 48
+
+// maxVibratoDepth bounds the depth of a vibrato, in semitones.
+const maxVibratoDepth = 12
+
+// PitchCurve names a ready-made pitch contour.
+type PitchCurve string
+
+const (
+	PitchFlat    PitchCurve = ""        // no bend
+	PitchRising  PitchCurve = "rising"  // rises steadily by Semitones over the scream
+	PitchFalling PitchCurve = "falling" // falls steadily by Semitones over the scream
+	PitchArch    PitchCurve = "arch"    // rises by Semitones until halfway, then falls back
+)
+
+// PitchContour bends a layer's pitch over the scream, on top of its jumps
+// and sweep. The bend is in semitones, so it moves every pitch of the layer
+// by the same musical interval. Points, if set, replace Curve with a line
+// through the given breakpoints.
+type PitchContour struct {
+	Curve     PitchCurve `yaml:"curve" json:"curve"`
+	Semitones float64    `yaml:"semitones" json:"semitones"` // How far Curve bends the pitch
+
+	// Points, if set, is the bend at given times, in time order; the bend
+	// moves linearly between them and holds the nearest one outside them.
+	Points []PitchPoint `yaml:"points,omitempty" json:"points,omitempty"`
+}
+
+// PitchPoint is a breakpoint of a PitchContour.
+type PitchPoint struct {
+	Time      float64 `yaml:"time" json:"time"`           // Seconds from the start of the scream
+	Semitones float64 `yaml:"semitones" json:"semitones"` // Bend, negative for lower
+}
+
+// LFO is a sine wave low-frequency oscillator that modulates a layer. The
+// meaning of Depth depends on what it modulates.
+type LFO struct {
+	Rate  float64 `yaml:"rate" json:"rate"`   // Hz; 0 disables the LFO
+	Depth float64 `yaml:"depth" json:"depth"` // Peak modulation
+}
+
+// On reports whether l modulates anything.
+func (l LFO) On() bool {
+	return l.Rate > 0 && l.Depth > 0
+}
+
+// Shape returns the bend c traces over a scream of length seconds, as
+// breakpoints with levels in semitones in the form accepted by ShapeAt, or
+// nil if c does not bend the pitch. ShapeAt of a nil shape is 1, not 0, so
+// callers must check for nil.
+func (c PitchContour) Shape(length float64) []EnvelopePoint {
+	if len(c.Points) > 0 {
+		shape := make([]EnvelopePoint, len(c.Points))
+		for i, p := range c.Points {
+			shape[i] = EnvelopePoint{Time: p.Time, Level: p.Semitones}
+		}
+		return shape
+	}
+	switch {
+	case c.Semitones == 0:
+		return nil
+	case c.Curve == PitchRising:
+		return []EnvelopePoint{{0, 0}, {length, c.Semitones}}
+	case c.Curve == PitchFalling:
+		return []EnvelopePoint{{0, 0}, {length, -c.Semitones}}
+	case c.Curve == PitchArch:
+		return []EnvelopePoint{{0, 0}, {length / 2, c.Semitones}, {length, 0}}
+	default:
+		return nil
+	}
+}
+
+// validate reports whether c has a known curve, every bend is within
+// maxSemitones and the breakpoints are at non-negative times in time order.
+func (c PitchContour) validate() bool {
+	switch c.Curve {
+	case PitchFlat, PitchRising, PitchFalling, PitchArch:
+	default:
+		return false
+	}
+	if c.Semitones < -maxSemitones || c.Semitones > maxSemitones {
+		return false
+	}
+	for i, p := range c.Points {
+		if p.Time < 0 || p.Semitones < -maxSemitones || p.Semitones > maxSemitones {
+			return false
+		}
+		if i > 0 && p.Time < c.Points[i-1].Time {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns a copy of c that shares no breakpoints with c.
+func (c PitchContour) clone() PitchContour {
+	if c.Points != nil {
+		c.Points = append([]PitchPoint(nil), c.Points...)
+	}
+	return c
+}
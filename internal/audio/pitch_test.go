@@ -0,0 +1,108 @@
+package audio
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// PitchContour.Shape()
+// ---------------------------------------------------------------------------
+
+func TestPitchContour_Shape(t *testing.T) {
+	tests := []struct {
+		name  string
+		pitch PitchContour
+		want  []EnvelopePoint
+	}{
+		{"zero is flat", PitchContour{}, nil},
+		{"curve without semitones is flat", PitchContour{Curve: PitchRising}, nil},
+		{"semitones without curve is flat", PitchContour{Semitones: 5}, nil},
+		{"rising", PitchContour{Curve: PitchRising, Semitones: 12}, []EnvelopePoint{{0, 0}, {4, 12}}},
+		{"falling", PitchContour{Curve: PitchFalling, Semitones: 7}, []EnvelopePoint{{0, 0}, {4, -7}}},
+		{"arch", PitchContour{Curve: PitchArch, Semitones: 5}, []EnvelopePoint{{0, 0}, {2, 5}, {4, 0}}},
+		{
+			"points replace the curve",
+			PitchContour{Curve: PitchRising, Semitones: 12, Points: []PitchPoint{{1, -3}, {3, 2}}},
+			[]EnvelopePoint{{1, -3}, {3, 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pitch.Shape(4); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Shape(4) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLFO_On(t *testing.T) {
+	tests := []struct {
+		lfo  LFO
+		want bool
+	}{
+		{LFO{}, false},
+		{LFO{Rate: 5}, false},
+		{LFO{Depth: 1}, false},
+		{LFO{Rate: 5, Depth: 1}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.lfo.On(); got != tt.want {
+			t.Errorf("%+v.On() = %v, want %v", tt.lfo, got, tt.want)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Validate() with pitch contours and LFOs
+// ---------------------------------------------------------------------------
+
+func TestValidate_InvalidPitch(t *testing.T) {
+	for _, pitch := range []PitchContour{
+		{Curve: "wobble", Semitones: 1},
+		{Curve: PitchRising, Semitones: 49},
+		{Curve: PitchFalling, Semitones: -49},
+		{Points: []PitchPoint{{-1, 0}}},
+		{Points: []PitchPoint{{0, 60}}},
+		{Points: []PitchPoint{{1, 0}, {0.5, 1}}},
+	} {
+		p := validBaseParams()
+		p.Layers[1].Pitch = pitch
+		err := p.Validate()
+		var lve *LayerValidationError
+		if !errors.As(err, &lve) || lve.Layer != 1 || !errors.Is(err, ErrInvalidPitch) {
+			t.Errorf("Validate() with pitch %+v = %v, want layer 1 ErrInvalidPitch", pitch, err)
+		}
+	}
+}
+
+func TestValidate_InvalidModulation(t *testing.T) {
+	for _, layer := range []LayerParams{
+		{Vibrato: LFO{Rate: -1}},
+		{Vibrato: LFO{Depth: -1}},
+		{Vibrato: LFO{Rate: 5, Depth: 13}},
+		{Tremolo: LFO{Rate: -1}},
+		{Tremolo: LFO{Depth: -0.1}},
+		{Tremolo: LFO{Rate: 5, Depth: 1.5}},
+	} {
+		p := validBaseParams()
+		p.Layers[2].Vibrato = layer.Vibrato
+		p.Layers[2].Tremolo = layer.Tremolo
+		err := p.Validate()
+		var lve *LayerValidationError
+		if !errors.As(err, &lve) || lve.Layer != 2 || !errors.Is(err, ErrInvalidModulation) {
+			t.Errorf("Validate() with vibrato %+v and tremolo %+v = %v, want layer 2 ErrInvalidModulation",
+				layer.Vibrato, layer.Tremolo, err)
+		}
+	}
+
+	p := validBaseParams()
+	p.Layers[0].Pitch = PitchContour{Curve: PitchArch, Semitones: -48}
+	p.Layers[0].Vibrato = LFO{Rate: 6, Depth: 12}
+	p.Layers[0].Tremolo = LFO{Rate: 10, Depth: 1}
+	p.Layers[1].Pitch.Points = []PitchPoint{{0, 0}, {1, 12}, {1, -12}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() with valid modulation = %v, want nil", err)
+	}
+}
@@ -12,6 +12,7 @@ const (
 	PresetGlitch     PresetName = "glitch"
 	PresetBanshee    PresetName = "banshee"
 	PresetRobot      PresetName = "robot"
+	PresetWail       PresetName = "wail"
 )
 
 // AllPresets returns a list of all available preset names.
@@ -23,13 +24,14 @@ func AllPresets() []PresetName {
 		PresetGlitch,
 		PresetBanshee,
 		PresetRobot,
+		PresetWail,
 	}
 }
 
 // GetPreset returns the ScreamParams for a named preset.
 func GetPreset(name PresetName) (ScreamParams, bool) {
 	p, ok := presets[name]
-	return p, ok
+	return p.Clone(), ok
 }
 
 func classicFilter() FilterParams {
@@ -62,6 +64,12 @@ func deathMetalFilter() FilterParams {
 	filter.CrusherMix = 0.7
 	filter.CompRatio = 12
 	filter.VolumeBoostDB = 12
+	// Saturated throat. The layers already peak well above full scale, so
+	// a little drive goes a long way; it holds the level up through the
+	// fade out, which is made longer to make up for it.
+	filter.Distortion = DistortionTube
+	filter.DistortionDrive = 6
+	filter.DistortionTone = 6000
 	return filter
 }
 
@@ -84,6 +92,11 @@ func bansheeFilter() FilterParams {
 	filter.CrusherMix = 0.4
 	filter.CompRatio = 6
 	filter.VolumeBoostDB = 10
+	// A long stone corridor.
+	filter.ReverbMix = 0.35
+	filter.ReverbRoom = 0.75
+	filter.ReverbDamping = 0.3
+	filter.ReverbPreDelay = 40
 	return filter
 }
 
@@ -98,89 +111,123 @@ func robotFilter() FilterParams {
 	return filter
 }
 
+func wailFilter() FilterParams {
+	filter := DefaultFilterParams()
+	filter.HighpassCutoff = 90
+	filter.LowpassCutoff = 9000
+	filter.CrusherBits = 12
+	filter.CrusherMix = 0.2
+	filter.CompRatio = 6
+	filter.VolumeBoostDB = 11
+	filter.ReverbMix = 0.25
+	filter.ReverbRoom = 0.6
+	filter.ReverbDamping = 0.4
+	return filter
+}
+
 var presets = map[PresetName]ScreamParams{
 	PresetClassic: {
 		Duration:   3 * time.Second,
 		SampleRate: DefaultSampleRate,
 		Channels:   DefaultChannels,
-		Layers: [5]LayerParams{
+		Layers: []LayerParams{
 			{Type: LayerPrimaryScream, BaseFreq: 500, FreqRange: 1500, JumpRate: 10, Amplitude: 0.4, Rise: 1.2, Seed: 4242},
-			{Type: LayerHarmonicSweep, BaseFreq: 350, SweepRate: 500, FreqRange: 800, JumpRate: 6, Amplitude: 0.25, Seed: 3000},
-			{Type: LayerHighShriek, BaseFreq: 1200, FreqRange: 1600, JumpRate: 20, Amplitude: 0.25, Rise: 2.5, Seed: 7000},
-			{Type: LayerNoiseBurst, Amplitude: 0.18, Seed: 4000},
-			{Type: LayerBackgroundNoise, Amplitude: 0.1},
+			{Type: LayerHarmonicSweep, BaseFreq: 350, SweepRate: 500, FreqRange: 800, JumpRate: 6, Amplitude: 0.25, Seed: 3000, Pan: -0.3},
+			{Type: LayerHighShriek, BaseFreq: 1200, FreqRange: 1600, JumpRate: 20, Amplitude: 0.25, Rise: 2.5, Seed: 7000, Pan: 0.3, PanRate: 0.25, PanDepth: 0.4},
+			{Type: LayerNoiseBurst, BurstRate: 8, Threshold: 0.7, Amplitude: 0.18, Seed: 4000, PanRate: 0.5, PanDepth: 0.5},
+			{Type: LayerBackgroundNoise, Amplitude: 0.1, Seed: 4000},
 		},
-		Noise:  NoiseParams{BurstRate: 8, Threshold: 0.7, BurstAmp: 0.18, FloorAmp: 0.1, BurstSeed: 4000},
-		Filter: classicFilter(),
+		Filter:   classicFilter(),
+		Envelope: Envelope{Attack: 0.02, Release: 0.3},
+		Width:    0.6,
 	},
 	PresetWhisper: {
 		Duration:   2 * time.Second,
 		SampleRate: DefaultSampleRate,
 		Channels:   DefaultChannels,
-		Layers: [5]LayerParams{
-			{Type: LayerPrimaryScream, BaseFreq: 300, FreqRange: 500, JumpRate: 5, Amplitude: 0.15, Rise: 0.3, Seed: 1111},
-			{Type: LayerHarmonicSweep, BaseFreq: 200, SweepRate: 150, FreqRange: 300, JumpRate: 3, Amplitude: 0.1, Seed: 2222},
-			{Type: LayerHighShriek, BaseFreq: 900, FreqRange: 400, JumpRate: 8, Amplitude: 0.08, Rise: 0.5, Seed: 3333},
-			{Type: LayerNoiseBurst, Amplitude: 0.05, Seed: 4444},
-			{Type: LayerBackgroundNoise, Amplitude: 0.12},
+		Layers: []LayerParams{
+			{Type: LayerPrimaryScream, BaseFreq: 300, FreqRange: 500, JumpRate: 5, Amplitude: 0.15, Rise: 0.3, Seed: 1111, Pan: -0.2, PanRate: 0.15, PanDepth: 0.6},
+			{Type: LayerHarmonicSweep, BaseFreq: 200, SweepRate: 150, FreqRange: 300, JumpRate: 3, Amplitude: 0.1, Seed: 2222, Pan: 0.2, PanRate: 0.12, PanDepth: 0.6},
+			{Type: LayerHighShriek, BaseFreq: 900, FreqRange: 400, JumpRate: 8, Amplitude: 0.08, Rise: 0.5, Seed: 3333, PanRate: 0.2, PanDepth: 0.5},
+			{Type: LayerNoiseBurst, BurstRate: 3, Threshold: 0.85, Amplitude: 0.05, Seed: 4444, PanRate: 0.3, PanDepth: 0.7},
+			{Type: LayerBackgroundNoise, Amplitude: 0.12, Seed: 4444},
 		},
-		Noise:  NoiseParams{BurstRate: 3, Threshold: 0.85, BurstAmp: 0.05, FloorAmp: 0.12, BurstSeed: 4444},
-		Filter: whisperFilter(),
+		Filter:   whisperFilter(),
+		Envelope: Envelope{Attack: 0.4, Release: 0.6},
+		Width:    0.8,
 	},
 	PresetDeathMetal: {
 		Duration:   4 * time.Second,
 		SampleRate: DefaultSampleRate,
 		Channels:   DefaultChannels,
-		Layers: [5]LayerParams{
+		Layers: []LayerParams{
 			{Type: LayerPrimaryScream, BaseFreq: 150, FreqRange: 800, JumpRate: 15, Amplitude: 0.5, Rise: 2.0, Seed: 6660},
-			{Type: LayerHarmonicSweep, BaseFreq: 100, SweepRate: 200, FreqRange: 600, JumpRate: 10, Amplitude: 0.3, Seed: 6661},
-			{Type: LayerHighShriek, BaseFreq: 600, FreqRange: 2400, JumpRate: 25, Amplitude: 0.3, Rise: 3.0, Seed: 6662},
-			{Type: LayerNoiseBurst, Amplitude: 0.25, Seed: 6663},
-			{Type: LayerBackgroundNoise, Amplitude: 0.15},
+			{Type: LayerHarmonicSweep, BaseFreq: 100, SweepRate: 200, FreqRange: 600, JumpRate: 10, Amplitude: 0.3, Seed: 6661, Pan: -0.4},
+			{Type: LayerHighShriek, BaseFreq: 600, FreqRange: 2400, JumpRate: 25, Amplitude: 0.3, Rise: 3.0, Seed: 6662, Pan: 0.4},
+			{Type: LayerNoiseBurst, BurstRate: 12, Threshold: 0.5, Amplitude: 0.25, Seed: 6663, PanRate: 0.8, PanDepth: 0.3},
+			{Type: LayerBackgroundNoise, Amplitude: 0.15, Seed: 6663},
 		},
-		Noise:  NoiseParams{BurstRate: 12, Threshold: 0.5, BurstAmp: 0.25, FloorAmp: 0.15, BurstSeed: 6663},
-		Filter: deathMetalFilter(),
+		Filter:   deathMetalFilter(),
+		Envelope: Envelope{Attack: 0.005, Decay: 0.3, Sustain: 0.8, Release: 0.5},
+		Width:    0.5,
 	},
 	PresetGlitch: {
 		Duration:   3 * time.Second,
 		SampleRate: DefaultSampleRate,
 		Channels:   DefaultChannels,
-		Layers: [5]LayerParams{
-			{Type: LayerPrimaryScream, BaseFreq: 700, FreqRange: 2500, JumpRate: 15, Amplitude: 0.35, Rise: 0.5, Seed: 1337},
-			{Type: LayerHarmonicSweep, BaseFreq: 500, SweepRate: 900, FreqRange: 1200, JumpRate: 10, Amplitude: 0.2, Seed: 1338},
-			{Type: LayerHighShriek, BaseFreq: 1800, FreqRange: 2400, JumpRate: 25, Amplitude: 0.2, Rise: 1.0, Seed: 1339},
-			{Type: LayerNoiseBurst, Amplitude: 0.22, Seed: 1340},
-			{Type: LayerBackgroundNoise, Amplitude: 0.05},
+		Layers: []LayerParams{
+			{Type: LayerPrimaryScream, BaseFreq: 700, FreqRange: 2500, JumpRate: 15, Amplitude: 0.35, Rise: 0.5, Seed: 1337, PanRate: 2, PanDepth: 0.6},
+			{Type: LayerHarmonicSweep, BaseFreq: 500, SweepRate: 900, FreqRange: 1200, JumpRate: 10, Amplitude: 0.2, Seed: 1338, Pan: -0.5, PanRate: 3, PanDepth: 0.5},
+			{Type: LayerHighShriek, BaseFreq: 1800, FreqRange: 2400, JumpRate: 25, Amplitude: 0.2, Rise: 1.0, Seed: 1339, Pan: 0.5, PanRate: 4, PanDepth: 0.5},
+			{Type: LayerNoiseBurst, BurstRate: 12, Threshold: 0.5, Amplitude: 0.22, Seed: 1340, PanRate: 6, PanDepth: 0.8},
+			{Type: LayerBackgroundNoise, Amplitude: 0.05, Seed: 1340},
 		},
-		Noise:  NoiseParams{BurstRate: 12, Threshold: 0.5, BurstAmp: 0.22, FloorAmp: 0.05, BurstSeed: 1340},
-		Filter: glitchFilter(),
+		Filter:   glitchFilter(),
+		Envelope: Envelope{Attack: 0.005, Release: 0.05},
+		Width:    1,
 	},
 	PresetBanshee: {
 		Duration:   4 * time.Second,
 		SampleRate: DefaultSampleRate,
 		Channels:   DefaultChannels,
-		Layers: [5]LayerParams{
-			{Type: LayerPrimaryScream, BaseFreq: 600, FreqRange: 2000, JumpRate: 8, Amplitude: 0.45, Rise: 2.0, Seed: 9001},
-			{Type: LayerHarmonicSweep, BaseFreq: 400, SweepRate: 800, FreqRange: 1000, JumpRate: 5, Amplitude: 0.25, Seed: 9002},
-			{Type: LayerHighShriek, BaseFreq: 1500, FreqRange: 2400, JumpRate: 12, Amplitude: 0.3, Rise: 3.0, Seed: 9003},
-			{Type: LayerNoiseBurst, Amplitude: 0.1, Seed: 9004},
-			{Type: LayerBackgroundNoise, Amplitude: 0.08},
+		Layers: []LayerParams{
+			{Type: LayerPrimaryScream, BaseFreq: 600, FreqRange: 2000, JumpRate: 8, Amplitude: 0.45, Rise: 2.0, Seed: 9001, PanRate: 0.3, PanDepth: 0.7},
+			{Type: LayerHarmonicSweep, BaseFreq: 400, SweepRate: 800, FreqRange: 1000, JumpRate: 5, Amplitude: 0.25, Seed: 9002, Pan: -0.3},
+			{Type: LayerHighShriek, BaseFreq: 1500, FreqRange: 2400, JumpRate: 12, Amplitude: 0.3, Rise: 3.0, Seed: 9003, PanRate: 0.45, PanDepth: 0.8},
+			{Type: LayerNoiseBurst, BurstRate: 5, Threshold: 0.8, Amplitude: 0.1, Seed: 9004, PanRate: 0.2, PanDepth: 0.5},
+			{Type: LayerBackgroundNoise, Amplitude: 0.08, Seed: 9004},
 		},
-		Noise:  NoiseParams{BurstRate: 5, Threshold: 0.8, BurstAmp: 0.1, FloorAmp: 0.08, BurstSeed: 9004},
-		Filter: bansheeFilter(),
+		Filter:   bansheeFilter(),
+		Envelope: Envelope{Attack: 0.5, Release: 1},
+		Width:    0.9,
 	},
 	PresetRobot: {
 		Duration:   3 * time.Second,
 		SampleRate: DefaultSampleRate,
 		Channels:   DefaultChannels,
-		Layers: [5]LayerParams{
-			{Type: LayerPrimaryScream, BaseFreq: 400, FreqRange: 1000, JumpRate: 12, Amplitude: 0.4, Rise: 0.5, Seed: 8080},
-			{Type: LayerHarmonicSweep, BaseFreq: 300, SweepRate: 600, FreqRange: 500, JumpRate: 8, Amplitude: 0.2, Seed: 8081},
-			{Type: LayerHighShriek, BaseFreq: 1000, FreqRange: 1200, JumpRate: 20, Amplitude: 0.2, Rise: 1.0, Seed: 8082},
-			{Type: LayerNoiseBurst, Amplitude: 0.15, Seed: 8083},
-			{Type: LayerBackgroundNoise, Amplitude: 0.07},
+		Layers: []LayerParams{
+			{Type: LayerPrimaryScream, BaseFreq: 400, FreqRange: 1000, JumpRate: 12, Amplitude: 0.4, Rise: 0.5, Seed: 8080, Waveform: WaveSquare},
+			{Type: LayerHarmonicSweep, BaseFreq: 300, SweepRate: 600, FreqRange: 500, JumpRate: 8, Amplitude: 0.2, Seed: 8081, Pan: -0.5, Waveform: WavePulse, PulseWidth: 0.2},
+			{Type: LayerHighShriek, BaseFreq: 1000, FreqRange: 1200, JumpRate: 20, Amplitude: 0.2, Rise: 1.0, Seed: 8082, Pan: 0.5},
+			{Type: LayerNoiseBurst, BurstRate: 10, Threshold: 0.6, Amplitude: 0.15, Seed: 8083},
+			{Type: LayerBackgroundNoise, Amplitude: 0.07, Seed: 8083},
+		},
+		Filter:   robotFilter(),
+		Envelope: Envelope{Attack: 0.01, Release: 0.1},
+		Width:    0.4,
+	},
+	PresetWail: {
+		Duration:   3500 * time.Millisecond,
+		SampleRate: DefaultSampleRate,
+		Channels:   DefaultChannels,
+		Layers: []LayerParams{
+			{Type: LayerVocal, BaseFreq: 220, FreqRange: 350, JumpRate: 3, Amplitude: 0.45, Rise: 1.5, Seed: 2101, Vowel: "aaaoou", Jitter: 0.03, Shimmer: 0.15, Pan: -0.2},
+			{Type: LayerVocal, BaseFreq: 440, FreqRange: 500, JumpRate: 6, Amplitude: 0.25, Rise: 2.5, Seed: 2102, Vowel: "eeea", Jitter: 0.05, Shimmer: 0.2, Pan: 0.3},
+			{Type: LayerNoiseBurst, BurstRate: 4, Threshold: 0.8, Amplitude: 0.08, Seed: 2103, Noise: NoiseParams{Color: NoiseBreath}},
+			{Type: LayerBackgroundNoise, Amplitude: 0.06, Seed: 2103, Noise: NoiseParams{Color: NoiseBreath}},
 		},
-		Noise:  NoiseParams{BurstRate: 10, Threshold: 0.6, BurstAmp: 0.15, FloorAmp: 0.07, BurstSeed: 8083},
-		Filter: robotFilter(),
+		Filter:   wailFilter(),
+		Envelope: Envelope{Attack: 0.15, Release: 0.6},
+		Width:    0.6,
 	},
 }
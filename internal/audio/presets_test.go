@@ -4,10 +4,10 @@ import (
 	"testing"
 )
 
-func TestAllPresets_ReturnsAll6(t *testing.T) {
+func TestAllPresets_ReturnsAll7(t *testing.T) {
 	presets := AllPresets()
-	if len(presets) != 6 {
-		t.Fatalf("AllPresets() returned %d presets, want 6", len(presets))
+	if len(presets) != 7 {
+		t.Fatalf("AllPresets() returned %d presets, want 7", len(presets))
 	}
 
 	// Verify all expected names are present
@@ -18,6 +18,7 @@ func TestAllPresets_ReturnsAll6(t *testing.T) {
 		PresetGlitch:     true,
 		PresetBanshee:    true,
 		PresetRobot:      true,
+		PresetWail:       true,
 	}
 	for _, name := range presets {
 		if !expected[name] {
@@ -51,6 +52,14 @@ func TestGetPreset_Unknown(t *testing.T) {
 	}
 }
 
+func TestGetPreset_ReturnsCopy(t *testing.T) {
+	p, _ := GetPreset(PresetClassic)
+	p.Layers[0].Amplitude = 1
+	if again, _ := GetPreset(PresetClassic); again.Layers[0].Amplitude == 1 {
+		t.Error("modifying a preset returned by GetPreset changed the preset")
+	}
+}
+
 func TestGetPreset_ParameterRanges(t *testing.T) {
 	for _, name := range AllPresets() {
 		t.Run(string(name), func(t *testing.T) {
@@ -68,6 +77,37 @@ func TestGetPreset_ParameterRanges(t *testing.T) {
 			if p.Duration <= 0 {
 				t.Errorf("Duration = %v, want > 0", p.Duration)
 			}
+			if !p.IsStereo() {
+				t.Errorf("Width = %v, want a stereo default", p.Width)
+			}
 		})
 	}
 }
+
+func TestPresets_HaveEnvelopes(t *testing.T) {
+	for _, name := range AllPresets() {
+		p, _ := GetPreset(name)
+		shape := p.Envelope.Shape(p.Duration.Seconds())
+		if ShapeAt(shape, 0) != 0 || ShapeAt(shape, p.Duration.Seconds()) != 0 {
+			t.Errorf("preset %s does not fade in and out", name)
+		}
+	}
+}
+
+func TestPresets_RobotUsesSquareWaves(t *testing.T) {
+	p, _ := GetPreset(PresetRobot)
+	for _, i := range []int{0, 1} {
+		if w := p.Layers[i].Waveform; w != WaveSquare && w != WavePulse {
+			t.Errorf("robot layer %d waveform = %q, want square or pulse", i, w)
+		}
+	}
+}
+
+func TestPresets_WailSingsVowels(t *testing.T) {
+	p, _ := GetPreset(PresetWail)
+	for _, i := range []int{0, 1} {
+		if l := p.Layers[i]; l.Type != LayerVocal || l.Vowel == "" {
+			t.Errorf("wail layer %d = %v singing %q, want a vocal layer with vowels", i, l.Type, l.Vowel)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// maxReverbPreDelay bounds the pre-delay of a reverb, in milliseconds.
+const maxReverbPreDelay = 500
+
+// Reverb tuning, after Freeverb: the comb filters' feedback rises from
+// reverbFeedbackMin in the smallest room by up to reverbFeedbackScale in the
+// largest.
+const (
+	reverbFeedbackMin   = 0.7
+	reverbFeedbackScale = 0.28
+)
+
+// ReverbCombDelays lists the delays of the reverb's parallel comb filters, in
+// seconds: Freeverb's tuning, in samples at 44.1 kHz. Both backends derive the
+// reverb's decay from them.
+var ReverbCombDelays = [...]float64{
+	1116.0 / 44100, 1188.0 / 44100, 1277.0 / 44100, 1356.0 / 44100,
+	1422.0 / 44100, 1491.0 / 44100, 1557.0 / 44100, 1617.0 / 44100,
+}
+
+// ReverbFeedback returns the feedback of the reverb's comb filters for room
+// size room in [0, 1].
+func ReverbFeedback(room float64) float64 {
+	return reverbFeedbackMin + reverbFeedbackScale*room
+}
+
+// ReverbTime returns how long the reverb of f takes to decay by 60 dB, in
+// seconds, going by its longest comb filter. It ignores ReverbMix.
+func (f FilterParams) ReverbTime() float64 {
+	longest := ReverbCombDelays[len(ReverbCombDelays)-1]
+	return longest * -3 / math.Log10(ReverbFeedback(f.ReverbRoom))
+}
+
+// ReverbTail returns how long the reverb of f rings on after its input
+// stops: its pre-delay and reverb time, or 0 if f has no reverb.
+func (f FilterParams) ReverbTail() time.Duration {
+	if f.ReverbMix == 0 {
+		return 0
+	}
+	tail := f.ReverbPreDelay/1000 + f.ReverbTime()
+	return time.Duration(math.Ceil(tail*1000)) * time.Millisecond
+}
+
+// validReverb reports whether the reverb settings of f are in range.
+func (f FilterParams) validReverb() bool {
+	return f.ReverbMix >= 0 && f.ReverbMix <= 1 &&
+		f.ReverbRoom >= 0 && f.ReverbRoom <= 1 &&
+		f.ReverbDamping >= 0 && f.ReverbDamping <= 1 &&
+		f.ReverbPreDelay >= 0 && f.ReverbPreDelay <= maxReverbPreDelay
+}
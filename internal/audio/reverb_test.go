@@ -0,0 +1,90 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate_Reverb(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *FilterParams)
+		ok     bool
+	}{
+		{"no reverb", func(f *FilterParams) {}, true},
+		{"full reverb", func(f *FilterParams) {
+			f.ReverbMix, f.ReverbRoom, f.ReverbDamping, f.ReverbPreDelay = 1, 1, 1, 500
+		}, true},
+		{"negative mix", func(f *FilterParams) { f.ReverbMix = -0.1 }, false},
+		{"mix above 1", func(f *FilterParams) { f.ReverbMix = 1.1 }, false},
+		{"room above 1", func(f *FilterParams) { f.ReverbRoom = 1.5 }, false},
+		{"negative damping", func(f *FilterParams) { f.ReverbDamping = -1 }, false},
+		{"negative pre-delay", func(f *FilterParams) { f.ReverbPreDelay = -5 }, false},
+		{"pre-delay too long", func(f *FilterParams) { f.ReverbPreDelay = 501 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			tt.modify(&p.Filter)
+			err := p.Validate()
+			if tt.ok && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidReverb) {
+				t.Errorf("Validate() = %v, want ErrInvalidReverb", err)
+			}
+		})
+	}
+}
+
+func TestFilterParams_ReverbTail(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FilterParams
+		want   time.Duration
+	}{
+		{"no reverb", FilterParams{ReverbRoom: 1, ReverbPreDelay: 100}, 0},
+		{"smallest room", FilterParams{ReverbMix: 0.5}, 711 * time.Millisecond},
+		{"largest room", FilterParams{ReverbMix: 0.5, ReverbRoom: 1}, 12538 * time.Millisecond},
+		{"pre-delay", FilterParams{ReverbMix: 0.5, ReverbPreDelay: 100}, 811 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.ReverbTail(); got != tt.want {
+				t.Errorf("ReverbTail() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterParams_ReverbTimeGrowsWithRoom(t *testing.T) {
+	prev := 0.0
+	for _, room := range []float64{0, 0.25, 0.5, 0.75, 1} {
+		got := FilterParams{ReverbRoom: room}.ReverbTime()
+		if got <= prev {
+			t.Errorf("ReverbTime() at room %v = %v, want more than %v", room, got, prev)
+		}
+		prev = got
+	}
+}
+
+func TestScreamParams_Tail(t *testing.T) {
+	p := validBaseParams()
+	if got := p.Tail(); got != 0 {
+		t.Errorf("Tail() without reverb = %v, want 0", got)
+	}
+
+	p.Filter.ReverbMix = 0.5
+	if got, want := p.Tail(), p.Filter.ReverbTail(); got != want {
+		t.Errorf("Tail() = %v, want %v", got, want)
+	}
+
+	// The longer tail of the two ends of a morph wins.
+	to := p.Clone()
+	to.Filter.ReverbRoom = 1
+	p.MorphTo = &to
+	if got, want := p.Tail(), to.Filter.ReverbTail(); got != want {
+		t.Errorf("Tail() of a morph = %v, want %v", got, want)
+	}
+}
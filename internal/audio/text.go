@@ -0,0 +1,231 @@
+package audio
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Text screams give every character of the text an equal share of the
+// scream, within a minimum and maximum total duration.
+const (
+	textCharDuration = 150 * time.Millisecond
+	textMinDuration  = time.Second
+	textMaxDuration  = 8 * time.Second
+)
+
+// maxTextLetters is the most letters FromText accepts.
+const maxTextLetters = 100
+
+// textQuiet is the loudness of a lower-case letter relative to an upper-case
+// one.
+const textQuiet = 0.5
+
+// textSound describes how a character of a text scream is sounded: by a voice
+// shaped by the formants of a vowel, by breath noise, or by both.
+type textSound struct {
+	vowel  byte    // vowel shaping the voice, or 0 to keep that of the nearest vowel
+	voice  float64 // loudness of the voice [0, 1]
+	breath float64 // loudness of the breath noise [0, 1]
+}
+
+// textSounds maps each lower-case letter, and the space, to its sound.
+// Vowels are sung with a little breath; h and the hissing consonants are
+// breath alone, through the shape of the vowel next to them; the humming and
+// gliding consonants are quieter voice through a closed vowel; stops and
+// spaces are silent.
+var textSounds = map[byte]textSound{
+	'a': {'a', 1, 0.1},
+	'e': {'e', 1, 0.1},
+	'i': {'i', 1, 0.1},
+	'o': {'o', 1, 0.1},
+	'u': {'u', 1, 0.1},
+	'y': {'i', 1, 0.1},
+	'h': {0, 0, 1},
+	's': {0, 0, 0.8},
+	'x': {0, 0, 0.8},
+	'f': {0, 0, 0.6},
+	'v': {0, 0.4, 0.4},
+	'z': {0, 0.4, 0.5},
+	'j': {'i', 0.3, 0.4},
+	'm': {'u', 0.6, 0},
+	'n': {'u', 0.6, 0},
+	'w': {'u', 0.7, 0},
+	'l': {0, 0.6, 0},
+	'r': {0, 0.7, 0.05},
+	'b': {0, 0.2, 0},
+	'd': {0, 0.2, 0},
+	'g': {0, 0.2, 0},
+	'c': {0, 0, 0},
+	'k': {0, 0, 0},
+	'p': {0, 0, 0},
+	'q': {0, 0, 0},
+	't': {0, 0, 0},
+	' ': {0, 0, 0},
+}
+
+// FromText returns parameters for a scream that sounds out text, such as
+// "AAAAAHHHHhhh" or "NOOOOO". Every letter and space of text gets an equal
+// share of the scream, so repeating a letter holds it for longer, and the
+// scream glides from each to the next: vowels are sung, h and hissing
+// consonants are breathed, stops and spaces are silent. Upper-case letters
+// are louder than lower-case ones, and a run of lower-case letters ending
+// text after upper-case ones fades out to silence. Characters other than
+// ASCII letters and spaces are skipped.
+//
+// The scream has two vocal layers, a voice and a quieter one an octave up,
+// and a breath noise layer, all following text through their Vowel and
+// Levels. Its pitch and roughness are drawn from seed, or from a random seed
+// if seed is 0, which is recorded in ScreamParams.Seed. FromText returns
+// ErrInvalidText if text has no letters or more than maxTextLetters.
+func FromText(text string, seed int64) (ScreamParams, error) {
+	chars, upper := textChars(text)
+	letters := len(chars) - strings.Count(string(chars), " ")
+	if letters == 0 || letters > maxTextLetters {
+		return ScreamParams{}, ErrInvalidText
+	}
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+	rf := func(min, max float64) float64 { return min + r.Float64()*(max-min) }
+	ri := func(min, max int64) int64 { return min + r.Int63n(max-min+1) }
+
+	dur := time.Duration(len(chars)) * textCharDuration
+	dur = max(textMinDuration, min(textMaxDuration, dur))
+
+	vowels, voice, breath := textTimeline(chars, upper)
+
+	params := ScreamParams{
+		Duration:   dur,
+		SampleRate: DefaultSampleRate,
+		Channels:   DefaultChannels,
+		Seed:       seed,
+		Width:      0.5,
+		Envelope:   DefaultEnvelope(),
+	}
+	lead := LayerParams{
+		Type:      LayerVocal,
+		BaseFreq:  rf(380, 620),
+		FreqRange: rf(20, 60),
+		JumpRate:  rf(4, 8),
+		Amplitude: 0.5,
+		Seed:      ri(1, 9999),
+		Levels:    voice,
+		Vowel:     vowels,
+		Jitter:    rf(0.01, 0.04),
+		Shimmer:   rf(0.05, 0.15),
+	}
+	octave := lead
+	octave.BaseFreq = 2 * lead.BaseFreq
+	octave.FreqRange = 2 * lead.FreqRange
+	octave.Amplitude = 0.2
+	octave.Seed = ri(1, 9999)
+	octave.Pan = rf(-0.4, 0.4)
+	octave.Levels = slices.Clone(voice)
+	params.Layers = []LayerParams{
+		lead,
+		octave,
+		{
+			Type:      LayerBackgroundNoise,
+			Amplitude: 0.3,
+			Seed:      ri(1, 9999),
+			Levels:    breath,
+		},
+	}
+
+	params.Filter = DefaultFilterParams()
+	params.Filter.HighpassCutoff = 100
+	params.Filter.LowpassCutoff = 9000
+	params.Filter.CrusherBits = 12
+	params.Filter.CompRatio = 6
+	params.Filter.VolumeBoostDB = 8
+	return params, nil
+}
+
+// textChars returns the lower-cased letters and spaces of text, without
+// leading or trailing spaces, and whether each was upper case.
+func textChars(text string) (chars []byte, upper []bool) {
+	for _, c := range []byte(text) {
+		switch {
+		case 'a' <= c && c <= 'z', c == ' ':
+			chars = append(chars, c)
+			upper = append(upper, false)
+		case 'A' <= c && c <= 'Z':
+			chars = append(chars, lowerASCII(c))
+			upper = append(upper, true)
+		}
+	}
+	start := 0
+	for start < len(chars) && chars[start] == ' ' {
+		start++
+	}
+	end := len(chars)
+	for end > start && chars[end-1] == ' ' {
+		end--
+	}
+	return chars[start:end], upper[start:end]
+}
+
+// textTimeline returns the vowel sequence, voice levels and breath levels
+// that sound out chars, one entry per character.
+func textTimeline(chars []byte, upper []bool) (vowels string, voice, breath []float64) {
+	sounds := make([]textSound, len(chars))
+	for i, c := range chars {
+		sounds[i] = textSounds[c]
+	}
+
+	// A consonant keeps the shape of the vowel before it, or of the first
+	// vowel if none comes before.
+	var v byte = 'a'
+	for _, s := range sounds {
+		if s.vowel != 0 {
+			v = s.vowel
+			break
+		}
+	}
+	vb := make([]byte, len(sounds))
+	for i, s := range sounds {
+		if s.vowel != 0 {
+			v = s.vowel
+		}
+		vb[i] = v
+	}
+
+	fade := textFade(chars, upper)
+	voice = make([]float64, len(sounds))
+	breath = make([]float64, len(sounds))
+	for i, s := range sounds {
+		loud := fade[i]
+		if !upper[i] {
+			loud *= textQuiet
+		}
+		voice[i] = s.voice * loud
+		breath[i] = s.breath * loud
+	}
+	return string(vb), voice, breath
+}
+
+// textFade returns the fade applied to each character: 1, except in a run of
+// lower-case letters ending the text after an upper-case one, which falls
+// evenly to 0 at the last letter.
+func textFade(chars []byte, upper []bool) []float64 {
+	fade := make([]float64, len(chars))
+	for i := range fade {
+		fade[i] = 1
+	}
+	start := len(chars)
+	for start > 0 && !upper[start-1] && chars[start-1] != ' ' {
+		start--
+	}
+	if start == 0 || !upper[start-1] {
+		return fade
+	}
+	n := len(chars) - start
+	for j := range n {
+		fade[start+j] = float64(n-1-j) / float64(n)
+	}
+	return fade
+}
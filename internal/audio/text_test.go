@@ -0,0 +1,122 @@
+package audio
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromText_Valid(t *testing.T) {
+	for _, text := range []string{"AAAAAAHHHHHHhhh", "NOOOOO", "no way!", "  eek  ", "Ah", strings.Repeat("A", 100)} {
+		t.Run(text, func(t *testing.T) {
+			p, err := FromText(text, 42)
+			if err != nil {
+				t.Fatalf("FromText(%q) unexpected error: %v", text, err)
+			}
+			if err := p.Validate(); err != nil {
+				t.Errorf("FromText(%q) params invalid: %v", text, err)
+			}
+			if p.Seed != 42 {
+				t.Errorf("Seed = %d, want 42", p.Seed)
+			}
+		})
+	}
+}
+
+func TestFromText_Invalid(t *testing.T) {
+	for _, text := range []string{"", "   ", "?!", "12345", strings.Repeat("A", 101)} {
+		if _, err := FromText(text, 1); !errors.Is(err, ErrInvalidText) {
+			t.Errorf("FromText(%q) error = %v, want ErrInvalidText", text, err)
+		}
+	}
+}
+
+func TestFromText_Duration(t *testing.T) {
+	tests := []struct {
+		text string
+		want time.Duration
+	}{
+		{"AAAAAAHHHHHHhhh", 15 * textCharDuration},
+		{"AAAAAAAAAAAAAAAAAAAA", 20 * textCharDuration},
+		{"A!!!", textMinDuration},
+		{strings.Repeat("A ", 99) + "A", textMaxDuration},
+	}
+	for _, tt := range tests {
+		p, err := FromText(tt.text, 1)
+		if err != nil {
+			t.Fatalf("FromText(%q) unexpected error: %v", tt.text, err)
+		}
+		if p.Duration != tt.want {
+			t.Errorf("FromText(%q) Duration = %v, want %v", tt.text, p.Duration, tt.want)
+		}
+	}
+}
+
+func TestFromText_Timeline(t *testing.T) {
+	p, err := FromText("NOOOoo", 1)
+	if err != nil {
+		t.Fatalf("FromText() unexpected error: %v", err)
+	}
+	voice, breath := p.Layers[0], p.Layers[2]
+	if voice.Type != LayerVocal || breath.Type != LayerBackgroundNoise {
+		t.Fatalf("layer types = %v, %v; want vocal, background noise", voice.Type, breath.Type)
+	}
+	if voice.Vowel != "uooooo" {
+		t.Errorf("Vowel = %q, want %q", voice.Vowel, "uooooo")
+	}
+	// N hums, O is sung loud, and the trailing lower-case o's fade out from
+	// half loudness.
+	if want := []float64{0.6, 1, 1, 1, 0.25, 0}; !reflect.DeepEqual(voice.Levels, want) {
+		t.Errorf("voice Levels = %v, want %v", voice.Levels, want)
+	}
+	if !reflect.DeepEqual(p.Layers[1].Levels, voice.Levels) {
+		t.Errorf("octave Levels = %v, want %v", p.Layers[1].Levels, voice.Levels)
+	}
+}
+
+func TestFromText_Breath(t *testing.T) {
+	p, err := FromText("AHa", 1)
+	if err != nil {
+		t.Fatalf("FromText() unexpected error: %v", err)
+	}
+	voice, breath := p.Layers[0].Levels, p.Layers[2].Levels
+	// The H is breathed through the shape of the A, with the voice silent.
+	if p.Layers[0].Vowel != "aaa" {
+		t.Errorf("Vowel = %q, want %q", p.Layers[0].Vowel, "aaa")
+	}
+	if voice[1] != 0 || breath[1] != 1 {
+		t.Errorf("H voice, breath = %v, %v; want 0, 1", voice[1], breath[1])
+	}
+	if breath[0] >= breath[1] {
+		t.Errorf("A breath %v should be quieter than H breath %v", breath[0], breath[1])
+	}
+}
+
+func TestFromText_Case(t *testing.T) {
+	upper, _ := FromText("AAAA", 1)
+	lower, _ := FromText("aaaa", 1)
+	for i, v := range lower.Layers[0].Levels {
+		// An all lower-case text is quieter but does not fade.
+		if want := textQuiet * upper.Layers[0].Levels[i]; v != want {
+			t.Errorf("lower-case level %d = %v, want %v", i, v, want)
+		}
+	}
+}
+
+func TestFromText_Deterministic(t *testing.T) {
+	a, _ := FromText("AAAHhh", 7)
+	b, _ := FromText("AAAHhh", 7)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("FromText with the same seed produced different params")
+	}
+	c, _ := FromText("AAAHhh", 8)
+	if c.Layers[0].BaseFreq == a.Layers[0].BaseFreq {
+		t.Error("FromText with different seeds produced the same pitch")
+	}
+	d, _ := FromText("AAAHhh", 0)
+	if d.Seed == 0 {
+		t.Error("FromText with seed 0 did not record the seed it used")
+	}
+}
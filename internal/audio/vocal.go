@@ -0,0 +1,85 @@
+package audio
+
+import "strings"
+
+// Formant is a resonance of the vocal tract: a peak in the spectrum of a
+// sung vowel.
+type Formant struct {
+	Freq      float64 // Centre frequency (Hz)
+	Bandwidth float64 // -3 dB bandwidth (Hz)
+	Gain      float64 // Linear peak gain relative to the first formant
+}
+
+// FormantCount is the number of formants used to shape each vowel.
+const FormantCount = 3
+
+// Vowels lists the vowels a vocal layer can sing, in the letters accepted by
+// LayerParams.Vowel.
+const Vowels = "aeiou"
+
+// vowelFormants holds the first three formants of each vowel, from the
+// classic tenor formant table.
+var vowelFormants = map[byte][FormantCount]Formant{
+	'a': {{650, 80, 1}, {1080, 90, 0.5}, {2650, 120, 0.45}},
+	'e': {{400, 70, 1}, {1700, 80, 0.2}, {2600, 100, 0.25}},
+	'i': {{290, 40, 1}, {1870, 90, 0.18}, {2800, 100, 0.13}},
+	'o': {{400, 40, 1}, {800, 80, 0.32}, {2600, 100, 0.08}},
+	'u': {{350, 40, 1}, {600, 60, 0.1}, {2700, 100, 0.14}},
+}
+
+// validVowel reports whether s is a vowel sequence accepted by
+// LayerParams.Vowel: empty, or made only of the letters in Vowels in either
+// case.
+func validVowel(s string) bool {
+	for _, c := range strings.ToLower(s) {
+		if !strings.ContainsRune(Vowels, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// VowelFormants returns the formants at position pos in [0, 1] along the
+// vowel sequence s. The letters of s are spaced evenly from 0 to 1 and the
+// formants glide linearly between neighbours, so "aaao" holds "a" for two
+// thirds of the way and then glides to "o". An empty s is "a"; letters
+// outside Vowels are skipped.
+func VowelFormants(s string, pos float64) [FormantCount]Formant {
+	var seq [][FormantCount]Formant
+	for i := 0; i < len(s); i++ {
+		if f, ok := vowelFormants[lowerASCII(s[i])]; ok {
+			seq = append(seq, f)
+		}
+	}
+	if len(seq) == 0 {
+		return vowelFormants['a']
+	}
+	if len(seq) == 1 || pos <= 0 {
+		return seq[0]
+	}
+	if pos >= 1 {
+		return seq[len(seq)-1]
+	}
+
+	x := pos * float64(len(seq)-1)
+	i := int(x)
+	frac := x - float64(i)
+	var out [FormantCount]Formant
+	for j := range out {
+		a, b := seq[i][j], seq[i+1][j]
+		out[j] = Formant{
+			Freq:      lerp(a.Freq, b.Freq, frac),
+			Bandwidth: lerp(a.Bandwidth, b.Bandwidth, frac),
+			Gain:      lerp(a.Gain, b.Gain, frac),
+		}
+	}
+	return out
+}
+
+// lowerASCII returns the lower-case form of the ASCII letter c.
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -0,0 +1,46 @@
+package audio
+
+import "testing"
+
+func TestVowelFormants(t *testing.T) {
+	a, o := vowelFormants['a'], vowelFormants['o']
+	tests := []struct {
+		name  string
+		vowel string
+		pos   float64
+		want  [FormantCount]Formant
+	}{
+		{"empty is a", "", 0.5, a},
+		{"single vowel", "o", 0.7, o},
+		{"upper case", "O", 0.2, o},
+		{"sequence start", "ao", 0, a},
+		{"sequence end", "ao", 1, o},
+		{"past the end", "ao", 1.5, o},
+		{"held vowel", "aaao", 0.5, a},
+		{"skips other letters", "a-o", 1, o},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VowelFormants(tt.vowel, tt.pos); got != tt.want {
+				t.Errorf("VowelFormants(%q, %v) = %v, want %v", tt.vowel, tt.pos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVowelFormants_Glide(t *testing.T) {
+	a, o := vowelFormants['a'], vowelFormants['o']
+	got := VowelFormants("ao", 0.25)
+	for i := range got {
+		want := a[i].Freq + (o[i].Freq-a[i].Freq)*0.25
+		if diff := got[i].Freq - want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("formant %d freq = %v, want %v", i, got[i].Freq, want)
+		}
+	}
+
+	// "aaao" holds "a" for two thirds and then glides to "o".
+	mid := VowelFormants("aaao", 5.0/6)
+	if want := (a[0].Freq + o[0].Freq) / 2; mid[0].Freq != want {
+		t.Errorf("VowelFormants(aaao, 5/6) F1 = %v, want %v", mid[0].Freq, want)
+	}
+}
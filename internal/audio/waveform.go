@@ -0,0 +1,53 @@
+package audio
+
+// maxDetune bounds the spread of a supersaw, in cents.
+const maxDetune = 100
+
+// Waveform names the wave a tonal layer is built from.
+type Waveform string
+
+const (
+	WaveSine     Waveform = "sine"     // the default; "" is also a sine
+	WaveSaw      Waveform = "saw"      // bright and buzzy, with every harmonic
+	WaveSquare   Waveform = "square"   // hollow, with odd harmonics only
+	WaveTriangle Waveform = "triangle" // soft, with weak odd harmonics
+	WavePulse    Waveform = "pulse"    // nasal; a square with a duty cycle of PulseWidth
+	WaveSupersaw Waveform = "supersaw" // thick; detuned saws spread by Detune
+)
+
+// SupersawVoice is one of the saws that make up a supersaw.
+type SupersawVoice struct {
+	Detune float64 // Fraction of LayerParams.Detune by which the voice is detuned [-1, 1]
+	Phase  float64 // Starting phase [0, 1)
+	Gain   float64 // Level relative to the centre voice
+}
+
+// SupersawVoices lists the voices of a supersaw: a centre voice at the
+// layer's pitch and three pairs detuned either side of it, starting at
+// scattered phases so that they do not sound as one saw at first. Both
+// backends mix them to the loudness of a single saw.
+var SupersawVoices = [...]SupersawVoice{
+	{0, 0, 1},
+	{-1, 0.31, 0.6},
+	{1, 0.73, 0.6},
+	{-0.62, 0.17, 0.6},
+	{0.62, 0.89, 0.6},
+	{-0.29, 0.52, 0.6},
+	{0.29, 0.44, 0.6},
+}
+
+// validWaveform reports whether the wave of l is known, a pulse wave has a
+// duty cycle strictly between 0 and 1 and a supersaw is spread by at most
+// maxDetune cents.
+func validWaveform(l LayerParams) bool {
+	switch l.Waveform {
+	case "", WaveSine, WaveSaw, WaveSquare, WaveTriangle, WaveSupersaw:
+	case WavePulse:
+		if l.PulseWidth <= 0 || l.PulseWidth >= 1 {
+			return false
+		}
+	default:
+		return false
+	}
+	return l.PulseWidth >= 0 && l.PulseWidth < 1 && l.Detune >= 0 && l.Detune <= maxDetune
+}
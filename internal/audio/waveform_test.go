@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate_Waveform(t *testing.T) {
+	tests := []struct {
+		name  string
+		layer LayerParams
+		ok    bool
+	}{
+		{"default sine", LayerParams{}, true},
+		{"sine", LayerParams{Waveform: WaveSine}, true},
+		{"saw", LayerParams{Waveform: WaveSaw}, true},
+		{"square", LayerParams{Waveform: WaveSquare}, true},
+		{"triangle", LayerParams{Waveform: WaveTriangle}, true},
+		{"pulse", LayerParams{Waveform: WavePulse, PulseWidth: 0.1}, true},
+		{"supersaw", LayerParams{Waveform: WaveSupersaw, Detune: 100}, true},
+		{"unknown", LayerParams{Waveform: "kazoo"}, false},
+		{"pulse without width", LayerParams{Waveform: WavePulse}, false},
+		{"pulse too wide", LayerParams{Waveform: WavePulse, PulseWidth: 1}, false},
+		{"negative width", LayerParams{Waveform: WaveSquare, PulseWidth: -0.1}, false},
+		{"negative detune", LayerParams{Waveform: WaveSupersaw, Detune: -1}, false},
+		{"detune too wide", LayerParams{Waveform: WaveSupersaw, Detune: 101}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validBaseParams()
+			p.Layers[1].Waveform = tt.layer.Waveform
+			p.Layers[1].PulseWidth = tt.layer.PulseWidth
+			p.Layers[1].Detune = tt.layer.Detune
+			err := p.Validate()
+			if tt.ok {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var lve *LayerValidationError
+			if !errors.As(err, &lve) || lve.Layer != 1 || !errors.Is(err, ErrInvalidWaveform) {
+				t.Errorf("Validate() = %v, want layer 1 ErrInvalidWaveform", err)
+			}
+		})
+	}
+}
+
+func TestSupersawVoices(t *testing.T) {
+	if SupersawVoices[0].Detune != 0 || SupersawVoices[0].Phase != 0 {
+		t.Errorf("centre voice = %+v, want no detune and phase 0", SupersawVoices[0])
+	}
+	for i, v := range SupersawVoices {
+		if v.Detune < -1 || v.Detune > 1 || v.Phase < 0 || v.Phase >= 1 || v.Gain <= 0 {
+			t.Errorf("voice %d = %+v out of range", i, v)
+		}
+	}
+}
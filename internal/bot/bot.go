@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/JamesPrial/go-scream/internal/config"
+	"github.com/JamesPrial/go-scream/internal/discord"
+	"github.com/JamesPrial/go-scream/internal/scream"
+)
+
+// Bot answers /scream slash commands by routing them to a scream.Service.
+// One Bot serves every guild the session is connected to, but plays at most
+// one scream per guild at a time.
+type Bot struct {
+	session Session
+	svc     *scream.Service
+	cfg     config.Config
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	busy    map[string]bool
+	closing bool
+	active  sync.WaitGroup
+}
+
+// New returns a Bot that answers interactions on session. cfg supplies the
+// defaults for every option a user leaves out, and svc is re-configured per
+// interaction with scream.Service.WithConfig.
+func New(session Session, svc *scream.Service, cfg config.Config, logger *slog.Logger) *Bot {
+	return &Bot{
+		session: session,
+		svc:     svc,
+		cfg:     cfg,
+		logger:  logger,
+		busy:    make(map[string]bool),
+	}
+}
+
+// Run registers the /scream command and serves interactions until ctx is
+// cancelled. Commands are registered in cfg.GuildID when it is set (which
+// takes effect immediately) or globally otherwise. On cancellation Run stops
+// accepting interactions, waits for in-flight screams to finish (they observe
+// the same cancellation and stop early), and returns nil.
+func (b *Bot) Run(ctx context.Context) error {
+	cmds := []*discordgo.ApplicationCommand{screamCommand(scream.ListPresets(b.svc.Presets()))}
+	if err := b.session.RegisterCommands(b.cfg.GuildID, cmds); err != nil {
+		return fmt.Errorf("%w: %w", ErrRegisterFailed, err)
+	}
+
+	remove := b.session.AddInteractionHandler(func(i *discordgo.Interaction) {
+		b.handleInteraction(ctx, i)
+	})
+	b.logger.Info("bot ready", "command", "/"+CommandName, "guild", b.cfg.GuildID)
+
+	<-ctx.Done()
+
+	remove()
+	b.mu.Lock()
+	b.closing = true
+	b.mu.Unlock()
+
+	b.logger.Info("bot shutting down, waiting for active screams")
+	b.active.Wait()
+	b.logger.Info("bot stopped")
+	return nil
+}
+
+// handleInteraction answers a single interaction. Interactions other than
+// the /scream command are ignored.
+func (b *Bot) handleInteraction(ctx context.Context, i *discordgo.Interaction) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+	data, ok := i.Data.(discordgo.ApplicationCommandInteractionData)
+	if !ok || data.Name != CommandName {
+		return
+	}
+
+	b.mu.Lock()
+	if b.closing {
+		b.mu.Unlock()
+		return
+	}
+	b.active.Add(1)
+	b.mu.Unlock()
+	defer b.active.Done()
+
+	logger := b.logger.With("interaction", i.ID, "guild", i.GuildID)
+
+	if i.GuildID == "" {
+		b.respond(logger, i, b.userMessage(ErrNotInGuild))
+		return
+	}
+
+	req, err := parseRequest(data.Options)
+	if err != nil {
+		b.respond(logger, i, b.userMessage(err))
+		return
+	}
+
+	svc := b.svc.WithConfig(req.apply(b.cfg))
+
+	channelID, err := b.resolveChannel(svc, i, req, logger)
+	if err != nil {
+		b.respond(logger, i, b.userMessage(err))
+		return
+	}
+
+	if !b.acquire(i.GuildID) {
+		b.respond(logger, i, b.userMessage(ErrGuildBusy))
+		return
+	}
+	defer b.release(i.GuildID)
+
+	if !b.respond(logger, i, fmt.Sprintf("Screaming in <#%s>...", channelID)) {
+		return
+	}
+
+	logger.Info("playing scream", "channel", channelID, "preset", req.Preset, "duration", req.Duration, "volume", req.Volume)
+
+	result := fmt.Sprintf("Screamed in <#%s>.", channelID)
+	if err := svc.Play(ctx, i.GuildID, channelID); err != nil {
+		logger.Warn("scream failed", "channel", channelID, "error", err)
+		result = b.userMessage(err)
+	}
+
+	if err := b.session.EditResponse(i, result); err != nil {
+		logger.Warn("failed to edit interaction response", "error", err)
+	}
+}
+
+// resolveChannel picks the voice channel for an interaction. An explicit
+// channel option wins; otherwise the invoking member's own voice channel is
+// used, falling back to the service's configured channel strategy when the
+// member is not in voice.
+func (b *Bot) resolveChannel(svc *scream.Service, i *discordgo.Interaction, req screamRequest, logger *slog.Logger) (string, error) {
+	if req.ChannelID != "" {
+		return req.ChannelID, nil
+	}
+
+	if i.Member != nil && i.Member.User != nil {
+		cfg := b.cfg
+		cfg.ChannelStrategy = discord.StrategyUser
+		cfg.ChannelUserID = i.Member.User.ID
+		sel, err := svc.WithConfig(cfg).ResolveChannel(i.GuildID)
+		if err == nil {
+			return sel.ChannelID, nil
+		}
+		if !errors.Is(err, discord.ErrUserNotInVoice) {
+			return "", err
+		}
+		logger.Debug("invoking member not in voice, using configured strategy", "user", i.Member.User.ID)
+	}
+
+	sel, err := svc.ResolveChannel(i.GuildID)
+	if err != nil {
+		return "", err
+	}
+	return sel.ChannelID, nil
+}
+
+// respond sends an ephemeral response and reports whether it succeeded.
+func (b *Bot) respond(logger *slog.Logger, i *discordgo.Interaction, content string) bool {
+	if err := b.session.Respond(i, content); err != nil {
+		logger.Warn("failed to respond to interaction", "error", err)
+		return false
+	}
+	return true
+}
+
+// acquire marks guildID as busy. It returns false if a scream is already
+// playing there.
+func (b *Bot) acquire(guildID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.busy[guildID] {
+		return false
+	}
+	b.busy[guildID] = true
+	return true
+}
+
+// release clears the busy mark set by acquire.
+func (b *Bot) release(guildID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.busy, guildID)
+}
+
+// userMessage converts an error into a short message suitable for showing to
+// the Discord user who invoked the command.
+func (b *Bot) userMessage(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "The bot is shutting down."
+	case errors.Is(err, scream.ErrUnknownPreset):
+		return "Unknown preset. Available presets: " + strings.Join(scream.ListPresets(b.svc.Presets()), ", ") + "."
+	case errors.Is(err, scream.ErrPlayFailed):
+		return "Playback failed: could not stream to the voice channel."
+	case errors.Is(err, scream.ErrGenerateFailed), errors.Is(err, scream.ErrEncodeFailed):
+		return "Could not generate the scream."
+	case errors.Is(err, discord.ErrNoPopulatedChannel):
+		return "Nobody is in a voice channel. Join one or pick a channel."
+	case errors.Is(err, scream.ErrChannelResolveFailed):
+		return "Could not find a voice channel to scream in."
+	case errors.Is(err, ErrGuildBusy):
+		return "Already screaming in this server. Try again when it finishes."
+	case errors.Is(err, ErrNotInGuild):
+		return "The /scream command only works in a server."
+	case errors.Is(err, ErrInvalidOption):
+		return "Invalid option: " + strings.TrimPrefix(err.Error(), ErrInvalidOption.Error()+": ") + "."
+	default:
+		return "Something went wrong."
+	}
+}
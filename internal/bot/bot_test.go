@@ -0,0 +1,506 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"gopkg.in/yaml.v3"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+	"github.com/JamesPrial/go-scream/internal/config"
+	"github.com/JamesPrial/go-scream/internal/discord"
+	"github.com/JamesPrial/go-scream/internal/preset"
+	"github.com/JamesPrial/go-scream/internal/scream"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// ---------------------------------------------------------------------------
+// Mock types
+// ---------------------------------------------------------------------------
+
+// mockSession implements Session for testing. Responses and edits are
+// recorded per interaction ID.
+type mockSession struct {
+	mu          sync.Mutex
+	registerErr error
+	guildID     string
+	commands    []*discordgo.ApplicationCommand
+	handler     func(*discordgo.Interaction)
+	removed     bool
+	responses   map[string]string
+	edits       map[string]string
+}
+
+func newMockSession() *mockSession {
+	return &mockSession{responses: make(map[string]string), edits: make(map[string]string)}
+}
+
+func (m *mockSession) RegisterCommands(guildID string, cmds []*discordgo.ApplicationCommand) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.registerErr != nil {
+		return m.registerErr
+	}
+	m.guildID = guildID
+	m.commands = cmds
+	return nil
+}
+
+func (m *mockSession) AddInteractionHandler(fn func(*discordgo.Interaction)) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handler = fn
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.removed = true
+	}
+}
+
+func (m *mockSession) Respond(i *discordgo.Interaction, content string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[i.ID] = content
+	return nil
+}
+
+func (m *mockSession) EditResponse(i *discordgo.Interaction, content string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.edits[i.ID] = content
+	return nil
+}
+
+func (m *mockSession) response(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.responses[id]
+}
+
+func (m *mockSession) edit(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.edits[id]
+}
+
+// mockGenerator implements audio.Generator and records the last params.
+type mockGenerator struct {
+	mu         sync.Mutex
+	lastParams audio.ScreamParams
+}
+
+func (m *mockGenerator) Generate(ctx context.Context, params audio.ScreamParams) (io.Reader, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastParams = params
+	return bytes.NewReader(make([]byte, 960*2*2)), nil
+}
+
+func (m *mockGenerator) params() audio.ScreamParams {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastParams
+}
+
+// mockFrameEncoder implements encoding.OpusFrameEncoder, emitting one frame.
+type mockFrameEncoder struct{}
+
+func (mockFrameEncoder) EncodeFrames(src io.Reader, sampleRate, channels int) (<-chan []byte, <-chan error) {
+	frameCh := make(chan []byte, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(frameCh)
+		defer close(errCh)
+		_, _ = io.Copy(io.Discard, src)
+		frameCh <- []byte{0x01}
+		errCh <- nil
+	}()
+	return frameCh, errCh
+}
+
+// mockFileEncoder implements encoding.FileEncoder. The bot never calls it.
+type mockFileEncoder struct{}
+
+func (mockFileEncoder) Encode(dst io.Writer, src io.Reader, sampleRate, channels int) error {
+	return nil
+}
+
+// mockPlayer implements discord.VoicePlayer. When block is non-nil, Play
+// waits for it to be closed (or for ctx to be cancelled) before returning.
+type mockPlayer struct {
+	mu          sync.Mutex
+	lastChannel string
+	err         error
+	block       chan struct{}
+	started     chan struct{}
+}
+
+func (m *mockPlayer) Play(ctx context.Context, guildID, channelID string, frames <-chan []byte) error {
+	for range frames {
+	}
+	m.mu.Lock()
+	m.lastChannel = channelID
+	block, started := m.block, m.started
+	m.mu.Unlock()
+
+	if started != nil {
+		close(started)
+	}
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return m.err
+}
+
+func (m *mockPlayer) channel() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastChannel
+}
+
+// voiceSession implements discord.Session for channel resolution.
+type voiceSession struct {
+	voiceStates []*discord.VoiceState
+}
+
+func (v *voiceSession) ChannelVoiceJoin(guildID, channelID string, mute, deaf bool) (discord.VoiceConn, error) {
+	return nil, errors.New("voiceSession: voice join not supported")
+}
+
+func (v *voiceSession) GuildVoiceStates(guildID string) ([]*discord.VoiceState, error) {
+	return v.voiceStates, nil
+}
+
+func (v *voiceSession) BotUserID() string { return "bot" }
+
+// ---------------------------------------------------------------------------
+// Test helpers
+// ---------------------------------------------------------------------------
+
+// testVoiceStates returns a guild where "member" is in c1 and two other
+// users are in c2.
+func testVoiceStates() []*discord.VoiceState {
+	return []*discord.VoiceState{
+		{UserID: "member", ChannelID: "c1", GuildID: "guild-1"},
+		{UserID: "u2", ChannelID: "c2", GuildID: "guild-1"},
+		{UserID: "u3", ChannelID: "c2", GuildID: "guild-1"},
+	}
+}
+
+func testConfig() config.Config {
+	cfg := config.Default()
+	cfg.Token = "test-token"
+	return cfg
+}
+
+// newTestBot returns a Bot backed by a real scream.Service with mocked
+// generation and playback.
+func newTestBot(cfg config.Config, gen *mockGenerator, pl *mockPlayer, states []*discord.VoiceState) (*Bot, *mockSession) {
+	sess := newMockSession()
+	finder := discord.NewChannelFinder(&voiceSession{voiceStates: states}, discardLogger)
+	svc := scream.NewServiceWithDeps(cfg, gen, mockFileEncoder{}, mockFrameEncoder{}, pl, finder, discardLogger)
+	return New(sess, svc, cfg, discardLogger), sess
+}
+
+// interaction builds a /scream interaction from userID in guildID.
+func interaction(id, guildID, userID string, opts ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.Interaction {
+	i := &discordgo.Interaction{
+		ID:      id,
+		Type:    discordgo.InteractionApplicationCommand,
+		GuildID: guildID,
+		Data: discordgo.ApplicationCommandInteractionData{
+			Name:    CommandName,
+			Options: opts,
+		},
+	}
+	if userID != "" {
+		i.Member = &discordgo.Member{User: &discordgo.User{ID: userID}}
+	}
+	return i
+}
+
+// ---------------------------------------------------------------------------
+// handleInteraction tests
+// ---------------------------------------------------------------------------
+
+func Test_handleInteraction_PlaysInMemberChannel(t *testing.T) {
+	gen, pl := &mockGenerator{}, &mockPlayer{}
+	b, sess := newTestBot(testConfig(), gen, pl, testVoiceStates())
+
+	b.handleInteraction(context.Background(), interaction("i1", "guild-1", "member"))
+
+	if got := pl.channel(); got != "c1" {
+		t.Errorf("played in %q, want c1", got)
+	}
+	if got := sess.response("i1"); got != "Screaming in <#c1>..." {
+		t.Errorf("response = %q", got)
+	}
+	if got := sess.edit("i1"); got != "Screamed in <#c1>." {
+		t.Errorf("edit = %q", got)
+	}
+}
+
+func Test_handleInteraction_ExplicitChannel(t *testing.T) {
+	pl := &mockPlayer{}
+	b, _ := newTestBot(testConfig(), &mockGenerator{}, pl, testVoiceStates())
+
+	b.handleInteraction(context.Background(), interaction("i1", "guild-1", "member", opt(optionChannel, "c9")))
+
+	if got := pl.channel(); got != "c9" {
+		t.Errorf("played in %q, want c9", got)
+	}
+}
+
+func Test_handleInteraction_MemberNotInVoice_FallsBackToStrategy(t *testing.T) {
+	cfg := testConfig()
+	cfg.ChannelStrategy = discord.StrategyMostPopulated
+	pl := &mockPlayer{}
+	b, _ := newTestBot(cfg, &mockGenerator{}, pl, testVoiceStates())
+
+	b.handleInteraction(context.Background(), interaction("i1", "guild-1", "stranger"))
+
+	if got := pl.channel(); got != "c2" {
+		t.Errorf("played in %q, want c2 (most populated)", got)
+	}
+}
+
+func Test_handleInteraction_AppliesOptions(t *testing.T) {
+	gen := &mockGenerator{}
+	b, _ := newTestBot(testConfig(), gen, &mockPlayer{}, testVoiceStates())
+
+	b.handleInteraction(context.Background(), interaction("i1", "guild-1", "member",
+		opt(optionPreset, "robot"), opt(optionDuration, 1.5)))
+
+	want, _ := audio.GetPreset(audio.PresetRobot)
+	got := gen.params()
+	if !reflect.DeepEqual(got.Layers, want.Layers) {
+		t.Error("expected robot preset layers")
+	}
+	if got.Duration != 1500*time.Millisecond {
+		t.Errorf("Duration = %v, want 1.5s", got.Duration)
+	}
+}
+
+func Test_handleInteraction_ErrorMessages(t *testing.T) {
+	tests := []struct {
+		name   string
+		guild  string
+		opts   []*discordgo.ApplicationCommandInteractionDataOption
+		states []*discord.VoiceState
+		want   string
+	}{
+		{"direct message", "", nil, testVoiceStates(), "only works in a server"},
+		{"invalid option", "guild-1", []*discordgo.ApplicationCommandInteractionDataOption{opt(optionVolume, 2.0)}, testVoiceStates(), "Invalid option: volume"},
+		{"nobody in voice", "guild-1", nil, nil, "Nobody is in a voice channel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := &mockPlayer{}
+			b, sess := newTestBot(testConfig(), &mockGenerator{}, pl, tt.states)
+
+			b.handleInteraction(context.Background(), interaction("i1", tt.guild, "member", tt.opts...))
+
+			if got := sess.response("i1"); !strings.Contains(got, tt.want) {
+				t.Errorf("response = %q, want it to contain %q", got, tt.want)
+			}
+			if pl.channel() != "" {
+				t.Error("player should not be invoked")
+			}
+		})
+	}
+}
+
+func Test_handleInteraction_UnknownPreset(t *testing.T) {
+	b, sess := newTestBot(testConfig(), &mockGenerator{}, &mockPlayer{}, testVoiceStates())
+
+	b.handleInteraction(context.Background(), interaction("i1", "guild-1", "member", opt(optionPreset, "opera")))
+
+	got := sess.edit("i1")
+	if !strings.Contains(got, "Unknown preset") || !strings.Contains(got, "classic") {
+		t.Errorf("edit = %q, want unknown preset message listing presets", got)
+	}
+}
+
+func Test_handleInteraction_PlayFailed(t *testing.T) {
+	pl := &mockPlayer{err: errors.New("voice join timeout")}
+	b, sess := newTestBot(testConfig(), &mockGenerator{}, pl, testVoiceStates())
+
+	b.handleInteraction(context.Background(), interaction("i1", "guild-1", "member"))
+
+	if got := sess.edit("i1"); !strings.Contains(got, "Playback failed") {
+		t.Errorf("edit = %q, want playback failure message", got)
+	}
+}
+
+func Test_handleInteraction_GuildBusy(t *testing.T) {
+	pl := &mockPlayer{block: make(chan struct{}), started: make(chan struct{})}
+	b, sess := newTestBot(testConfig(), &mockGenerator{}, pl, testVoiceStates())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.handleInteraction(context.Background(), interaction("i1", "guild-1", "member"))
+	}()
+	<-pl.started
+
+	b.handleInteraction(context.Background(), interaction("i2", "guild-1", "member"))
+	if got := sess.response("i2"); !strings.Contains(got, "Already screaming") {
+		t.Errorf("second response = %q, want busy message", got)
+	}
+
+	close(pl.block)
+	<-done
+	if got := sess.edit("i1"); got != "Screamed in <#c1>." {
+		t.Errorf("first edit = %q", got)
+	}
+}
+
+func Test_handleInteraction_IgnoresOtherInteractions(t *testing.T) {
+	pl := &mockPlayer{}
+	b, sess := newTestBot(testConfig(), &mockGenerator{}, pl, testVoiceStates())
+
+	other := interaction("i1", "guild-1", "member")
+	other.Data = discordgo.ApplicationCommandInteractionData{Name: "other"}
+	b.handleInteraction(context.Background(), other)
+
+	ping := &discordgo.Interaction{ID: "i2", Type: discordgo.InteractionPing}
+	b.handleInteraction(context.Background(), ping)
+
+	if sess.response("i1") != "" || sess.response("i2") != "" {
+		t.Error("expected no responses for unrelated interactions")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Run tests
+// ---------------------------------------------------------------------------
+
+func Test_Run_RegistersCommand(t *testing.T) {
+	cfg := testConfig()
+	cfg.GuildID = "guild-1"
+	b, sess := newTestBot(cfg, &mockGenerator{}, &mockPlayer{}, testVoiceStates())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if sess.guildID != "guild-1" {
+		t.Errorf("registered in guild %q, want guild-1", sess.guildID)
+	}
+	if len(sess.commands) != 1 || sess.commands[0].Name != CommandName {
+		t.Errorf("registered commands = %v, want /%s", sess.commands, CommandName)
+	}
+	if !sess.removed {
+		t.Error("interaction handler should be removed on shutdown")
+	}
+}
+
+func Test_Run_RegisterError(t *testing.T) {
+	b, sess := newTestBot(testConfig(), &mockGenerator{}, &mockPlayer{}, nil)
+	sess.registerErr = errors.New("missing access")
+
+	err := b.Run(context.Background())
+	if !errors.Is(err, ErrRegisterFailed) {
+		t.Errorf("Run() error = %v, want ErrRegisterFailed", err)
+	}
+}
+
+func Test_Run_WaitsForActiveScreams(t *testing.T) {
+	pl := &mockPlayer{block: make(chan struct{}), started: make(chan struct{})}
+	b, sess := newTestBot(testConfig(), &mockGenerator{}, pl, testVoiceStates())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- b.Run(ctx) }()
+
+	// Wait for the handler to be registered, then deliver an interaction.
+	var handler func(*discordgo.Interaction)
+	for handler == nil {
+		sess.mu.Lock()
+		handler = sess.handler
+		sess.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+	go handler(interaction("i1", "guild-1", "member"))
+	<-pl.started
+
+	cancel()
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+
+	if got := sess.edit("i1"); !strings.Contains(got, "shutting down") {
+		t.Errorf("edit = %q, want shutdown message", got)
+	}
+
+	// Interactions after shutdown are ignored.
+	handler(interaction("i2", "guild-1", "member"))
+	if sess.response("i2") != "" {
+		t.Error("interaction after shutdown should be ignored")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// userMessage tests
+// ---------------------------------------------------------------------------
+
+func Test_userMessage(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{context.Canceled, "shutting down"},
+		{scream.ErrGenerateFailed, "Could not generate"},
+		{scream.ErrChannelResolveFailed, "Could not find a voice channel"},
+		{ErrGuildBusy, "Already screaming"},
+		{errors.New("boom"), "Something went wrong"},
+	}
+	b, _ := newTestBot(testConfig(), &mockGenerator{}, &mockPlayer{}, nil)
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := b.userMessage(tt.err); !strings.Contains(got, tt.want) {
+				t.Errorf("userMessage(%v) = %q, want it to contain %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_userMessage_UnknownPresetListsUserPresets(t *testing.T) {
+	reg := preset.Builtin()
+	var def preset.Definition
+	if err := yaml.Unmarshal([]byte("duration: 1s\nfilter:\n  crusher_bits: 8\n"), &def); err != nil {
+		t.Fatalf("yaml.Unmarshal: %v", err)
+	}
+	if err := reg.Add("howler", def); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	b, _ := newTestBot(testConfig(), &mockGenerator{}, &mockPlayer{}, nil)
+	b.svc = b.svc.WithPresets(reg)
+
+	got := b.userMessage(scream.ErrUnknownPreset)
+	if !strings.Contains(got, "classic") || !strings.Contains(got, "howler") {
+		t.Errorf("userMessage(ErrUnknownPreset) = %q, want built-in and user presets listed", got)
+	}
+}
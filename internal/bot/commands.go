@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/JamesPrial/go-scream/internal/config"
+)
+
+// CommandName is the name of the slash command registered by the bot.
+const CommandName = "scream"
+
+// Slash command option names.
+const (
+	optionPreset   = "preset"
+	optionDuration = "duration"
+	optionVolume   = "volume"
+	optionChannel  = "channel"
+)
+
+// Bounds for the duration option, in seconds. The upper bound keeps a single
+// interaction from tying up a voice channel indefinitely.
+const (
+	minDurationSeconds = 0.5
+	maxDurationSeconds = 30
+)
+
+// minVolume is the lower bound of the volume option. The service treats a
+// zero volume as "unchanged", so zero is not offered.
+const minVolume = 0.05
+
+// maxChoices is the maximum number of choices Discord accepts for an option.
+const maxChoices = 25
+
+// screamCommand returns the /scream application command definition. presets
+// become the choices of the preset option; at most maxChoices are offered.
+func screamCommand(presets []string) *discordgo.ApplicationCommand {
+	if len(presets) > maxChoices {
+		presets = presets[:maxChoices]
+	}
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(presets))
+	for i, name := range presets {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name}
+	}
+
+	minDuration := minDurationSeconds
+	minVol := minVolume
+
+	return &discordgo.ApplicationCommand{
+		Name:        CommandName,
+		Description: "Scream in a voice channel",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        optionPreset,
+				Description: "Scream preset",
+				Choices:     choices,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        optionDuration,
+				Description: "Duration in seconds",
+				MinValue:    &minDuration,
+				MaxValue:    maxDurationSeconds,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        optionVolume,
+				Description: "Volume multiplier up to 1.0",
+				MinValue:    &minVol,
+				MaxValue:    1,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionChannel,
+				Name:         optionChannel,
+				Description:  "Voice channel (defaults to yours)",
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice, discordgo.ChannelTypeGuildStageVoice},
+			},
+		},
+	}
+}
+
+// screamRequest holds the options of a single /scream invocation. Zero
+// values mean the option was not supplied.
+type screamRequest struct {
+	Preset    string
+	Duration  time.Duration
+	Volume    float64
+	ChannelID string
+}
+
+// parseRequest extracts a screamRequest from slash command options. It
+// returns an error wrapping ErrInvalidOption if an option has the wrong type
+// or is outside the range advertised by screamCommand.
+func parseRequest(opts []*discordgo.ApplicationCommandInteractionDataOption) (screamRequest, error) {
+	var req screamRequest
+	for _, opt := range opts {
+		switch opt.Name {
+		case optionPreset:
+			v, ok := opt.Value.(string)
+			if !ok {
+				return screamRequest{}, fmt.Errorf("%w: %s must be a string", ErrInvalidOption, opt.Name)
+			}
+			req.Preset = v
+		case optionDuration:
+			v, ok := opt.Value.(float64)
+			if !ok || math.IsNaN(v) || v < minDurationSeconds || v > maxDurationSeconds {
+				return screamRequest{}, fmt.Errorf("%w: %s must be between %g and %g seconds", ErrInvalidOption, opt.Name, minDurationSeconds, float64(maxDurationSeconds))
+			}
+			req.Duration = time.Duration(v * float64(time.Second))
+		case optionVolume:
+			v, ok := opt.Value.(float64)
+			if !ok || math.IsNaN(v) || v < minVolume || v > 1 {
+				return screamRequest{}, fmt.Errorf("%w: %s must be between %g and 1", ErrInvalidOption, opt.Name, minVolume)
+			}
+			req.Volume = v
+		case optionChannel:
+			v, ok := opt.Value.(string)
+			if !ok {
+				return screamRequest{}, fmt.Errorf("%w: %s must be a channel", ErrInvalidOption, opt.Name)
+			}
+			req.ChannelID = v
+		}
+	}
+	return req, nil
+}
+
+// apply overlays the request options onto cfg and returns the result.
+func (r screamRequest) apply(cfg config.Config) config.Config {
+	if r.Preset != "" {
+		cfg.Preset = r.Preset
+	}
+	if r.Duration > 0 {
+		cfg.Duration = r.Duration
+	}
+	if r.Volume > 0 {
+		cfg.Volume = r.Volume
+	}
+	return cfg
+}
@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/JamesPrial/go-scream/internal/config"
+)
+
+// ---------------------------------------------------------------------------
+// screamCommand tests
+// ---------------------------------------------------------------------------
+
+func Test_screamCommand_PresetChoices(t *testing.T) {
+	cmd := screamCommand([]string{"classic", "whisper"})
+
+	if cmd.Name != CommandName {
+		t.Errorf("Name = %q, want %q", cmd.Name, CommandName)
+	}
+	if len(cmd.Options) == 0 || cmd.Options[0].Name != optionPreset {
+		t.Fatalf("first option should be %q", optionPreset)
+	}
+	choices := cmd.Options[0].Choices
+	if len(choices) != 2 {
+		t.Fatalf("len(Choices) = %d, want 2", len(choices))
+	}
+	if choices[0].Name != "classic" || choices[0].Value != "classic" {
+		t.Errorf("Choices[0] = %+v, want classic", choices[0])
+	}
+}
+
+func Test_screamCommand_CapsChoices(t *testing.T) {
+	presets := make([]string, maxChoices+5)
+	for i := range presets {
+		presets[i] = "p"
+	}
+	cmd := screamCommand(presets)
+	if got := len(cmd.Options[0].Choices); got != maxChoices {
+		t.Errorf("len(Choices) = %d, want %d", got, maxChoices)
+	}
+}
+
+func Test_screamCommand_AllOptionsOptional(t *testing.T) {
+	cmd := screamCommand(nil)
+	for _, opt := range cmd.Options {
+		if opt.Required {
+			t.Errorf("option %q is required, want optional", opt.Name)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// parseRequest tests
+// ---------------------------------------------------------------------------
+
+func opt(name string, value interface{}) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Value: value}
+}
+
+func Test_parseRequest_Valid(t *testing.T) {
+	req, err := parseRequest([]*discordgo.ApplicationCommandInteractionDataOption{
+		opt(optionPreset, "banshee"),
+		opt(optionDuration, 2.5),
+		opt(optionVolume, 0.5),
+		opt(optionChannel, "chan-1"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := screamRequest{Preset: "banshee", Duration: 2500 * time.Millisecond, Volume: 0.5, ChannelID: "chan-1"}
+	if req != want {
+		t.Errorf("parseRequest() = %+v, want %+v", req, want)
+	}
+}
+
+func Test_parseRequest_Empty(t *testing.T) {
+	req, err := parseRequest(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req != (screamRequest{}) {
+		t.Errorf("parseRequest(nil) = %+v, want zero", req)
+	}
+}
+
+func Test_parseRequest_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  *discordgo.ApplicationCommandInteractionDataOption
+	}{
+		{"preset not string", opt(optionPreset, 1.0)},
+		{"duration not number", opt(optionDuration, "3s")},
+		{"duration too short", opt(optionDuration, 0.1)},
+		{"duration too long", opt(optionDuration, 31.0)},
+		{"volume zero", opt(optionVolume, 0.0)},
+		{"volume too high", opt(optionVolume, 1.5)},
+		{"channel not string", opt(optionChannel, 42.0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRequest([]*discordgo.ApplicationCommandInteractionDataOption{tt.opt})
+			if !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("parseRequest() error = %v, want ErrInvalidOption", err)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// screamRequest.apply tests
+// ---------------------------------------------------------------------------
+
+func Test_screamRequest_apply(t *testing.T) {
+	base := config.Default()
+	base.Preset = "classic"
+
+	t.Run("empty request keeps config", func(t *testing.T) {
+		got := screamRequest{}.apply(base)
+		if got.Preset != base.Preset || got.Duration != base.Duration || got.Volume != base.Volume {
+			t.Errorf("apply() = %+v, want unchanged %+v", got, base)
+		}
+	})
+
+	t.Run("options override config", func(t *testing.T) {
+		got := screamRequest{Preset: "robot", Duration: time.Second, Volume: 0.25}.apply(base)
+		if got.Preset != "robot" {
+			t.Errorf("Preset = %q, want robot", got.Preset)
+		}
+		if got.Duration != time.Second {
+			t.Errorf("Duration = %v, want 1s", got.Duration)
+		}
+		if got.Volume != 0.25 {
+			t.Errorf("Volume = %v, want 0.25", got.Volume)
+		}
+	})
+}
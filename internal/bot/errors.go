@@ -0,0 +1,21 @@
+// Package bot runs go-scream as a long-lived Discord bot that answers
+// /scream slash commands over a single gateway session.
+package bot
+
+import "errors"
+
+// Sentinel errors returned by the bot package.
+var (
+	// ErrRegisterFailed is returned when the slash commands cannot be registered.
+	ErrRegisterFailed = errors.New("bot: failed to register slash commands")
+
+	// ErrGuildBusy is returned when a scream is already playing in the guild.
+	ErrGuildBusy = errors.New("bot: already screaming in this guild")
+
+	// ErrNotInGuild is returned when the command is invoked outside a guild.
+	ErrNotInGuild = errors.New("bot: command must be used in a server")
+
+	// ErrInvalidOption is returned when a slash command option has an
+	// unexpected type or an out-of-range value.
+	ErrInvalidOption = errors.New("bot: invalid command option")
+)
@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// Session abstracts the subset of *discordgo.Session behaviour used by the bot.
+type Session interface {
+	// RegisterCommands replaces the bot's application commands in guildID, or
+	// globally when guildID is empty.
+	RegisterCommands(guildID string, cmds []*discordgo.ApplicationCommand) error
+
+	// AddInteractionHandler registers fn for every incoming interaction and
+	// returns a function that removes the handler.
+	AddInteractionHandler(fn func(i *discordgo.Interaction)) func()
+
+	// Respond sends the initial ephemeral response to an interaction.
+	Respond(i *discordgo.Interaction, content string) error
+
+	// EditResponse replaces the content of the initial interaction response.
+	EditResponse(i *discordgo.Interaction, content string) error
+}
+
+// GoSession wraps *discordgo.Session to satisfy the Session interface.
+type GoSession struct {
+	S *discordgo.Session
+}
+
+// Compile-time interface check.
+var _ Session = (*GoSession)(nil)
+
+// RegisterCommands bulk-overwrites the application commands for the bot user.
+// Overwriting is idempotent, so it is safe to call on every start.
+func (d *GoSession) RegisterCommands(guildID string, cmds []*discordgo.ApplicationCommand) error {
+	_, err := d.S.ApplicationCommandBulkOverwrite(d.S.State.User.ID, guildID, cmds)
+	return err
+}
+
+// AddInteractionHandler registers fn as an InteractionCreate handler.
+func (d *GoSession) AddInteractionHandler(fn func(i *discordgo.Interaction)) func() {
+	return d.S.AddHandler(func(_ *discordgo.Session, ic *discordgo.InteractionCreate) {
+		fn(ic.Interaction)
+	})
+}
+
+// Respond answers the interaction with an ephemeral channel message.
+func (d *GoSession) Respond(i *discordgo.Interaction, content string) error {
+	return d.S.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// EditResponse edits the original interaction response.
+func (d *GoSession) EditResponse(i *discordgo.Interaction, content string) error {
+	_, err := d.S.InteractionResponseEdit(i, &discordgo.WebhookEdit{Content: &content})
+	return err
+}
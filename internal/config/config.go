@@ -7,6 +7,10 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+	"github.com/JamesPrial/go-scream/internal/discord"
+	"github.com/JamesPrial/go-scream/internal/preset"
 )
 
 // BackendType identifies the audio generation backend.
@@ -37,6 +41,7 @@ type Config struct {
 	GuildID    string        `yaml:"guild_id"`
 	Backend    BackendType   `yaml:"backend"`
 	Preset     string        `yaml:"preset"`
+	Seed       int64         `yaml:"seed"`
 	Duration   time.Duration `yaml:"duration"`
 	Volume     float64       `yaml:"volume"`
 	OutputFile string        `yaml:"output_file"`
@@ -44,6 +49,46 @@ type Config struct {
 	DryRun     bool          `yaml:"dry_run"`
 	Verbose    bool          `yaml:"verbose"`
 	LogLevel   string        `yaml:"log_level"`
+
+	ChannelStrategy   discord.ChannelStrategy `yaml:"channel_strategy"`
+	ChannelUserID     string                  `yaml:"channel_user_id"`
+	PreferredChannels []string                `yaml:"preferred_channels"`
+
+	// PresetsDir is a directory of YAML preset files loaded alongside the
+	// built-in presets. Presets holds further user presets defined inline.
+	PresetsDir string                       `yaml:"presets_dir"`
+	Presets    map[string]preset.Definition `yaml:"presets"`
+
+	// Overrides are "path=value" parameter overrides (see preset.Override)
+	// applied, in order, to the selected preset or random scream.
+	Overrides []string `yaml:"overrides"`
+
+	// MorphTo names a second preset to blend with Preset. With a zero Mix
+	// the scream morphs from Preset to MorphTo over its duration; otherwise
+	// it is a static blend, Mix of the way from Preset to MorphTo.
+	MorphTo string  `yaml:"morph_to"`
+	Mix     float64 `yaml:"mix"`
+
+	// Text, if set, makes a scream that sounds out the text (see
+	// audio.FromText) in place of Preset. Its duration follows the text, so
+	// Duration is ignored.
+	Text string `yaml:"text"`
+
+	// TargetLUFS, if non-zero, normalizes every scream to this integrated
+	// loudness (see audio.Normalize), without letting its true peak rise
+	// above TruePeak dBTP. truePeakSet records that a config file gave
+	// TruePeak, so that Merge keeps a 0 dBTP ceiling from it.
+	TargetLUFS  float64 `yaml:"target_lufs"`
+	TruePeak    float64 `yaml:"true_peak"`
+	truePeakSet bool
+
+	// Voices, if more than 1, makes the scream a crowd of that many voices
+	// (see audio.ScreamParams.CrowdVoices), Spread [0, 1] apart; a zero
+	// Spread keeps that of the preset. VoicePresets names further presets
+	// that take turns with Preset to give voices.
+	Voices       int      `yaml:"voices"`
+	Spread       float64  `yaml:"spread"`
+	VoicePresets []string `yaml:"voice_presets"`
 }
 
 // rawConfig is an intermediate struct used for YAML unmarshaling. It captures
@@ -54,6 +99,7 @@ type rawConfig struct {
 	GuildID    string      `yaml:"guild_id"`
 	Backend    BackendType `yaml:"backend"`
 	Preset     string      `yaml:"preset"`
+	Seed       int64       `yaml:"seed"`
 	Duration   yaml.Node   `yaml:"duration"`
 	Volume     float64     `yaml:"volume"`
 	OutputFile string      `yaml:"output_file"`
@@ -61,6 +107,27 @@ type rawConfig struct {
 	DryRun     bool        `yaml:"dry_run"`
 	Verbose    bool        `yaml:"verbose"`
 	LogLevel   string      `yaml:"log_level"`
+
+	ChannelStrategy   discord.ChannelStrategy `yaml:"channel_strategy"`
+	ChannelUserID     string                  `yaml:"channel_user_id"`
+	PreferredChannels []string                `yaml:"preferred_channels"`
+
+	PresetsDir string                       `yaml:"presets_dir"`
+	Presets    map[string]preset.Definition `yaml:"presets"`
+
+	Overrides []string `yaml:"overrides"`
+
+	MorphTo string  `yaml:"morph_to"`
+	Mix     float64 `yaml:"mix"`
+
+	Text string `yaml:"text"`
+
+	TargetLUFS float64  `yaml:"target_lufs"`
+	TruePeak   *float64 `yaml:"true_peak"`
+
+	Voices       int      `yaml:"voices"`
+	Spread       float64  `yaml:"spread"`
+	VoicePresets []string `yaml:"voice_presets"`
 }
 
 // UnmarshalYAML implements yaml.Unmarshaler so that duration fields are parsed
@@ -76,12 +143,30 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 	c.GuildID = raw.GuildID
 	c.Backend = raw.Backend
 	c.Preset = raw.Preset
+	c.Seed = raw.Seed
 	c.Volume = raw.Volume
 	c.OutputFile = raw.OutputFile
 	c.Format = raw.Format
 	c.DryRun = raw.DryRun
 	c.Verbose = raw.Verbose
 	c.LogLevel = raw.LogLevel
+	c.ChannelStrategy = raw.ChannelStrategy
+	c.ChannelUserID = raw.ChannelUserID
+	c.PreferredChannels = raw.PreferredChannels
+	c.PresetsDir = raw.PresetsDir
+	c.Presets = raw.Presets
+	c.Overrides = raw.Overrides
+	c.MorphTo = raw.MorphTo
+	c.Mix = raw.Mix
+	c.Text = raw.Text
+	c.TargetLUFS = raw.TargetLUFS
+	if raw.TruePeak != nil {
+		c.TruePeak = *raw.TruePeak
+		c.truePeakSet = true
+	}
+	c.Voices = raw.Voices
+	c.Spread = raw.Spread
+	c.VoicePresets = raw.VoicePresets
 
 	// Parse duration from the raw YAML node when present.
 	if raw.Duration.Value != "" {
@@ -97,7 +182,8 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 
 // Default returns a Config with sensible default values.
 // Backend defaults to "native", Preset to "classic", Duration to 3 seconds,
-// Volume to 1.0, and Format to "ogg". All other fields are zero values.
+// Volume to 1.0, Format to "ogg", and TruePeak to audio.DefaultTruePeak.
+// All other fields are zero values.
 func Default() Config {
 	return Config{
 		Backend:  BackendNative,
@@ -105,12 +191,14 @@ func Default() Config {
 		Duration: 3 * time.Second,
 		Volume:   1.0,
 		Format:   FormatOGG,
+		TruePeak: audio.DefaultTruePeak,
 	}
 }
 
 // Merge combines base and overlay into a new Config. Non-zero overlay fields
 // replace the corresponding base fields. Zero values (empty string, 0 duration,
-// 0.0 float64, false bool) are treated as unset and the base value is kept.
+// 0.0 float64, false bool, empty slice or map) are treated as unset and the base
+// value is kept, except for a TruePeak of 0 that overlay was loaded with.
 // Neither base nor overlay is mutated.
 func Merge(base, overlay Config) Config {
 	result := base
@@ -127,6 +215,9 @@ func Merge(base, overlay Config) Config {
 	if overlay.Preset != "" {
 		result.Preset = overlay.Preset
 	}
+	if overlay.Seed != 0 {
+		result.Seed = overlay.Seed
+	}
 	if overlay.Duration != 0 {
 		result.Duration = overlay.Duration
 	}
@@ -148,6 +239,49 @@ func Merge(base, overlay Config) Config {
 	if overlay.LogLevel != "" {
 		result.LogLevel = overlay.LogLevel
 	}
+	if overlay.ChannelStrategy != "" {
+		result.ChannelStrategy = overlay.ChannelStrategy
+	}
+	if overlay.ChannelUserID != "" {
+		result.ChannelUserID = overlay.ChannelUserID
+	}
+	if len(overlay.PreferredChannels) > 0 {
+		result.PreferredChannels = overlay.PreferredChannels
+	}
+	if overlay.PresetsDir != "" {
+		result.PresetsDir = overlay.PresetsDir
+	}
+	if len(overlay.Presets) > 0 {
+		result.Presets = overlay.Presets
+	}
+	if len(overlay.Overrides) > 0 {
+		result.Overrides = overlay.Overrides
+	}
+	if overlay.MorphTo != "" {
+		result.MorphTo = overlay.MorphTo
+	}
+	if overlay.Mix != 0 {
+		result.Mix = overlay.Mix
+	}
+	if overlay.Text != "" {
+		result.Text = overlay.Text
+	}
+	if overlay.TargetLUFS != 0 {
+		result.TargetLUFS = overlay.TargetLUFS
+	}
+	if overlay.TruePeak != 0 || overlay.truePeakSet {
+		result.TruePeak = overlay.TruePeak
+		result.truePeakSet = true
+	}
+	if overlay.Voices != 0 {
+		result.Voices = overlay.Voices
+	}
+	if overlay.Spread != 0 {
+		result.Spread = overlay.Spread
+	}
+	if len(overlay.VoicePresets) > 0 {
+		result.VoicePresets = overlay.VoicePresets
+	}
 
 	return result
 }
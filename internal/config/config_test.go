@@ -6,6 +6,9 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/JamesPrial/go-scream/internal/discord"
+	"github.com/JamesPrial/go-scream/internal/preset"
 )
 
 // ---------------------------------------------------------------------------
@@ -63,6 +66,14 @@ func TestDefault_ReturnsExpectedValues(t *testing.T) {
 	if cfg.LogLevel != "" {
 		t.Errorf("Default().LogLevel = %q, want %q", cfg.LogLevel, "")
 	}
+
+	// Loudness normalization is off, with a -1 dBTP ceiling once enabled.
+	if cfg.TargetLUFS != 0 {
+		t.Errorf("Default().TargetLUFS = %v, want 0", cfg.TargetLUFS)
+	}
+	if cfg.TruePeak != -1 {
+		t.Errorf("Default().TruePeak = %v, want -1", cfg.TruePeak)
+	}
 }
 
 func TestDefault_BackendConstant(t *testing.T) {
@@ -287,6 +298,83 @@ func TestMerge_FieldTypes(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:    "int64 field: Seed override",
+			base:    Config{Seed: 1},
+			overlay: Config{Seed: 42},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if got.Seed != 42 {
+					t.Errorf("Seed = %d, want %d", got.Seed, 42)
+				}
+			},
+		},
+		{
+			name:    "int64 field: zero Seed keeps base",
+			base:    Config{Seed: 7},
+			overlay: Config{},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if got.Seed != 7 {
+					t.Errorf("Seed = %d, want %d", got.Seed, 7)
+				}
+			},
+		},
+		{
+			name:    "morph fields override",
+			base:    Config{MorphTo: "whisper", Mix: 0.2},
+			overlay: Config{MorphTo: "banshee", Mix: 0.6},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if got.MorphTo != "banshee" || got.Mix != 0.6 {
+					t.Errorf("MorphTo, Mix = %q, %v; want %q, %v", got.MorphTo, got.Mix, "banshee", 0.6)
+				}
+			},
+		},
+		{
+			name:    "loudness fields override",
+			base:    Config{TargetLUFS: -23, TruePeak: -1},
+			overlay: Config{TargetLUFS: -14, TruePeak: -2},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if got.TargetLUFS != -14 || got.TruePeak != -2 {
+					t.Errorf("TargetLUFS, TruePeak = %v, %v; want %v, %v", got.TargetLUFS, got.TruePeak, -14.0, -2.0)
+				}
+			},
+		},
+		{
+			name:    "crowd fields override",
+			base:    Config{Voices: 4, Spread: 0.2, VoicePresets: []string{"whisper"}},
+			overlay: Config{Voices: 8, Spread: 0.7, VoicePresets: []string{"banshee", "robot"}},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if got.Voices != 8 || got.Spread != 0.7 || len(got.VoicePresets) != 2 || got.VoicePresets[0] != "banshee" {
+					t.Errorf("Voices, Spread, VoicePresets = %v, %v, %v; want 8, 0.7, [banshee robot]", got.Voices, got.Spread, got.VoicePresets)
+				}
+			},
+		},
+		{
+			name:    "crowd fields kept when overlay unset",
+			base:    Config{Voices: 4, Spread: 0.2, VoicePresets: []string{"whisper"}},
+			overlay: Config{},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if got.Voices != 4 || got.Spread != 0.2 || len(got.VoicePresets) != 1 {
+					t.Errorf("Voices, Spread, VoicePresets = %v, %v, %v; want 4, 0.2, [whisper]", got.Voices, got.Spread, got.VoicePresets)
+				}
+			},
+		},
+		{
+			name:    "text overrides",
+			base:    Config{Text: "AAAA"},
+			overlay: Config{Text: "NOOO"},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if got.Text != "NOOO" {
+					t.Errorf("Text = %q, want %q", got.Text, "NOOO")
+				}
+			},
+		},
 		{
 			name:    "float64 field: Volume override",
 			base:    Config{Volume: 1.0},
@@ -298,6 +386,39 @@ func TestMerge_FieldTypes(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:    "ChannelStrategy field override",
+			base:    Config{ChannelStrategy: discord.StrategyFirstPopulated},
+			overlay: Config{ChannelStrategy: discord.StrategyMostPopulated},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if got.ChannelStrategy != discord.StrategyMostPopulated {
+					t.Errorf("ChannelStrategy = %q, want %q", got.ChannelStrategy, discord.StrategyMostPopulated)
+				}
+			},
+		},
+		{
+			name:    "slice field: PreferredChannels override",
+			base:    Config{PreferredChannels: []string{"old"}},
+			overlay: Config{PreferredChannels: []string{"new1", "new2"}},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if len(got.PreferredChannels) != 2 || got.PreferredChannels[0] != "new1" {
+					t.Errorf("PreferredChannels = %v, want [new1 new2]", got.PreferredChannels)
+				}
+			},
+		},
+		{
+			name:    "slice field: empty overlay preserves base",
+			base:    Config{PreferredChannels: []string{"keep"}},
+			overlay: Config{},
+			check: func(t *testing.T, got Config) {
+				t.Helper()
+				if len(got.PreferredChannels) != 1 || got.PreferredChannels[0] != "keep" {
+					t.Errorf("PreferredChannels = %v, want [keep]", got.PreferredChannels)
+				}
+			},
+		},
 		{
 			name:    "bool field: DryRun override true",
 			base:    Config{},
@@ -417,6 +538,42 @@ func TestMerge_LogLevel(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Merge() — user preset fields
+// ---------------------------------------------------------------------------
+
+func TestMerge_Presets(t *testing.T) {
+	base := Config{
+		PresetsDir: "/base",
+		Presets:    map[string]preset.Definition{"a": {}},
+	}
+
+	got := Merge(base, Config{})
+	if got.PresetsDir != "/base" || len(got.Presets) != 1 {
+		t.Errorf("Merge() with empty overlay = %q / %d presets, want base kept", got.PresetsDir, len(got.Presets))
+	}
+
+	got = Merge(base, Config{
+		PresetsDir: "/overlay",
+		Presets:    map[string]preset.Definition{"b": {}, "c": {}},
+	})
+	if got.PresetsDir != "/overlay" || len(got.Presets) != 2 {
+		t.Errorf("Merge() = %q / %d presets, want overlay to win", got.PresetsDir, len(got.Presets))
+	}
+}
+
+func TestMerge_Overrides(t *testing.T) {
+	base := Config{Overrides: []string{"duration=5s"}}
+
+	if got := Merge(base, Config{}); len(got.Overrides) != 1 {
+		t.Errorf("Merge() with empty overlay Overrides = %q, want base kept", got.Overrides)
+	}
+	got := Merge(base, Config{Overrides: []string{"width=0", "seed=1"}})
+	if len(got.Overrides) != 2 || got.Overrides[0] != "width=0" {
+		t.Errorf("Merge() Overrides = %q, want overlay to win", got.Overrides)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // UnmarshalYAML — LogLevel field
 // ---------------------------------------------------------------------------
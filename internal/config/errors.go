@@ -18,12 +18,52 @@ var (
 	// ErrInvalidPreset is returned when the preset name is not known.
 	ErrInvalidPreset = errors.New("config: unknown preset name")
 
+	// ErrInvalidMorphTo is returned when the morph_to preset name is not
+	// known.
+	ErrInvalidMorphTo = errors.New("config: unknown morph_to preset name")
+
+	// ErrInvalidMix is returned when the mix is outside [0.0, 1.0] or is set
+	// without a morph_to preset.
+	ErrInvalidMix = errors.New("config: mix must be between 0.0 and 1.0 and requires morph_to")
+
+	// ErrInvalidVoicePreset is returned when a voice_presets name is not
+	// known.
+	ErrInvalidVoicePreset = errors.New("config: unknown voice_presets preset name")
+
+	// ErrPresetsLoad is returned when the user presets in PresetsDir or
+	// Presets cannot be loaded or are invalid.
+	ErrPresetsLoad = errors.New("config: failed to load user presets")
+
+	// ErrInvalidOverride is returned when a parameter override is malformed
+	// or names an unknown parameter.
+	ErrInvalidOverride = errors.New("config: invalid parameter override")
+
+	// ErrInvalidText is returned when the text of a text scream has no
+	// letters or too many.
+	ErrInvalidText = errors.New("config: invalid text")
+
 	// ErrInvalidDuration is returned when the duration is not positive.
 	ErrInvalidDuration = errors.New("config: duration must be positive")
 
 	// ErrInvalidVolume is returned when the volume is outside [0.0, 1.0].
 	ErrInvalidVolume = errors.New("config: volume must be between 0.0 and 1.0")
 
+	// ErrInvalidTargetLUFS is returned when the loudness target is set but
+	// outside [-70, -5] LUFS.
+	ErrInvalidTargetLUFS = errors.New("config: target LUFS must be between -70 and -5")
+
+	// ErrInvalidTruePeak is returned when the true-peak ceiling is outside
+	// [-20, 0] dBTP.
+	ErrInvalidTruePeak = errors.New("config: true peak must be between -20 and 0 dBTP")
+
+	// ErrInvalidVoices is returned when the number of voices is outside
+	// [0, 16].
+	ErrInvalidVoices = errors.New("config: voices must be between 0 and 16")
+
+	// ErrInvalidSpread is returned when the spread of a crowd's voices is
+	// outside [0.0, 1.0].
+	ErrInvalidSpread = errors.New("config: spread must be between 0.0 and 1.0")
+
 	// ErrInvalidFormat is returned when the format is not "ogg" or "wav".
 	ErrInvalidFormat = errors.New("config: format must be 'ogg' or 'wav'")
 
@@ -42,4 +82,16 @@ var (
 	// ErrInvalidLogLevel is returned when the log level is not one of the
 	// accepted values: debug, info, warn, or error.
 	ErrInvalidLogLevel = errors.New("config: invalid log level (must be debug, info, warn, or error)")
+
+	// ErrInvalidChannelStrategy is returned when the channel strategy is not
+	// one of: first, most, user, preferred.
+	ErrInvalidChannelStrategy = errors.New("config: channel strategy must be 'first', 'most', 'user', or 'preferred'")
+
+	// ErrMissingChannelUser is returned when the "user" channel strategy is
+	// selected without a ChannelUserID.
+	ErrMissingChannelUser = errors.New("config: channel user ID is required for the 'user' channel strategy")
+
+	// ErrMissingPreferredChannels is returned when the "preferred" channel
+	// strategy is selected without any PreferredChannels.
+	ErrMissingPreferredChannels = errors.New("config: preferred channels are required for the 'preferred' channel strategy")
 )
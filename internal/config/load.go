@@ -3,17 +3,23 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/JamesPrial/go-scream/internal/discord"
+	"github.com/JamesPrial/go-scream/internal/preset"
 )
 
 // Load reads a YAML config file at path and returns the parsed Config.
 // If the file does not exist, ErrConfigNotFound is returned (wrapped).
 // If the file cannot be parsed, ErrConfigParse is returned (wrapped).
 // An empty file returns a zero-value Config with no error.
-// Unknown YAML fields are silently ignored.
+// Unknown YAML fields are silently ignored. A relative presets_dir is
+// resolved against the directory containing the config file.
 func Load(path string) (Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -28,9 +34,24 @@ func Load(path string) (Config, error) {
 		return Config{}, fmt.Errorf("%w: %w", ErrConfigParse, err)
 	}
 
+	if cfg.PresetsDir != "" && !filepath.IsAbs(cfg.PresetsDir) {
+		cfg.PresetsDir = filepath.Join(filepath.Dir(path), cfg.PresetsDir)
+	}
+
 	return cfg, nil
 }
 
+// LoadPresets returns the preset registry described by cfg: the built-in
+// presets, the preset files in cfg.PresetsDir, and the inline cfg.Presets.
+// Errors wrap ErrPresetsLoad together with the preset package's error.
+func LoadPresets(cfg Config) (*preset.Registry, error) {
+	reg, err := preset.Load(cfg.PresetsDir, cfg.Presets)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPresetsLoad, err)
+	}
+	return reg, nil
+}
+
 // ApplyEnv overlays environment variable values onto cfg. Only non-empty
 // environment variable values are applied; empty strings leave the field
 // unchanged. Parse errors for numeric or boolean values are silently ignored.
@@ -40,10 +61,23 @@ func Load(path string) (Config, error) {
 //   - SCREAM_GUILD_ID -> cfg.GuildID
 //   - SCREAM_BACKEND  -> cfg.Backend
 //   - SCREAM_PRESET   -> cfg.Preset
+//   - SCREAM_SEED     -> cfg.Seed (int64)
+//   - SCREAM_MORPH_TO -> cfg.MorphTo
+//   - SCREAM_MIX      -> cfg.Mix (float64)
 //   - SCREAM_DURATION -> cfg.Duration (Go duration string, e.g. "5s")
 //   - SCREAM_VOLUME   -> cfg.Volume (float64)
+//   - SCREAM_TARGET_LUFS -> cfg.TargetLUFS (float64)
+//   - SCREAM_TRUE_PEAK   -> cfg.TruePeak (float64)
+//   - SCREAM_VOICES   -> cfg.Voices (int)
+//   - SCREAM_SPREAD   -> cfg.Spread (float64)
+//   - SCREAM_VOICE_PRESETS -> cfg.VoicePresets (comma-separated)
 //   - SCREAM_FORMAT   -> cfg.Format
 //   - SCREAM_VERBOSE  -> cfg.Verbose (bool)
+//   - SCREAM_LOG_LEVEL -> cfg.LogLevel
+//   - SCREAM_CHANNEL_STRATEGY -> cfg.ChannelStrategy
+//   - SCREAM_CHANNEL_USER_ID  -> cfg.ChannelUserID
+//   - SCREAM_PREFERRED_CHANNELS -> cfg.PreferredChannels (comma-separated)
+//   - SCREAM_PRESETS_DIR -> cfg.PresetsDir
 func ApplyEnv(cfg *Config) {
 	if v := os.Getenv("DISCORD_TOKEN"); v != "" {
 		cfg.Token = v
@@ -57,6 +91,19 @@ func ApplyEnv(cfg *Config) {
 	if v := os.Getenv("SCREAM_PRESET"); v != "" {
 		cfg.Preset = v
 	}
+	if v := os.Getenv("SCREAM_SEED"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.Seed = n
+		}
+	}
+	if v := os.Getenv("SCREAM_MORPH_TO"); v != "" {
+		cfg.MorphTo = v
+	}
+	if v := os.Getenv("SCREAM_MIX"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Mix = f
+		}
+	}
 	if v := os.Getenv("SCREAM_DURATION"); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
 			cfg.Duration = d
@@ -67,6 +114,29 @@ func ApplyEnv(cfg *Config) {
 			cfg.Volume = f
 		}
 	}
+	if v := os.Getenv("SCREAM_TARGET_LUFS"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.TargetLUFS = f
+		}
+	}
+	if v := os.Getenv("SCREAM_TRUE_PEAK"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.TruePeak = f
+		}
+	}
+	if v := os.Getenv("SCREAM_VOICES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.Voices = n
+		}
+	}
+	if v := os.Getenv("SCREAM_SPREAD"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.Spread = f
+		}
+	}
+	if v := os.Getenv("SCREAM_VOICE_PRESETS"); v != "" {
+		cfg.VoicePresets = splitList(v)
+	}
 	if v := os.Getenv("SCREAM_FORMAT"); v != "" {
 		cfg.Format = FormatType(v)
 	}
@@ -78,4 +148,28 @@ func ApplyEnv(cfg *Config) {
 	if v := os.Getenv("SCREAM_LOG_LEVEL"); v != "" {
 		cfg.LogLevel = v
 	}
+	if v := os.Getenv("SCREAM_CHANNEL_STRATEGY"); v != "" {
+		cfg.ChannelStrategy = discord.ChannelStrategy(v)
+	}
+	if v := os.Getenv("SCREAM_CHANNEL_USER_ID"); v != "" {
+		cfg.ChannelUserID = v
+	}
+	if v := os.Getenv("SCREAM_PREFERRED_CHANNELS"); v != "" {
+		cfg.PreferredChannels = splitList(v)
+	}
+	if v := os.Getenv("SCREAM_PRESETS_DIR"); v != "" {
+		cfg.PresetsDir = v
+	}
+}
+
+// splitList splits a comma-separated list, trimming whitespace and dropping
+// empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
 }
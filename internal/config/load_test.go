@@ -4,8 +4,11 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/JamesPrial/go-scream/internal/discord"
 )
 
 // ---------------------------------------------------------------------------
@@ -595,3 +598,387 @@ func TestApplyEnv_LogLevel(t *testing.T) {
 		})
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Channel resolution settings
+// ---------------------------------------------------------------------------
+
+func TestLoad_ChannelSettings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `channel_strategy: preferred
+channel_user_id: "user-1"
+preferred_channels:
+  - "c1"
+  - "c2"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if cfg.ChannelStrategy != discord.StrategyPreferred {
+		t.Errorf("ChannelStrategy = %q, want %q", cfg.ChannelStrategy, discord.StrategyPreferred)
+	}
+	if cfg.ChannelUserID != "user-1" {
+		t.Errorf("ChannelUserID = %q, want %q", cfg.ChannelUserID, "user-1")
+	}
+	if len(cfg.PreferredChannels) != 2 || cfg.PreferredChannels[0] != "c1" || cfg.PreferredChannels[1] != "c2" {
+		t.Errorf("PreferredChannels = %v, want [c1 c2]", cfg.PreferredChannels)
+	}
+}
+
+func TestApplyEnv_ChannelSettings(t *testing.T) {
+	t.Setenv("SCREAM_CHANNEL_STRATEGY", "user")
+	t.Setenv("SCREAM_CHANNEL_USER_ID", "user-9")
+	t.Setenv("SCREAM_PREFERRED_CHANNELS", " c1, ,c2 ")
+
+	cfg := Config{}
+	ApplyEnv(&cfg)
+
+	if cfg.ChannelStrategy != discord.StrategyUser {
+		t.Errorf("ChannelStrategy = %q, want %q", cfg.ChannelStrategy, discord.StrategyUser)
+	}
+	if cfg.ChannelUserID != "user-9" {
+		t.Errorf("ChannelUserID = %q, want %q", cfg.ChannelUserID, "user-9")
+	}
+	if len(cfg.PreferredChannels) != 2 || cfg.PreferredChannels[0] != "c1" || cfg.PreferredChannels[1] != "c2" {
+		t.Errorf("PreferredChannels = %q, want [c1 c2]", cfg.PreferredChannels)
+	}
+}
+
+func TestApplyEnv_ChannelSettings_EmptyPreserves(t *testing.T) {
+	t.Setenv("SCREAM_CHANNEL_STRATEGY", "")
+	t.Setenv("SCREAM_PREFERRED_CHANNELS", "")
+
+	cfg := Config{ChannelStrategy: discord.StrategyMostPopulated, PreferredChannels: []string{"c1"}}
+	ApplyEnv(&cfg)
+
+	if cfg.ChannelStrategy != discord.StrategyMostPopulated {
+		t.Errorf("ChannelStrategy = %q, want %q", cfg.ChannelStrategy, discord.StrategyMostPopulated)
+	}
+	if len(cfg.PreferredChannels) != 1 {
+		t.Errorf("PreferredChannels = %v, want [c1]", cfg.PreferredChannels)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// User presets
+// ---------------------------------------------------------------------------
+
+// userPresetYAML defines a valid user preset called "howler".
+const userPresetYAML = `howler:
+  duration: 2s
+  filter:
+    crusher_bits: 8
+    lowpass_cutoff: 4000
+`
+
+func TestLoad_InlinePresets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := "preset: howler\npresets:\n  " + strings.ReplaceAll(userPresetYAML, "\n", "\n  ")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if _, ok := cfg.Presets["howler"]; !ok {
+		t.Fatalf("Presets = %v, want a howler entry", cfg.Presets)
+	}
+
+	reg, err := LoadPresets(cfg)
+	if err != nil {
+		t.Fatalf("LoadPresets() unexpected error: %v", err)
+	}
+	p, ok := reg.Get("howler")
+	if !ok {
+		t.Fatal("registry is missing howler")
+	}
+	if p.Duration != 2*time.Second || p.Filter.LowpassCutoff != 4000 {
+		t.Errorf("howler = %v / %v Hz, want 2s / 4000 Hz", p.Duration, p.Filter.LowpassCutoff)
+	}
+}
+
+func TestLoad_RelativePresetsDir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("presets_dir: presets\n"), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if want := filepath.Join(dir, "presets"); cfg.PresetsDir != want {
+		t.Errorf("PresetsDir = %q, want %q", cfg.PresetsDir, want)
+	}
+}
+
+func TestLoadPresets_FromDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "howler.yaml"), []byte(userPresetYAML), 0644); err != nil {
+		t.Fatalf("failed to write preset file: %v", err)
+	}
+
+	reg, err := LoadPresets(Config{PresetsDir: dir})
+	if err != nil {
+		t.Fatalf("LoadPresets() unexpected error: %v", err)
+	}
+	if !reg.Has("howler") || !reg.Has("classic") {
+		t.Errorf("registry names = %v, want built-ins and howler", reg.Names())
+	}
+}
+
+func TestLoadPresets_MissingDir(t *testing.T) {
+	_, err := LoadPresets(Config{PresetsDir: filepath.Join(t.TempDir(), "nope")})
+	if !errors.Is(err, ErrPresetsLoad) {
+		t.Errorf("LoadPresets() error = %v, want ErrPresetsLoad", err)
+	}
+}
+
+func TestApplyEnv_PresetsDir(t *testing.T) {
+	t.Setenv("SCREAM_PRESETS_DIR", "/etc/scream/presets")
+
+	cfg := Config{}
+	ApplyEnv(&cfg)
+
+	if cfg.PresetsDir != "/etc/scream/presets" {
+		t.Errorf("PresetsDir = %q, want %q", cfg.PresetsDir, "/etc/scream/presets")
+	}
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := "overrides:\n  - filter.lowpass_cutoff=4000\n  - layers[2].base_freq=400\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(cfg.Overrides) != 2 || cfg.Overrides[1] != "layers[2].base_freq=400" {
+		t.Errorf("Overrides = %q, want the two overrides in order", cfg.Overrides)
+	}
+}
+
+func TestLoad_Seed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("preset: banshee\nseed: 1234567890123\n"), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.Seed != 1234567890123 {
+		t.Errorf("Seed = %d, want %d", cfg.Seed, int64(1234567890123))
+	}
+}
+
+func TestApplyEnv_Seed(t *testing.T) {
+	t.Setenv("SCREAM_SEED", "-99")
+
+	cfg := Config{}
+	ApplyEnv(&cfg)
+
+	if cfg.Seed != -99 {
+		t.Errorf("Seed = %d, want %d", cfg.Seed, -99)
+	}
+}
+
+func TestApplyEnv_InvalidSeedSilentlyIgnored(t *testing.T) {
+	cfg := Config{Seed: 5}
+	t.Setenv("SCREAM_SEED", "not-a-number")
+
+	ApplyEnv(&cfg)
+
+	if cfg.Seed != 5 {
+		t.Errorf("Seed = %d, want %d (invalid value should be silently ignored)", cfg.Seed, 5)
+	}
+}
+
+func TestLoad_Morph(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("preset: death-metal\nmorph_to: whisper\nmix: 0.3\n"), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.MorphTo != "whisper" || cfg.Mix != 0.3 {
+		t.Errorf("MorphTo, Mix = %q, %v; want %q, %v", cfg.MorphTo, cfg.Mix, "whisper", 0.3)
+	}
+}
+
+func TestLoad_Text(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("text: AAAAHHhh\n"), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.Text != "AAAAHHhh" {
+		t.Errorf("Text = %q, want %q", cfg.Text, "AAAAHHhh")
+	}
+}
+
+func TestApplyEnv_Morph(t *testing.T) {
+	t.Setenv("SCREAM_MORPH_TO", "banshee")
+	t.Setenv("SCREAM_MIX", "0.75")
+
+	cfg := Config{}
+	ApplyEnv(&cfg)
+
+	if cfg.MorphTo != "banshee" || cfg.Mix != 0.75 {
+		t.Errorf("MorphTo, Mix = %q, %v; want %q, %v", cfg.MorphTo, cfg.Mix, "banshee", 0.75)
+	}
+}
+
+func TestApplyEnv_InvalidMixSilentlyIgnored(t *testing.T) {
+	cfg := Config{Mix: 0.5}
+	t.Setenv("SCREAM_MIX", "half")
+
+	ApplyEnv(&cfg)
+
+	if cfg.Mix != 0.5 {
+		t.Errorf("Mix = %v, want %v (invalid value should be silently ignored)", cfg.Mix, 0.5)
+	}
+}
+
+func TestLoad_Loudness(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("target_lufs: -16\ntrue_peak: -1.5\n"), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.TargetLUFS != -16 || cfg.TruePeak != -1.5 {
+		t.Errorf("TargetLUFS, TruePeak = %v, %v; want %v, %v", cfg.TargetLUFS, cfg.TruePeak, -16.0, -1.5)
+	}
+}
+
+func TestApplyEnv_Loudness(t *testing.T) {
+	t.Setenv("SCREAM_TARGET_LUFS", "-14")
+	t.Setenv("SCREAM_TRUE_PEAK", "-2")
+
+	cfg := Config{}
+	ApplyEnv(&cfg)
+
+	if cfg.TargetLUFS != -14 || cfg.TruePeak != -2 {
+		t.Errorf("TargetLUFS, TruePeak = %v, %v; want %v, %v", cfg.TargetLUFS, cfg.TruePeak, -14.0, -2.0)
+	}
+}
+
+func TestLoad_TruePeakZeroSurvivesMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    float64
+	}{
+		{"zero ceiling", "true_peak: 0\n", 0},
+		{"unset keeps default", "target_lufs: -16\n", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test config: %v", err)
+			}
+
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+			if got := Merge(Default(), loaded).TruePeak; got != tt.want {
+				t.Errorf("Merge(Default(), Load()).TruePeak = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnv_TruePeakZero(t *testing.T) {
+	t.Setenv("SCREAM_TRUE_PEAK", "0")
+
+	cfg := Default()
+	ApplyEnv(&cfg)
+
+	if cfg.TruePeak != 0 {
+		t.Errorf("TruePeak = %v, want 0", cfg.TruePeak)
+	}
+}
+
+func TestApplyEnv_InvalidTargetLUFSSilentlyIgnored(t *testing.T) {
+	cfg := Config{TargetLUFS: -23}
+	t.Setenv("SCREAM_TARGET_LUFS", "loud")
+
+	ApplyEnv(&cfg)
+
+	if cfg.TargetLUFS != -23 {
+		t.Errorf("TargetLUFS = %v, want %v (invalid value should be silently ignored)", cfg.TargetLUFS, -23.0)
+	}
+}
+
+func TestLoad_Crowd(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("voices: 6\nspread: 0.8\nvoice_presets: [whisper, banshee]\n"), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.Voices != 6 || cfg.Spread != 0.8 || len(cfg.VoicePresets) != 2 || cfg.VoicePresets[0] != "whisper" || cfg.VoicePresets[1] != "banshee" {
+		t.Errorf("Voices, Spread, VoicePresets = %v, %v, %v; want 6, 0.8, [whisper banshee]", cfg.Voices, cfg.Spread, cfg.VoicePresets)
+	}
+}
+
+func TestApplyEnv_Crowd(t *testing.T) {
+	t.Setenv("SCREAM_VOICES", "5")
+	t.Setenv("SCREAM_SPREAD", "0.3")
+	t.Setenv("SCREAM_VOICE_PRESETS", "whisper, robot")
+
+	cfg := Config{}
+	ApplyEnv(&cfg)
+
+	if cfg.Voices != 5 || cfg.Spread != 0.3 || len(cfg.VoicePresets) != 2 || cfg.VoicePresets[0] != "whisper" || cfg.VoicePresets[1] != "robot" {
+		t.Errorf("Voices, Spread, VoicePresets = %v, %v, %v; want 5, 0.3, [whisper robot]", cfg.Voices, cfg.Spread, cfg.VoicePresets)
+	}
+}
+
+func TestApplyEnv_InvalidVoicesSilentlyIgnored(t *testing.T) {
+	cfg := Config{Voices: 4}
+	t.Setenv("SCREAM_VOICES", "many")
+
+	ApplyEnv(&cfg)
+
+	if cfg.Voices != 4 {
+		t.Errorf("Voices = %v, want %v (invalid value should be silently ignored)", cfg.Voices, 4)
+	}
+}
@@ -1,37 +1,68 @@
 package config
 
-import "strings"
+import (
+	"fmt"
+	"strings"
 
-// knownPresets lists every valid preset name accepted by Validate.
-// This list must be kept in sync with the preset constants defined in
-// internal/audio/presets.go (audio.AllPresets).
-var knownPresets = []string{
-	"classic",
-	"whisper",
-	"death-metal",
-	"glitch",
-	"banshee",
-	"robot",
-}
+	"github.com/JamesPrial/go-scream/internal/audio"
+	"github.com/JamesPrial/go-scream/internal/discord"
+	"github.com/JamesPrial/go-scream/internal/preset"
+)
 
 // Validate checks that cfg contains valid values for all fields.
 // It returns the first validation error encountered, or nil if cfg is valid.
 //
 // Rules:
 //   - Backend must be BackendNative or BackendFFmpeg
-//   - Preset, if non-empty, must be one of the known preset names
+//   - User presets from PresetsDir and Presets must load and be valid
+//   - Preset, if non-empty, must name a built-in or user preset
+//   - MorphTo, if non-empty, must name a built-in or user preset
+//   - Mix must be >= 0.0 and <= 1.0, and non-zero only with MorphTo
+//   - VoicePresets must each name a built-in or user preset
+//   - Overrides must be "path=value" with a path naming a scream parameter
+//   - Text, if non-empty, must be accepted by audio.FromText
 //   - Duration must be > 0
 //   - Volume must be >= 0.0 and <= 1.0
+//   - TargetLUFS must be 0 (no normalization) or between -70 and -5 LUFS
+//   - TruePeak must be between -20 and 0 dBTP
+//   - Voices must be between 0 and 16
+//   - Spread must be >= 0.0 and <= 1.0
 //   - Format must be FormatOGG or FormatWAV
 //   - LogLevel, if non-empty, must be one of: debug, info, warn, error
+//   - ChannelStrategy, if non-empty, must be one of: first, most, user,
+//     preferred; "user" requires ChannelUserID and "preferred" requires
+//     PreferredChannels
 func Validate(cfg Config) error {
 	if cfg.Backend != BackendNative && cfg.Backend != BackendFFmpeg {
 		return ErrInvalidBackend
 	}
 
-	if cfg.Preset != "" {
-		if !isValidPreset(cfg.Preset) {
-			return ErrInvalidPreset
+	presets, err := LoadPresets(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.Preset != "" && !presets.Has(cfg.Preset) {
+		return ErrInvalidPreset
+	}
+	if cfg.MorphTo != "" && !presets.Has(cfg.MorphTo) {
+		return ErrInvalidMorphTo
+	}
+	if cfg.Mix < 0.0 || cfg.Mix > 1.0 || (cfg.Mix != 0 && cfg.MorphTo == "") {
+		return ErrInvalidMix
+	}
+	for _, name := range cfg.VoicePresets {
+		if !presets.Has(name) {
+			return ErrInvalidVoicePreset
+		}
+	}
+
+	if _, err := preset.ParseOverrides(cfg.Overrides); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+	}
+
+	if cfg.Text != "" {
+		if _, err := audio.FromText(cfg.Text, 1); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidText, err)
 		}
 	}
 
@@ -43,6 +74,20 @@ func Validate(cfg Config) error {
 		return ErrInvalidVolume
 	}
 
+	if cfg.TargetLUFS != 0 && (cfg.TargetLUFS < audio.MinTargetLUFS || cfg.TargetLUFS > audio.MaxTargetLUFS) {
+		return ErrInvalidTargetLUFS
+	}
+	if cfg.TruePeak < audio.MinTruePeak || cfg.TruePeak > audio.MaxTruePeak {
+		return ErrInvalidTruePeak
+	}
+
+	if cfg.Voices < 0 || cfg.Voices > audio.MaxVoices {
+		return ErrInvalidVoices
+	}
+	if cfg.Spread < 0.0 || cfg.Spread > 1.0 {
+		return ErrInvalidSpread
+	}
+
 	if cfg.Format != FormatOGG && cfg.Format != FormatWAV {
 		return ErrInvalidFormat
 	}
@@ -56,15 +101,20 @@ func Validate(cfg Config) error {
 		}
 	}
 
-	return nil
-}
-
-// isValidPreset reports whether name matches one of the known preset names.
-func isValidPreset(name string) bool {
-	for _, p := range knownPresets {
-		if p == name {
-			return true
+	switch cfg.ChannelStrategy {
+	case "", discord.StrategyFirstPopulated, discord.StrategyMostPopulated:
+		// valid
+	case discord.StrategyUser:
+		if cfg.ChannelUserID == "" {
+			return ErrMissingChannelUser
+		}
+	case discord.StrategyPreferred:
+		if len(cfg.PreferredChannels) == 0 {
+			return ErrMissingPreferredChannels
 		}
+	default:
+		return ErrInvalidChannelStrategy
 	}
-	return false
+
+	return nil
 }
@@ -2,8 +2,14 @@ package config
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+	"github.com/JamesPrial/go-scream/internal/discord"
 )
 
 // ---------------------------------------------------------------------------
@@ -397,6 +403,45 @@ func TestValidate_LogLevel(t *testing.T) {
 	}
 }
 
+func TestValidate_ChannelStrategy(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  discord.ChannelStrategy
+		userID    string
+		preferred []string
+		wantErr   error
+	}{
+		{name: "empty is valid", strategy: "", wantErr: nil},
+		{name: "first is valid", strategy: discord.StrategyFirstPopulated, wantErr: nil},
+		{name: "most is valid", strategy: discord.StrategyMostPopulated, wantErr: nil},
+		{name: "user with ID is valid", strategy: discord.StrategyUser, userID: "u1", wantErr: nil},
+		{name: "user without ID is invalid", strategy: discord.StrategyUser, wantErr: ErrMissingChannelUser},
+		{name: "preferred with list is valid", strategy: discord.StrategyPreferred, preferred: []string{"c1"}, wantErr: nil},
+		{name: "preferred without list is invalid", strategy: discord.StrategyPreferred, wantErr: ErrMissingPreferredChannels},
+		{name: "unknown strategy is invalid", strategy: "random", wantErr: ErrInvalidChannelStrategy},
+		{name: "case sensitive: First is invalid", strategy: "First", wantErr: ErrInvalidChannelStrategy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.ChannelStrategy = tt.strategy
+			cfg.ChannelUserID = tt.userID
+			cfg.PreferredChannels = tt.preferred
+			err := Validate(cfg)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+			} else {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
 func TestValidate_MultipleInvalidFields(t *testing.T) {
 	// When multiple fields are invalid, Validate should return an error.
 	// We do not prescribe which error it returns first; we just confirm
@@ -436,10 +481,21 @@ func TestValidate_SentinelErrorsExist(t *testing.T) {
 		{"ErrConfigParse", ErrConfigParse},
 		{"ErrInvalidBackend", ErrInvalidBackend},
 		{"ErrInvalidPreset", ErrInvalidPreset},
+		{"ErrInvalidMorphTo", ErrInvalidMorphTo},
+		{"ErrInvalidMix", ErrInvalidMix},
+		{"ErrInvalidText", ErrInvalidText},
 		{"ErrInvalidDuration", ErrInvalidDuration},
 		{"ErrInvalidVolume", ErrInvalidVolume},
+		{"ErrInvalidTargetLUFS", ErrInvalidTargetLUFS},
+		{"ErrInvalidTruePeak", ErrInvalidTruePeak},
+		{"ErrInvalidVoices", ErrInvalidVoices},
+		{"ErrInvalidSpread", ErrInvalidSpread},
+		{"ErrInvalidVoicePreset", ErrInvalidVoicePreset},
 		{"ErrInvalidFormat", ErrInvalidFormat},
 		{"ErrInvalidLogLevel", ErrInvalidLogLevel},
+		{"ErrInvalidChannelStrategy", ErrInvalidChannelStrategy},
+		{"ErrMissingChannelUser", ErrMissingChannelUser},
+		{"ErrMissingPreferredChannels", ErrMissingPreferredChannels},
 	}
 
 	for _, s := range sentinels {
@@ -487,6 +543,70 @@ func TestValidate_LogLevel_ErrorWrapping(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Validate() — user presets
+// ---------------------------------------------------------------------------
+
+func TestValidate_UserPreset(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "howler.yaml"), []byte(userPresetYAML), 0644); err != nil {
+		t.Fatalf("failed to write preset file: %v", err)
+	}
+
+	cfg := Default()
+	cfg.Preset = "howler"
+	if err := Validate(cfg); !errors.Is(err, ErrInvalidPreset) {
+		t.Errorf("Validate() without PresetsDir error = %v, want ErrInvalidPreset", err)
+	}
+
+	cfg.PresetsDir = dir
+	if err := Validate(cfg); err != nil {
+		t.Errorf("Validate() with PresetsDir unexpected error: %v", err)
+	}
+}
+
+func TestValidate_InvalidUserPreset(t *testing.T) {
+	dir := t.TempDir()
+	content := "broken:\n  duration: 2s\n  filter:\n    crusher_bits: 40\n"
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write preset file: %v", err)
+	}
+
+	cfg := Default()
+	cfg.PresetsDir = dir
+	err := Validate(cfg)
+	if !errors.Is(err, ErrPresetsLoad) {
+		t.Errorf("Validate() error = %v, want ErrPresetsLoad", err)
+	}
+	if !errors.Is(err, audio.ErrInvalidCrusherBits) {
+		t.Errorf("Validate() error = %v, want it to wrap audio.ErrInvalidCrusherBits", err)
+	}
+}
+
+func TestValidate_Overrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []string
+		wantErr   error
+	}{
+		{"none", nil, nil},
+		{"valid", []string{"filter.lowpass_cutoff=4000", "layers[2].base_freq=400"}, nil},
+		{"missing value", []string{"duration"}, ErrInvalidOverride},
+		{"unknown field", []string{"filter.cutoff=1"}, ErrInvalidOverride},
+		{"malformed index", []string{"layers[x].amplitude=1"}, ErrInvalidOverride},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Overrides = tt.overrides
+			if err := Validate(cfg); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Benchmarks
 // ---------------------------------------------------------------------------
@@ -517,3 +637,136 @@ func BenchmarkMerge(b *testing.B) {
 		_ = Merge(base, overlay)
 	}
 }
+
+func TestValidate_Morph(t *testing.T) {
+	tests := []struct {
+		name    string
+		morphTo string
+		mix     float64
+		wantErr error
+	}{
+		{name: "no morph is valid"},
+		{name: "morph over time is valid", morphTo: "whisper"},
+		{name: "blend is valid", morphTo: "whisper", mix: 0.3},
+		{name: "full blend is valid", morphTo: "whisper", mix: 1},
+		{name: "unknown morph_to preset", morphTo: "kazoo", wantErr: ErrInvalidMorphTo},
+		{name: "mix above 1.0", morphTo: "whisper", mix: 1.5, wantErr: ErrInvalidMix},
+		{name: "negative mix", morphTo: "whisper", mix: -0.1, wantErr: ErrInvalidMix},
+		{name: "mix without morph_to", mix: 0.5, wantErr: ErrInvalidMix},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.MorphTo = tt.morphTo
+			cfg.Mix = tt.mix
+			err := Validate(cfg)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Text(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr error
+	}{
+		{name: "no text is valid"},
+		{name: "shout is valid", text: "AAAAAHHHhhh"},
+		{name: "words are valid", text: "no way!"},
+		{name: "no letters", text: "!!! ...", wantErr: ErrInvalidText},
+		{name: "too many letters", text: strings.Repeat("A", 101), wantErr: ErrInvalidText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Text = tt.text
+			err := Validate(cfg)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Loudness(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   float64
+		truePeak float64
+		wantErr  error
+	}{
+		{name: "no normalization is valid", truePeak: -1},
+		{name: "EBU R128 target is valid", target: -23, truePeak: -1},
+		{name: "loudest target is valid", target: -5, truePeak: 0},
+		{name: "quietest target is valid", target: -70, truePeak: -20},
+		{name: "target too loud", target: -4, truePeak: -1, wantErr: ErrInvalidTargetLUFS},
+		{name: "target too quiet", target: -71, truePeak: -1, wantErr: ErrInvalidTargetLUFS},
+		{name: "true peak above 0 dBTP", target: -16, truePeak: 0.5, wantErr: ErrInvalidTruePeak},
+		{name: "true peak too low", target: -16, truePeak: -21, wantErr: ErrInvalidTruePeak},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.TargetLUFS = tt.target
+			cfg.TruePeak = tt.truePeak
+			err := Validate(cfg)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Crowd(t *testing.T) {
+	tests := []struct {
+		name    string
+		voices  int
+		spread  float64
+		presets []string
+		wantErr error
+	}{
+		{name: "single voice is valid"},
+		{name: "largest crowd is valid", voices: 16, spread: 1},
+		{name: "voice presets are valid", voices: 4, presets: []string{"whisper", "banshee"}},
+		{name: "negative voices", voices: -1, wantErr: ErrInvalidVoices},
+		{name: "too many voices", voices: 17, wantErr: ErrInvalidVoices},
+		{name: "negative spread", voices: 4, spread: -0.1, wantErr: ErrInvalidSpread},
+		{name: "spread above 1", voices: 4, spread: 1.5, wantErr: ErrInvalidSpread},
+		{name: "unknown voice preset", voices: 4, presets: []string{"kazoo"}, wantErr: ErrInvalidVoicePreset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Voices = tt.voices
+			cfg.Spread = tt.spread
+			cfg.VoicePresets = tt.presets
+			err := Validate(cfg)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,6 +5,109 @@ import (
 	"log/slog"
 )
 
+// ChannelStrategy identifies how a voice channel is chosen when the caller
+// does not name one explicitly.
+type ChannelStrategy string
+
+const (
+	// StrategyFirstPopulated picks the first voice channel containing at
+	// least one non-bot user. It is the default strategy.
+	StrategyFirstPopulated ChannelStrategy = "first"
+
+	// StrategyMostPopulated picks the voice channel with the most non-bot
+	// users. Ties are broken by the order in which channels are first seen.
+	StrategyMostPopulated ChannelStrategy = "most"
+
+	// StrategyUser picks the voice channel the given user is connected to.
+	StrategyUser ChannelStrategy = "user"
+
+	// StrategyPreferred picks the first channel from a preferred list that
+	// contains at least one non-bot user.
+	StrategyPreferred ChannelStrategy = "preferred"
+)
+
+// ChannelQuery describes how ResolveChannel should choose a voice channel.
+type ChannelQuery struct {
+	// Strategy selects the resolution method. An empty strategy is treated
+	// as StrategyFirstPopulated.
+	Strategy ChannelStrategy
+
+	// UserID is the user to follow when Strategy is StrategyUser.
+	UserID string
+
+	// Preferred lists channel IDs in priority order for StrategyPreferred.
+	Preferred []string
+}
+
+// ChannelSelection reports the channel chosen by a ChannelResolver and the
+// strategy that picked it.
+type ChannelSelection struct {
+	ChannelID string
+	Strategy  ChannelStrategy
+}
+
+// ChannelResolver picks a voice channel in a guild for playback.
+type ChannelResolver interface {
+	ResolveChannel(guildID string, q ChannelQuery) (ChannelSelection, error)
+}
+
+// ChannelFinder implements ChannelResolver using the voice states exposed by
+// a Session. The bot's own user ID is taken from the session so that the
+// bot never counts as a listener.
+type ChannelFinder struct {
+	session Session
+	logger  *slog.Logger
+}
+
+// Compile-time interface check.
+var _ ChannelResolver = (*ChannelFinder)(nil)
+
+// NewChannelFinder returns a ChannelFinder using the provided Session and logger.
+func NewChannelFinder(session Session, logger *slog.Logger) *ChannelFinder {
+	return &ChannelFinder{session: session, logger: logger}
+}
+
+// ResolveChannel chooses a voice channel in guildID according to q. It
+// returns ErrEmptyGuildID if guildID is empty, ErrUnknownStrategy if
+// q.Strategy is not recognised, ErrGuildStateFailed if the guild state cannot
+// be retrieved, and a strategy-specific error (ErrNoPopulatedChannel,
+// ErrEmptyUserID, ErrUserNotInVoice) when no channel matches.
+func (f *ChannelFinder) ResolveChannel(guildID string, q ChannelQuery) (ChannelSelection, error) {
+	if guildID == "" {
+		return ChannelSelection{}, ErrEmptyGuildID
+	}
+
+	strategy := q.Strategy
+	if strategy == "" {
+		strategy = StrategyFirstPopulated
+	}
+
+	botUserID := f.session.BotUserID()
+	f.logger.Debug("resolving voice channel", "guild", guildID, "strategy", strategy, "bot_user", botUserID)
+
+	var (
+		channelID string
+		err       error
+	)
+	switch strategy {
+	case StrategyFirstPopulated:
+		channelID, err = FindPopulatedChannel(f.session, guildID, botUserID, f.logger)
+	case StrategyMostPopulated:
+		channelID, err = FindMostPopulatedChannel(f.session, guildID, botUserID, f.logger)
+	case StrategyUser:
+		channelID, err = FindUserChannel(f.session, guildID, q.UserID, f.logger)
+	case StrategyPreferred:
+		channelID, err = FindPreferredChannel(f.session, guildID, botUserID, q.Preferred, f.logger)
+	default:
+		return ChannelSelection{}, fmt.Errorf("%w: %q", ErrUnknownStrategy, strategy)
+	}
+	if err != nil {
+		return ChannelSelection{}, err
+	}
+
+	return ChannelSelection{ChannelID: channelID, Strategy: strategy}, nil
+}
+
 // FindPopulatedChannel returns the channel ID of the first voice channel
 // containing at least one non-bot user. It returns ErrEmptyGuildID if guildID
 // is empty, ErrGuildStateFailed if the guild state cannot be retrieved, and
@@ -31,3 +134,107 @@ func FindPopulatedChannel(session Session, guildID, botUserID string, logger *sl
 
 	return "", ErrNoPopulatedChannel
 }
+
+// FindMostPopulatedChannel returns the channel ID of the voice channel with
+// the most non-bot users. When several channels share the highest count, the
+// one seen first in the guild's voice states wins. Errors match
+// FindPopulatedChannel.
+func FindMostPopulatedChannel(session Session, guildID, botUserID string, logger *slog.Logger) (string, error) {
+	if guildID == "" {
+		return "", ErrEmptyGuildID
+	}
+
+	logger.Debug("searching for most populated voice channel", "guild", guildID)
+
+	voiceStates, err := session.GuildVoiceStates(guildID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrGuildStateFailed, err)
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	for _, vs := range voiceStates {
+		if vs.ChannelID == "" || vs.UserID == botUserID {
+			continue
+		}
+		if _, seen := counts[vs.ChannelID]; !seen {
+			order = append(order, vs.ChannelID)
+		}
+		counts[vs.ChannelID]++
+	}
+
+	best, bestCount := "", 0
+	for _, id := range order {
+		if counts[id] > bestCount {
+			best, bestCount = id, counts[id]
+		}
+	}
+	if best == "" {
+		return "", ErrNoPopulatedChannel
+	}
+
+	logger.Debug("found most populated channel", "channel", best, "users", bestCount)
+	return best, nil
+}
+
+// FindUserChannel returns the channel ID of the voice channel userID is
+// connected to. It returns ErrEmptyUserID if userID is empty and
+// ErrUserNotInVoice if the user has no voice state in the guild. Other
+// errors match FindPopulatedChannel.
+func FindUserChannel(session Session, guildID, userID string, logger *slog.Logger) (string, error) {
+	if guildID == "" {
+		return "", ErrEmptyGuildID
+	}
+	if userID == "" {
+		return "", ErrEmptyUserID
+	}
+
+	logger.Debug("searching for user voice channel", "guild", guildID, "user", userID)
+
+	voiceStates, err := session.GuildVoiceStates(guildID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrGuildStateFailed, err)
+	}
+
+	for _, vs := range voiceStates {
+		if vs.UserID == userID && vs.ChannelID != "" {
+			logger.Debug("found user channel", "channel", vs.ChannelID, "user", userID)
+			return vs.ChannelID, nil
+		}
+	}
+
+	return "", ErrUserNotInVoice
+}
+
+// FindPreferredChannel returns the first channel in preferred that contains
+// at least one non-bot user. Channels are tried in the order given. It
+// returns ErrNoPopulatedChannel if preferred is empty or none of its channels
+// are populated. Other errors match FindPopulatedChannel.
+func FindPreferredChannel(session Session, guildID, botUserID string, preferred []string, logger *slog.Logger) (string, error) {
+	if guildID == "" {
+		return "", ErrEmptyGuildID
+	}
+
+	logger.Debug("searching preferred voice channels", "guild", guildID, "preferred", preferred)
+
+	voiceStates, err := session.GuildVoiceStates(guildID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrGuildStateFailed, err)
+	}
+
+	populated := make(map[string]bool)
+	for _, vs := range voiceStates {
+		if vs.ChannelID != "" && vs.UserID != botUserID {
+			populated[vs.ChannelID] = true
+		}
+	}
+
+	for _, id := range preferred {
+		if populated[id] {
+			logger.Debug("found preferred channel", "channel", id)
+			return id, nil
+		}
+	}
+
+	return "", ErrNoPopulatedChannel
+}
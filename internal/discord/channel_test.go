@@ -219,6 +219,285 @@ func TestFindPopulatedChannel_Cases(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// FindMostPopulatedChannel tests
+// ---------------------------------------------------------------------------
+
+func TestFindMostPopulatedChannel_PicksLargest(t *testing.T) {
+	sess := &mockSession{
+		voiceStates: []*VoiceState{
+			{UserID: "u1", ChannelID: "c1", GuildID: "g1"},
+			{UserID: "u2", ChannelID: "c2", GuildID: "g1"},
+			{UserID: "u3", ChannelID: "c2", GuildID: "g1"},
+		},
+	}
+
+	got, err := FindMostPopulatedChannel(sess, "g1", "bot", discardLogger)
+	if err != nil {
+		t.Fatalf("FindMostPopulatedChannel() unexpected error: %v", err)
+	}
+	if got != "c2" {
+		t.Errorf("FindMostPopulatedChannel() = %q, want %q", got, "c2")
+	}
+}
+
+func TestFindMostPopulatedChannel_IgnoresBot(t *testing.T) {
+	sess := &mockSession{
+		voiceStates: []*VoiceState{
+			{UserID: "bot", ChannelID: "c1", GuildID: "g1"},
+			{UserID: "u1", ChannelID: "c1", GuildID: "g1"},
+			{UserID: "u2", ChannelID: "c2", GuildID: "g1"},
+			{UserID: "u3", ChannelID: "c2", GuildID: "g1"},
+		},
+	}
+
+	got, err := FindMostPopulatedChannel(sess, "g1", "bot", discardLogger)
+	if err != nil {
+		t.Fatalf("FindMostPopulatedChannel() unexpected error: %v", err)
+	}
+	if got != "c2" {
+		t.Errorf("FindMostPopulatedChannel() = %q, want %q (bot must not count)", got, "c2")
+	}
+}
+
+func TestFindMostPopulatedChannel_TieKeepsFirstSeen(t *testing.T) {
+	sess := &mockSession{
+		voiceStates: []*VoiceState{
+			{UserID: "u1", ChannelID: "c3", GuildID: "g1"},
+			{UserID: "u2", ChannelID: "c1", GuildID: "g1"},
+		},
+	}
+
+	got, err := FindMostPopulatedChannel(sess, "g1", "bot", discardLogger)
+	if err != nil {
+		t.Fatalf("FindMostPopulatedChannel() unexpected error: %v", err)
+	}
+	if got != "c3" {
+		t.Errorf("FindMostPopulatedChannel() = %q, want %q (first seen on tie)", got, "c3")
+	}
+}
+
+func TestFindMostPopulatedChannel_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		guildID string
+		states  []*VoiceState
+		err     error
+		wantErr error
+	}{
+		{name: "empty guild", guildID: "", wantErr: ErrEmptyGuildID},
+		{name: "only bot", guildID: "g1", states: []*VoiceState{{UserID: "bot", ChannelID: "c1"}}, wantErr: ErrNoPopulatedChannel},
+		{name: "state error", guildID: "g1", err: errors.New("boom"), wantErr: ErrGuildStateFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := &mockSession{voiceStates: tt.states, stateErr: tt.err}
+			_, err := FindMostPopulatedChannel(sess, tt.guildID, "bot", discardLogger)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FindMostPopulatedChannel() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// FindUserChannel tests
+// ---------------------------------------------------------------------------
+
+func TestFindUserChannel_FindsUser(t *testing.T) {
+	sess := &mockSession{
+		voiceStates: []*VoiceState{
+			{UserID: "u1", ChannelID: "c1", GuildID: "g1"},
+			{UserID: "u2", ChannelID: "c2", GuildID: "g1"},
+		},
+	}
+
+	got, err := FindUserChannel(sess, "g1", "u2", discardLogger)
+	if err != nil {
+		t.Fatalf("FindUserChannel() unexpected error: %v", err)
+	}
+	if got != "c2" {
+		t.Errorf("FindUserChannel() = %q, want %q", got, "c2")
+	}
+}
+
+func TestFindUserChannel_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		guildID string
+		userID  string
+		states  []*VoiceState
+		err     error
+		wantErr error
+	}{
+		{name: "empty guild", guildID: "", userID: "u1", wantErr: ErrEmptyGuildID},
+		{name: "empty user", guildID: "g1", userID: "", wantErr: ErrEmptyUserID},
+		{name: "user absent", guildID: "g1", userID: "u9", states: []*VoiceState{{UserID: "u1", ChannelID: "c1"}}, wantErr: ErrUserNotInVoice},
+		{name: "user without channel", guildID: "g1", userID: "u1", states: []*VoiceState{{UserID: "u1", ChannelID: ""}}, wantErr: ErrUserNotInVoice},
+		{name: "state error", guildID: "g1", userID: "u1", err: errors.New("boom"), wantErr: ErrGuildStateFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := &mockSession{voiceStates: tt.states, stateErr: tt.err}
+			_, err := FindUserChannel(sess, tt.guildID, tt.userID, discardLogger)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FindUserChannel() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// FindPreferredChannel tests
+// ---------------------------------------------------------------------------
+
+func TestFindPreferredChannel_RespectsOrder(t *testing.T) {
+	sess := &mockSession{
+		voiceStates: []*VoiceState{
+			{UserID: "u1", ChannelID: "c1", GuildID: "g1"},
+			{UserID: "u2", ChannelID: "c2", GuildID: "g1"},
+		},
+	}
+
+	got, err := FindPreferredChannel(sess, "g1", "bot", []string{"c9", "c2", "c1"}, discardLogger)
+	if err != nil {
+		t.Fatalf("FindPreferredChannel() unexpected error: %v", err)
+	}
+	if got != "c2" {
+		t.Errorf("FindPreferredChannel() = %q, want %q", got, "c2")
+	}
+}
+
+func TestFindPreferredChannel_SkipsBotOnlyChannel(t *testing.T) {
+	sess := &mockSession{
+		voiceStates: []*VoiceState{
+			{UserID: "bot", ChannelID: "c1", GuildID: "g1"},
+			{UserID: "u1", ChannelID: "c2", GuildID: "g1"},
+		},
+	}
+
+	got, err := FindPreferredChannel(sess, "g1", "bot", []string{"c1", "c2"}, discardLogger)
+	if err != nil {
+		t.Fatalf("FindPreferredChannel() unexpected error: %v", err)
+	}
+	if got != "c2" {
+		t.Errorf("FindPreferredChannel() = %q, want %q", got, "c2")
+	}
+}
+
+func TestFindPreferredChannel_NonePopulated(t *testing.T) {
+	sess := &mockSession{
+		voiceStates: []*VoiceState{
+			{UserID: "u1", ChannelID: "c1", GuildID: "g1"},
+		},
+	}
+
+	for _, preferred := range [][]string{nil, {"c2", "c3"}} {
+		_, err := FindPreferredChannel(sess, "g1", "bot", preferred, discardLogger)
+		if !errors.Is(err, ErrNoPopulatedChannel) {
+			t.Errorf("FindPreferredChannel(%v) error = %v, want ErrNoPopulatedChannel", preferred, err)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ChannelFinder tests
+// ---------------------------------------------------------------------------
+
+var _ ChannelResolver = (*ChannelFinder)(nil)
+
+func TestChannelFinder_ResolveChannel(t *testing.T) {
+	states := []*VoiceState{
+		{UserID: "bot", ChannelID: "c1", GuildID: "g1"},
+		{UserID: "u1", ChannelID: "c1", GuildID: "g1"},
+		{UserID: "u2", ChannelID: "c2", GuildID: "g1"},
+		{UserID: "u3", ChannelID: "c2", GuildID: "g1"},
+		{UserID: "u4", ChannelID: "c3", GuildID: "g1"},
+	}
+
+	tests := []struct {
+		name         string
+		query        ChannelQuery
+		wantChannel  string
+		wantStrategy ChannelStrategy
+	}{
+		{name: "default is first", query: ChannelQuery{}, wantChannel: "c1", wantStrategy: StrategyFirstPopulated},
+		{name: "first", query: ChannelQuery{Strategy: StrategyFirstPopulated}, wantChannel: "c1", wantStrategy: StrategyFirstPopulated},
+		{name: "most", query: ChannelQuery{Strategy: StrategyMostPopulated}, wantChannel: "c2", wantStrategy: StrategyMostPopulated},
+		{name: "user", query: ChannelQuery{Strategy: StrategyUser, UserID: "u4"}, wantChannel: "c3", wantStrategy: StrategyUser},
+		{name: "preferred", query: ChannelQuery{Strategy: StrategyPreferred, Preferred: []string{"c3", "c1"}}, wantChannel: "c3", wantStrategy: StrategyPreferred},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := &mockSession{voiceStates: states, botUserID: "bot"}
+			finder := NewChannelFinder(sess, discardLogger)
+
+			got, err := finder.ResolveChannel("g1", tt.query)
+			if err != nil {
+				t.Fatalf("ResolveChannel() unexpected error: %v", err)
+			}
+			if got.ChannelID != tt.wantChannel {
+				t.Errorf("ResolveChannel().ChannelID = %q, want %q", got.ChannelID, tt.wantChannel)
+			}
+			if got.Strategy != tt.wantStrategy {
+				t.Errorf("ResolveChannel().Strategy = %q, want %q", got.Strategy, tt.wantStrategy)
+			}
+		})
+	}
+}
+
+func TestChannelFinder_UsesSessionBotUserID(t *testing.T) {
+	sess := &mockSession{
+		voiceStates: []*VoiceState{
+			{UserID: "self", ChannelID: "c1", GuildID: "g1"},
+			{UserID: "u1", ChannelID: "c2", GuildID: "g1"},
+		},
+		botUserID: "self",
+	}
+	finder := NewChannelFinder(sess, discardLogger)
+
+	got, err := finder.ResolveChannel("g1", ChannelQuery{})
+	if err != nil {
+		t.Fatalf("ResolveChannel() unexpected error: %v", err)
+	}
+	if got.ChannelID != "c2" {
+		t.Errorf("ResolveChannel().ChannelID = %q, want %q (bot's own channel skipped)", got.ChannelID, "c2")
+	}
+}
+
+func TestChannelFinder_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		guildID string
+		query   ChannelQuery
+		wantErr error
+	}{
+		{name: "empty guild", guildID: "", wantErr: ErrEmptyGuildID},
+		{name: "unknown strategy", guildID: "g1", query: ChannelQuery{Strategy: "loudest"}, wantErr: ErrUnknownStrategy},
+		{name: "user missing ID", guildID: "g1", query: ChannelQuery{Strategy: StrategyUser}, wantErr: ErrEmptyUserID},
+		{name: "user not in voice", guildID: "g1", query: ChannelQuery{Strategy: StrategyUser, UserID: "u9"}, wantErr: ErrUserNotInVoice},
+		{name: "preferred empty", guildID: "g1", query: ChannelQuery{Strategy: StrategyPreferred}, wantErr: ErrNoPopulatedChannel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := &mockSession{
+				voiceStates: []*VoiceState{{UserID: "u1", ChannelID: "c1", GuildID: "g1"}},
+				botUserID:   "bot",
+			}
+			finder := NewChannelFinder(sess, discardLogger)
+
+			_, err := finder.ResolveChannel(tt.guildID, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ResolveChannel() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Benchmarks
 // ---------------------------------------------------------------------------
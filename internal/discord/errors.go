@@ -13,4 +13,7 @@ var (
 	ErrEmptyChannelID     = errors.New("discord: channel ID must not be empty")
 	ErrNilFrameChannel    = errors.New("discord: frame channel must not be nil")
 	ErrEncryptionFailed   = errors.New("discord: voice encryption failed")
+	ErrUnknownStrategy    = errors.New("discord: unknown channel strategy")
+	ErrEmptyUserID        = errors.New("discord: user ID must not be empty")
+	ErrUserNotInVoice     = errors.New("discord: user is not in a voice channel")
 )
@@ -99,6 +99,7 @@ type mockSession struct {
 	joinCalls   []joinCall
 	voiceStates []*VoiceState
 	stateErr    error
+	botUserID   string
 }
 
 type joinCall struct {
@@ -127,6 +128,12 @@ func (m *mockSession) GuildVoiceStates(guildID string) ([]*VoiceState, error) {
 	return m.voiceStates, nil
 }
 
+func (m *mockSession) BotUserID() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.botUserID
+}
+
 // ---------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------
@@ -11,6 +11,7 @@ import (
 type Session interface {
 	ChannelVoiceJoin(guildID, channelID string, mute, deaf bool) (VoiceConn, error)
 	GuildVoiceStates(guildID string) ([]*VoiceState, error)
+	BotUserID() string
 }
 
 // VoiceConn abstracts the subset of *discordgo.VoiceConnection methods.
@@ -63,6 +64,16 @@ func (d *GoSession) GuildVoiceStates(guildID string) ([]*VoiceState, error) {
 	return states, nil
 }
 
+// BotUserID returns the user ID of the bot account the session is logged in
+// as. It returns an empty string until the gateway Ready event has populated
+// the session state.
+func (d *GoSession) BotUserID() string {
+	if d.S.State == nil || d.S.State.User == nil {
+		return ""
+	}
+	return d.S.State.User.ID
+}
+
 // GoVoiceConn wraps *discordgo.VoiceConnection to satisfy the VoiceConn interface.
 type GoVoiceConn struct {
 	VC     *discordgo.VoiceConnection
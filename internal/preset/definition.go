@@ -0,0 +1,113 @@
+package preset
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// Definition is a user preset as written in YAML: a mapping using the yaml
+// field names of audio.ScreamParams, for example
+//
+//	duration: 4s
+//	layers:
+//	  - base_freq: 300
+//	    amplitude: 0.4
+//	  ...
+//	filter:
+//	  lowpass_cutoff: 5000
+//
+// A definition may instead extend another preset and change only some of its
+// parameters. Keys may then be override paths as accepted by ParseOverride:
+//
+//	extends: banshee
+//	duration: 5s
+//	layers[2].base_freq: 400
+//	filter.crusher_bits: 6
+//
+// Nested mappings change only the fields they list. Lists are merged element
+// by element: a shorter list drops the elements after it and a longer one
+// adds elements that start from zero, so a layer added beyond the base
+// preset's layers is a primary scream unless it sets its type. Without
+// extends, the definition starts from the defaults: the default sample rate
+// and channel count, audio.DefaultFilterParams, audio.DefaultEnvelope, five
+// layers of the built-in layer types in their usual order, and zero for
+// everything else.
+type Definition struct {
+	node   yaml.Node
+	source string
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. It checks that the node is a
+// mapping and that extends, if present, is a preset name; the remaining
+// fields are checked when the definition is added to a Registry.
+func (d *Definition) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: preset definition must be a mapping", value.Line)
+	}
+	if ext := field(value, extendsKey); ext != nil && (ext.Kind != yaml.ScalarNode || ext.Value == "") {
+		return fmt.Errorf("line %d: %s must be a preset name", ext.Line, extendsKey)
+	}
+	d.node = *value
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler, writing the definition back out as
+// it was read.
+func (d Definition) MarshalYAML() (interface{}, error) {
+	return &d.node, nil
+}
+
+// Extends returns the name of the preset the definition extends, or "" if it
+// starts from the defaults.
+func (d Definition) Extends() string {
+	if ext := field(&d.node, extendsKey); ext != nil {
+		return ext.Value
+	}
+	return ""
+}
+
+// extendsKey is the definition key that names the preset being extended.
+const extendsKey = "extends"
+
+// resolve applies the definition's fields, in the order written, on top of
+// base.
+func (d Definition) resolve(base audio.ScreamParams) (audio.ScreamParams, error) {
+	root, err := encodeParams(base)
+	if err != nil {
+		return audio.ScreamParams{}, err
+	}
+	for i := 0; i+1 < len(d.node.Content); i += 2 {
+		key := d.node.Content[i].Value
+		if key == extendsKey {
+			continue
+		}
+		if err := set(root, key, d.node.Content[i+1]); err != nil {
+			return audio.ScreamParams{}, err
+		}
+	}
+	return decodeParams(root)
+}
+
+// defaultParams returns the parameters a definition without extends starts
+// from.
+func defaultParams() audio.ScreamParams {
+	params := audio.ScreamParams{
+		SampleRate: audio.DefaultSampleRate,
+		Channels:   audio.DefaultChannels,
+		Filter:     audio.DefaultFilterParams(),
+		Envelope:   audio.DefaultEnvelope(),
+	}
+	for _, t := range []audio.LayerType{
+		audio.LayerPrimaryScream,
+		audio.LayerHarmonicSweep,
+		audio.LayerHighShriek,
+		audio.LayerNoiseBurst,
+		audio.LayerBackgroundNoise,
+	} {
+		params.Layers = append(params.Layers, audio.LayerParams{Type: t})
+	}
+	return params
+}
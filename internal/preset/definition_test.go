@@ -0,0 +1,281 @@
+package preset
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+func TestDefinition_RejectsNonMapping(t *testing.T) {
+	var def Definition
+	if err := yaml.Unmarshal([]byte("- 1\n- 2\n"), &def); err == nil {
+		t.Error("yaml.Unmarshal of a sequence into Definition succeeded, want error")
+	}
+}
+
+func TestDefinition_Defaults(t *testing.T) {
+	p, err := mustDefinition(t, minimalPreset).resolve(defaultParams())
+	if err != nil {
+		t.Fatalf("resolve() unexpected error: %v", err)
+	}
+
+	if p.SampleRate != audio.DefaultSampleRate {
+		t.Errorf("SampleRate = %d, want %d", p.SampleRate, audio.DefaultSampleRate)
+	}
+	if p.Channels != audio.DefaultChannels {
+		t.Errorf("Channels = %d, want %d", p.Channels, audio.DefaultChannels)
+	}
+	want := audio.DefaultFilterParams()
+	want.CrusherBits = 8
+	if !reflect.DeepEqual(p.Filter, want) {
+		t.Errorf("Filter = %+v, want %+v", p.Filter, want)
+	}
+	if !reflect.DeepEqual(p.Envelope, audio.DefaultEnvelope()) {
+		t.Errorf("Envelope = %+v, want %+v", p.Envelope, audio.DefaultEnvelope())
+	}
+}
+
+func TestDefinition_Envelope(t *testing.T) {
+	src := minimalPreset + `envelope: {release: 0.5}
+layers[0].envelope:
+  points: [{time: 0, level: 0}, {time: 1, level: 1}]
+`
+	p, err := mustDefinition(t, src).resolve(defaultParams())
+	if err != nil {
+		t.Fatalf("resolve() unexpected error: %v", err)
+	}
+
+	want := audio.DefaultEnvelope()
+	want.Release = 0.5
+	if !reflect.DeepEqual(p.Envelope, want) {
+		t.Errorf("Envelope = %+v, want %+v", p.Envelope, want)
+	}
+	points := []audio.EnvelopePoint{{Time: 0, Level: 0}, {Time: 1, Level: 1}}
+	if !reflect.DeepEqual(p.Layers[0].Envelope.Points, points) {
+		t.Errorf("Layers[0].Envelope.Points = %v, want %v", p.Layers[0].Envelope.Points, points)
+	}
+	if p.Layers[1].Envelope.Points != nil {
+		t.Errorf("Layers[1].Envelope.Points = %v, want nil", p.Layers[1].Envelope.Points)
+	}
+}
+
+func TestDefinition_DefaultLayerTypes(t *testing.T) {
+	src := minimalPreset + `layers:
+  - amplitude: 0.4
+  - amplitude: 0.2
+  - type: primary_scream
+  - {}
+  - {}
+`
+	p, err := mustDefinition(t, src).resolve(defaultParams())
+	if err != nil {
+		t.Fatalf("resolve() unexpected error: %v", err)
+	}
+
+	want := [5]audio.LayerType{
+		audio.LayerPrimaryScream,
+		audio.LayerHarmonicSweep,
+		audio.LayerPrimaryScream,
+		audio.LayerNoiseBurst,
+		audio.LayerBackgroundNoise,
+	}
+	for i, l := range p.Layers {
+		if l.Type != want[i] {
+			t.Errorf("Layers[%d].Type = %v, want %v", i, l.Type, want[i])
+		}
+	}
+	if p.Layers[1].Amplitude != 0.2 {
+		t.Errorf("Layers[1].Amplitude = %v, want 0.2", p.Layers[1].Amplitude)
+	}
+}
+
+func TestDefinition_LayerCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		types []audio.LayerType
+	}{
+		{"fewer layers", minimalPreset + "layers:\n  - {amplitude: 0.4}\n  - {type: high_shriek}\n",
+			[]audio.LayerType{audio.LayerPrimaryScream, audio.LayerHighShriek}},
+		{"extra layer", minimalPreset + "layers:\n  - {}\n  - {}\n  - {}\n  - {}\n  - {}\n  - {type: high_shriek}\n  - {amplitude: 0.1}\n",
+			[]audio.LayerType{
+				audio.LayerPrimaryScream, audio.LayerHarmonicSweep, audio.LayerHighShriek,
+				audio.LayerNoiseBurst, audio.LayerBackgroundNoise, audio.LayerHighShriek, audio.LayerPrimaryScream,
+			}},
+		{"extended preset", "extends: classic\nlayers:\n  - {}\n  - {}\n  - {}\n  - {}\n",
+			[]audio.LayerType{audio.LayerPrimaryScream, audio.LayerHarmonicSweep, audio.LayerHighShriek, audio.LayerNoiseBurst}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := defaultParams()
+			if def := mustDefinition(t, tt.src); def.Extends() != "" {
+				base, _ = Builtin().Get(def.Extends())
+			}
+			p, err := mustDefinition(t, tt.src).resolve(base)
+			if err != nil {
+				t.Fatalf("resolve() unexpected error: %v", err)
+			}
+			if len(p.Layers) != len(tt.types) {
+				t.Fatalf("len(Layers) = %d, want %d", len(p.Layers), len(tt.types))
+			}
+			for i, l := range p.Layers {
+				if l.Type != tt.types[i] {
+					t.Errorf("Layers[%d].Type = %v, want %v", i, l.Type, tt.types[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDefinition_VocalLayer(t *testing.T) {
+	src := minimalPreset + `layers:
+  - {type: vocal, base_freq: 180, freq_range: 300, jump_rate: 4, amplitude: 0.5, vowel: aaao, jitter: 0.02, shimmer: 0.1}
+  - {}
+  - {}
+  - {}
+  - {}
+`
+	p, err := mustDefinition(t, src).resolve(defaultParams())
+	if err != nil {
+		t.Fatalf("resolve() unexpected error: %v", err)
+	}
+	l := p.Layers[0]
+	if l.Type != audio.LayerVocal || l.Vowel != "aaao" || l.Jitter != 0.02 || l.Shimmer != 0.1 {
+		t.Errorf("Layers[0] = %+v, want a vocal layer singing aaao", l)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestDefinition_DoesNotModifyNode(t *testing.T) {
+	def := mustDefinition(t, minimalPreset+"layers:\n  - {}\n  - {}\n  - {}\n  - {}\n  - {}\n")
+	if _, err := def.resolve(defaultParams()); err != nil {
+		t.Fatalf("resolve() unexpected error: %v", err)
+	}
+
+	out, err := yaml.Marshal(def)
+	if err != nil {
+		t.Fatalf("yaml.Marshal: %v", err)
+	}
+	var back map[string]interface{}
+	if err := yaml.Unmarshal(out, &back); err != nil {
+		t.Fatalf("yaml.Unmarshal: %v", err)
+	}
+	layers := back["layers"].([]interface{})
+	if first := layers[0].(map[string]interface{}); len(first) != 0 {
+		t.Errorf("layers[0] = %v after resolve, want the original empty mapping", first)
+	}
+}
+
+func TestDefinition_RoundTripsBuiltins(t *testing.T) {
+	for _, name := range audio.AllPresets() {
+		t.Run(string(name), func(t *testing.T) {
+			want, _ := audio.GetPreset(name)
+			data, err := yaml.Marshal(want)
+			if err != nil {
+				t.Fatalf("yaml.Marshal: %v", err)
+			}
+
+			got, err := mustDefinition(t, string(data)).resolve(defaultParams())
+			if err != nil {
+				t.Fatalf("resolve() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round-tripped %s differs:\n got %+v\nwant %+v", name, got, want)
+			}
+		})
+	}
+}
+
+func TestDefinition_RoundTripsMorph(t *testing.T) {
+	want, _ := audio.GetPreset(audio.PresetDeathMetal)
+	end, _ := audio.GetPreset(audio.PresetWhisper)
+	want.MorphTo = &end
+	data, err := yaml.Marshal(want)
+	if err != nil {
+		t.Fatalf("yaml.Marshal: %v", err)
+	}
+
+	got, err := mustDefinition(t, string(data)).resolve(defaultParams())
+	if err != nil {
+		t.Fatalf("resolve() unexpected error: %v", err)
+	}
+	if got.MorphTo == nil || !reflect.DeepEqual(*got.MorphTo, end) || !reflect.DeepEqual(got.MorphStart(), want.MorphStart()) {
+		t.Errorf("round-tripped morph differs:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// extends
+// ---------------------------------------------------------------------------
+
+func TestDefinition_ExtendsOverridesOnlyListedFields(t *testing.T) {
+	src := `extends: banshee
+duration: 5s
+layers[2].base_freq: 400
+filter:
+  lowpass_cutoff: 4000
+filter.crusher_bits: 6
+`
+	r := Builtin()
+	if err := r.Add("low-banshee", mustDefinition(t, src)); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	got, _ := r.Get("low-banshee")
+
+	want, _ := audio.GetPreset(audio.PresetBanshee)
+	want.Duration = 5 * time.Second
+	want.Layers[2].BaseFreq = 400
+	want.Filter.LowpassCutoff = 4000
+	want.Filter.CrusherBits = 6
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("low-banshee differs from banshee with overrides:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDefinition_ExtendsAccessor(t *testing.T) {
+	if got := mustDefinition(t, "extends: robot\n").Extends(); got != "robot" {
+		t.Errorf("Extends() = %q, want robot", got)
+	}
+	if got := mustDefinition(t, minimalPreset).Extends(); got != "" {
+		t.Errorf("Extends() = %q, want empty", got)
+	}
+}
+
+func TestDefinition_InvalidExtends(t *testing.T) {
+	for _, src := range []string{"extends: [a, b]\n", "extends: \"\"\n"} {
+		var def Definition
+		if err := yaml.Unmarshal([]byte(src), &def); err == nil {
+			t.Errorf("yaml.Unmarshal(%q) succeeded, want error", src)
+		}
+	}
+}
+
+func TestDefinition_ExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr error
+	}{
+		{"unknown base", "extends: nope\n", ErrUnknownBase},
+		{"unknown path", "extends: classic\nfilter.cutoff: 3\n", ErrInvalidParams},
+		{"index out of range", "extends: classic\nlayers[5].amplitude: 0.1\n", ErrInvalidParams},
+		{"result invalid", "extends: classic\nlayers[0].amplitude: 3\n", audio.ErrInvalidAmplitude},
+		{"scalar for mapping", "extends: classic\nfilter: 3\n", ErrInvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Builtin().Add("p", mustDefinition(t, tt.src))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Add() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Package preset provides the registry of named scream presets: the built-in
+// presets from the audio package plus user presets loaded from YAML.
+package preset
+
+import "errors"
+
+// Sentinel errors returned by the preset package.
+var (
+	// ErrLoadFailed is returned when a presets file or directory cannot be
+	// read or parsed.
+	ErrLoadFailed = errors.New("preset: failed to load presets")
+
+	// ErrInvalidName is returned when a user preset has an empty name or a
+	// name containing whitespace.
+	ErrInvalidName = errors.New("preset: invalid preset name")
+
+	// ErrDuplicate is returned when a user preset reuses the name of a
+	// built-in or previously loaded preset.
+	ErrDuplicate = errors.New("preset: duplicate preset name")
+
+	// ErrInvalidParams is returned when a user preset does not decode to a
+	// valid audio.ScreamParams.
+	ErrInvalidParams = errors.New("preset: invalid preset parameters")
+
+	// ErrUnknownBase is returned when a user preset extends a preset that
+	// does not exist.
+	ErrUnknownBase = errors.New("preset: extended preset not found")
+
+	// ErrCycle is returned when user presets extend each other in a cycle.
+	ErrCycle = errors.New("preset: presets extend each other in a cycle")
+
+	// ErrInvalidOverride is returned when a parameter override is malformed,
+	// names an unknown field, or has a value of the wrong type.
+	ErrInvalidOverride = errors.New("preset: invalid parameter override")
+)
@@ -0,0 +1,159 @@
+package preset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load returns a Registry holding the built-in presets, the presets defined in
+// every preset file in dir, and the inline definitions, which usually come from
+// the config file. An empty dir is skipped. All definitions are collected
+// before any is resolved, so a preset may extend one defined in any file or
+// inline. Loading stops at the first error.
+func Load(dir string, inline map[string]Definition) (*Registry, error) {
+	defs := make(map[string]Definition)
+	if dir != "" {
+		if err := readDir(dir, defs); err != nil {
+			return nil, err
+		}
+	}
+	for name, def := range inline {
+		if err := collect(defs, name, def); err != nil {
+			return nil, err
+		}
+	}
+
+	r := Builtin()
+	if err := r.AddAll(defs); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LoadDir adds the presets from every .yaml, .yml and .json file in dir.
+// Subdirectories are not searched. Errors reading dir wrap ErrLoadFailed.
+func (r *Registry) LoadDir(dir string) error {
+	defs := make(map[string]Definition)
+	if err := readDir(dir, defs); err != nil {
+		return err
+	}
+	return r.AddAll(defs)
+}
+
+// LoadFile adds the presets defined in the YAML file at path. The file is a
+// mapping from preset name to Definition, the same shape as the presets
+// section of the config file. An empty file adds nothing. Read and parse
+// errors wrap ErrLoadFailed.
+func (r *Registry) LoadFile(path string) error {
+	defs := make(map[string]Definition)
+	if err := readFile(path, defs); err != nil {
+		return err
+	}
+	return r.AddAll(defs)
+}
+
+// AddAll adds every definition in defs. A definition that extends another
+// definition in defs is added after it; the rest are added in name order.
+// AddAll stops at the first error and returns an error wrapping ErrCycle if
+// definitions extend each other in a loop.
+func (r *Registry) AddAll(defs map[string]Definition) error {
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	done := make(map[string]bool)
+	visiting := make(map[string]bool)
+	var add func(name string) error
+	add = func(name string) error {
+		if done[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("%w: %s", ErrCycle, name)
+		}
+		visiting[name] = true
+
+		def := defs[name]
+		if parent := def.Extends(); parent != "" {
+			if _, ok := defs[parent]; ok {
+				if err := add(parent); err != nil {
+					return err
+				}
+			}
+		}
+		if err := r.Add(name, def); err != nil {
+			if def.source != "" {
+				return fmt.Errorf("%s: %w", def.source, err)
+			}
+			return err
+		}
+		done[name] = true
+		return nil
+	}
+
+	for _, name := range names {
+		if err := add(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDir collects the definitions from every .yaml, .yml and .json file in
+// dir into defs. JSON files are parsed as YAML, of which JSON is a subset.
+func readDir(dir string, defs map[string]Definition) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrLoadFailed, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+			if err := readFile(filepath.Join(dir, e.Name()), defs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readFile collects the definitions in the YAML file at path into defs,
+// recording path as their source for error messages.
+func readFile(path string, defs map[string]Definition) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrLoadFailed, err)
+	}
+
+	var file map[string]Definition
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrLoadFailed, path, err)
+	}
+	for name, def := range file {
+		def.source = path
+		if err := collect(defs, name, def); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// collect adds def to defs under name, rejecting names that are already
+// taken.
+func collect(defs map[string]Definition, name string, def Definition) error {
+	if _, ok := defs[name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicate, name)
+	}
+	defs[name] = def
+	return nil
+}
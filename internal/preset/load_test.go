@@ -0,0 +1,181 @@
+package preset
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// writeFile writes content to name in dir.
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+// indent prefixes every line of s with two spaces so it can be nested under
+// a preset name.
+func indent(s string) string {
+	return "  " + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n  ") + "\n"
+}
+
+// ---------------------------------------------------------------------------
+// LoadDir() / LoadFile()
+// ---------------------------------------------------------------------------
+
+func TestLoadDir_LoadsYAMLFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "howler:\n"+indent(minimalPreset))
+	writeFile(t, dir, "b.yml", "moaner:\n"+indent(minimalPreset)+"groaner:\n"+indent(minimalPreset))
+	writeFile(t, dir, "notes.txt", "not yaml: [")
+	writeFile(t, dir, "c.json", `{"shrieker": {"duration": "2s", "filter": {"crusher_bits": 8}}}`)
+	writeFile(t, dir, "empty.yaml", "")
+	if err := os.Mkdir(filepath.Join(dir, "sub.yaml"), 0755); err != nil {
+		t.Fatalf("failed to create subdirectory: %v", err)
+	}
+
+	r := Builtin()
+	if err := r.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() unexpected error: %v", err)
+	}
+	for _, name := range []string{"howler", "moaner", "groaner", "shrieker", "classic"} {
+		if !r.Has(name) {
+			t.Errorf("Has(%q) = false, want true", name)
+		}
+	}
+}
+
+func TestLoadDir_MissingDir(t *testing.T) {
+	err := Builtin().LoadDir(filepath.Join(t.TempDir(), "missing"))
+	if !errors.Is(err, ErrLoadFailed) {
+		t.Errorf("LoadDir() error = %v, want ErrLoadFailed", err)
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{"invalid yaml", "howler: [", ErrLoadFailed},
+		{"not a mapping", "- howler\n", ErrLoadFailed},
+		{"definition not a mapping", "howler: 3\n", ErrLoadFailed},
+		{"invalid params", "howler:\n  duration: 2s\n", ErrInvalidParams},
+		{"redefines built-in", "banshee:\n" + indent(minimalPreset), ErrDuplicate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "p.yaml", tt.content)
+			err := Builtin().LoadFile(filepath.Join(dir, "p.yaml"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadFile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadDir_DuplicateAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "howler:\n"+indent(minimalPreset))
+	writeFile(t, dir, "b.yaml", "howler:\n"+indent(minimalPreset))
+
+	if err := Builtin().LoadDir(dir); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("LoadDir() error = %v, want ErrDuplicate", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Load()
+// ---------------------------------------------------------------------------
+
+func TestLoad_DirAndInline(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "howler:\n"+indent(minimalPreset))
+	inline := map[string]Definition{"moaner": mustDefinition(t, minimalPreset)}
+
+	r, err := Load(dir, inline)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if !r.Has("howler") || !r.Has("moaner") || !r.Has("robot") {
+		t.Errorf("Names() = %v, want built-ins plus howler and moaner", r.Names())
+	}
+}
+
+func TestLoad_EmptyDirSkipped(t *testing.T) {
+	r, err := Load("", nil)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if got, want := len(r.Names()), len(Builtin().Names()); got != want {
+		t.Errorf("len(Names()) = %d, want %d", got, want)
+	}
+}
+
+func TestLoad_InlineConflictsWithDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "howler:\n"+indent(minimalPreset))
+	inline := map[string]Definition{"howler": mustDefinition(t, minimalPreset)}
+
+	if _, err := Load(dir, inline); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Load() error = %v, want ErrDuplicate", err)
+	}
+}
+
+func TestLoad_ExtendsAcrossFilesAndInline(t *testing.T) {
+	dir := t.TempDir()
+	// aaa sorts first but extends a preset defined in a later file.
+	writeFile(t, dir, "a.yaml", "aaa:\n  extends: zzz\n  layers[0].amplitude: 0.1\n")
+	writeFile(t, dir, "z.yaml", "zzz:\n  extends: robot\n  duration: 6s\n")
+	inline := map[string]Definition{"inline": mustDefinition(t, "extends: aaa\nwidth: 0\n")}
+
+	r, err := Load(dir, inline)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	got, _ := r.Get("inline")
+	want, _ := audio.GetPreset(audio.PresetRobot)
+	want.Duration = 6 * time.Second
+	want.Layers[0].Amplitude = 0.1
+	want.Width = 0
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("inline preset:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLoad_ExtendsCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "p.yaml", "a:\n  extends: b\nb:\n  extends: c\nc:\n  extends: a\n")
+
+	if _, err := Load(dir, nil); !errors.Is(err, ErrCycle) {
+		t.Errorf("Load() error = %v, want ErrCycle", err)
+	}
+	self := map[string]Definition{"me": mustDefinition(t, "extends: me\n")}
+	if _, err := Load("", self); !errors.Is(err, ErrCycle) {
+		t.Errorf("Load() self-extension error = %v, want ErrCycle", err)
+	}
+}
+
+func TestLoad_ErrorNamesSourceFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "broken.yaml", "p:\n  extends: nope\n")
+
+	_, err := Load(dir, nil)
+	if !errors.Is(err, ErrUnknownBase) {
+		t.Fatalf("Load() error = %v, want ErrUnknownBase", err)
+	}
+	if !strings.Contains(err.Error(), "broken.yaml") {
+		t.Errorf("Load() error = %q, want it to name broken.yaml", err)
+	}
+}
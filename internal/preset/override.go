@@ -0,0 +1,352 @@
+package preset
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// Override sets a single scream parameter. Path addresses the parameter by the
+// yaml field names of audio.ScreamParams, joined with dots, with layers
+// selected by index: "duration", "filter.lowpass_cutoff", "layers[2].base_freq".
+// Value is the new value written as YAML, for example "4000", "5s" or
+// "harmonic_sweep".
+type Override struct {
+	Path  string
+	Value string
+}
+
+// String returns the override in the "path=value" form accepted by
+// ParseOverride.
+func (o Override) String() string {
+	return o.Path + "=" + o.Value
+}
+
+// ParseOverride parses an override written as "path=value". The path must
+// address a field of audio.ScreamParams; list indexes and the value are only
+// checked when the override is applied, since they depend on the parameters
+// it is applied to. Errors wrap ErrInvalidOverride.
+func ParseOverride(s string) (Override, error) {
+	path, value, ok := strings.Cut(s, "=")
+	path = strings.TrimSpace(path)
+	if !ok || path == "" {
+		return Override{}, fmt.Errorf("%w: %q: want path=value", ErrInvalidOverride, s)
+	}
+	o := Override{Path: path, Value: strings.TrimSpace(value)}
+
+	// Check the path against the parameter schema so that typos are reported
+	// before any audio is generated. The schema has one layer with one level,
+	// envelope point and pitch point, one scream envelope point and one EQ
+	// band, which stand in for every index.
+	env := audio.Envelope{Points: make([]audio.EnvelopePoint, 1)}
+	schema := audio.ScreamParams{
+		Layers: []audio.LayerParams{{
+			Levels:   make([]float64, 1),
+			Envelope: env,
+			Pitch:    audio.PitchContour{Points: make([]audio.PitchPoint, 1)},
+		}},
+		Filter:   audio.FilterParams{EQ: make([]audio.EQBand, 1)},
+		Envelope: env,
+	}
+	schema.MorphTo = &audio.ScreamParams{Layers: schema.Layers, Filter: schema.Filter, Envelope: env}
+	root, err := encodeParams(schema)
+	if err != nil {
+		return Override{}, err
+	}
+	if _, err := lookup(root, schemaIndex.ReplaceAllString(path, "[0]")); err != nil {
+		return Override{}, fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+	}
+	return o, nil
+}
+
+// schemaIndex matches a list index in an override path.
+var schemaIndex = regexp.MustCompile(`\[\d+\]`)
+
+// ParseOverrides parses each string in ss with ParseOverride, stopping at the
+// first error.
+func ParseOverrides(ss []string) ([]Override, error) {
+	out := make([]Override, 0, len(ss))
+	for _, s := range ss {
+		o, err := ParseOverride(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// Apply returns a copy of params with each override applied in order, so a
+// later override of the same path wins. The result is not validated; callers
+// should call audio.ScreamParams.Validate. Errors wrap ErrInvalidOverride.
+func Apply(params audio.ScreamParams, overrides ...Override) (audio.ScreamParams, error) {
+	if len(overrides) == 0 {
+		return params, nil
+	}
+
+	root, err := encodeParams(params)
+	if err != nil {
+		return audio.ScreamParams{}, err
+	}
+	for _, o := range overrides {
+		value, err := parseValue(o.Value)
+		if err != nil {
+			return audio.ScreamParams{}, fmt.Errorf("%w: %s: %w", ErrInvalidOverride, o.Path, err)
+		}
+		if err := set(root, o.Path, value); err != nil {
+			return audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+		}
+	}
+
+	out, err := decodeParams(root)
+	if err != nil {
+		return audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+	}
+	return out, nil
+}
+
+// parseValue parses s as a single YAML value.
+func parseValue(s string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("missing value")
+	}
+	return doc.Content[0], nil
+}
+
+// encodeParams returns params as a YAML mapping node in which every field is
+// present. Fields that are omitted when encoding because they are unset, an
+// unset morph_to, empty layer levels, envelope and pitch points and EQ
+// bands, are added as null.
+func encodeParams(params audio.ScreamParams) (*yaml.Node, error) {
+	var n yaml.Node
+	if err := n.Encode(params); err != nil {
+		return nil, err
+	}
+	addNull(&n, morphToKey)
+	addNullLists(&n)
+	if end := field(&n, morphToKey); end.Kind == yaml.MappingNode {
+		addNullLists(end)
+	}
+	return &n, nil
+}
+
+// Keys of the fields that encodeParams adds when they are omitted: the yaml
+// field names of audio.ScreamParams.MorphTo, audio.LayerParams.Levels,
+// audio.FilterParams.EQ and the Points of audio.Envelope and
+// audio.PitchContour, and of the fields holding the points and bands.
+const (
+	morphToKey  = "morph_to"
+	levelsKey   = "levels"
+	filterKey   = "filter"
+	eqKey       = "eq"
+	envelopeKey = "envelope"
+	pitchKey    = "pitch"
+	pointsKey   = "points"
+)
+
+// addNull adds key to the mapping n with a null value, unless n has it.
+func addNull(n *yaml.Node, key string) {
+	if field(n, key) != nil {
+		return
+	}
+	n.Content = append(n.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"},
+	)
+}
+
+// addNullLists adds null envelope points and EQ bands to the params mapping
+// n, and null levels and envelope and pitch points to each of its layers,
+// where they are missing.
+func addNullLists(n *yaml.Node) {
+	addNullPoints(n, envelopeKey)
+	if f := field(n, filterKey); f != nil {
+		addNull(f, eqKey)
+	}
+	layers := field(n, "layers")
+	if layers == nil {
+		return
+	}
+	for _, l := range layers.Content {
+		if l.Kind == yaml.MappingNode {
+			addNull(l, levelsKey)
+			addNullPoints(l, envelopeKey)
+			addNullPoints(l, pitchKey)
+		}
+	}
+}
+
+// addNullPoints adds null points to the field key of the mapping n if it has
+// that field without points.
+func addNullPoints(n *yaml.Node, key string) {
+	if f := field(n, key); f != nil {
+		addNull(f, pointsKey)
+	}
+}
+
+// decodeParams converts a mapping node produced by encodeParams, and possibly
+// modified by set, back into ScreamParams.
+func decodeParams(n *yaml.Node) (audio.ScreamParams, error) {
+	var params audio.ScreamParams
+	if err := n.Decode(&params); err != nil {
+		return audio.ScreamParams{}, err
+	}
+	return params, nil
+}
+
+// set merges value into the node that path addresses within root. If that
+// adds list elements, root is re-encoded so that the new elements have every
+// field and can be addressed by later paths.
+func set(root *yaml.Node, path string, value *yaml.Node) error {
+	target, err := lookup(root, path)
+	if err != nil {
+		return err
+	}
+	grown, err := merge(target, value, path)
+	if err != nil || !grown {
+		return err
+	}
+
+	params, err := decodeParams(root)
+	if err != nil {
+		return fmt.Errorf("%q: %w", path, err)
+	}
+	n, err := encodeParams(params)
+	if err != nil {
+		return err
+	}
+	*root = *n
+	return nil
+}
+
+// lookup returns the node that path addresses within root. Each dot-separated
+// element of path is a field name, optionally followed by one or more [index]
+// selectors.
+func lookup(root *yaml.Node, path string) (*yaml.Node, error) {
+	cur := root
+	for _, elem := range strings.Split(path, ".") {
+		name, indexes, err := splitElem(elem)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", path, err)
+		}
+		if cur = field(cur, name); cur == nil {
+			return nil, fmt.Errorf("%q: unknown field %q", path, name)
+		}
+		for _, i := range indexes {
+			if cur.Kind != yaml.SequenceNode {
+				return nil, fmt.Errorf("%q: %s is not a list", path, name)
+			}
+			if i >= len(cur.Content) {
+				return nil, fmt.Errorf("%q: index %d out of range [0, %d)", path, i, len(cur.Content))
+			}
+			cur = cur.Content[i]
+		}
+	}
+	return cur, nil
+}
+
+// splitElem splits a path element such as "layers[2]" into its field name and
+// indexes.
+func splitElem(elem string) (string, []int, error) {
+	name, rest, _ := strings.Cut(elem, "[")
+	if name == "" {
+		return "", nil, fmt.Errorf("empty field name")
+	}
+	if rest == "" {
+		return name, nil, nil
+	}
+
+	var indexes []int
+	for _, part := range strings.Split("["+rest, "[")[1:] {
+		digits, ok := strings.CutSuffix(part, "]")
+		if !ok {
+			return "", nil, fmt.Errorf("malformed index in %q", elem)
+		}
+		i, err := strconv.Atoi(digits)
+		if err != nil || i < 0 {
+			return "", nil, fmt.Errorf("invalid index %q in %q", digits, elem)
+		}
+		indexes = append(indexes, i)
+	}
+	return name, indexes, nil
+}
+
+// field returns the value of key in the mapping n, or nil if n is not a
+// mapping or has no such key.
+func field(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// merge overwrites dst with src. Mappings are merged key by key, so only the
+// fields present in src change, and lists are merged element by element,
+// dropping the elements of dst beyond the end of src and adding the elements
+// of src beyond the end of dst as they are. Any other value, and any value
+// written over a null such as an unset morph_to, replaces dst.
+//
+// grown reports whether elements or mappings were added, since they may lack
+// fields. path names dst in error messages.
+func merge(dst, src *yaml.Node, path string) (grown bool, err error) {
+	switch {
+	case dst.Tag == "!!null":
+		// An unset field has no structure to merge into; src may lack fields
+		// just as added list elements do.
+		*dst = *src
+		return src.Kind != yaml.ScalarNode, nil
+
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key := src.Content[i].Value
+			child := field(dst, key)
+			if child == nil {
+				return false, fmt.Errorf("%q: unknown field %q", path, key)
+			}
+			g, err := merge(child, src.Content[i+1], path+"."+key)
+			if err != nil {
+				return false, err
+			}
+			grown = grown || g
+		}
+		return grown, nil
+
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		n := min(len(dst.Content), len(src.Content))
+		for i := range n {
+			g, err := merge(dst.Content[i], src.Content[i], fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return false, err
+			}
+			grown = grown || g
+		}
+		dst.Content = append(dst.Content[:n], src.Content[n:]...)
+		return grown || len(src.Content) > n, nil
+
+	case dst.Kind == yaml.MappingNode:
+		return false, fmt.Errorf("%q: want a mapping", path)
+
+	case dst.Kind == yaml.SequenceNode:
+		return false, fmt.Errorf("%q: want a list", path)
+
+	case src.Kind != yaml.ScalarNode:
+		return false, fmt.Errorf("%q: want a single value", path)
+	}
+
+	*dst = *src
+	return false, nil
+}
@@ -0,0 +1,319 @@
+package preset
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// ---------------------------------------------------------------------------
+// ParseOverride()
+// ---------------------------------------------------------------------------
+
+func TestParseOverride_Valid(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Override
+	}{
+		{"duration=5s", Override{Path: "duration", Value: "5s"}},
+		{"filter.lowpass_cutoff=4000", Override{Path: "filter.lowpass_cutoff", Value: "4000"}},
+		{" layers[2].base_freq = 400 ", Override{Path: "layers[2].base_freq", Value: "400"}},
+		{"layers[4].type=noise_burst", Override{Path: "layers[4].type", Value: "noise_burst"}},
+		{"layers[9].burst_rate=", Override{Path: "layers[9].burst_rate", Value: ""}},
+		{"morph_to.filter.crusher_bits=4", Override{Path: "morph_to.filter.crusher_bits", Value: "4"}},
+		{"layers[0].levels[3]=0.5", Override{Path: "layers[0].levels[3]", Value: "0.5"}},
+		{"envelope.release=0.5", Override{Path: "envelope.release", Value: "0.5"}},
+		{"layers[1].envelope.points[2].level=1", Override{Path: "layers[1].envelope.points[2].level", Value: "1"}},
+		{"morph_to.envelope.points[0].time=1", Override{Path: "morph_to.envelope.points[0].time", Value: "1"}},
+		{"layers[0].pitch.curve=rising", Override{Path: "layers[0].pitch.curve", Value: "rising"}},
+		{"layers[0].pitch.points[1].semitones=3", Override{Path: "layers[0].pitch.points[1].semitones", Value: "3"}},
+		{"layers[0].vibrato.rate=5", Override{Path: "layers[0].vibrato.rate", Value: "5"}},
+		{"layers[2].waveform=supersaw", Override{Path: "layers[2].waveform", Value: "supersaw"}},
+		{"layers[3].noise.color=pink", Override{Path: "layers[3].noise.color", Value: "pink"}},
+		{"filter.reverb_mix=0.3", Override{Path: "filter.reverb_mix", Value: "0.3"}},
+		{"filter.delay_ping_pong=true", Override{Path: "filter.delay_ping_pong", Value: "true"}},
+		{"filter.distortion=tube", Override{Path: "filter.distortion", Value: "tube"}},
+		{"filter.limiter=lookahead", Override{Path: "filter.limiter", Value: "lookahead"}},
+		{"filter.eq[1].gain=6", Override{Path: "filter.eq[1].gain", Value: "6"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseOverride(tt.in)
+			if err != nil {
+				t.Fatalf("ParseOverride(%q) unexpected error: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOverride(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOverride_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"duration",
+		"=5s",
+		"volume=3",
+		"filter.cutoff=3",
+		"noise.burst_rate=2",
+		"layers[-1].amplitude=0.1",
+		"layers[x].amplitude=0.1",
+		"layers[1.amplitude=0.1",
+		"filter[0]=1",
+		"filter..lowpass_cutoff=1",
+	} {
+		t.Run(in, func(t *testing.T) {
+			if _, err := ParseOverride(in); !errors.Is(err, ErrInvalidOverride) {
+				t.Errorf("ParseOverride(%q) error = %v, want ErrInvalidOverride", in, err)
+			}
+		})
+	}
+}
+
+func TestParseOverrides_StopsAtFirstError(t *testing.T) {
+	got, err := ParseOverrides([]string{"duration=5s", "filter.lowpass_cutoff=1"})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("ParseOverrides() = %v, %v; want 2 overrides", got, err)
+	}
+	if _, err := ParseOverrides([]string{"duration=5s", "bogus=1"}); !errors.Is(err, ErrInvalidOverride) {
+		t.Errorf("ParseOverrides() error = %v, want ErrInvalidOverride", err)
+	}
+}
+
+func TestOverride_String(t *testing.T) {
+	o := Override{Path: "filter.crusher_bits", Value: "6"}
+	if got := o.String(); got != "filter.crusher_bits=6" {
+		t.Errorf("String() = %q, want %q", got, "filter.crusher_bits=6")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Apply()
+// ---------------------------------------------------------------------------
+
+func TestApply_SetsFields(t *testing.T) {
+	base, _ := audio.GetPreset(audio.PresetClassic)
+	got, err := Apply(base,
+		Override{Path: "duration", Value: "5s"},
+		Override{Path: "filter.lowpass_cutoff", Value: "4000"},
+		Override{Path: "layers[2].base_freq", Value: "400"},
+		Override{Path: "layers[1].type", Value: "high_shriek"},
+		Override{Path: "layers[3]", Value: "{burst_rate: 2}"},
+	)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+
+	want := base.Clone()
+	want.Duration = 5 * time.Second
+	want.Filter.LowpassCutoff = 4000
+	want.Layers[2].BaseFreq = 400
+	want.Layers[1].Type = audio.LayerHighShriek
+	want.Layers[3].BurstRate = 2
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply():\n got %+v\nwant %+v", got, want)
+	}
+
+	orig, _ := audio.GetPreset(audio.PresetClassic)
+	if !reflect.DeepEqual(base, orig) {
+		t.Error("Apply() modified its input")
+	}
+}
+
+func TestApply_LaterOverrideWins(t *testing.T) {
+	base, _ := audio.GetPreset(audio.PresetRobot)
+	got, err := Apply(base,
+		Override{Path: "filter.crusher_bits", Value: "4"},
+		Override{Path: "filter.crusher_bits", Value: "10"},
+	)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	if got.Filter.CrusherBits != 10 {
+		t.Errorf("CrusherBits = %d, want 10", got.Filter.CrusherBits)
+	}
+}
+
+func TestApply_NoOverrides(t *testing.T) {
+	base := audio.Randomize(42)
+	got, err := Apply(base)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, base) {
+		t.Error("Apply() without overrides changed the params")
+	}
+}
+
+func TestApply_ResizesLayers(t *testing.T) {
+	base, _ := audio.GetPreset(audio.PresetClassic)
+
+	got, err := Apply(base, Override{Path: "layers", Value: "[{}, {amplitude: 0.5}]"})
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	want := base.Clone()
+	want.Layers = want.Layers[:2]
+	want.Layers[1].Amplitude = 0.5
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shortened layers:\n got %+v\nwant %+v", got.Layers, want.Layers)
+	}
+
+	got, err = Apply(base,
+		Override{Path: "layers", Value: "[{}, {}, {}, {}, {}, {type: high_shriek, amplitude: 0.2}]"},
+		Override{Path: "layers[5].base_freq", Value: "900"},
+	)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	want = base.Clone()
+	want.Layers = append(want.Layers, audio.LayerParams{Type: audio.LayerHighShriek, Amplitude: 0.2, BaseFreq: 900})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lengthened layers:\n got %+v\nwant %+v", got.Layers, want.Layers)
+	}
+}
+
+func TestApply_Levels(t *testing.T) {
+	base, _ := audio.GetPreset(audio.PresetClassic)
+
+	got, err := Apply(base,
+		Override{Path: "layers[0].levels", Value: "[0, 1, 0]"},
+		Override{Path: "layers[0].levels[1]", Value: "0.5"},
+	)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	want := base.Clone()
+	want.Layers[0].Levels = []float64{0, 0.5, 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply():\n got %+v\nwant %+v", got.Layers[0], want.Layers[0])
+	}
+	if got.Layers[1].Levels != nil {
+		t.Errorf("Layers[1].Levels = %v, want nil", got.Layers[1].Levels)
+	}
+}
+
+func TestApply_EQ(t *testing.T) {
+	base, _ := audio.GetPreset(audio.PresetClassic)
+
+	got, err := Apply(base,
+		Override{Path: "filter.biquad", Value: "true"},
+		Override{Path: "filter.eq", Value: "[{type: peak, freq: 3000, gain: 6}, {type: notch, freq: 1000}]"},
+		Override{Path: "filter.eq[1].q", Value: "4"},
+	)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	want := base.Clone()
+	want.Filter.Biquad = true
+	want.Filter.EQ = []audio.EQBand{
+		{Type: audio.EQPeak, Freq: 3000, Gain: 6},
+		{Type: audio.EQNotch, Freq: 1000, Q: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply():\n got %+v\nwant %+v", got.Filter, want.Filter)
+	}
+}
+
+func TestApply_Envelope(t *testing.T) {
+	base, _ := audio.GetPreset(audio.PresetClassic)
+
+	got, err := Apply(base,
+		Override{Path: "envelope.attack", Value: "0.5"},
+		Override{Path: "layers[2].envelope", Value: "{points: [{time: 0, level: 1}, {time: 2, level: 0}]}"},
+		Override{Path: "layers[2].envelope.points[1].time", Value: "1"},
+	)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	want := base.Clone()
+	want.Envelope.Attack = 0.5
+	want.Layers[2].Envelope.Points = []audio.EnvelopePoint{{Time: 0, Level: 1}, {Time: 1, Level: 0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply():\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestApply_Pitch(t *testing.T) {
+	base, _ := audio.GetPreset(audio.PresetClassic)
+
+	got, err := Apply(base,
+		Override{Path: "layers[0].pitch.curve", Value: "falling"},
+		Override{Path: "layers[0].pitch.semitones", Value: "7"},
+		Override{Path: "layers[0].vibrato", Value: "{rate: 5, depth: 0.5}"},
+		Override{Path: "layers[1].pitch", Value: "{points: [{time: 0, semitones: 0}, {time: 2, semitones: 12}]}"},
+		Override{Path: "layers[1].pitch.points[1].semitones", Value: "-12"},
+		Override{Path: "layers[2].tremolo.depth", Value: "0.3"},
+	)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	want := base.Clone()
+	want.Layers[0].Pitch = audio.PitchContour{Curve: audio.PitchFalling, Semitones: 7}
+	want.Layers[0].Vibrato = audio.LFO{Rate: 5, Depth: 0.5}
+	want.Layers[1].Pitch.Points = []audio.PitchPoint{{Time: 0, Semitones: 0}, {Time: 2, Semitones: -12}}
+	want.Layers[2].Tremolo.Depth = 0.3
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply():\n got %+v\nwant %+v", got, want)
+	}
+	if got.Layers[0].Pitch.Points != nil {
+		t.Errorf("Apply() pitch points = %v, want nil", got.Layers[0].Pitch.Points)
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	base, _ := audio.GetPreset(audio.PresetClassic)
+	for _, o := range []Override{
+		{Path: "filter.crusher_bits", Value: "lots"},
+		{Path: "duration", Value: "forever"},
+		{Path: "layers[0].type", Value: "kazoo"},
+		{Path: "filter", Value: "3"},
+		{Path: "layers", Value: "[{}, 3]"},
+		{Path: "layers[5].amplitude", Value: "0.1"},
+		{Path: "layers[0].amplitude", Value: "[1, 2]"},
+		{Path: "filter.bogus", Value: "1"},
+		{Path: "layers[0]", Value: "{bogus: 1}"},
+		{Path: "width", Value: ""},
+		{Path: "morph_to.filter.crusher_bits", Value: "4"},
+	} {
+		t.Run(o.String(), func(t *testing.T) {
+			if _, err := Apply(base, o); !errors.Is(err, ErrInvalidOverride) {
+				t.Errorf("Apply(%s) error = %v, want ErrInvalidOverride", o, err)
+			}
+		})
+	}
+}
+
+func TestApply_MorphTo(t *testing.T) {
+	base, _ := audio.GetPreset(audio.PresetClassic)
+	whisper, _ := audio.GetPreset(audio.PresetWhisper)
+	data, err := yaml.Marshal(whisper)
+	if err != nil {
+		t.Fatalf("yaml.Marshal: %v", err)
+	}
+
+	got, err := Apply(base,
+		Override{Path: "morph_to", Value: string(data)},
+		Override{Path: "morph_to.filter.crusher_bits", Value: "4"},
+	)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	if got.MorphTo == nil {
+		t.Fatal("Apply() did not set MorphTo")
+	}
+	want := whisper
+	want.Filter.CrusherBits = 4
+	if !reflect.DeepEqual(*got.MorphTo, want) {
+		t.Errorf("MorphTo:\n got %+v\nwant %+v", *got.MorphTo, want)
+	}
+	if !reflect.DeepEqual(got.MorphStart(), base) {
+		t.Error("Apply() to morph_to changed the start params")
+	}
+}
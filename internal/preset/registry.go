@@ -0,0 +1,99 @@
+package preset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// Registry holds every preset that can be selected by name: the built-in
+// presets from the audio package and any user presets added to it. A Registry
+// is not safe for concurrent mutation, but once loaded it may be read from
+// multiple goroutines.
+type Registry struct {
+	params map[string]audio.ScreamParams
+	user   []string
+}
+
+// Builtin returns a Registry containing only the built-in presets.
+func Builtin() *Registry {
+	r := &Registry{params: make(map[string]audio.ScreamParams)}
+	for _, name := range audio.AllPresets() {
+		p, _ := audio.GetPreset(name)
+		r.params[string(name)] = p
+	}
+	return r
+}
+
+// Add resolves def and registers it under name. The definition is applied to
+// the preset it extends, which must already be registered, or to the defaults
+// described by Definition, and the result must pass
+// audio.ScreamParams.Validate. Names must be non-empty, contain no whitespace,
+// and not already be registered; built-in presets cannot be replaced.
+func (r *Registry) Add(name string, def Definition) error {
+	if name == "" || strings.ContainsFunc(name, isSpace) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	if _, ok := r.params[name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicate, name)
+	}
+
+	base := defaultParams()
+	if parent := def.Extends(); parent != "" {
+		p, ok := r.params[parent]
+		if !ok {
+			return fmt.Errorf("%w: %s extends %q", ErrUnknownBase, name, parent)
+		}
+		base = p.Clone()
+	}
+
+	params, err := def.resolve(base)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidParams, name, err)
+	}
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidParams, name, err)
+	}
+
+	r.params[name] = params
+	r.user = append(r.user, name)
+	return nil
+}
+
+// Get returns the parameters of the named preset. The caller may modify them.
+func (r *Registry) Get(name string) (audio.ScreamParams, bool) {
+	p, ok := r.params[name]
+	return p.Clone(), ok
+}
+
+// Has reports whether a preset called name is registered.
+func (r *Registry) Has(name string) bool {
+	_, ok := r.params[name]
+	return ok
+}
+
+// IsBuiltin reports whether name is one of the built-in presets.
+func (r *Registry) IsBuiltin(name string) bool {
+	_, ok := audio.GetPreset(audio.PresetName(name))
+	return ok && r.Has(name)
+}
+
+// Names returns the names of all registered presets: the built-in presets in
+// audio.AllPresets order, followed by user presets sorted alphabetically.
+func (r *Registry) Names() []string {
+	builtin := audio.AllPresets()
+	names := make([]string, 0, len(builtin)+len(r.user))
+	for _, n := range builtin {
+		names = append(names, string(n))
+	}
+	user := append([]string(nil), r.user...)
+	sort.Strings(user)
+	return append(names, user...)
+}
+
+// isSpace reports whether c is an ASCII whitespace character.
+func isSpace(c rune) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
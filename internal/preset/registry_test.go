@@ -0,0 +1,159 @@
+package preset
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JamesPrial/go-scream/internal/audio"
+)
+
+// mustDefinition parses src as a single preset Definition.
+func mustDefinition(t *testing.T, src string) Definition {
+	t.Helper()
+	var def Definition
+	if err := yaml.Unmarshal([]byte(src), &def); err != nil {
+		t.Fatalf("yaml.Unmarshal(%q): %v", src, err)
+	}
+	return def
+}
+
+// minimalPreset is the smallest valid user preset: everything else defaults.
+const minimalPreset = "duration: 2s\nfilter:\n  crusher_bits: 8\n"
+
+// ---------------------------------------------------------------------------
+// Builtin()
+// ---------------------------------------------------------------------------
+
+func TestBuiltin_MatchesAudioPresets(t *testing.T) {
+	r := Builtin()
+	for _, name := range audio.AllPresets() {
+		got, ok := r.Get(string(name))
+		if !ok {
+			t.Fatalf("Builtin() missing %q", name)
+		}
+		want, _ := audio.GetPreset(name)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Builtin().Get(%q) differs from audio.GetPreset", name)
+		}
+		if !r.IsBuiltin(string(name)) {
+			t.Errorf("IsBuiltin(%q) = false, want true", name)
+		}
+	}
+	if got := len(r.Names()); got != len(audio.AllPresets()) {
+		t.Errorf("len(Names()) = %d, want %d", got, len(audio.AllPresets()))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Add()
+// ---------------------------------------------------------------------------
+
+func TestAdd_Valid(t *testing.T) {
+	r := Builtin()
+	if err := r.Add("howler", mustDefinition(t, minimalPreset)); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	p, ok := r.Get("howler")
+	if !ok {
+		t.Fatal("Get(howler) returned false after Add")
+	}
+	if p.Duration != 2*time.Second {
+		t.Errorf("Duration = %v, want 2s", p.Duration)
+	}
+	if r.IsBuiltin("howler") {
+		t.Error("IsBuiltin(howler) = true, want false")
+	}
+}
+
+func TestAdd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		preset  string
+		src     string
+		wantErr error
+	}{
+		{"empty name", "", minimalPreset, ErrInvalidName},
+		{"name with space", "my preset", minimalPreset, ErrInvalidName},
+		{"built-in name", "classic", minimalPreset, ErrDuplicate},
+		{"missing duration", "p", "filter:\n  crusher_bits: 8\n", ErrInvalidParams},
+		{"invalid amplitude", "p", minimalPreset + "layers:\n  - amplitude: 2\n  - {}\n  - {}\n  - {}\n  - {}\n", ErrInvalidParams},
+		{"unknown field", "p", minimalPreset + "volume: 3\n", ErrInvalidParams},
+		{"no layers", "p", minimalPreset + "layers: []\n", ErrInvalidParams},
+		{"unknown layer type", "p", minimalPreset + "layers:\n  - type: kazoo\n  - {}\n  - {}\n  - {}\n  - {}\n", ErrInvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Builtin()
+			err := r.Add(tt.preset, mustDefinition(t, tt.src))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Add() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.preset != "classic" && r.Has(tt.preset) {
+				t.Errorf("Has(%q) = true after failed Add", tt.preset)
+			}
+		})
+	}
+}
+
+func TestAdd_InvalidParamsWrapsValidationError(t *testing.T) {
+	r := Builtin()
+	err := r.Add("p", mustDefinition(t, "duration: 2s\nfilter:\n  crusher_bits: 40\n"))
+	if !errors.Is(err, audio.ErrInvalidCrusherBits) {
+		t.Errorf("Add() error = %v, want it to wrap audio.ErrInvalidCrusherBits", err)
+	}
+}
+
+func TestAdd_DuplicateUserPreset(t *testing.T) {
+	r := Builtin()
+	if err := r.Add("howler", mustDefinition(t, minimalPreset)); err != nil {
+		t.Fatalf("first Add() unexpected error: %v", err)
+	}
+	if err := r.Add("howler", mustDefinition(t, minimalPreset)); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("second Add() error = %v, want ErrDuplicate", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Names()
+// ---------------------------------------------------------------------------
+
+func TestNames_BuiltinsFirstThenUserSorted(t *testing.T) {
+	r := Builtin()
+	for _, name := range []string{"zeta", "alpha", "mid"} {
+		if err := r.Add(name, mustDefinition(t, minimalPreset)); err != nil {
+			t.Fatalf("Add(%q) unexpected error: %v", name, err)
+		}
+	}
+
+	got := r.Names()
+	builtin := audio.AllPresets()
+	if len(got) != len(builtin)+3 {
+		t.Fatalf("len(Names()) = %d, want %d", len(got), len(builtin)+3)
+	}
+	for i, name := range builtin {
+		if got[i] != string(name) {
+			t.Errorf("Names()[%d] = %q, want %q", i, got[i], name)
+		}
+	}
+	want := []string{"alpha", "mid", "zeta"}
+	for i, name := range want {
+		if got[len(builtin)+i] != name {
+			t.Errorf("Names()[%d] = %q, want %q", len(builtin)+i, got[len(builtin)+i], name)
+		}
+	}
+}
+
+func TestNames_ReturnsCopy(t *testing.T) {
+	r := Builtin()
+	names := r.Names()
+	names[0] = "mutated"
+	if r.Names()[0] == "mutated" {
+		t.Error("mutating the result of Names() changed the registry")
+	}
+}
@@ -12,6 +12,14 @@ var (
 	// ErrUnknownPreset is returned when the configured preset name does not exist.
 	ErrUnknownPreset = errors.New("scream: unknown preset name")
 
+	// ErrInvalidOverride is returned when the configured parameter overrides
+	// cannot be applied or produce invalid parameters.
+	ErrInvalidOverride = errors.New("scream: invalid parameter override")
+
+	// ErrInvalidText is returned when the configured text cannot be made into
+	// a scream.
+	ErrInvalidText = errors.New("scream: invalid scream text")
+
 	// ErrGenerateFailed is returned when audio generation fails.
 	ErrGenerateFailed = errors.New("scream: audio generation failed")
 
@@ -20,4 +28,16 @@ var (
 
 	// ErrPlayFailed is returned when Discord voice playback fails.
 	ErrPlayFailed = errors.New("scream: playback failed")
+
+	// ErrNoResolver is returned when a voice channel must be auto-detected
+	// but no ChannelResolver is configured.
+	ErrNoResolver = errors.New("scream: channel resolver not configured")
+
+	// ErrChannelResolveFailed is returned when no voice channel matches the
+	// configured channel strategy.
+	ErrChannelResolveFailed = errors.New("scream: could not resolve voice channel")
+
+	// ErrCancelled is returned when Play or Generate stops because its context
+	// was cancelled. Errors wrapping it also wrap the context's error.
+	ErrCancelled = errors.New("scream: cancelled")
 )
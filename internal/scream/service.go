@@ -1,7 +1,10 @@
 package scream
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -11,6 +14,7 @@ import (
 	"github.com/JamesPrial/go-scream/internal/config"
 	"github.com/JamesPrial/go-scream/internal/discord"
 	"github.com/JamesPrial/go-scream/internal/encoding"
+	"github.com/JamesPrial/go-scream/internal/preset"
 )
 
 // Service orchestrates audio generation, encoding, and Discord voice playback.
@@ -20,19 +24,26 @@ type Service struct {
 	fileEnc   encoding.FileEncoder
 	frameEnc  encoding.OpusFrameEncoder
 	player    discord.VoicePlayer
+	resolver  discord.ChannelResolver
+	presets   *preset.Registry
+	onParams  func(audio.ScreamParams)
 	logger    *slog.Logger
 }
 
 // NewServiceWithDeps constructs a Service with all dependencies explicitly
-// injected. It never returns nil. The player argument may be nil when the
-// service is used in DryRun mode or for file generation only. Callers must
-// pass an untyped nil (not a typed-nil interface value) when no player is needed.
+// injected. It never returns nil. The player and resolver arguments may be nil
+// when the service is used in DryRun mode or for file generation only; a nil
+// resolver also disables voice channel auto-detection. Callers must pass an
+// untyped nil (not a typed-nil interface value) when no player or resolver is
+// needed. The service starts with only the built-in presets; use WithPresets
+// to make user presets available.
 func NewServiceWithDeps(
 	cfg config.Config,
 	gen audio.Generator,
 	fileEnc encoding.FileEncoder,
 	frameEnc encoding.OpusFrameEncoder,
 	player discord.VoicePlayer,
+	resolver discord.ChannelResolver,
 	logger *slog.Logger,
 ) *Service {
 	return &Service{
@@ -41,33 +52,138 @@ func NewServiceWithDeps(
 		fileEnc:   fileEnc,
 		frameEnc:  frameEnc,
 		player:    player,
+		resolver:  resolver,
+		presets:   preset.Builtin(),
 		logger:    logger,
 	}
 }
 
+// WithConfig returns a copy of the service that uses cfg in place of the
+// configuration it was constructed with. All dependencies are shared with
+// the original, so the copy is cheap enough to create per request.
+func (s *Service) WithConfig(cfg config.Config) *Service {
+	c := *s
+	c.cfg = cfg
+	return &c
+}
+
+// WithPresets returns a copy of the service that resolves preset names in
+// reg, which typically comes from config.LoadPresets. A nil reg restores the
+// built-in presets.
+func (s *Service) WithPresets(reg *preset.Registry) *Service {
+	if reg == nil {
+		reg = preset.Builtin()
+	}
+	c := *s
+	c.presets = reg
+	return &c
+}
+
+// WithParamsHook returns a copy of the service that calls fn with the resolved
+// parameters of every scream just before it is generated. The CLI uses it to
+// report the seed of random screams. A nil fn removes the hook.
+func (s *Service) WithParamsHook(fn func(audio.ScreamParams)) *Service {
+	c := *s
+	c.onParams = fn
+	return &c
+}
+
+// Presets returns the preset registry the service resolves preset names in.
+func (s *Service) Presets() *preset.Registry {
+	return s.presets
+}
+
 // generatePCM resolves audio parameters from the service config and calls the
 // generator to produce raw PCM. It is the shared preamble for Play and Generate.
-func (s *Service) generatePCM() (io.Reader, audio.ScreamParams, error) {
+func (s *Service) generatePCM(ctx context.Context) (io.Reader, audio.ScreamParams, error) {
 	s.logger.Debug("resolving audio params", "preset", s.cfg.Preset, "duration", s.cfg.Duration, "volume", s.cfg.Volume)
 
-	params, err := resolveParams(s.cfg)
+	params, err := ResolveParams(s.cfg, s.presets)
 	if err != nil {
 		return nil, audio.ScreamParams{}, err
 	}
 
+	switch {
+	case s.cfg.Text != "":
+		s.logger.Info("text scream", "text", s.cfg.Text, "seed", params.Seed, "params", paramsValue(params))
+	case s.cfg.Preset == "":
+		s.logger.Info("randomized scream", "seed", params.Seed, "params", paramsValue(params))
+	}
+	if s.onParams != nil {
+		s.onParams(params)
+	}
+
 	s.logger.Debug("generating audio")
 
-	pcm, err := s.generator.Generate(params)
+	pcm, err := s.generator.Generate(ctx, params)
+	if errors.Is(err, audio.ErrCancelled) {
+		return nil, audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrCancelled, err)
+	}
 	if err != nil {
 		return nil, audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrGenerateFailed, err)
 	}
 
+	if s.cfg.TargetLUFS != 0 {
+		pcm, err = s.normalize(pcm, params)
+		if errors.Is(err, audio.ErrCancelled) {
+			return nil, audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrCancelled, err)
+		}
+		if err != nil {
+			return nil, audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrGenerateFailed, err)
+		}
+	}
+
 	return pcm, params, nil
 }
 
+// normalize reads all of pcm and scales it to the loudness target and
+// true-peak ceiling of the service config with audio.Normalize. The whole
+// scream must be generated before its loudness is known, so the first frame
+// is only available once generation completes.
+func (s *Service) normalize(pcm io.Reader, params audio.ScreamParams) (io.Reader, error) {
+	data, err := io.ReadAll(pcm)
+	if err != nil {
+		return nil, err
+	}
+
+	measured, gainDB := audio.Normalize(data, params.SampleRate, params.Channels, s.cfg.TargetLUFS, s.cfg.TruePeak)
+	s.logger.Debug("normalized loudness",
+		"integrated_lufs", measured.Integrated,
+		"true_peak_dbtp", measured.TruePeak,
+		"target_lufs", s.cfg.TargetLUFS,
+		"ceiling_dbtp", s.cfg.TruePeak,
+		"gain_db", gainDB,
+	)
+	return bytes.NewReader(data), nil
+}
+
+// ResolveChannel picks a voice channel in guildID using the channel strategy
+// from the service config. It returns ErrNoResolver if the service has no
+// ChannelResolver, or an error wrapping ErrChannelResolveFailed if no channel
+// matches the strategy.
+func (s *Service) ResolveChannel(guildID string) (discord.ChannelSelection, error) {
+	if guildID == "" {
+		return discord.ChannelSelection{}, config.ErrMissingGuildID
+	}
+	if s.resolver == nil {
+		return discord.ChannelSelection{}, ErrNoResolver
+	}
+
+	sel, err := s.resolver.ResolveChannel(guildID, channelQuery(s.cfg))
+	if err != nil {
+		return discord.ChannelSelection{}, fmt.Errorf("%w: %w", ErrChannelResolveFailed, err)
+	}
+
+	s.logger.Info("resolved voice channel", "guild", guildID, "channel", sel.ChannelID, "strategy", sel.Strategy)
+	return sel, nil
+}
+
 // Play generates a scream and streams it to the specified Discord voice channel.
 // It validates guildID, checks for a configured player (unless DryRun is set),
-// and checks for a pre-cancelled context before proceeding.
+// and checks for a pre-cancelled context before proceeding. When channelID is
+// empty and DryRun is not set, the channel is chosen with ResolveChannel.
+// If ctx is cancelled at any point, generation and playback stop and the
+// returned error wraps ErrCancelled.
 func (s *Service) Play(ctx context.Context, guildID, channelID string) error {
 	if guildID == "" {
 		return config.ErrMissingGuildID
@@ -77,11 +193,19 @@ func (s *Service) Play(ctx context.Context, guildID, channelID string) error {
 		return ErrNoPlayer
 	}
 
-	if err := ctx.Err(); err != nil {
+	if err := checkCancelled(ctx); err != nil {
 		return err
 	}
 
-	pcm, params, err := s.generatePCM()
+	if channelID == "" && !s.cfg.DryRun {
+		sel, err := s.ResolveChannel(guildID)
+		if err != nil {
+			return err
+		}
+		channelID = sel.ChannelID
+	}
+
+	pcm, params, err := s.generatePCM(ctx)
 	if err != nil {
 		return err
 	}
@@ -96,6 +220,9 @@ func (s *Service) Play(ctx context.Context, guildID, channelID string) error {
 		}
 		encErr := <-errCh
 		if encErr != nil {
+			if err := checkCancelled(ctx); err != nil {
+				return err
+			}
 			return fmt.Errorf("%w: %w", ErrEncodeFailed, encErr)
 		}
 		return nil
@@ -106,6 +233,11 @@ func (s *Service) Play(ctx context.Context, guildID, channelID string) error {
 	playErr := s.player.Play(ctx, guildID, channelID, frameCh)
 	encErr := <-errCh
 
+	if playErr != nil || encErr != nil {
+		if err := checkCancelled(ctx); err != nil {
+			return err
+		}
+	}
 	if playErr != nil {
 		return fmt.Errorf("%w: %w", ErrPlayFailed, playErr)
 	}
@@ -117,13 +249,14 @@ func (s *Service) Play(ctx context.Context, guildID, channelID string) error {
 }
 
 // Generate creates a scream and writes it to dst using the configured file encoder.
-// It does not require a Discord token or player.
+// It does not require a Discord token or player. If ctx is cancelled before
+// encoding completes, the returned error wraps ErrCancelled.
 func (s *Service) Generate(ctx context.Context, dst io.Writer) error {
-	if err := ctx.Err(); err != nil {
+	if err := checkCancelled(ctx); err != nil {
 		return err
 	}
 
-	pcm, params, err := s.generatePCM()
+	pcm, params, err := s.generatePCM(ctx)
 	if err != nil {
 		return err
 	}
@@ -131,54 +264,182 @@ func (s *Service) Generate(ctx context.Context, dst io.Writer) error {
 	s.logger.Debug("encoding to file")
 
 	if err := s.fileEnc.Encode(dst, pcm, params.SampleRate, params.Channels); err != nil {
+		if cerr := checkCancelled(ctx); cerr != nil {
+			return cerr
+		}
 		return fmt.Errorf("%w: %w", ErrEncodeFailed, err)
 	}
 
 	return nil
 }
 
-// ListPresets returns the names of all available scream presets.
-func ListPresets() []string {
-	names := audio.AllPresets()
-	result := make([]string, len(names))
-	for i, n := range names {
-		result[i] = string(n)
+// checkCancelled returns an error wrapping ErrCancelled and ctx.Err() if ctx
+// is done, or nil otherwise.
+func checkCancelled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrCancelled, err)
+	}
+	return nil
+}
+
+// ListPresets returns the names of all presets in reg: the built-in presets
+// first, then user presets in alphabetical order. A nil reg lists only the
+// built-in presets.
+func ListPresets(reg *preset.Registry) []string {
+	if reg == nil {
+		reg = preset.Builtin()
 	}
-	return result
+	return reg.Names()
 }
 
-// resolveParams derives audio.ScreamParams from the provided Config.
-// If cfg.Preset is set, it looks up the named preset and returns an error
-// if the name is unknown. If cfg.Preset is empty, Randomize is used to
-// generate random parameters. In either case, a positive cfg.Duration
-// overrides the duration from the preset or random params.
+// channelQuery builds the discord.ChannelQuery described by cfg.
+func channelQuery(cfg config.Config) discord.ChannelQuery {
+	return discord.ChannelQuery{
+		Strategy:  cfg.ChannelStrategy,
+		UserID:    cfg.ChannelUserID,
+		Preferred: cfg.PreferredChannels,
+	}
+}
+
+// paramsValue is a slog.LogValuer that logs params as a single line of JSON,
+// the same form accepted as a user preset.
+type paramsValue audio.ScreamParams
+
+// LogValue implements slog.LogValuer.
+func (p paramsValue) LogValue() slog.Value {
+	data, err := json.Marshal(audio.ScreamParams(p))
+	if err != nil {
+		return slog.StringValue(err.Error())
+	}
+	return slog.StringValue(string(data))
+}
+
+// ResolveParams derives audio.ScreamParams from the provided Config. These are
+// exactly the parameters Play and Generate pass to the generator.
+// If cfg.Text is set, audio.FromText makes a scream that sounds it out, with
+// its pitch drawn from cfg.Seed, and cfg.Preset is ignored; text that
+// FromText rejects returns an error wrapping ErrInvalidText. Otherwise, if
+// cfg.Preset is set, it looks up the named preset in presets (the built-in
+// presets if presets is nil) and returns an error if the name is unknown. If
+// cfg.Preset is empty, Randomize is used to generate random parameters from
+// cfg.Seed (a time-based seed when it is zero), and the seed used is recorded
+// in ScreamParams.Seed. A non-zero cfg.Seed also replaces a preset's global
+// Seed, which produces a deterministic variation of the preset.
+//
+// If cfg.MorphTo names a second preset, a non-zero cfg.Mix blends the two with
+// audio.Interpolate; otherwise the second preset becomes the MorphTo of the
+// result, so the scream morphs into it over its duration. A non-zero
+// cfg.Voices or cfg.Spread replaces that of the params, and the presets named
+// by cfg.VoicePresets become their VoiceMix, seeded like MorphTo. A positive
+// cfg.Duration then overrides the duration from the preset or random params,
+// but not that of a text scream, which follows the text. In every case,
+// cfg.Overrides are applied last; an override that fails or yields
+// invalid parameters returns an error wrapping ErrInvalidOverride.
 //
 // cfg.Volume is a linear multiplier where 1.0 means no change. It is
 // converted to decibels and applied as an offset to FilterParams.VolumeBoostDB
 // so that the existing preset/random boost is scaled by the user's intent.
-func resolveParams(cfg config.Config) (audio.ScreamParams, error) {
+func ResolveParams(cfg config.Config, presets *preset.Registry) (audio.ScreamParams, error) {
+	if presets == nil {
+		presets = preset.Builtin()
+	}
 	var params audio.ScreamParams
 
-	if cfg.Preset != "" {
-		p, ok := audio.GetPreset(audio.PresetName(cfg.Preset))
+	switch {
+	case cfg.Text != "":
+		p, err := audio.FromText(cfg.Text, cfg.Seed)
+		if err != nil {
+			return audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrInvalidText, err)
+		}
+		params = p
+	case cfg.Preset != "":
+		p, ok := presets.Get(cfg.Preset)
 		if !ok {
 			return audio.ScreamParams{}, ErrUnknownPreset
 		}
 		params = p
-	} else {
-		params = audio.Randomize(0)
+		if cfg.Seed != 0 {
+			params.Seed = cfg.Seed
+		}
+	default:
+		params = audio.Randomize(cfg.Seed)
 	}
 
-	if cfg.Duration > 0 {
+	if cfg.MorphTo != "" {
+		target, ok := presets.Get(cfg.MorphTo)
+		if !ok {
+			return audio.ScreamParams{}, ErrUnknownPreset
+		}
+		if cfg.Seed != 0 {
+			target.Seed = cfg.Seed
+		}
+		if cfg.Mix > 0 {
+			params = audio.Interpolate(params, target, cfg.Mix)
+		} else {
+			end := target.MorphStart()
+			params.MorphTo = &end
+		}
+	}
+
+	if cfg.Voices != 0 {
+		params.Voices = cfg.Voices
+	}
+	if cfg.Spread != 0 {
+		params.Spread = cfg.Spread
+	}
+	if len(cfg.VoicePresets) > 0 {
+		mix := make([]audio.ScreamParams, len(cfg.VoicePresets))
+		for i, name := range cfg.VoicePresets {
+			voice, ok := presets.Get(name)
+			if !ok {
+				return audio.ScreamParams{}, ErrUnknownPreset
+			}
+			if cfg.Seed != 0 {
+				voice.Seed = cfg.Seed
+			}
+			mix[i] = voice
+		}
+		params.VoiceMix = mix
+	}
+
+	if cfg.Duration > 0 && cfg.Text == "" {
 		params.Duration = cfg.Duration
 	}
 
+	if len(cfg.Overrides) > 0 {
+		overrides, err := preset.ParseOverrides(cfg.Overrides)
+		if err != nil {
+			return audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+		}
+		if params, err = preset.Apply(params, overrides...); err != nil {
+			return audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+		}
+		if err := params.Validate(); err != nil {
+			return audio.ScreamParams{}, fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+		}
+	}
+
 	// Apply volume: cfg.Volume is a linear multiplier (1.0 = no change).
 	// Convert to dB and add to the existing VolumeBoostDB so that the preset
 	// or randomized boost is offset by the user's intent. When Volume == 1.0,
 	// log10(1.0) == 0, so this is a no-op and remains backward-compatible.
 	if cfg.Volume > 0 {
-		params.Filter.VolumeBoostDB += 20 * math.Log10(cfg.Volume)
+		gainDB := 20 * math.Log10(cfg.Volume)
+		params.Filter.VolumeBoostDB += gainDB
+		if params.MorphTo != nil {
+			// Copy the target so a preset's own MorphTo is not modified.
+			end := *params.MorphTo
+			end.Filter.VolumeBoostDB += gainDB
+			params.MorphTo = &end
+		}
+		// The voice mix is a copy already: presets.Get clones it.
+		for i := range params.VoiceMix {
+			voice := &params.VoiceMix[i]
+			voice.Filter.VolumeBoostDB += gainDB
+			if voice.MorphTo != nil {
+				voice.MorphTo.Filter.VolumeBoostDB += gainDB
+			}
+		}
 	}
 
 	return params, nil
@@ -4,17 +4,23 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"math"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/JamesPrial/go-scream/internal/audio"
+	"github.com/JamesPrial/go-scream/internal/audio/native"
 	"github.com/JamesPrial/go-scream/internal/config"
 	"github.com/JamesPrial/go-scream/internal/discord"
+	"github.com/JamesPrial/go-scream/internal/preset"
 )
 
 var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
@@ -27,15 +33,17 @@ var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
 type mockGenerator struct {
 	mu         sync.Mutex
 	callCount  int
+	lastCtx    context.Context
 	lastParams audio.ScreamParams
 	reader     io.Reader
 	err        error
 }
 
-func (m *mockGenerator) Generate(params audio.ScreamParams) (io.Reader, error) {
+func (m *mockGenerator) Generate(ctx context.Context, params audio.ScreamParams) (io.Reader, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.callCount++
+	m.lastCtx = ctx
 	m.lastParams = params
 	if m.err != nil {
 		return nil, m.err
@@ -168,10 +176,50 @@ func (m *mockPlayer) called() int {
 	return m.callCount
 }
 
+// fakeSession implements discord.Session for channel resolution tests. Voice
+// joins are never expected because playback goes through mockPlayer.
+type fakeSession struct {
+	botUserID   string
+	voiceStates []*discord.VoiceState
+	stateErr    error
+}
+
+func (f *fakeSession) ChannelVoiceJoin(guildID, channelID string, mute, deaf bool) (discord.VoiceConn, error) {
+	return nil, errors.New("fakeSession: voice join not supported")
+}
+
+func (f *fakeSession) GuildVoiceStates(guildID string) ([]*discord.VoiceState, error) {
+	if f.stateErr != nil {
+		return nil, f.stateErr
+	}
+	return f.voiceStates, nil
+}
+
+func (f *fakeSession) BotUserID() string { return f.botUserID }
+
 // ---------------------------------------------------------------------------
 // Test helpers
 // ---------------------------------------------------------------------------
 
+// newResolvingService creates a Service whose channel resolver is a real
+// discord.ChannelFinder backed by sess.
+func newResolvingService(cfg config.Config, sess discord.Session, gen *mockGenerator, pl *mockPlayer) *Service {
+	finder := discord.NewChannelFinder(sess, discardLogger)
+	return NewServiceWithDeps(cfg, gen, &mockFileEncoder{}, &mockFrameEncoder{}, pl, finder, discardLogger)
+}
+
+// guildVoiceStates returns a guild where the bot sits alone in c0, one user
+// is in c1, two users are in c2, and user "u4" is in c3.
+func guildVoiceStates() []*discord.VoiceState {
+	return []*discord.VoiceState{
+		{UserID: "bot", ChannelID: "c0", GuildID: "guild-123"},
+		{UserID: "u1", ChannelID: "c1", GuildID: "guild-123"},
+		{UserID: "u2", ChannelID: "c2", GuildID: "guild-123"},
+		{UserID: "u3", ChannelID: "c2", GuildID: "guild-123"},
+		{UserID: "u4", ChannelID: "c3", GuildID: "guild-123"},
+	}
+}
+
 // validPlayConfig returns a config suitable for Play() tests.
 func validPlayConfig() config.Config {
 	return config.Config{
@@ -199,7 +247,7 @@ func validGenerateConfig() config.Config {
 
 // newTestService creates a Service with all mocks wired in.
 func newTestService(cfg config.Config, gen *mockGenerator, fEnc *mockFileEncoder, frEnc *mockFrameEncoder, pl *mockPlayer) *Service {
-	return NewServiceWithDeps(cfg, gen, fEnc, frEnc, pl, discardLogger)
+	return NewServiceWithDeps(cfg, gen, fEnc, frEnc, pl, nil, discardLogger)
 }
 
 // ---------------------------------------------------------------------------
@@ -213,7 +261,7 @@ func Test_NewServiceWithDeps_ReturnsNonNil(t *testing.T) {
 	pl := &mockPlayer{}
 	cfg := validPlayConfig()
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, pl, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, pl, nil, discardLogger)
 
 	if svc == nil {
 		t.Fatal("NewServiceWithDeps returned nil")
@@ -226,7 +274,7 @@ func Test_NewServiceWithDeps_NilPlayer(t *testing.T) {
 	frEnc := &mockFrameEncoder{}
 	cfg := validPlayConfig()
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	if svc == nil {
 		t.Fatal("NewServiceWithDeps returned nil even with nil player")
@@ -241,7 +289,7 @@ func Test_NewServiceWithDeps_StoresConfig(t *testing.T) {
 	cfg := validPlayConfig()
 	cfg.Preset = "whisper"
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, pl, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, pl, nil, discardLogger)
 
 	if svc == nil {
 		t.Fatal("NewServiceWithDeps returned nil")
@@ -363,7 +411,7 @@ func Test_Play_Validation(t *testing.T) {
 			frEnc := &mockFrameEncoder{}
 			cfg := validPlayConfig()
 
-			svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, tt.player, discardLogger)
+			svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, tt.player, nil, discardLogger)
 
 			err := svc.Play(context.Background(), tt.guildID, tt.channelID)
 			if err == nil {
@@ -452,7 +500,7 @@ func Test_Play_DryRun_NilPlayerOK(t *testing.T) {
 	cfg.DryRun = true
 
 	// nil player should not cause an error in dry run mode.
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	err := svc.Play(context.Background(), "guild-123", "chan-456")
 	if err != nil {
@@ -479,6 +527,9 @@ func Test_Play_ContextCancelled(t *testing.T) {
 	if !errors.Is(err, context.Canceled) {
 		t.Errorf("Play() error = %v, want wrapping context.Canceled", err)
 	}
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("Play() error = %v, want wrapping ErrCancelled", err)
+	}
 }
 
 func Test_Play_UnknownPreset(t *testing.T) {
@@ -501,7 +552,7 @@ func Test_Play_UnknownPreset(t *testing.T) {
 }
 
 func Test_Play_MultiplePresets(t *testing.T) {
-	presets := []string{"classic", "whisper", "death-metal", "glitch", "banshee", "robot"}
+	presets := []string{"classic", "whisper", "death-metal", "glitch", "banshee", "robot", "wail"}
 
 	for _, preset := range presets {
 		t.Run(preset, func(t *testing.T) {
@@ -533,7 +584,7 @@ func Test_Generate_HappyPath_OGG(t *testing.T) {
 	cfg := validGenerateConfig()
 	cfg.Format = config.FormatOGG
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -556,7 +607,7 @@ func Test_Generate_HappyPath_WAV(t *testing.T) {
 	cfg := validGenerateConfig()
 	cfg.Format = config.FormatWAV
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -579,7 +630,7 @@ func Test_Generate_NoTokenRequired(t *testing.T) {
 	cfg := validGenerateConfig()
 	cfg.Token = "" // No token needed for generate-only mode.
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -595,7 +646,7 @@ func Test_Generate_GeneratorError(t *testing.T) {
 	frEnc := &mockFrameEncoder{}
 	cfg := validGenerateConfig()
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -618,7 +669,7 @@ func Test_Generate_FileEncoderError(t *testing.T) {
 	frEnc := &mockFrameEncoder{}
 	cfg := validGenerateConfig()
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -637,7 +688,7 @@ func Test_Generate_UnknownPreset(t *testing.T) {
 	cfg := validGenerateConfig()
 	cfg.Preset = "bogus-preset"
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -656,7 +707,7 @@ func Test_Generate_PlayerNotInvoked(t *testing.T) {
 	pl := &mockPlayer{}
 	cfg := validGenerateConfig()
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, pl, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, pl, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -671,20 +722,20 @@ func Test_Generate_PlayerNotInvoked(t *testing.T) {
 }
 
 // ---------------------------------------------------------------------------
-// ListPresets() tests
+// ListPresets tests
 // ---------------------------------------------------------------------------
 
 func Test_ListPresets_ReturnsAllPresets(t *testing.T) {
-	presets := ListPresets()
+	presets := ListPresets(nil)
 
-	if len(presets) != 6 {
-		t.Fatalf("ListPresets() returned %d presets, want 6", len(presets))
+	if len(presets) != 7 {
+		t.Fatalf("ListPresets(nil) returned %d presets, want 7", len(presets))
 	}
 }
 
 func Test_ListPresets_ContainsExpectedNames(t *testing.T) {
-	expected := []string{"classic", "whisper", "death-metal", "glitch", "banshee", "robot"}
-	presets := ListPresets()
+	expected := []string{"classic", "whisper", "death-metal", "glitch", "banshee", "robot", "wail"}
+	presets := ListPresets(nil)
 
 	presetSet := make(map[string]bool)
 	for _, p := range presets {
@@ -693,39 +744,39 @@ func Test_ListPresets_ContainsExpectedNames(t *testing.T) {
 
 	for _, name := range expected {
 		if !presetSet[name] {
-			t.Errorf("ListPresets() missing expected preset %q", name)
+			t.Errorf("ListPresets(nil) missing expected preset %q", name)
 		}
 	}
 }
 
 func Test_ListPresets_NoDuplicates(t *testing.T) {
-	presets := ListPresets()
+	presets := ListPresets(nil)
 
 	seen := make(map[string]bool)
 	for _, p := range presets {
 		if seen[p] {
-			t.Errorf("ListPresets() returned duplicate preset %q", p)
+			t.Errorf("ListPresets(nil) returned duplicate preset %q", p)
 		}
 		seen[p] = true
 	}
 }
 
 func Test_ListPresets_Deterministic(t *testing.T) {
-	first := ListPresets()
-	second := ListPresets()
+	first := ListPresets(nil)
+	second := ListPresets(nil)
 
 	if len(first) != len(second) {
-		t.Fatalf("ListPresets() non-deterministic: first=%d, second=%d", len(first), len(second))
+		t.Fatalf("ListPresets(nil) non-deterministic: first=%d, second=%d", len(first), len(second))
 	}
 	for i := range first {
 		if first[i] != second[i] {
-			t.Errorf("ListPresets()[%d] = %q then %q; not deterministic", i, first[i], second[i])
+			t.Errorf("ListPresets(nil)[%d] = %q then %q; not deterministic", i, first[i], second[i])
 		}
 	}
 }
 
 // ---------------------------------------------------------------------------
-// resolveParams (tested indirectly through Play/Generate behavior)
+// ResolveParams
 // ---------------------------------------------------------------------------
 
 func Test_ResolveParams_PresetOverridesDuration(t *testing.T) {
@@ -776,8 +827,94 @@ func Test_ResolveParams_EmptyPresetUsesRandomize(t *testing.T) {
 	}
 }
 
+func Test_ResolveParams_Overrides(t *testing.T) {
+	gen := &mockGenerator{}
+	cfg := validGenerateConfig()
+	cfg.Preset = "banshee"
+	cfg.Duration = 3 * time.Second
+	cfg.Overrides = []string{
+		"filter.lowpass_cutoff=4000",
+		"layers[2].base_freq=400",
+		"duration=5s",
+	}
+
+	svc := newTestService(cfg, gen, &mockFileEncoder{}, &mockFrameEncoder{}, &mockPlayer{})
+	if err := svc.Generate(context.Background(), &bytes.Buffer{}); err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	want, _ := audio.GetPreset(audio.PresetBanshee)
+	want.Filter.LowpassCutoff = 4000
+	want.Layers[2].BaseFreq = 400
+	want.Duration = 5 * time.Second
+	if got := gen.params(); !reflect.DeepEqual(got, want) {
+		t.Errorf("generator params:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func Test_ResolveParams_InvalidOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []string
+	}{
+		{"unknown path", []string{"filter.cutoff=1"}},
+		{"wrong type", []string{"filter.crusher_bits=lots"}},
+		{"invalid result", []string{"filter.crusher_bits=99"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &mockGenerator{}
+			cfg := validGenerateConfig()
+			cfg.Overrides = tt.overrides
+
+			svc := newTestService(cfg, gen, &mockFileEncoder{}, &mockFrameEncoder{}, &mockPlayer{})
+			err := svc.Generate(context.Background(), &bytes.Buffer{})
+			if !errors.Is(err, ErrInvalidOverride) {
+				t.Errorf("Generate() error = %v, want ErrInvalidOverride", err)
+			}
+			if gen.called() != 0 {
+				t.Errorf("generator called %d times, want 0", gen.called())
+			}
+		})
+	}
+}
+
+func Test_ResolveParams_NilRegistryUsesBuiltins(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "robot"
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	want, _ := audio.GetPreset(audio.PresetRobot)
+	want.Duration = cfg.Duration
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveParams() = %+v, want robot with the configured duration", got)
+	}
+}
+
+func Test_ResolveParams_RandomRecordsSeed(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = ""
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	if got.Seed == 0 {
+		t.Fatal("random params have a zero Seed")
+	}
+	want := audio.Randomize(got.Seed)
+	want.Duration = cfg.Duration
+	if !reflect.DeepEqual(got, want) {
+		t.Error("random params differ from audio.Randomize(Seed)")
+	}
+}
+
 // ---------------------------------------------------------------------------
-// resolveParams: Config.Volume applied to VolumeBoostDB (Stage 6 bug fix)
+// ResolveParams: Config.Volume applied to VolumeBoostDB (Stage 6 bug fix)
 // ---------------------------------------------------------------------------
 
 func Test_ResolveParams_VolumeApplied(t *testing.T) {
@@ -870,7 +1007,7 @@ func Test_ResolveParams_VolumeApplied_Generate(t *testing.T) {
 	cfg.Preset = "classic"
 	cfg.Volume = 0.5
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -921,6 +1058,190 @@ func Test_ResolveParams_VolumeZero_NoChange(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Channel resolution tests
+// ---------------------------------------------------------------------------
+
+func Test_Play_EmptyChannel_ResolvesWithStrategy(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  discord.ChannelStrategy
+		userID    string
+		preferred []string
+		wantChan  string
+	}{
+		{name: "default first populated", strategy: "", wantChan: "c1"},
+		{name: "first populated", strategy: discord.StrategyFirstPopulated, wantChan: "c1"},
+		{name: "most populated", strategy: discord.StrategyMostPopulated, wantChan: "c2"},
+		{name: "user", strategy: discord.StrategyUser, userID: "u4", wantChan: "c3"},
+		{name: "preferred", strategy: discord.StrategyPreferred, preferred: []string{"c0", "c3", "c1"}, wantChan: "c3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validPlayConfig()
+			cfg.ChannelStrategy = tt.strategy
+			cfg.ChannelUserID = tt.userID
+			cfg.PreferredChannels = tt.preferred
+			sess := &fakeSession{botUserID: "bot", voiceStates: guildVoiceStates()}
+			pl := &mockPlayer{}
+
+			svc := newResolvingService(cfg, sess, &mockGenerator{}, pl)
+
+			if err := svc.Play(context.Background(), "guild-123", ""); err != nil {
+				t.Fatalf("Play() unexpected error: %v", err)
+			}
+
+			pl.mu.Lock()
+			defer pl.mu.Unlock()
+			if pl.lastChan != tt.wantChan {
+				t.Errorf("player channelID = %q, want %q", pl.lastChan, tt.wantChan)
+			}
+		})
+	}
+}
+
+func Test_Play_EmptyChannel_SkipsBotOwnChannel(t *testing.T) {
+	cfg := validPlayConfig()
+	sess := &fakeSession{
+		botUserID: "self",
+		voiceStates: []*discord.VoiceState{
+			{UserID: "self", ChannelID: "c-bot", GuildID: "guild-123"},
+			{UserID: "u1", ChannelID: "c-user", GuildID: "guild-123"},
+		},
+	}
+	pl := &mockPlayer{}
+
+	svc := newResolvingService(cfg, sess, &mockGenerator{}, pl)
+
+	if err := svc.Play(context.Background(), "guild-123", ""); err != nil {
+		t.Fatalf("Play() unexpected error: %v", err)
+	}
+
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if pl.lastChan != "c-user" {
+		t.Errorf("player channelID = %q, want %q", pl.lastChan, "c-user")
+	}
+}
+
+func Test_Play_ExplicitChannel_BypassesResolver(t *testing.T) {
+	cfg := validPlayConfig()
+	sess := &fakeSession{stateErr: errors.New("state must not be queried")}
+	pl := &mockPlayer{}
+
+	svc := newResolvingService(cfg, sess, &mockGenerator{}, pl)
+
+	if err := svc.Play(context.Background(), "guild-123", "explicit"); err != nil {
+		t.Fatalf("Play() unexpected error: %v", err)
+	}
+
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if pl.lastChan != "explicit" {
+		t.Errorf("player channelID = %q, want %q", pl.lastChan, "explicit")
+	}
+}
+
+func Test_Play_EmptyChannel_NoPopulatedChannel(t *testing.T) {
+	cfg := validPlayConfig()
+	sess := &fakeSession{
+		botUserID:   "bot",
+		voiceStates: []*discord.VoiceState{{UserID: "bot", ChannelID: "c0", GuildID: "guild-123"}},
+	}
+	gen := &mockGenerator{}
+	pl := &mockPlayer{}
+
+	svc := newResolvingService(cfg, sess, gen, pl)
+
+	err := svc.Play(context.Background(), "guild-123", "")
+	if !errors.Is(err, ErrChannelResolveFailed) {
+		t.Errorf("Play() error = %v, want wrapping ErrChannelResolveFailed", err)
+	}
+	if !errors.Is(err, discord.ErrNoPopulatedChannel) {
+		t.Errorf("Play() error = %v, want wrapping discord.ErrNoPopulatedChannel", err)
+	}
+	if gen.called() != 0 {
+		t.Errorf("generator called %d times, want 0 when no channel resolves", gen.called())
+	}
+	if pl.called() != 0 {
+		t.Errorf("player called %d times, want 0 when no channel resolves", pl.called())
+	}
+}
+
+func Test_Play_EmptyChannel_UserNotInVoice(t *testing.T) {
+	cfg := validPlayConfig()
+	cfg.ChannelStrategy = discord.StrategyUser
+	cfg.ChannelUserID = "ghost"
+	sess := &fakeSession{botUserID: "bot", voiceStates: guildVoiceStates()}
+
+	svc := newResolvingService(cfg, sess, &mockGenerator{}, &mockPlayer{})
+
+	err := svc.Play(context.Background(), "guild-123", "")
+	if !errors.Is(err, discord.ErrUserNotInVoice) {
+		t.Errorf("Play() error = %v, want wrapping discord.ErrUserNotInVoice", err)
+	}
+}
+
+func Test_Play_EmptyChannel_NilResolver(t *testing.T) {
+	svc := newTestService(validPlayConfig(), &mockGenerator{}, &mockFileEncoder{}, &mockFrameEncoder{}, &mockPlayer{})
+
+	err := svc.Play(context.Background(), "guild-123", "")
+	if !errors.Is(err, ErrNoResolver) {
+		t.Errorf("Play() error = %v, want ErrNoResolver", err)
+	}
+}
+
+func Test_Play_DryRun_EmptyChannelSkipsResolution(t *testing.T) {
+	cfg := validPlayConfig()
+	cfg.DryRun = true
+
+	svc := NewServiceWithDeps(cfg, &mockGenerator{}, &mockFileEncoder{}, &mockFrameEncoder{}, nil, nil, discardLogger)
+
+	if err := svc.Play(context.Background(), "guild-123", ""); err != nil {
+		t.Errorf("Play() dry-run with empty channel unexpected error: %v", err)
+	}
+}
+
+func Test_ResolveChannel_ReportsStrategy(t *testing.T) {
+	cfg := validPlayConfig()
+	cfg.ChannelStrategy = discord.StrategyMostPopulated
+	sess := &fakeSession{botUserID: "bot", voiceStates: guildVoiceStates()}
+
+	svc := newResolvingService(cfg, sess, &mockGenerator{}, &mockPlayer{})
+
+	sel, err := svc.ResolveChannel("guild-123")
+	if err != nil {
+		t.Fatalf("ResolveChannel() unexpected error: %v", err)
+	}
+	if sel.ChannelID != "c2" {
+		t.Errorf("ResolveChannel().ChannelID = %q, want %q", sel.ChannelID, "c2")
+	}
+	if sel.Strategy != discord.StrategyMostPopulated {
+		t.Errorf("ResolveChannel().Strategy = %q, want %q", sel.Strategy, discord.StrategyMostPopulated)
+	}
+}
+
+func Test_ResolveChannel_EmptyGuild(t *testing.T) {
+	sess := &fakeSession{botUserID: "bot", voiceStates: guildVoiceStates()}
+	svc := newResolvingService(validPlayConfig(), sess, &mockGenerator{}, &mockPlayer{})
+
+	_, err := svc.ResolveChannel("")
+	if !errors.Is(err, config.ErrMissingGuildID) {
+		t.Errorf("ResolveChannel() error = %v, want config.ErrMissingGuildID", err)
+	}
+}
+
+func Test_ResolveChannel_StateError(t *testing.T) {
+	sess := &fakeSession{stateErr: errors.New("gateway down")}
+	svc := newResolvingService(validPlayConfig(), sess, &mockGenerator{}, &mockPlayer{})
+
+	_, err := svc.ResolveChannel("guild-123")
+	if !errors.Is(err, discord.ErrGuildStateFailed) {
+		t.Errorf("ResolveChannel() error = %v, want wrapping discord.ErrGuildStateFailed", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Sentinel error existence tests
 // ---------------------------------------------------------------------------
@@ -936,6 +1257,9 @@ func Test_SentinelErrors_Exist(t *testing.T) {
 		{"ErrGenerateFailed", ErrGenerateFailed, "scream:"},
 		{"ErrEncodeFailed", ErrEncodeFailed, "scream:"},
 		{"ErrPlayFailed", ErrPlayFailed, "scream:"},
+		{"ErrNoResolver", ErrNoResolver, "scream:"},
+		{"ErrChannelResolveFailed", ErrChannelResolveFailed, "scream:"},
+		{"ErrCancelled", ErrCancelled, "scream:"},
 	}
 
 	for _, tt := range tests {
@@ -1000,7 +1324,7 @@ func Test_Generate_GeneratorError_WrapsOriginal(t *testing.T) {
 	frEnc := &mockFrameEncoder{}
 	cfg := validGenerateConfig()
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -1019,7 +1343,7 @@ func Test_Generate_EncoderError_WrapsOriginal(t *testing.T) {
 	frEnc := &mockFrameEncoder{}
 	cfg := validGenerateConfig()
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 
 	var buf bytes.Buffer
 	err := svc.Generate(context.Background(), &buf)
@@ -1057,7 +1381,7 @@ func Benchmark_Generate_HappyPath(b *testing.B) {
 	frEnc := &mockFrameEncoder{}
 	cfg := validGenerateConfig()
 
-	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, discardLogger)
+	svc := NewServiceWithDeps(cfg, gen, fEnc, frEnc, nil, nil, discardLogger)
 	ctx := context.Background()
 
 	b.ResetTimer()
@@ -1069,6 +1393,637 @@ func Benchmark_Generate_HappyPath(b *testing.B) {
 
 func Benchmark_ListPresets(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		_ = ListPresets()
+		_ = ListPresets(nil)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// WithConfig tests
+// ---------------------------------------------------------------------------
+
+func Test_WithConfig_UsesNewConfigAndSharesDeps(t *testing.T) {
+	gen := &mockGenerator{}
+	pl := &mockPlayer{}
+	base := newTestService(validPlayConfig(), gen, &mockFileEncoder{}, &mockFrameEncoder{}, pl)
+
+	cfg := validPlayConfig()
+	cfg.Preset = string(audio.PresetWhisper)
+	svc := base.WithConfig(cfg)
+
+	if err := svc.Play(context.Background(), "guild-123", "chan-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want, _ := audio.GetPreset(audio.PresetWhisper)
+	if !reflect.DeepEqual(gen.params().Layers, want.Layers) {
+		t.Error("expected WithConfig copy to use the whisper preset")
+	}
+	if pl.called() != 1 {
+		t.Errorf("player called %d times, want 1", pl.called())
+	}
+	if base.cfg.Preset != "classic" {
+		t.Errorf("original service preset = %q, want classic", base.cfg.Preset)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// WithParamsHook tests
+// ---------------------------------------------------------------------------
+
+func Test_WithParamsHook_ReceivesGeneratedParams(t *testing.T) {
+	gen := &mockGenerator{}
+	var hooked []audio.ScreamParams
+	base := newTestService(validPlayConfig(), gen, &mockFileEncoder{}, &mockFrameEncoder{}, &mockPlayer{})
+	svc := base.WithParamsHook(func(p audio.ScreamParams) { hooked = append(hooked, p) })
+
+	if err := svc.Play(context.Background(), "guild-123", "chan-456"); err != nil {
+		t.Fatalf("Play() unexpected error: %v", err)
+	}
+	if len(hooked) != 1 {
+		t.Fatalf("hook called %d times, want 1", len(hooked))
+	}
+	if !reflect.DeepEqual(hooked[0], gen.params()) {
+		t.Error("hook params differ from the params passed to the generator")
+	}
+
+	if err := base.Play(context.Background(), "guild-123", "chan-456"); err != nil {
+		t.Fatalf("Play() on the original service unexpected error: %v", err)
+	}
+	if len(hooked) != 1 {
+		t.Errorf("hook called %d times after playing on the original service, want 1", len(hooked))
+	}
+}
+
+func Test_Generate_LogsRandomSeed(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	gen := &mockGenerator{}
+	cfg := validGenerateConfig()
+	cfg.Preset = ""
+
+	svc := NewServiceWithDeps(cfg, gen, &mockFileEncoder{}, &mockFrameEncoder{}, nil, nil, logger)
+	if err := svc.Generate(context.Background(), &bytes.Buffer{}); err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	seed := fmt.Sprintf("seed=%d", gen.params().Seed)
+	if !strings.Contains(logs.String(), seed) {
+		t.Errorf("logs %q do not contain %q", logs.String(), seed)
+	}
+	if !strings.Contains(logs.String(), `\"layers\":[`) {
+		t.Errorf("logs %q do not contain the params as JSON", logs.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// WithPresets tests
+// ---------------------------------------------------------------------------
+
+// userRegistry returns the built-in presets plus a user preset "howler" with
+// a 2s duration and a 4000 Hz low-pass.
+func userRegistry(t *testing.T) *preset.Registry {
+	t.Helper()
+	var def preset.Definition
+	src := "duration: 2s\nfilter:\n  crusher_bits: 8\n  lowpass_cutoff: 4000\n"
+	if err := yaml.Unmarshal([]byte(src), &def); err != nil {
+		t.Fatalf("yaml.Unmarshal: %v", err)
+	}
+	reg := preset.Builtin()
+	if err := reg.Add("howler", def); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	return reg
+}
+
+func Test_ListPresets_IncludesUserPresets(t *testing.T) {
+	got := ListPresets(userRegistry(t))
+	if len(got) != 8 {
+		t.Fatalf("ListPresets() returned %d presets, want 8", len(got))
+	}
+	if got[0] != "classic" || got[7] != "howler" {
+		t.Errorf("ListPresets() = %v, want built-ins first and howler last", got)
+	}
+}
+
+func Test_WithPresets_ResolvesUserPreset(t *testing.T) {
+	gen := &mockGenerator{}
+	cfg := validGenerateConfig()
+	cfg.Preset = "howler"
+	cfg.Duration = 0
+
+	svc := newTestService(cfg, gen, &mockFileEncoder{}, &mockFrameEncoder{}, &mockPlayer{}).WithPresets(userRegistry(t))
+	if err := svc.Generate(context.Background(), &bytes.Buffer{}); err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+
+	got := gen.params()
+	if got.Duration != 2*time.Second {
+		t.Errorf("Duration = %v, want 2s", got.Duration)
+	}
+	if got.Filter.LowpassCutoff != 4000 {
+		t.Errorf("LowpassCutoff = %v, want 4000", got.Filter.LowpassCutoff)
+	}
+}
+
+func Test_WithPresets_BuiltinServiceRejectsUserPreset(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "howler"
+
+	base := newTestService(cfg, &mockGenerator{}, &mockFileEncoder{}, &mockFrameEncoder{}, &mockPlayer{})
+	_ = base.WithPresets(userRegistry(t))
+
+	err := base.Generate(context.Background(), &bytes.Buffer{})
+	if !errors.Is(err, ErrUnknownPreset) {
+		t.Errorf("Generate() error = %v, want ErrUnknownPreset on the original service", err)
+	}
+	if got := ListPresets(base.WithPresets(nil).Presets()); len(got) != 7 {
+		t.Errorf("WithPresets(nil) lists %d presets, want 7", len(got))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Cancellation tests
+// ---------------------------------------------------------------------------
+
+type ctxKey struct{}
+
+func Test_Generate_PassesContextToGenerator(t *testing.T) {
+	gen := &mockGenerator{}
+	svc := newTestService(validGenerateConfig(), gen, &mockFileEncoder{}, &mockFrameEncoder{}, &mockPlayer{})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "marker")
+	if err := svc.Generate(ctx, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gen.mu.Lock()
+	got := gen.lastCtx
+	gen.mu.Unlock()
+	if got == nil || got.Value(ctxKey{}) != "marker" {
+		t.Error("generator did not receive the caller's context")
+	}
+}
+
+func Test_Play_GeneratorCancelled(t *testing.T) {
+	gen := &mockGenerator{err: fmt.Errorf("%w: %w", audio.ErrCancelled, context.Canceled)}
+	pl := &mockPlayer{}
+	svc := newTestService(validPlayConfig(), gen, &mockFileEncoder{}, &mockFrameEncoder{}, pl)
+
+	err := svc.Play(context.Background(), "guild-123", "chan-456")
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("Play() error = %v, want wrapping ErrCancelled", err)
+	}
+	if !errors.Is(err, audio.ErrCancelled) {
+		t.Errorf("Play() error = %v, want wrapping audio.ErrCancelled", err)
+	}
+	if errors.Is(err, ErrGenerateFailed) {
+		t.Errorf("Play() error = %v, cancellation should not be reported as ErrGenerateFailed", err)
+	}
+	if pl.called() != 0 {
+		t.Error("player should not be invoked after cancelled generation")
+	}
+}
+
+func Test_Play_CancelledDuringPlayback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The player cancels the context mid-stream and reports the context error,
+	// as discord.Player does.
+	pl := &mockPlayer{err: context.Canceled}
+	player := &cancellingPlayer{mockPlayer: pl, cancel: cancel}
+	svc := NewServiceWithDeps(validPlayConfig(), &mockGenerator{}, &mockFileEncoder{}, &mockFrameEncoder{}, player, nil, discardLogger)
+
+	err := svc.Play(ctx, "guild-123", "chan-456")
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("Play() error = %v, want wrapping ErrCancelled", err)
+	}
+	if errors.Is(err, ErrPlayFailed) {
+		t.Errorf("Play() error = %v, cancellation should not be reported as ErrPlayFailed", err)
+	}
+}
+
+func Test_Generate_ContextCancelled(t *testing.T) {
+	gen := &mockGenerator{}
+	svc := newTestService(validGenerateConfig(), gen, &mockFileEncoder{}, &mockFrameEncoder{}, &mockPlayer{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := svc.Generate(ctx, &bytes.Buffer{})
+	if !errors.Is(err, ErrCancelled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Generate() error = %v, want wrapping ErrCancelled and context.Canceled", err)
+	}
+	if gen.called() != 0 {
+		t.Error("generator should not be invoked with a cancelled context")
+	}
+}
+
+func Test_Generate_EncoderFailsAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fEnc := &cancellingFileEncoder{cancel: cancel}
+	svc := NewServiceWithDeps(validGenerateConfig(), &mockGenerator{}, fEnc, &mockFrameEncoder{}, nil, nil, discardLogger)
+
+	err := svc.Generate(ctx, &bytes.Buffer{})
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("Generate() error = %v, want wrapping ErrCancelled", err)
+	}
+	if errors.Is(err, ErrEncodeFailed) {
+		t.Errorf("Generate() error = %v, cancellation should not be reported as ErrEncodeFailed", err)
+	}
+}
+
+// cancellingPlayer cancels the context before delegating to mockPlayer.
+type cancellingPlayer struct {
+	*mockPlayer
+	cancel context.CancelFunc
+}
+
+func (c *cancellingPlayer) Play(ctx context.Context, guildID, channelID string, frames <-chan []byte) error {
+	c.cancel()
+	return c.mockPlayer.Play(ctx, guildID, channelID, frames)
+}
+
+// cancellingFileEncoder cancels the context and fails the way an encoder
+// reading from a cancelled generator would.
+type cancellingFileEncoder struct {
+	cancel context.CancelFunc
+}
+
+func (c *cancellingFileEncoder) Encode(dst io.Writer, src io.Reader, sampleRate, channels int) error {
+	c.cancel()
+	return fmt.Errorf("reading PCM: %w", audio.ErrCancelled)
+}
+
+// ---------------------------------------------------------------------------
+// Seed tests
+// ---------------------------------------------------------------------------
+
+// rawFileEncoder implements encoding.FileEncoder by copying the PCM through
+// unchanged, so tests can compare generated audio directly.
+type rawFileEncoder struct{}
+
+func (rawFileEncoder) Encode(dst io.Writer, src io.Reader, sampleRate, channels int) error {
+	_, err := io.Copy(dst, src)
+	return err
+}
+
+// generateNativePCM runs Generate with the native generator and returns the
+// raw PCM it produced.
+func generateNativePCM(t *testing.T, cfg config.Config) []byte {
+	t.Helper()
+	svc := NewServiceWithDeps(cfg, native.NewGenerator(discardLogger), rawFileEncoder{}, &mockFrameEncoder{}, nil, nil, discardLogger)
+	var buf bytes.Buffer
+	if err := svc.Generate(context.Background(), &buf); err != nil {
+		t.Fatalf("Generate() unexpected error: %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Fatal("Generate() produced no audio")
+	}
+	return buf.Bytes()
+}
+
+func Test_ResolveParams_SeedRandom(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = ""
+	cfg.Seed = 12345
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	want := audio.Randomize(12345)
+	want.Duration = cfg.Duration
+	if !reflect.DeepEqual(got, want) {
+		t.Error("seeded random params differ from audio.Randomize(12345)")
+	}
+}
+
+func Test_ResolveParams_SeedPreset(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "banshee"
+	cfg.Seed = 99
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	want, _ := audio.GetPreset(audio.PresetBanshee)
+	want.Duration = cfg.Duration
+	want.Seed = 99
+	if !reflect.DeepEqual(got, want) {
+		t.Error("seeded preset params should differ from the preset only in Seed")
+	}
+}
+
+func Test_ResolveParams_ZeroSeedKeepsPresetSeed(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "banshee"
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	p, _ := audio.GetPreset(audio.PresetBanshee)
+	if got.Seed != p.Seed {
+		t.Errorf("Seed = %d, want the preset's %d", got.Seed, p.Seed)
+	}
+}
+
+func Test_Generate_SameSeedIdenticalPCM(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset string
+	}{
+		{name: "random", preset: ""},
+		{name: "preset", preset: "banshee"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validGenerateConfig()
+			cfg.Preset = tt.preset
+			cfg.Duration = 250 * time.Millisecond
+			cfg.Seed = 4242
+
+			first := generateNativePCM(t, cfg)
+			second := generateNativePCM(t, cfg)
+			if !bytes.Equal(first, second) {
+				t.Error("two generations with the same seed produced different PCM")
+			}
+
+			cfg.Seed = 4243
+			other := generateNativePCM(t, cfg)
+			if bytes.Equal(first, other) {
+				t.Error("generations with different seeds produced identical PCM")
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Morph tests
+// ---------------------------------------------------------------------------
+
+func Test_ResolveParams_MorphBlend(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "death-metal"
+	cfg.MorphTo = "whisper"
+	cfg.Mix = 0.3
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	a, _ := audio.GetPreset(audio.PresetDeathMetal)
+	b, _ := audio.GetPreset(audio.PresetWhisper)
+	want := audio.Interpolate(a, b, 0.3)
+	want.Duration = cfg.Duration
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveParams() = %+v, want the 30%% blend %+v", got, want)
+	}
+}
+
+func Test_ResolveParams_MorphOverTime(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "death-metal"
+	cfg.MorphTo = "whisper"
+	cfg.Seed = 9
+	cfg.Volume = 0.5
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	if got.MorphTo == nil {
+		t.Fatal("ResolveParams() did not set MorphTo")
+	}
+	if got.Seed != 9 || got.MorphTo.Seed != 9 {
+		t.Errorf("seeds = %d, %d; want both 9", got.Seed, got.MorphTo.Seed)
+	}
+
+	// The volume offset applies to both ends of the morph.
+	a, _ := audio.GetPreset(audio.PresetDeathMetal)
+	b, _ := audio.GetPreset(audio.PresetWhisper)
+	gainDB := 20 * math.Log10(0.5)
+	if diff := got.Filter.VolumeBoostDB - (a.Filter.VolumeBoostDB + gainDB); math.Abs(diff) > 1e-9 {
+		t.Errorf("start VolumeBoostDB off by %v", diff)
+	}
+	if diff := got.MorphTo.Filter.VolumeBoostDB - (b.Filter.VolumeBoostDB + gainDB); math.Abs(diff) > 1e-9 {
+		t.Errorf("end VolumeBoostDB off by %v", diff)
+	}
+}
+
+func Test_ResolveParams_UnknownMorphTarget(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.MorphTo = "kazoo"
+
+	if _, err := ResolveParams(cfg, nil); !errors.Is(err, ErrUnknownPreset) {
+		t.Errorf("ResolveParams() error = %v, want ErrUnknownPreset", err)
+	}
+}
+
+func Test_Generate_MorphNative(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "death-metal"
+	cfg.Duration = 250 * time.Millisecond
+
+	static := generateNativePCM(t, cfg)
+	cfg.MorphTo = "whisper"
+	morph := generateNativePCM(t, cfg)
+	if len(morph) != len(static) {
+		t.Fatalf("morph produced %d bytes, want %d", len(morph), len(static))
+	}
+	if bytes.Equal(morph, static) {
+		t.Error("morph produced the same audio as the start preset")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Crowd tests
+// ---------------------------------------------------------------------------
+
+func Test_ResolveParams_Crowd(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "death-metal"
+	cfg.Voices = 6
+	cfg.Spread = 0.4
+	cfg.VoicePresets = []string{"whisper", "banshee"}
+	cfg.Seed = 9
+	cfg.Volume = 0.5
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	if got.Voices != 6 || got.Spread != 0.4 {
+		t.Errorf("voices, spread = %d, %v; want 6, 0.4", got.Voices, got.Spread)
+	}
+	if len(got.VoiceMix) != 2 {
+		t.Fatalf("VoiceMix has %d screams, want 2", len(got.VoiceMix))
+	}
+
+	// The voice mix is seeded and offset in volume like the preset.
+	gainDB := 20 * math.Log10(0.5)
+	for i, name := range []audio.PresetName{audio.PresetWhisper, audio.PresetBanshee} {
+		want, _ := audio.GetPreset(name)
+		voice := got.VoiceMix[i]
+		if voice.Seed != 9 {
+			t.Errorf("voice mix %d seed = %d, want 9", i, voice.Seed)
+		}
+		if diff := voice.Filter.VolumeBoostDB - (want.Filter.VolumeBoostDB + gainDB); math.Abs(diff) > 1e-9 {
+			t.Errorf("voice mix %d VolumeBoostDB off by %v", i, diff)
+		}
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func Test_ResolveParams_NoCrowd(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "classic"
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	if got.IsCrowd() || got.Spread != 0 || got.VoiceMix != nil {
+		t.Errorf("voices %d, spread %v, voice mix %v; want a single voice", got.Voices, got.Spread, got.VoiceMix)
+	}
+}
+
+func Test_ResolveParams_UnknownVoicePreset(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Voices = 3
+	cfg.VoicePresets = []string{"kazoo"}
+
+	if _, err := ResolveParams(cfg, nil); !errors.Is(err, ErrUnknownPreset) {
+		t.Errorf("ResolveParams() error = %v, want ErrUnknownPreset", err)
+	}
+}
+
+func Test_Generate_CrowdNative(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "classic"
+	cfg.Duration = 250 * time.Millisecond
+
+	single := generateNativePCM(t, cfg)
+	cfg.Voices = 4
+	cfg.Spread = 1
+	crowd := generateNativePCM(t, cfg)
+	if len(crowd) <= len(single) {
+		t.Errorf("crowd produced %d bytes, want more than %d for its late voices", len(crowd), len(single))
+	}
+	if bytes.Equal(crowd[:len(single)], single) {
+		t.Error("crowd produced the same audio as a single voice")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Text tests
+// ---------------------------------------------------------------------------
+
+func Test_ResolveParams_Text(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Preset = "banshee"
+	cfg.Text = "NOOOOooo"
+	cfg.Seed = 7
+
+	got, err := ResolveParams(cfg, nil)
+	if err != nil {
+		t.Fatalf("ResolveParams() unexpected error: %v", err)
+	}
+	// The preset and the configured duration are ignored.
+	want, err := audio.FromText("NOOOOooo", 7)
+	if err != nil {
+		t.Fatalf("FromText() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveParams() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_ResolveParams_InvalidText(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Text = "?!"
+
+	if _, err := ResolveParams(cfg, nil); !errors.Is(err, ErrInvalidText) || !errors.Is(err, audio.ErrInvalidText) {
+		t.Errorf("ResolveParams() error = %v, want ErrInvalidText wrapping audio.ErrInvalidText", err)
+	}
+}
+
+func Test_Generate_TextNative(t *testing.T) {
+	cfg := validGenerateConfig()
+	cfg.Text = "AAAHhh"
+	cfg.Seed = 3
+
+	first := generateNativePCM(t, cfg)
+	second := generateNativePCM(t, cfg)
+	if !bytes.Equal(first, second) {
+		t.Error("two generations of the same text and seed produced different PCM")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Loudness normalization tests
+// ---------------------------------------------------------------------------
+
+func Test_Generate_NormalizesLoudness(t *testing.T) {
+	for _, name := range []string{"whisper", "death-metal"} {
+		t.Run(name, func(t *testing.T) {
+			cfg := validGenerateConfig()
+			cfg.Preset = name
+			cfg.TargetLUFS = -16
+			cfg.TruePeak = -1
+
+			pcm := generateNativePCM(t, cfg)
+			got := audio.MeasureLoudness(pcm, audio.DefaultSampleRate, audio.DefaultChannels)
+			if got.TruePeak > -0.9 {
+				t.Errorf("TruePeak = %.2f dBTP, want at most -1", got.TruePeak)
+			}
+			// The target is met unless the ceiling held the gain back.
+			if got.Integrated > -15.9 || (got.Integrated < -16.1 && got.TruePeak < -1.1) {
+				t.Errorf("Integrated = %.2f LUFS with true peak %.2f dBTP, want -16", got.Integrated, got.TruePeak)
+			}
+		})
+	}
+}
+
+func Test_Generate_NoTargetLeavesLoudness(t *testing.T) {
+	cfg := validGenerateConfig()
+	want := generateNativePCM(t, cfg)
+
+	cfg.TruePeak = -10
+	if got := generateNativePCM(t, cfg); !bytes.Equal(got, want) {
+		t.Error("a true-peak ceiling without a target LUFS changed the audio")
+	}
+}
+
+func Test_Generate_NormalizeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := validGenerateConfig()
+	cfg.TargetLUFS = -16
+	gen := &mockGenerator{reader: cancellingReader{cancel: cancel}}
+	svc := newTestService(cfg, gen, &mockFileEncoder{}, &mockFrameEncoder{}, nil)
+
+	err := svc.Generate(ctx, &bytes.Buffer{})
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("Generate() error = %v, want ErrCancelled", err)
+	}
+}
+
+// cancellingReader cancels the context and fails the way a native PCM
+// stream does once its context is done.
+type cancellingReader struct {
+	cancel context.CancelFunc
+}
+
+func (c cancellingReader) Read([]byte) (int, error) {
+	c.cancel()
+	return 0, fmt.Errorf("%w: %w", audio.ErrCancelled, context.Canceled)
+}